@@ -3,10 +3,14 @@ package main
 import (
 	wmtheme "fyshos.com/fynedesk/theme"
 
+	_ "fyshos.com/fynedesk/modules/clipboard"
 	_ "fyshos.com/fynedesk/modules/composit"
 	_ "fyshos.com/fynedesk/modules/desktops"
+	_ "fyshos.com/fynedesk/modules/emoji"
 	_ "fyshos.com/fynedesk/modules/launcher"
+	_ "fyshos.com/fynedesk/modules/media"
 	_ "fyshos.com/fynedesk/modules/quaketerm"
+	_ "fyshos.com/fynedesk/modules/recorder"
 	_ "fyshos.com/fynedesk/modules/status"
 	_ "fyshos.com/fynedesk/modules/systray"
 
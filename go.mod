@@ -9,12 +9,13 @@ require (
 	github.com/FyshOS/backgrounds v0.0.0-20230616202904-0a8b6ebaa184
 	github.com/Knetic/govaluate v3.0.0+incompatible
 	github.com/disintegration/imaging v1.6.2
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/fyne-io/image v0.0.0-20221020213044-f609c6a24345
 	github.com/godbus/dbus/v5 v5.1.0
 	github.com/jackmordaunt/icns v1.0.1-0.20200413110149-9e181b441ab2
-	github.com/mafik/pulseaudio v0.0.0-20200511091429-8449222912dd
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/image v0.14.0
 	howett.net/plist v0.0.0-20181124034731-591f970eefbb
 )
 
@@ -27,7 +28,6 @@ require (
 	github.com/creack/pty v1.1.11 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/fredbi/uri v1.1.0 // indirect
-	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/fyne-io/gl-js v0.0.0-20220119005834-d2da28d9ccfe // indirect
 	github.com/fyne-io/glfw-js v0.0.0-20240101223322-6e1efdc71b7a // indirect
 	github.com/fyne-io/terminal v0.0.0-20240206170039-2e129cdfd85f
@@ -42,7 +42,6 @@ require (
 	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c // indirect
 	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef // indirect
 	github.com/yuin/goldmark v1.5.5 // indirect
-	golang.org/x/image v0.14.0 // indirect
 	golang.org/x/mobile v0.0.0-20231127183840-76ac6878050a // indirect
 	golang.org/x/net v0.19.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
@@ -7,11 +7,20 @@ type AppData interface {
 	Name() string       // Name is the name of the app usually
 	Run([]string) error // Run is the command to run the app, passing any environment variables to be set
 
+	Actions() []AppAction                      // Actions lists additional ways to start the app, such as a new private window
 	Categories() []string                      // Categories is a list of categories that the app fits in (platform specific)
 	Hidden() bool                              // Hidden specifies whether instances of this app should be hidden
 	Icon(theme string, size int) fyne.Resource // Icon returns an icon for the app in the requested theme and size
 }
 
+// AppAction describes an additional task that an application offers, such as "New Private Window"
+type AppAction interface {
+	Name() string       // Name is the label of the action
+	Run([]string) error // Run starts the action, passing any environment variables to be set
+
+	Icon(theme string, size int) fyne.Resource // Icon returns an icon for the action, or the app icon if it has none
+}
+
 // ApplicationProvider describes a type that can locate icons and applications for the current system
 type ApplicationProvider interface {
 	AvailableApps() []AppData
@@ -21,4 +30,7 @@ type ApplicationProvider interface {
 	FindAppsMatching(pattern string) []AppData
 	DefaultApps() []AppData
 	CategorizedApps() map[string][]AppData
+
+	AppsForMime(mimeType string) []AppData     // AppsForMime lists the apps that can open a MIME type, default first
+	DefaultAppForMime(mimeType string) AppData // DefaultAppForMime returns the app chosen to open a MIME type
 }
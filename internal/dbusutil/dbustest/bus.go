@@ -0,0 +1,48 @@
+// Package dbustest runs a private message bus, so that D-Bus clients can be tested against mock services.
+package dbustest // import "fyshos.com/fynedesk/internal/dbusutil/dbustest"
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// StartPrivateBus runs a message bus for this test only, returning a connection for a service and for a client.
+// The test is skipped if dbus-daemon is not installed.
+func StartPrivateBus(t *testing.T) (*dbus.Conn, *dbus.Conn) {
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is required to test D-Bus services")
+	}
+
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	assert.Nil(t, err)
+	if err = cmd.Start(); err != nil {
+		t.Skip("Unable to start dbus-daemon", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Skip("Unable to read dbus-daemon address", err)
+	}
+	address = strings.TrimSpace(address)
+
+	server, err := dbus.Connect(address)
+	assert.Nil(t, err)
+	client, err := dbus.Connect(address)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+	return server, client
+}
@@ -0,0 +1,68 @@
+// Package dbusutil contains helpers for modules that talk to system services over D-Bus.
+package dbusutil // import "fyshos.com/fynedesk/internal/dbusutil"
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// SignalWatch is a set of signal match rules on a bus, and the channel that receives the signals.
+type SignalWatch struct {
+	conn    *dbus.Conn
+	matches [][]dbus.MatchOption
+	signals chan *dbus.Signal
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// WatchSignals adds each match rule to the bus and calls handle with every signal that is received,
+// until Stop is called. As the connection may be shared, handle will also see signals that other code asked for.
+func WatchSignals(conn *dbus.Conn, matches [][]dbus.MatchOption, handle func(*dbus.Signal)) (*SignalWatch, error) {
+	w := &SignalWatch{conn: conn, signals: make(chan *dbus.Signal, 10), stop: make(chan struct{})}
+	for _, match := range matches {
+		if err := conn.AddMatchSignal(match...); err != nil {
+			w.removeMatches()
+			return nil, err
+		}
+		w.matches = append(w.matches, match)
+	}
+
+	conn.Signal(w.signals)
+	go func() {
+		for {
+			select {
+			case sig, ok := <-w.signals:
+				if !ok {
+					return // the connection was closed
+				}
+				handle(sig)
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	return w, nil
+}
+
+// Stop removes the match rules and stops passing signals to the handler.
+// It is safe to call more than once, or on a nil watch.
+func (w *SignalWatch) Stop() {
+	if w == nil {
+		return
+	}
+
+	w.stopOnce.Do(func() {
+		w.removeMatches()
+		w.conn.RemoveSignal(w.signals)
+		close(w.stop)
+	})
+}
+
+func (w *SignalWatch) removeMatches() {
+	for _, match := range w.matches {
+		_ = w.conn.RemoveMatchSignal(match...)
+	}
+	w.matches = nil
+}
@@ -0,0 +1,39 @@
+package dbusutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk/internal/dbusutil/dbustest"
+)
+
+func TestWatchSignals(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+
+	received := make(chan string, 1)
+	w, err := WatchSignals(client, [][]dbus.MatchOption{{dbus.WithMatchInterface("io.fyshos.Test")}},
+		func(sig *dbus.Signal) {
+			received <- sig.Name
+		})
+	assert.Nil(t, err)
+	assert.Nil(t, server.Emit("/", "io.fyshos.Test.Changed"))
+
+	select {
+	case name := <-received:
+		assert.Equal(t, "io.fyshos.Test.Changed", name)
+	case <-time.After(time.Second):
+		t.Error("Signal was not received")
+	}
+
+	w.Stop()
+	w.Stop()
+	assert.Nil(t, server.Emit("/", "io.fyshos.Test.Changed"))
+	select {
+	case <-received:
+		t.Error("Signal was received after stopping")
+	case <-time.After(time.Second / 10):
+	}
+}
@@ -0,0 +1,77 @@
+package icon
+
+import (
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+)
+
+// fdoAppAction is an additional action from a [Desktop Action] group of a .desktop file.
+type fdoAppAction struct {
+	app      *fdoApplicationData
+	name     string
+	iconName string
+	exec     string
+
+	iconCache fyne.Resource
+	iconTheme string // the theme that iconCache was loaded from
+}
+
+// Name returns the label of this action
+func (a *fdoAppAction) Name() string {
+	return a.name
+}
+
+// Icon returns the icon for this action, or the icon of the app if the action does not set one
+func (a *fdoAppAction) Icon(theme string, size int) fyne.Resource {
+	if a.iconName == "" {
+		return a.app.Icon(theme, size)
+	}
+	if a.iconCache != nil && a.iconTheme == theme {
+		return a.iconCache
+	}
+
+	path := ""
+	if filepath.IsAbs(a.iconName) {
+		if _, err := os.Stat(a.iconName); err == nil {
+			path = a.iconName
+		}
+	} else {
+		path = FdoLookupIconPath(theme, size, a.iconName)
+	}
+	if path == "" {
+		return a.app.Icon(theme, size)
+	}
+
+	a.iconCache, a.iconTheme = loadIcon(path), theme
+	return a.iconCache
+}
+
+// Run executes the command of this action, using the working directory and terminal setting of its app
+func (a *fdoAppAction) Run(env []string) error {
+	return a.app.runExec(a.exec, env, nil)
+}
+
+// newFdoActions returns the actions listed in the Actions key, in order.
+// Actions without a matching group, a name or a command are skipped.
+func newFdoActions(app *fdoApplicationData, ids []string, groups map[string]desktopEntry) []fynedesk.AppAction {
+	var actions []fynedesk.AppAction
+	for _, id := range ids {
+		group := groups[desktopActionGroup+id]
+		if group == nil {
+			continue
+		}
+
+		action := &fdoAppAction{app: app, name: group.localeString("Name"), iconName: group.localeString("Icon"),
+			exec: group.string("Exec")}
+		if action.name == "" || action.exec == "" {
+			continue
+		}
+		actions = append(actions, action)
+	}
+
+	return actions
+}
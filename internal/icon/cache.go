@@ -1,24 +1,51 @@
 package icon
 
-import "fyshos.com/fynedesk"
+import (
+	"sync"
+
+	"fyshos.com/fynedesk"
+)
 
 type appCache struct {
-	source  fynedesk.ApplicationProvider
+	load    func() []fynedesk.AppData
+	lock    sync.RWMutex
 	appList []fynedesk.AppData
 }
 
-func (c *appCache) forEachCachedApplication(f func(string, fynedesk.AppData) bool) {
+// apps returns the cached applications, loading them the first time it is called.
+func (c *appCache) apps() []fynedesk.AppData {
+	c.lock.RLock()
+	list := c.appList
+	c.lock.RUnlock()
+	if list != nil {
+		return list
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	if c.appList == nil {
-		c.appList = c.source.AvailableApps()
+		c.appList = c.load()
 	}
+	return c.appList
+}
 
-	for _, a := range c.appList {
+func (c *appCache) forEachCachedApplication(f func(string, fynedesk.AppData) bool) {
+	for _, a := range c.apps() {
 		if f(a.Name(), a) {
 			return
 		}
 	}
 }
 
-func newAppCache(c fynedesk.ApplicationProvider) *appCache {
-	return &appCache{source: c}
+// reload reads the applications again, replacing the cached list and any icons loaded for them.
+func (c *appCache) reload() {
+	list := c.load()
+
+	c.lock.Lock()
+	c.appList = list
+	c.lock.Unlock()
+}
+
+func newAppCache(load func() []fynedesk.AppData) *appCache {
+	return &appCache{load: load}
 }
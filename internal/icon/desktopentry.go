@@ -0,0 +1,425 @@
+package icon
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	desktopEntryGroup  = "Desktop Entry"
+	desktopActionGroup = "Desktop Action "
+)
+
+// terminalPreference is the preference key that names the terminal used to run apps with Terminal=true.
+const terminalPreference = "terminal"
+
+// knownTerminals are tried in order if the user has not configured a terminal.
+var knownTerminals = []string{"fyneterm", "x-terminal-emulator", "xfce4-terminal", "gnome-terminal",
+	"konsole", "xterm"}
+
+// desktopEntry holds the keys of one group of a .desktop file, such as [Desktop Entry].
+// Localised keys such as "Name[de]" are stored with their locale suffix.
+type desktopEntry map[string]string
+
+// parseDesktopGroups reads every group from a .desktop file, keyed by the group name without brackets.
+func parseDesktopGroups(r io.Reader) (map[string]desktopEntry, error) {
+	groups := make(map[string]desktopEntry)
+	var entry desktopEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			name := line[1 : len(line)-1]
+			if groups[name] == nil { // a repeated group is invalid, so we merge the first values
+				groups[name] = desktopEntry{}
+			}
+			entry = groups[name]
+			continue
+		}
+		if entry == nil { // keys before the first group are invalid
+			continue
+		}
+
+		pos := strings.Index(line, "=")
+		if pos <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:pos])
+		if _, exists := entry[key]; exists { // the first value wins if a key is repeated
+			continue
+		}
+		entry[key] = strings.TrimSpace(line[pos+1:])
+	}
+
+	return groups, scanner.Err()
+}
+
+// bool returns true if the key is set to "true".
+func (e desktopEntry) bool(key string) bool {
+	return e[key] == "true"
+}
+
+// list returns the values of a semicolon separated key, which may contain escaped semicolons.
+func (e desktopEntry) list(key string) []string {
+	raw, ok := e[key]
+	if !ok {
+		return nil
+	}
+
+	var items []string
+	item := &strings.Builder{}
+	escaped := false
+	for _, r := range raw {
+		switch {
+		case escaped:
+			if r == ';' {
+				item.WriteRune(';')
+			} else {
+				item.WriteString(unescapeValue("\\" + string(r)))
+			}
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ';':
+			items = append(items, item.String())
+			item.Reset()
+		default:
+			item.WriteRune(r)
+		}
+	}
+	if item.Len() > 0 {
+		items = append(items, item.String())
+	}
+	return items
+}
+
+// localeString returns the value of a key in the best match for the user's language.
+func (e desktopEntry) localeString(key string) string {
+	for _, locale := range localeVariants(currentLocale()) {
+		if val, ok := e[key+"["+locale+"]"]; ok {
+			return unescapeValue(val)
+		}
+	}
+
+	return unescapeValue(e[key])
+}
+
+// localeList returns a list value in the best match for the user's language.
+func (e desktopEntry) localeList(key string) []string {
+	for _, locale := range localeVariants(currentLocale()) {
+		if _, ok := e[key+"["+locale+"]"]; ok {
+			return e.list(key + "[" + locale + "]")
+		}
+	}
+
+	return e.list(key)
+}
+
+// string returns the value of a key with escape sequences replaced.
+func (e desktopEntry) string(key string) string {
+	return unescapeValue(e[key])
+}
+
+// currentLocale returns the locale used for messages, such as "de_DE.UTF-8@euro".
+func currentLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if val := os.Getenv(name); val != "" {
+			return val
+		}
+	}
+
+	return ""
+}
+
+// localeVariants returns the locale suffixes to look for in order of preference, as described in the
+// Desktop Entry specification. The encoding is ignored, so "sr_YU.UTF-8@Latn" returns
+// "sr_YU@Latn", "sr_YU", "sr@Latn" and "sr".
+func localeVariants(locale string) []string {
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+
+	modifier := ""
+	if pos := strings.Index(locale, "@"); pos != -1 {
+		modifier = locale[pos+1:]
+		locale = locale[:pos]
+	}
+	if pos := strings.Index(locale, "."); pos != -1 {
+		locale = locale[:pos]
+	}
+	lang, country := locale, ""
+	if pos := strings.Index(locale, "_"); pos != -1 {
+		lang, country = locale[:pos], locale[pos+1:]
+	}
+
+	var variants []string
+	if country != "" && modifier != "" {
+		variants = append(variants, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		variants = append(variants, lang+"_"+country)
+	}
+	if modifier != "" {
+		variants = append(variants, lang+"@"+modifier)
+	}
+	return append(variants, lang)
+}
+
+// showInDesktop returns true if the OnlyShowIn and NotShowIn keys allow an entry in the desktops passed.
+func (e desktopEntry) showInDesktop(desktops []string) bool {
+	for _, not := range e.list("NotShowIn") {
+		for _, desk := range desktops {
+			if strings.EqualFold(not, desk) {
+				return false
+			}
+		}
+	}
+
+	only := e.list("OnlyShowIn")
+	if len(only) == 0 {
+		return true
+	}
+	for _, want := range only {
+		for _, desk := range desktops {
+			if strings.EqualFold(want, desk) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// currentDesktops returns the names of the desktop environment from XDG_CURRENT_DESKTOP.
+func currentDesktops() []string {
+	if desks := os.Getenv("XDG_CURRENT_DESKTOP"); desks != "" {
+		return strings.Split(desks, ":")
+	}
+
+	return []string{"FyneDesk"}
+}
+
+// unescapeValue replaces the escape sequences allowed in string values.
+func unescapeValue(val string) string {
+	if !strings.Contains(val, "\\") {
+		return val
+	}
+
+	return strings.NewReplacer(`\s`, " ", `\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`).Replace(val)
+}
+
+// execExists returns true if the TryExec value names an executable file, either absolute or on the PATH.
+func execExists(tryExec string) bool {
+	if filepath.IsAbs(tryExec) {
+		info, err := os.Stat(tryExec)
+		return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+	}
+
+	_, err := exec.LookPath(tryExec)
+	return err == nil
+}
+
+// splitExec breaks an Exec value into arguments, handling the quoting rules of the Desktop Entry specification.
+// Field codes are left in place to be expanded later.
+func splitExec(command string) ([]string, error) {
+	var args []string
+	arg := &strings.Builder{}
+	inArg, quoted, escaped := false, false, false
+	for _, r := range command {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quoted || escaped {
+		return nil, errors.New("unterminated quote in Exec " + command)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty Exec value")
+	}
+	return args, nil
+}
+
+// expandExec replaces the field codes in the arguments of an Exec value.
+// Files may be local paths or URLs; %f and %F receive paths and %u and %U receive URLs.
+func (data *fdoApplicationData) expandExec(args []string, files []string) []string {
+	var ret []string
+	for _, arg := range args {
+		switch arg {
+		case "%f":
+			if paths := filePaths(files); len(paths) > 0 {
+				ret = append(ret, paths[0])
+			}
+			continue
+		case "%F":
+			ret = append(ret, filePaths(files)...)
+			continue
+		case "%u":
+			if urls := fileURLs(files); len(urls) > 0 {
+				ret = append(ret, urls[0])
+			}
+			continue
+		case "%U":
+			ret = append(ret, fileURLs(files)...)
+			continue
+		case "%i":
+			if data.iconName != "" {
+				ret = append(ret, "--icon", data.iconName)
+			}
+			continue
+		}
+
+		expanded := data.expandField(arg, files)
+		if expanded == "" && len(arg) == 2 && arg[0] == '%' { // a field code with no value is removed
+			continue
+		}
+		ret = append(ret, expanded)
+	}
+
+	return ret
+}
+
+// expandField replaces field codes that appear within a larger argument.
+func (data *fdoApplicationData) expandField(arg string, files []string) string {
+	if !strings.Contains(arg, "%") {
+		return arg
+	}
+
+	out := &strings.Builder{}
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' || i == len(arg)-1 {
+			out.WriteByte(arg[i])
+			continue
+		}
+
+		i++
+		switch arg[i] {
+		case '%':
+			out.WriteByte('%')
+		case 'c':
+			out.WriteString(data.name)
+		case 'k':
+			out.WriteString(data.desktopPath)
+		case 'f':
+			if paths := filePaths(files); len(paths) > 0 {
+				out.WriteString(paths[0])
+			}
+		case 'u':
+			if urls := fileURLs(files); len(urls) > 0 {
+				out.WriteString(urls[0])
+			}
+		}
+		// other field codes are deprecated or cannot be used inside an argument, so are removed
+	}
+	return out.String()
+}
+
+// filePaths returns the local paths of files, dropping any remote URLs.
+func filePaths(files []string) []string {
+	var paths []string
+	for _, f := range files {
+		u, err := url.Parse(f)
+		if err != nil || u.Scheme == "" {
+			paths = append(paths, f)
+		} else if u.Scheme == "file" {
+			paths = append(paths, u.Path)
+		}
+	}
+	return paths
+}
+
+// fileURLs returns files as URLs, converting local paths to file URLs.
+func fileURLs(files []string) []string {
+	var urls []string
+	for _, f := range files {
+		if u, err := url.Parse(f); err == nil && u.Scheme != "" {
+			urls = append(urls, f)
+			continue
+		}
+
+		if abs, err := filepath.Abs(f); err == nil {
+			f = abs
+		}
+		urls = append(urls, (&url.URL{Scheme: "file", Path: f}).String())
+	}
+	return urls
+}
+
+// singleFileExec returns true if the arguments only accept one file, so an app must be run once per file.
+func singleFileExec(args []string) bool {
+	for _, arg := range args {
+		if arg == "%F" || arg == "%U" {
+			return false
+		}
+	}
+	for _, arg := range args {
+		if strings.Contains(arg, "%f") || strings.Contains(arg, "%u") {
+			return true
+		}
+	}
+	return false
+}
+
+// TerminalCommand returns the arguments to run a command inside the terminal configured by the user.
+func TerminalCommand(args []string) []string {
+	term := ""
+	if app := fyne.CurrentApp(); app != nil {
+		term = strings.TrimSpace(app.Preferences().String(terminalPreference))
+	}
+	if term == "" {
+		term = os.Getenv("TERMINAL")
+	}
+	if term == "" {
+		for _, known := range knownTerminals {
+			if _, err := exec.LookPath(known); err == nil {
+				term = known
+				break
+			}
+		}
+	}
+	if term == "" {
+		term = "xterm"
+	}
+
+	termArgs, err := splitExec(term)
+	if err != nil {
+		termArgs = []string{term}
+	}
+	flag := "-e"
+	switch filepath.Base(termArgs[0]) {
+	case "gnome-terminal":
+		flag = "--"
+	case "xfce4-terminal": // -e takes the command as a single string, -x takes the remaining arguments
+		flag = "-x"
+	}
+	return append(append(termArgs, flag), args...)
+}
@@ -2,6 +2,7 @@ package icon // import "fyshos.com/fynedesk/internal/icon"
 
 import (
 	"bufio"
+	"errors"
 	"io/fs"
 	"math"
 	"os"
@@ -9,8 +10,11 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"github.com/fsnotify/fsnotify"
 
 	"fyshos.com/fynedesk"
 	_ "github.com/fyne-io/image/xpm" // load XPM icons to supported image format
@@ -26,14 +30,22 @@ var (
 
 // fdoApplicationData is a structure that contains information about .desktop files
 type fdoApplicationData struct {
-	name     string // Application name
-	iconName string // Icon name
-	iconPath string // Icon path
-	exec     string // Command to execute application
-
+	name        string // Application name
+	genericName string // Generic name of the application, such as "Web Browser"
+	iconName    string // Icon name
+	iconPath    string // Icon path
+	exec        string // Command to execute application
+	path        string // Working directory to run the application in
+	desktopPath string // Location of the .desktop file
+
+	actions    []fynedesk.AppAction
 	categories []string
+	keywords   []string
+	mimeTypes  []string
 	hide       bool
+	terminal   bool
 	iconCache  fyne.Resource
+	iconTheme  string // the theme that iconCache was loaded from
 }
 
 // Name returns the name associated with an fdo app
@@ -41,11 +53,26 @@ func (data *fdoApplicationData) Name() string {
 	return data.name
 }
 
+// Actions returns the additional actions declared by the [Desktop Action] groups of an fdo app
+func (data *fdoApplicationData) Actions() []fynedesk.AppAction {
+	return data.actions
+}
+
 // Categories returns a list of the categories this icon has configured
 func (data *fdoApplicationData) Categories() []string {
 	return data.categories
 }
 
+// Executable returns the name of the program that an fdo app runs, without its directory
+func (data *fdoApplicationData) Executable() string {
+	return commandName(data.exec)
+}
+
+// GenericName returns the generic name of an fdo app, such as "Web Browser"
+func (data *fdoApplicationData) GenericName() string {
+	return data.genericName
+}
+
 func (data *fdoApplicationData) Hidden() bool {
 	return data.hide
 }
@@ -57,7 +84,7 @@ func (data *fdoApplicationData) IconName() string {
 
 // IconPath returns the path of the icon that an fdo app wishes to use
 func (data *fdoApplicationData) Icon(theme string, size int) fyne.Resource {
-	if data.iconCache != nil {
+	if data.iconCache != nil && data.iconTheme == theme {
 		return data.iconCache
 	}
 
@@ -69,43 +96,74 @@ func (data *fdoApplicationData) Icon(theme string, size int) fyne.Resource {
 		}
 	}
 
-	data.iconCache = loadIcon(path)
+	data.iconCache, data.iconTheme = loadIcon(path), theme
 	return data.iconCache
 }
 
-// extractArgs sanitises argument parameters from an Exec configuration
-func extractArgs(args []string) []string {
-	var ret []string
-	for _, arg := range args {
-		if len(arg) >= 2 && arg[0] == '%' {
-			continue
-		}
-		ret = append(ret, arg)
-	}
-
-	return ret
+// Keywords returns the additional search terms for an fdo app
+func (data *fdoApplicationData) Keywords() []string {
+	return data.keywords
 }
 
 // Run executes the command for this fdo app
 func (data *fdoApplicationData) Run(env []string) error {
-	vars := os.Environ()
-	vars = append(vars, env...)
+	return data.RunWithFiles(env, nil)
+}
+
+// RunWithFiles executes the command for this fdo app, passing the files or URLs to open.
+// If the app can only open a single file it is started once for each file.
+func (data *fdoApplicationData) RunWithFiles(env []string, files []string) error {
+	return data.runExec(data.exec, env, files)
+}
+
+// runExec starts an Exec value from this app's .desktop file, expanding any field codes.
+func (data *fdoApplicationData) runExec(command string, env, files []string) error {
+	args, err := splitExec(command)
+	if err != nil {
+		return err
+	}
 
-	commands := strings.Split(data.exec, " ")
-	command := commands[0]
-	if command[0] == '"' {
-		command = command[1 : len(command)-1]
+	if len(files) > 1 && singleFileExec(args) {
+		for _, f := range files {
+			if err = data.start(env, data.expandExec(args, []string{f})); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
+	return data.start(env, data.expandExec(args, files))
+}
 
-	cmd := exec.Command(command)
-	if len(commands) > 1 {
-		cmd.Args = extractArgs(commands) // Args[0] should be binary path
+func (data *fdoApplicationData) start(env, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no command to run for " + data.name)
+	}
+	if data.terminal {
+		args = TerminalCommand(args)
 	}
 
-	cmd.Env = vars
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Dir = data.path
 	return cmd.Start()
 }
 
+// matches returns true if the lower case search text is found in the app names, command or keywords
+func (data *fdoApplicationData) matches(search string) bool {
+	if strings.Contains(strings.ToLower(data.name), search) ||
+		strings.Contains(strings.ToLower(data.genericName), search) ||
+		strings.Contains(strings.ToLower(data.exec), search) {
+		return true
+	}
+
+	for _, key := range data.keywords {
+		if strings.Contains(strings.ToLower(key), search) {
+			return true
+		}
+	}
+	return false
+}
+
 func (data fdoApplicationData) mainCategory() string {
 	if len(data.Categories()) == 0 {
 		return fallbackCategory
@@ -150,8 +208,21 @@ func fdoLookupXdgDataDirs() []string {
 	return locationLookup
 }
 
+// fdoDataDirs returns the user's data dir followed by the XDG_DATA_DIRS, most important first.
+func fdoDataDirs() []string {
+	home := filepath.Clean(xdgDataHome())
+	dirs := []string{home}
+	for _, dir := range fdoLookupXdgDataDirs() {
+		if filepath.Clean(dir) != home {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
 func fdoForEachApplicationFile(f func(data fynedesk.AppData) bool) {
-	locationLookup := fdoLookupXdgDataDirs()
+	locationLookup := fdoDataDirs()
+	seen := make(map[string]bool)
 	for _, dataDir := range locationLookup {
 		testLocation := filepath.Join(dataDir, "applications")
 		files, err := os.ReadDir(testLocation)
@@ -162,6 +233,11 @@ func fdoForEachApplicationFile(f func(data fynedesk.AppData) bool) {
 			if strings.HasPrefix(file.Name(), ".") || file.IsDir() || !strings.HasSuffix(file.Name(), ".desktop") {
 				continue
 			}
+			// an entry in an earlier data dir replaces, or hides, one with the same name in later dirs
+			if seen[file.Name()] {
+				continue
+			}
+			seen[file.Name()] = true
 
 			icon := newFdoIconData(filepath.Join(testLocation, file.Name()))
 			if icon == nil {
@@ -388,7 +464,7 @@ func FdoLookupIconPathInTheme(iconSize string, dir string, parentDir string, ico
 
 // FdoLookupIconPath will take the name of an icon and find a matching image file
 func FdoLookupIconPath(theme string, size int, iconName string) string {
-	locationLookup := fdoLookupXdgDataDirs()
+	locationLookup := fdoDataDirs()
 	iconTheme := theme
 	iconSize := strconv.Itoa(size)
 	for _, dataDir := range locationLookup {
@@ -422,7 +498,7 @@ func FdoLookupIconPath(theme string, size int, iconName string) string {
 
 func fdoLookupAvailableThemes() []string {
 	var themes []string
-	locationLookup := fdoLookupXdgDataDirs()
+	locationLookup := fdoDataDirs()
 	for _, dataDir := range locationLookup {
 		files, err := os.ReadDir(filepath.Join(dataDir, "icons"))
 		if err != nil {
@@ -440,7 +516,8 @@ func fdoLookupAvailableThemes() []string {
 	return themes
 }
 
-// newFdoIconData creates and returns a struct that contains needed fields from a .desktop file
+// newFdoIconData creates and returns a struct that contains needed fields from a .desktop file.
+// Entries that are hidden, are not applications or whose TryExec is missing return nil.
 func newFdoIconData(desktopPath string) fynedesk.AppData {
 	file, err := os.Open(desktopPath)
 	if err != nil {
@@ -449,52 +526,62 @@ func newFdoIconData(desktopPath string) fynedesk.AppData {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	fdoApp := fdoApplicationData{name: "", iconName: "", iconPath: "", exec: ""}
-	var currentSection string
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "[") {
-			currentSection = line
-		}
-		if currentSection != "[Desktop Entry]" {
-			continue
-		}
-		if strings.HasPrefix(line, "Name=") {
-			name := strings.SplitAfter(line, "=")
-			fdoApp.name = name[1]
-		} else if strings.HasPrefix(line, "Icon=") {
-			icon := strings.SplitAfter(line, "=")
-			fdoApp.iconName = icon[1]
-			if _, err := os.Stat(icon[1]); err == nil {
-				fdoApp.iconPath = icon[1]
-			}
-		} else if strings.HasPrefix(line, "Exec=") {
-			exec := strings.SplitAfter(line, "=")
-			fdoApp.exec = exec[1]
-		} else if strings.HasPrefix(line, "Categories=") {
-			cats := strings.SplitAfter(line, "=")
-			fdoApp.categories = strings.Split(cats[1], ";")
-		} else if strings.HasPrefix(line, "NoDisplay=") {
-			val := strings.Split(line, "=")
-			if strings.TrimSpace(val[1]) == "true" {
-				fdoApp.hide = true
-			}
-		}
-	}
-	if err := scanner.Err(); err != nil {
+	groups, err := parseDesktopGroups(file)
+	if err != nil {
 		fyne.LogError("Could not read file", err)
 		return nil
 	}
+	entry := groups[desktopEntryGroup]
+	if entry == nil {
+		return nil
+	}
+	if entry.bool("Hidden") || (entry["Type"] != "" && entry["Type"] != "Application") {
+		return nil
+	}
+	if tryExec := entry.string("TryExec"); tryExec != "" && !execExists(tryExec) {
+		return nil
+	}
+
+	fdoApp := fdoApplicationData{
+		name:        entry.localeString("Name"),
+		genericName: entry.localeString("GenericName"),
+		iconName:    entry.localeString("Icon"),
+		exec:        entry.string("Exec"),
+		path:        entry.string("Path"),
+		desktopPath: desktopPath,
+		categories:  entry.list("Categories"),
+		keywords:    entry.localeList("Keywords"),
+		mimeTypes:   entry.list("MimeType"),
+		hide:        entry.bool("NoDisplay") || !entry.showInDesktop(currentDesktops()),
+		terminal:    entry.bool("Terminal"),
+	}
+	if filepath.IsAbs(fdoApp.iconName) {
+		if _, err := os.Stat(fdoApp.iconName); err == nil {
+			fdoApp.iconPath = fdoApp.iconName
+		}
+	}
+	fdoApp.actions = newFdoActions(&fdoApp, entry.list("Actions"), groups)
 	return &fdoApp
 }
 
 type fdoIconProvider struct {
 	cache *appCache
+
+	lock        sync.Mutex
+	listeners   []func()
+	watcher     *fsnotify.Watcher
+	configDir   string
+	dataDirs    map[string]bool
+	reloadTimer *time.Timer
 }
 
 // AvailableApps returns all of the available applications in a AppData slice
 func (f *fdoIconProvider) AvailableApps() []fynedesk.AppData {
+	return f.cache.apps()
+}
+
+// loadApps reads all of the available applications from the .desktop files in the data dirs
+func (f *fdoIconProvider) loadApps() []fynedesk.AppData {
 	var icons []fynedesk.AppData
 	fdoForEachApplicationFile(func(icon fynedesk.AppData) bool {
 		if icon == nil {
@@ -523,8 +610,7 @@ func (f *fdoIconProvider) FindAppsMatching(appName string) []fynedesk.AppData {
 		if icon == nil {
 			return false
 		}
-		if strings.Contains(strings.ToLower(icon.(*fdoApplicationData).name), strings.ToLower(appName)) ||
-			strings.Contains(strings.ToLower(icon.(*fdoApplicationData).exec), strings.ToLower(appName)) {
+		if icon.(*fdoApplicationData).matches(strings.ToLower(appName)) {
 			icons = append(icons, icon)
 		}
 
@@ -574,8 +660,16 @@ func (f *fdoIconProvider) DefaultApps() []fynedesk.AppData {
 	var apps []fynedesk.AppData
 
 	apps = appendAppIfExists(apps, findOneAppFromNames(f, "fyneterm", "xfce4-terminal", "gnome-terminal", "org.kde.konsole", "xterm"))
-	apps = appendAppIfExists(apps, findOneAppFromNames(f, "chromium", "google-chrome", "firefox"))
-	apps = appendAppIfExists(apps, findOneAppFromNames(f, "sylpheed", "thunderbird", "evolution"))
+	browser := f.DefaultAppForMime(mimeSchemeHandler + "http")
+	if browser == nil {
+		browser = findOneAppFromNames(f, "chromium", "google-chrome", "firefox")
+	}
+	apps = appendAppIfExists(apps, browser)
+	mail := f.DefaultAppForMime(mimeSchemeHandler + "mailto")
+	if mail == nil {
+		mail = findOneAppFromNames(f, "sylpheed", "thunderbird", "evolution")
+	}
+	apps = appendAppIfExists(apps, mail)
 	apps = appendAppIfExists(apps, f.FindAppFromName("gimp"))
 
 	return apps
@@ -600,6 +694,6 @@ func (f *fdoIconProvider) CategorizedApps() map[string][]fynedesk.AppData {
 // NewFDOIconProvider returns a new icon provider following the FreeDesktop.org specifications
 func NewFDOIconProvider() fynedesk.ApplicationProvider {
 	source := &fdoIconProvider{}
-	source.cache = newAppCache(source)
+	source.cache = newAppCache(source.loadApps)
 	return source
 }
@@ -9,7 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"fyne.io/fyne/v2"
-	_ "fyne.io/fyne/v2/test"
+	fynetest "fyne.io/fyne/v2/test"
 
 	"fyshos.com/fynedesk"
 	"fyshos.com/fynedesk/test"
@@ -33,6 +33,7 @@ func setTestEnv(t *testing.T) {
 		fyne.LogError("Could not set test environment variable", err)
 		t.FailNow()
 	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir()) // keep apps installed for the user out of the tests
 }
 
 // applications/app1.desktop and icons/default_theme/apps/32x32/app1.png
@@ -42,6 +43,15 @@ func TestFdoLookupDefaultTheme(t *testing.T) {
 	assert.Equal(t, true, exists(data))
 }
 
+// an icon loaded from one theme is not used for another
+func TestFdoLookupThemeChanged(t *testing.T) {
+	setTestEnv(t)
+	data := NewFDOIconProvider().(*fdoIconProvider).lookupApplication("app1")
+	assert.NotNil(t, data.Icon(iconTheme, iconSize))
+	assert.Nil(t, data.Icon("third_theme", iconSize))
+	assert.NotNil(t, data.Icon(iconTheme, iconSize))
+}
+
 // applications/com.fyne.app.desktop and icons/default_theme/apps/scalable/app2.svg
 func TestFdoFileNameMisMatchAndScalable(t *testing.T) {
 	setTestEnv(t)
@@ -179,10 +189,213 @@ func TestFdoIconProvider_DefaultApps(t *testing.T) {
 	assert.True(t, len(defaults) > 0)
 }
 
-func TestFdoExtractArgs(t *testing.T) {
-	params := []string{"-u", "thing", "%U"}
+func TestFdoHiddenAndTryExec(t *testing.T) {
+	dir := t.TempDir()
+	hidden := filepath.Join(dir, "hidden.desktop")
+	assert.Nil(t, os.WriteFile(hidden, []byte("[Desktop Entry]\nName=Hidden\nExec=sh\nHidden=true\n"), 0644))
+	assert.Nil(t, newFdoIconData(hidden))
+
+	missing := filepath.Join(dir, "missing.desktop")
+	assert.Nil(t, os.WriteFile(missing, []byte("[Desktop Entry]\nName=Missing\nExec=nothing\n"+
+		"TryExec=/does/not/exist\n"), 0644))
+	assert.Nil(t, newFdoIconData(missing))
+
+	link := filepath.Join(dir, "link.desktop")
+	assert.Nil(t, os.WriteFile(link, []byte("[Desktop Entry]\nType=Link\nName=Link\nURL=https://fyne.io\n"), 0644))
+	assert.Nil(t, newFdoIconData(link))
+}
+
+func TestFdoLocalizedEntry(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	t.Setenv("XDG_CURRENT_DESKTOP", "FyneDesk")
+	path := filepath.Join(t.TempDir(), "editor.desktop")
+	content := `# a comment
+[Desktop Entry]
+Type=Application
+Name=Text Editor
+Name[de]=Texteditor
+GenericName=Editor
+GenericName[de_DE]=Bearbeiter
+Keywords=text;notes\;ideas;
+Keywords[de]=Text;Notizen;
+Exec=editor %U
+Path=/tmp
+Terminal=true
+OnlyShowIn=GNOME;FyneDesk;
+
+[Desktop Action new]
+Name=New Window
+`
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+
+	data := newFdoIconData(path).(*fdoApplicationData)
+	assert.Equal(t, "Texteditor", data.Name())
+	assert.Equal(t, "Bearbeiter", data.GenericName())
+	assert.Equal(t, []string{"Text", "Notizen"}, data.Keywords())
+	assert.Equal(t, "/tmp", data.path)
+	assert.True(t, data.terminal)
+	assert.False(t, data.Hidden())
+	assert.True(t, data.matches("notizen"))
+	assert.Equal(t, "editor", data.Executable())
+
+	t.Setenv("LANG", "C")
+	data = newFdoIconData(path).(*fdoApplicationData)
+	assert.Equal(t, "Text Editor", data.Name())
+	assert.Equal(t, []string{"text", "notes;ideas"}, data.Keywords())
+
+	t.Setenv("XDG_CURRENT_DESKTOP", "KDE")
+	assert.True(t, newFdoIconData(path).Hidden())
+}
+
+func TestFdoActions(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	path := filepath.Join(t.TempDir(), "browser.desktop")
+	content := `[Desktop Entry]
+Type=Application
+Name=Browser
+Icon=app1
+Exec=browser %u
+Actions=new-window;private;broken;missing;
+
+[Desktop Action private]
+Name=New Private Window
+Name[fr]=Nouvelle fenêtre privée
+Exec=browser --private-window
+
+[Desktop Action new-window]
+Name=New Window
+Icon=app5
+Exec=browser --new-window
+
+[Desktop Action broken]
+Name=No Command
+`
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+
+	data := newFdoIconData(path)
+	actions := data.Actions()
+	assert.Equal(t, 2, len(actions))
+	assert.Equal(t, "New Window", actions[0].Name())
+	assert.Equal(t, "browser --new-window", actions[0].(*fdoAppAction).exec)
+	assert.Equal(t, "Nouvelle fenêtre privée", actions[1].Name())
+
+	setTestEnv(t)
+	assert.Equal(t, "app5.png", actions[0].Icon(iconTheme, iconSize).Name())
+	assert.Equal(t, data.Icon(iconTheme, iconSize), actions[1].Icon(iconTheme, iconSize))
+}
+
+func TestFdoRecentFiles(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "notes.txt")
+	assert.Nil(t, os.WriteFile(doc, []byte("notes"), 0644))
+	older := filepath.Join(dir, "old notes.txt")
+	assert.Nil(t, os.WriteFile(older, []byte("notes"), 0644))
+	xbel := filepath.Join(dir, "recently-used.xbel")
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<xbel version="1.0" xmlns:bookmark="http://www.freedesktop.org/standards/desktop-bookmarks"
+      xmlns:mime="http://www.freedesktop.org/standards/shared-mime-info">
+  <bookmark href="file://` + older + `" modified="2023-01-01T10:00:00Z">
+    <info><metadata owner="http://freedesktop.org"><bookmark:applications>
+      <bookmark:application name="editor" exec="&apos;editor %u&apos;" modified="2023-01-01T10:00:00Z" count="1"/>
+    </bookmark:applications></metadata></info>
+  </bookmark>
+  <bookmark href="file://` + doc + `" modified="2023-02-01T10:00:00Z">
+    <info><metadata owner="http://freedesktop.org"><bookmark:applications>
+      <bookmark:application name="Text Editor" exec="&apos;/usr/bin/editor %u&apos;" modified="2023-02-01T10:00:00.5Z" count="2"/>
+    </bookmark:applications></metadata></info>
+  </bookmark>
+  <bookmark href="file:///does/not/exist.txt" modified="2023-03-01T10:00:00Z">
+    <info><metadata owner="http://freedesktop.org"><bookmark:applications>
+      <bookmark:application name="editor" exec="&apos;editor %u&apos;" modified="2023-03-01T10:00:00Z" count="1"/>
+    </bookmark:applications></metadata></info>
+  </bookmark>
+  <bookmark href="https://fyne.io" modified="2023-04-01T10:00:00Z">
+    <info><metadata owner="http://freedesktop.org"><bookmark:applications>
+      <bookmark:application name="flatpak" exec="&apos;flatpak run org.other.App %u&apos;" modified="2023-04-01T10:00:00Z" count="1"/>
+    </bookmark:applications></metadata></info>
+  </bookmark>
+</xbel>
+`
+	assert.Nil(t, os.WriteFile(xbel, []byte(content), 0644))
+
+	data := &fdoApplicationData{name: "Text Editor", exec: "editor %U", desktopPath: "/apps/org.example.Editor.desktop"}
+	assert.Equal(t, []string{"file://" + doc, "file://" + older}, data.recentFilesIn(xbel))
+
+	other := &fdoApplicationData{name: "Other", exec: "flatpak run org.other.App", desktopPath: "/apps/org.other.App.desktop"}
+	assert.Empty(t, other.recentFilesIn(xbel))
+	assert.Nil(t, data.recentFilesIn(filepath.Join(dir, "missing.xbel")))
+}
+
+func TestFdoExpandExec(t *testing.T) {
+	data := &fdoApplicationData{name: "Viewer", iconName: "viewer", desktopPath: "/usr/share/applications/viewer.desktop"}
+	files := []string{"/home/user/a file.png", "https://example.com/b.png", "file:///tmp/c.png"}
+
+	args, err := splitExec("viewer --title=%c %F")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"viewer", "--title=Viewer", "/home/user/a file.png", "/tmp/c.png"},
+		data.expandExec(args, files))
 
-	extracted := extractArgs(params)
+	args, _ = splitExec("viewer %U")
+	assert.Equal(t, []string{"viewer", "file:///home/user/a%20file.png", "https://example.com/b.png",
+		"file:///tmp/c.png"}, data.expandExec(args, files))
+
+	args, _ = splitExec("viewer %i %k %f %d 100%%")
+	assert.Equal(t, []string{"viewer", "--icon", "viewer", "/usr/share/applications/viewer.desktop",
+		"/home/user/a file.png", "100%"}, data.expandExec(args, files))
+	assert.Equal(t, []string{"viewer", "--icon", "viewer", "/usr/share/applications/viewer.desktop", "100%"},
+		data.expandExec(args, nil))
+	assert.True(t, singleFileExec(args))
+
+	data.iconName = ""
+	args, _ = splitExec("viewer %i %u")
+	assert.Equal(t, []string{"viewer"}, data.expandExec(args, nil))
+}
+
+func TestFdoRunExec_Empty(t *testing.T) {
+	data := &fdoApplicationData{name: "Viewer"}
+	assert.NotNil(t, data.runExec("%f", nil, nil))
+	assert.NotNil(t, data.runExec("", nil, nil))
+}
+
+func TestFdoLocaleVariants(t *testing.T) {
+	assert.Nil(t, localeVariants("C"))
+	assert.Equal(t, []string{"de"}, localeVariants("de"))
+	assert.Equal(t, []string{"sr_YU@Latn", "sr_YU", "sr@Latn", "sr"}, localeVariants("sr_YU.UTF-8@Latn"))
+}
+
+func TestFdoSplitExec(t *testing.T) {
+	args, err := splitExec(`"/opt/My App/app" --name "a \"quoted\" \$value" %f`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/opt/My App/app", "--name", `a "quoted" $value`, "%f"}, args)
+
+	args, err = splitExec("  app   -x  ")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"app", "-x"}, args)
+
+	_, err = splitExec(`app "unterminated`)
+	assert.NotNil(t, err)
+	_, err = splitExec("")
+	assert.NotNil(t, err)
+}
+
+func TestFdoTerminalCommand(t *testing.T) {
+	a := fynetest.NewApp()
+	a.Preferences().SetString(terminalPreference, "gnome-terminal --wait")
+	assert.Equal(t, []string{"gnome-terminal", "--wait", "--", "htop", "-d", "5"},
+		TerminalCommand([]string{"htop", "-d", "5"}))
+
+	a.Preferences().SetString(terminalPreference, "xfce4-terminal")
+	assert.Equal(t, []string{"xfce4-terminal", "-x", "htop", "-d", "5"},
+		TerminalCommand([]string{"htop", "-d", "5"}))
+
+	a.Preferences().SetString(terminalPreference, "xterm")
+	assert.Equal(t, []string{"xterm", "-e", "htop"}, TerminalCommand([]string{"htop"}))
+}
 
-	assert.Equal(t, []string{"-u", "thing"}, extracted)
+func TestFdoUnescapeValue(t *testing.T) {
+	assert.Equal(t, "a b\nc\\", unescapeValue(`a\sb\nc\\`))
 }
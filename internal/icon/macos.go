@@ -34,6 +34,10 @@ func (m *macOSAppBundle) Name() string {
 	return m.DisplayName
 }
 
+func (m *macOSAppBundle) Actions() []fynedesk.AppAction {
+	return nil
+}
+
 func (m *macOSAppBundle) Categories() []string {
 	return m.categories
 }
@@ -125,7 +129,16 @@ func (m *macOSAppProvider) forEachApplication(f func(name, path, category string
 	}
 }
 
+// AppsForMime returns nil as file associations are not read from macOS app bundles
+func (m *macOSAppProvider) AppsForMime(string) []fynedesk.AppData {
+	return nil
+}
+
 func (m *macOSAppProvider) AvailableApps() []fynedesk.AppData {
+	return m.cache.apps()
+}
+
+func (m *macOSAppProvider) loadApps() []fynedesk.AppData {
 	var icons []fynedesk.AppData
 	m.forEachApplication(func(name, path, category string) bool {
 		app := loadAppBundle(name, path, category)
@@ -160,6 +173,11 @@ func (m *macOSAppProvider) FindAppFromWinInfo(win fynedesk.Window) fynedesk.AppD
 	return m.FindAppFromName(win.Properties().Title())
 }
 
+// DefaultAppForMime returns nil as file associations are not read from macOS app bundles
+func (m *macOSAppProvider) DefaultAppForMime(string) fynedesk.AppData {
+	return nil
+}
+
 func (m *macOSAppProvider) DefaultApps() []fynedesk.AppData {
 	var apps []fynedesk.AppData
 
@@ -207,6 +225,6 @@ func (m *macOSAppProvider) CategorizedApps() map[string][]fynedesk.AppData {
 func NewMacOSAppProvider() fynedesk.ApplicationProvider {
 	source := &macOSAppProvider{rootDirs: []string{"/Applications", "/Applications/Utilities",
 		"/System/Applications", "/System/Applications/Utilities"}}
-	source.cache = newAppCache(source)
+	source.cache = newAppCache(source.loadApps)
 	return source
 }
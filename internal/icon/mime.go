@@ -0,0 +1,519 @@
+package icon
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+)
+
+// The groups of a mimeapps.list file.
+const (
+	defaultAppsGroup = "Default Applications"
+	addedAppsGroup   = "Added Associations"
+	removedAppsGroup = "Removed Associations"
+)
+
+const (
+	mimeTypeDirectory = "inode/directory"
+	mimeTypeText      = "text/plain"
+	mimeTypeUnknown   = "application/octet-stream"
+
+	// mimeSchemeHandler is the start of the MIME type used to find the app for a URI scheme.
+	mimeSchemeHandler = "x-scheme-handler/"
+
+	defaultGlobWeight = 50
+	mimeSniffLength   = 512
+)
+
+// mimeGlob is a file name pattern from the shared-mime-info database.
+type mimeGlob struct {
+	weight        int
+	mimeType      string
+	pattern       string
+	caseSensitive bool
+}
+
+// mimeDatabase holds the parts of the shared-mime-info database used to find the type of a file by its name.
+type mimeDatabase struct {
+	globs   []*mimeGlob         // highest weight, then longest pattern, first
+	aliases map[string]string   // alternative names for a type, mapped to the canonical name
+	parents map[string][]string // the types that each type is a subclass of
+}
+
+// sharedMimeInfo is the database loaded from the data dirs, or nil if it needs to be read.
+var sharedMimeInfo struct {
+	lock sync.Mutex
+	db   *mimeDatabase
+}
+
+// mimeAppsCache holds the parsed mimeapps.list files, most important first, or nil if they need to be read.
+var mimeAppsCache struct {
+	lock  sync.Mutex
+	lists []map[string]desktopEntry
+}
+
+// MimeTypeForFile returns the MIME type of a file, looking up its name in the shared-mime-info database.
+// Folders return "inode/directory" and files that no pattern matches are recognised by their content.
+func MimeTypeForFile(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return mimeTypeDirectory
+	}
+
+	if found := loadSharedMimeInfo().typeForName(filepath.Base(path)); found != "" {
+		return found
+	}
+	if found := mime.TypeByExtension(filepath.Ext(path)); found != "" {
+		return strings.TrimSpace(strings.Split(found, ";")[0])
+	}
+	return sniffMimeType(path)
+}
+
+// MimeTypeForURI returns the MIME type of a file URI, or the scheme handler type of any other URI,
+// such as "x-scheme-handler/https".
+func MimeTypeForURI(uri *url.URL) string {
+	if uri.Scheme == "file" {
+		return MimeTypeForFile(uri.Path)
+	}
+
+	return mimeSchemeHandler + strings.ToLower(uri.Scheme)
+}
+
+// loadSharedMimeInfo reads the shared-mime-info database from the data dirs the first time it is needed,
+// or after it was reset.
+func loadSharedMimeInfo() *mimeDatabase {
+	sharedMimeInfo.lock.Lock()
+	defer sharedMimeInfo.lock.Unlock()
+
+	if sharedMimeInfo.db == nil {
+		var dirs []string
+		for _, dir := range fdoDataDirs() {
+			dirs = append(dirs, filepath.Join(dir, "mime"))
+		}
+		sharedMimeInfo.db = newMimeDatabase(dirs)
+	}
+	return sharedMimeInfo.db
+}
+
+// resetSharedMimeInfo forgets the shared-mime-info database, so that it is read again when next used.
+func resetSharedMimeInfo() {
+	sharedMimeInfo.lock.Lock()
+	defer sharedMimeInfo.lock.Unlock()
+
+	sharedMimeInfo.db = nil
+}
+
+// loadMimeAppsLists parses the mimeapps.list files the first time they are needed, or after they were reset.
+func loadMimeAppsLists() []map[string]desktopEntry {
+	mimeAppsCache.lock.Lock()
+	defer mimeAppsCache.lock.Unlock()
+
+	if mimeAppsCache.lists == nil {
+		lists := []map[string]desktopEntry{}
+		for _, path := range mimeAppsLists() {
+			if groups := readMimeAppsList(path); groups != nil {
+				lists = append(lists, groups)
+			}
+		}
+		mimeAppsCache.lists = lists
+	}
+	return mimeAppsCache.lists
+}
+
+// resetMimeAppsLists forgets the parsed mimeapps.list files, so that they are read again when next used.
+func resetMimeAppsLists() {
+	mimeAppsCache.lock.Lock()
+	defer mimeAppsCache.lock.Unlock()
+
+	mimeAppsCache.lists = nil
+}
+
+// newMimeDatabase reads the globs2, aliases and subclasses files from each of the mime dirs.
+func newMimeDatabase(dirs []string) *mimeDatabase {
+	db := &mimeDatabase{aliases: make(map[string]string), parents: make(map[string][]string)}
+	for _, dir := range dirs {
+		readMimeFile(filepath.Join(dir, "globs2"), func(line string) {
+			fields := strings.Split(line, ":")
+			if len(fields) < 3 || fields[2] == "__NOGLOBS__" {
+				return
+			}
+			weight, err := strconv.Atoi(fields[0])
+			if err != nil {
+				weight = defaultGlobWeight
+			}
+			cs := len(fields) > 3 && strings.Contains(fields[3], "cs")
+			db.globs = append(db.globs, &mimeGlob{weight: weight, mimeType: fields[1], pattern: fields[2],
+				caseSensitive: cs})
+		})
+		readMimeFile(filepath.Join(dir, "aliases"), func(line string) {
+			if fields := strings.Fields(line); len(fields) == 2 {
+				db.aliases[fields[0]] = fields[1]
+			}
+		})
+		readMimeFile(filepath.Join(dir, "subclasses"), func(line string) {
+			if fields := strings.Fields(line); len(fields) == 2 {
+				db.parents[fields[0]] = append(db.parents[fields[0]], fields[1])
+			}
+		})
+	}
+
+	sort.SliceStable(db.globs, func(i, j int) bool {
+		if db.globs[i].weight != db.globs[j].weight {
+			return db.globs[i].weight > db.globs[j].weight
+		}
+		return len(db.globs[i].pattern) > len(db.globs[j].pattern)
+	})
+	return db
+}
+
+// canonical returns the main name of a MIME type that may be an alias.
+func (db *mimeDatabase) canonical(mimeType string) string {
+	if name, ok := db.aliases[mimeType]; ok {
+		return name
+	}
+	return mimeType
+}
+
+// parentTypes returns the types that can be used to open a type if no app supports it directly.
+// All text types are also plain text.
+func (db *mimeDatabase) parentTypes(mimeType string) []string {
+	parents := db.parents[mimeType]
+	if !strings.HasPrefix(mimeType, "text/") || mimeType == mimeTypeText {
+		return parents
+	}
+
+	for _, parent := range parents {
+		if parent == mimeTypeText {
+			return parents
+		}
+	}
+	return append(append([]string{}, parents...), mimeTypeText)
+}
+
+// typeForName returns the type of the best glob matching a file name, or "" if none match.
+// Case sensitive globs are tried first, as they are more specific.
+func (db *mimeDatabase) typeForName(name string) string {
+	for _, glob := range db.globs {
+		if ok, _ := filepath.Match(glob.pattern, name); ok && glob.caseSensitive {
+			return glob.mimeType
+		}
+	}
+
+	lower := strings.ToLower(name)
+	for _, glob := range db.globs {
+		if ok, _ := filepath.Match(strings.ToLower(glob.pattern), lower); ok && !glob.caseSensitive {
+			return glob.mimeType
+		}
+	}
+	return ""
+}
+
+// AppsForMime returns the apps that can open a MIME type, with the default app first.
+func (f *fdoIconProvider) AppsForMime(mimeType string) []fynedesk.AppData {
+	mimeType = loadSharedMimeInfo().canonical(mimeType)
+	var apps []fynedesk.AppData
+	if app := f.defaultAppListed(mimeType); app != nil {
+		apps = append(apps, app)
+	}
+
+	for _, app := range f.associatedApps(mimeType) {
+		if len(apps) == 0 || app != apps[0] {
+			apps = append(apps, app)
+		}
+	}
+	return apps
+}
+
+// DefaultAppForMime returns the app that should open a MIME type, following the XDG MIME Applications
+// specification. If no app supports the type, the default for a type that it is a subclass of is used.
+func (f *fdoIconProvider) DefaultAppForMime(mimeType string) fynedesk.AppData {
+	db := loadSharedMimeInfo()
+	seen := make(map[string]bool)
+	queue := []string{db.canonical(mimeType)}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+
+		if app := f.defaultAppListed(next); app != nil {
+			return app
+		}
+		if apps := f.associatedApps(next); len(apps) > 0 {
+			return apps[0]
+		}
+		queue = append(queue, db.parentTypes(next)...)
+	}
+	return nil
+}
+
+// SetDefaultAppForMime makes an app the default for MIME types by writing to the user's mimeapps.list.
+func (f *fdoIconProvider) SetDefaultAppForMime(app fynedesk.AppData, mimeTypes ...string) error {
+	data, ok := app.(*fdoApplicationData)
+	if !ok {
+		return nil
+	}
+
+	path := filepath.Join(xdgConfigHome(), "mimeapps.list")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	updated := setMimeDefaults(string(content), data.desktopID(), mimeTypes)
+	err = os.WriteFile(path, []byte(updated), 0644)
+	resetMimeAppsLists()
+	return err
+}
+
+// associatedApps returns the apps added for a MIME type in mimeapps.list files, then those whose desktop
+// entry lists the type, leaving out any that a mimeapps.list removes.
+func (f *fdoIconProvider) associatedApps(mimeType string) []fynedesk.AppData {
+	installed := f.appsByID()
+	removed := make(map[string]bool)
+	added := make(map[fynedesk.AppData]bool)
+	var apps []fynedesk.AppData
+	add := func(app fynedesk.AppData) {
+		if app != nil && !added[app] {
+			added[app] = true
+			apps = append(apps, app)
+		}
+	}
+
+	for _, groups := range loadMimeAppsLists() {
+		for _, id := range groups[removedAppsGroup].list(mimeType) {
+			removed[id] = true
+		}
+		for _, id := range groups[addedAppsGroup].list(mimeType) {
+			if !removed[id] {
+				add(installed[id])
+			}
+		}
+	}
+
+	f.cache.forEachCachedApplication(func(_ string, app fynedesk.AppData) bool {
+		data := app.(*fdoApplicationData)
+		if !removed[data.desktopID()] && data.supportsMime(mimeType) {
+			add(app)
+		}
+		return false
+	})
+	return apps
+}
+
+// appsByID returns the installed apps keyed by their desktop file ID.
+func (f *fdoIconProvider) appsByID() map[string]fynedesk.AppData {
+	ids := make(map[string]fynedesk.AppData)
+	f.cache.forEachCachedApplication(func(_ string, app fynedesk.AppData) bool {
+		ids[app.(*fdoApplicationData).desktopID()] = app
+		return false
+	})
+	return ids
+}
+
+// defaultAppListed returns the first installed app in the Default Applications of the mimeapps.list files.
+func (f *fdoIconProvider) defaultAppListed(mimeType string) fynedesk.AppData {
+	installed := f.appsByID()
+	for _, groups := range loadMimeAppsLists() {
+		for _, id := range groups[defaultAppsGroup].list(mimeType) {
+			if app, ok := installed[id]; ok {
+				return app
+			}
+		}
+	}
+	return nil
+}
+
+// desktopID returns the ID of the .desktop file that an app was loaded from.
+func (data *fdoApplicationData) desktopID() string {
+	return filepath.Base(data.desktopPath)
+}
+
+// supportsMime returns true if the MimeType key of the app lists the type.
+func (data *fdoApplicationData) supportsMime(mimeType string) bool {
+	db := loadSharedMimeInfo()
+	for _, supported := range data.mimeTypes {
+		if supported == mimeType || db.canonical(supported) == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// mimeAppsLists returns the possible locations of mimeapps.list files, most important first.
+func mimeAppsLists() []string {
+	var desktops []string
+	for _, desk := range currentDesktops() {
+		desktops = append(desktops, strings.ToLower(desk))
+	}
+
+	var lists []string
+	addDir := func(dir string) {
+		for _, desk := range desktops {
+			lists = append(lists, filepath.Join(dir, desk+"-mimeapps.list"))
+		}
+		lists = append(lists, filepath.Join(dir, "mimeapps.list"))
+	}
+
+	addDir(xdgConfigHome())
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range strings.Split(configDirs, ":") {
+		addDir(dir)
+	}
+	addDir(filepath.Join(xdgDataHome(), "applications"))
+	for _, dir := range fdoLookupXdgDataDirs() {
+		if dir != xdgDataHome() {
+			addDir(filepath.Join(dir, "applications"))
+		}
+	}
+	return lists
+}
+
+// readMimeAppsList parses the groups of a mimeapps.list file, returning nil if it does not exist.
+func readMimeAppsList(path string) map[string]desktopEntry {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	groups, err := parseDesktopGroups(file)
+	if err != nil {
+		fyne.LogError("Could not read "+path, err)
+	}
+	return groups
+}
+
+// readMimeFile calls f with each line of a shared-mime-info file, skipping comments.
+func readMimeFile(path string, f func(string)) {
+	file, err := os.Open(path)
+	if err != nil {
+		return // this data dir has no MIME database
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && line[0] != '#' {
+			f(line)
+		}
+	}
+}
+
+// setMimeDefaults returns the content of a mimeapps.list with the app set as the default for each MIME type.
+// Other lines, including comments, are kept as they were.
+func setMimeDefaults(content, id string, mimeTypes []string) string {
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	}
+	start, end := -1, len(lines)
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "[") {
+			continue
+		}
+		if start != -1 {
+			end = i
+			break
+		}
+		if strings.TrimSpace(line) == "["+defaultAppsGroup+"]" {
+			start = i
+		}
+	}
+	if start == -1 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+defaultAppsGroup+"]")
+		start, end = len(lines)-1, len(lines)
+	}
+
+	pending := make(map[string]bool)
+	for _, mimeType := range mimeTypes {
+		pending[mimeType] = true
+	}
+	var group []string
+	for _, line := range lines[start+1 : end] {
+		key := strings.TrimSpace(strings.SplitN(line, "=", 2)[0])
+		if _, ok := pending[key]; ok {
+			if !pending[key] {
+				continue // a repeated key
+			}
+			line = key + "=" + id + ";"
+			pending[key] = false
+		}
+		group = append(group, line)
+	}
+
+	blank := len(group) // new keys go before any blank lines that separate the next group
+	for blank > 0 && strings.TrimSpace(group[blank-1]) == "" {
+		blank--
+	}
+	updated := append([]string{}, lines[:start+1]...)
+	updated = append(updated, group[:blank]...)
+	for _, mimeType := range mimeTypes {
+		if pending[mimeType] {
+			updated = append(updated, mimeType+"="+id+";")
+		}
+	}
+	updated = append(updated, group[blank:]...)
+	updated = append(updated, lines[end:]...)
+	return strings.Join(updated, "\n") + "\n"
+}
+
+// sniffMimeType looks at the start of a file to tell text from other content.
+func sniffMimeType(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return mimeTypeUnknown
+	}
+	defer file.Close()
+
+	head := make([]byte, mimeSniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return mimeTypeUnknown
+	}
+	found := http.DetectContentType(head[:n])
+	return strings.TrimSpace(strings.Split(found, ";")[0])
+}
+
+// xdgConfigHome returns the folder where user configuration is stored.
+func xdgConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config")
+}
+
+// xdgDataHome returns the folder where user data, such as installed apps, is stored.
+func xdgDataHome() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share")
+}
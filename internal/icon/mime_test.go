@@ -0,0 +1,146 @@
+package icon
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestMimeDatabase(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "globs2"), "# comment\n50:image/png:*.png\n50:text/x-csrc:*.c\n"+
+		"50:text/x-c++src:*.C:cs\n60:text/x-makefile:makefile\n40:text/plain:*.txt\n50:text/x-log:*.log.txt\n")
+	writeTestFile(t, filepath.Join(dir, "aliases"), "text/x-c text/x-csrc\n")
+	writeTestFile(t, filepath.Join(dir, "subclasses"), "text/x-csrc text/plain\nimage/svg+xml application/xml\n")
+	db := newMimeDatabase([]string{dir, filepath.Join(dir, "missing")})
+
+	assert.Equal(t, "image/png", db.typeForName("Photo.PNG"))
+	assert.Equal(t, "text/x-csrc", db.typeForName("main.c"))
+	assert.Equal(t, "text/x-c++src", db.typeForName("main.C"))
+	assert.Equal(t, "text/x-makefile", db.typeForName("Makefile"))
+	assert.Equal(t, "text/x-log", db.typeForName("build.log.txt"))
+	assert.Equal(t, "", db.typeForName("README"))
+
+	assert.Equal(t, "text/x-csrc", db.canonical("text/x-c"))
+	assert.Equal(t, "image/png", db.canonical("image/png"))
+	assert.Equal(t, []string{"text/plain"}, db.parentTypes("text/x-csrc"))
+	assert.Equal(t, []string{"text/plain"}, db.parentTypes("text/x-log"))
+	assert.Equal(t, []string{"application/xml"}, db.parentTypes("image/svg+xml"))
+	assert.Empty(t, db.parentTypes("text/plain"))
+}
+
+func TestMimeTypeForFile(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "NOTES")
+	writeTestFile(t, notes, "some notes\n")
+
+	assert.Equal(t, "inode/directory", MimeTypeForFile(dir))
+	assert.Equal(t, "text/plain", MimeTypeForFile(notes))
+	assert.Equal(t, "x-scheme-handler/https", MimeTypeForURI(&url.URL{Scheme: "HTTPS", Host: "fyne.io"}))
+	assert.Equal(t, "inode/directory", MimeTypeForURI(&url.URL{Scheme: "file", Path: dir}))
+}
+
+func TestFdoDefaultAppForMime(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(dir, "data"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "xdg"))
+	t.Setenv("XDG_CURRENT_DESKTOP", "FyneDesk")
+	resetMimeAppsLists()
+	defer resetMimeAppsLists()
+	apps := filepath.Join(dir, "data", "applications")
+	writeTestFile(t, filepath.Join(apps, "editor.desktop"),
+		"[Desktop Entry]\nName=Editor\nExec=editor %F\nMimeType=text/plain;text/x-csrc;\n")
+	writeTestFile(t, filepath.Join(apps, "ide.desktop"),
+		"[Desktop Entry]\nName=IDE\nExec=ide %F\nMimeType=text/x-csrc;\n")
+	writeTestFile(t, filepath.Join(apps, "browser.desktop"),
+		"[Desktop Entry]\nName=Browser\nExec=browser %U\nMimeType=text/html;x-scheme-handler/https;\n")
+	writeTestFile(t, filepath.Join(apps, "viewer.desktop"), "[Desktop Entry]\nName=Viewer\nExec=viewer %U\n")
+	writeTestFile(t, filepath.Join(dir, "xdg", "fynedesk-mimeapps.list"),
+		"[Default Applications]\ntext/x-csrc=missing.desktop;ide.desktop;\n\n"+
+			"[Added Associations]\ntext/html=viewer.desktop;\n\n[Removed Associations]\ntext/html=browser.desktop;\n")
+	p := NewFDOIconProvider()
+
+	assert.Equal(t, "IDE", p.DefaultAppForMime("text/x-csrc").Name())
+	assert.Equal(t, []string{"IDE", "Editor"}, appNames(p.AppsForMime("text/x-csrc")))
+	assert.Equal(t, "Editor", p.DefaultAppForMime("text/x-python").Name())
+	assert.Equal(t, "Viewer", p.DefaultAppForMime("text/html").Name())
+	assert.Equal(t, []string{"Viewer"}, appNames(p.AppsForMime("text/html")))
+	assert.Equal(t, "Browser", p.DefaultAppForMime("x-scheme-handler/https").Name())
+	assert.Nil(t, p.DefaultAppForMime("image/png"))
+
+	editor := p.FindAppFromName("Editor")
+	setter := p.(*fdoIconProvider)
+	assert.Nil(t, setter.SetDefaultAppForMime(editor, "text/x-csrc", "text/html"))
+	assert.Equal(t, "Editor", p.DefaultAppForMime("text/x-csrc").Name())
+	assert.Equal(t, "Editor", p.DefaultAppForMime("text/html").Name())
+}
+
+func TestResetMimeAppsLists(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "none"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(dir, "none"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "none"))
+	resetMimeAppsLists()
+	defer resetMimeAppsLists()
+
+	assert.Empty(t, loadMimeAppsLists())
+	writeTestFile(t, filepath.Join(dir, "mimeapps.list"), "[Default Applications]\ntext/html=browser.desktop;\n")
+	assert.Empty(t, loadMimeAppsLists())
+
+	resetMimeAppsLists()
+	lists := loadMimeAppsLists()
+	assert.Equal(t, 1, len(lists))
+	assert.Equal(t, []string{"browser.desktop"}, lists[0][defaultAppsGroup].list("text/html"))
+}
+
+func TestResetSharedMimeInfo(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(dir, "data"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "home"))
+	resetSharedMimeInfo()
+	defer resetSharedMimeInfo()
+
+	assert.Equal(t, "", loadSharedMimeInfo().typeForName("notes.fynetest"))
+	writeTestFile(t, filepath.Join(dir, "home", "mime", "globs2"), "50:text/x-fynetest:*.fynetest\n")
+	assert.Equal(t, "", loadSharedMimeInfo().typeForName("notes.fynetest"))
+
+	resetSharedMimeInfo()
+	assert.Equal(t, "text/x-fynetest", loadSharedMimeInfo().typeForName("notes.fynetest"))
+}
+
+func TestSetMimeDefaults(t *testing.T) {
+	assert.Equal(t, "[Default Applications]\ntext/html=browser.desktop;\n",
+		setMimeDefaults("", "browser.desktop", []string{"text/html"}))
+
+	content := "# my apps\n[Added Associations]\ntext/plain=editor.desktop;\n"
+	assert.Equal(t, "# my apps\n[Added Associations]\ntext/plain=editor.desktop;\n\n[Default Applications]\n"+
+		"text/html=browser.desktop;\nx-scheme-handler/http=browser.desktop;\n",
+		setMimeDefaults(content, "browser.desktop", []string{"text/html", "x-scheme-handler/http"}))
+
+	content = "[Default Applications]\ntext/html=old.desktop;\nimage/png=viewer.desktop;\n\n" +
+		"[Added Associations]\ntext/html=old.desktop;\n"
+	assert.Equal(t, "[Default Applications]\ntext/html=browser.desktop;\nimage/png=viewer.desktop;\n"+
+		"x-scheme-handler/http=browser.desktop;\n\n[Added Associations]\ntext/html=old.desktop;\n",
+		setMimeDefaults(content, "browser.desktop", []string{"text/html", "x-scheme-handler/http"}))
+}
+
+func appNames(apps []fynedesk.AppData) []string {
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.Name())
+	}
+	return names
+}
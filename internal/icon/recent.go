@@ -0,0 +1,141 @@
+package icon
+
+import (
+	"encoding/xml"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// maxRecentFiles is the number of recently opened files that are listed for each app.
+const maxRecentFiles = 10
+
+// genericLaunchers start other apps, so the command name does not identify which app opened a file.
+var genericLaunchers = []string{"env", "flatpak", "snap"}
+
+// xbelFile is the list of recently used files stored by GTK and other toolkits.
+type xbelFile struct {
+	Bookmarks []xbelBookmark `xml:"bookmark"`
+}
+
+type xbelBookmark struct {
+	Href     string            `xml:"href,attr"`
+	Modified string            `xml:"modified,attr"`
+	Apps     []xbelApplication `xml:"info>metadata>applications>application"`
+}
+
+// xbelApplication records an app that opened a bookmark, with the command it was registered with.
+type xbelApplication struct {
+	Name     string `xml:"name,attr"`
+	Exec     string `xml:"exec,attr"`
+	Modified string `xml:"modified,attr"`
+}
+
+// RecentFiles returns the URIs of the files this app opened most recently, newest first
+func (data *fdoApplicationData) RecentFiles() []string {
+	return data.recentFilesIn(recentlyUsedPath())
+}
+
+func (data *fdoApplicationData) recentFilesIn(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fyne.LogError("Could not open recent files", err)
+		}
+		return nil
+	}
+	defer file.Close()
+
+	var list xbelFile
+	if err = xml.NewDecoder(file).Decode(&list); err != nil {
+		fyne.LogError("Could not read recent files", err)
+		return nil
+	}
+
+	type recentFile struct {
+		uri  string
+		used time.Time
+	}
+	var found []recentFile
+	for _, mark := range list.Bookmarks {
+		for _, app := range mark.Apps {
+			if !data.openedBy(app) {
+				continue
+			}
+			if !recentFileExists(mark.Href) {
+				break
+			}
+
+			used := app.Modified
+			if used == "" {
+				used = mark.Modified
+			}
+			when, _ := time.Parse(time.RFC3339Nano, used)
+			found = append(found, recentFile{uri: mark.Href, used: when})
+			break
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].used.After(found[j].used)
+	})
+	if len(found) > maxRecentFiles {
+		found = found[:maxRecentFiles]
+	}
+	uris := make([]string, len(found))
+	for i, f := range found {
+		uris[i] = f.uri
+	}
+	return uris
+}
+
+// openedBy returns true if an application recorded in the recent files list refers to this app.
+// It matches the app name, the desktop file ID or the name of the command that was run.
+func (data *fdoApplicationData) openedBy(app xbelApplication) bool {
+	if app.Name != "" && (strings.EqualFold(app.Name, data.name) ||
+		strings.EqualFold(app.Name, strings.TrimSuffix(filepath.Base(data.desktopPath), ".desktop"))) {
+		return true
+	}
+
+	opened := commandName(strings.Trim(app.Exec, `'"`))
+	if opened == "" {
+		return false
+	}
+	for _, launcher := range genericLaunchers {
+		if opened == launcher {
+			return false
+		}
+	}
+	return opened == commandName(data.exec)
+}
+
+// commandName returns the name of the program run by an Exec value, without its directory.
+func commandName(command string) string {
+	args, err := splitExec(command)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(args[0])
+}
+
+// recentFileExists returns false for local files that have been removed since they were opened.
+func recentFileExists(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return err == nil
+	}
+
+	_, err = os.Stat(u.Path)
+	return err == nil
+}
+
+// recentlyUsedPath returns the location of the recently used files list.
+func recentlyUsedPath() string {
+	return filepath.Join(xdgDataHome(), "recently-used.xbel")
+}
@@ -0,0 +1,171 @@
+package icon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay waits for an install or update to finish writing files before apps and icons are loaded again.
+const reloadDelay = time.Second
+
+// maxIconDepth is how far into an icons folder sub-folders are watched, for themes then their size and
+// context folders, such as "hicolor/48x48/apps".
+const maxIconDepth = 3
+
+// watchedDataDirs are the folders in each data dir that hold apps, icons and the shared-mime-info database.
+var watchedDataDirs = []string{"applications", "icons", "mime", "pixmaps"}
+
+// AddChangeListener registers a function to call when apps are installed or removed, or an icon theme changes.
+// Adding the first listener starts watching the application and icon folders of each data dir.
+func (f *fdoIconProvider) AddChangeListener(listener func()) {
+	f.lock.Lock()
+	f.listeners = append(f.listeners, listener)
+	start := f.watcher == nil
+	f.lock.Unlock()
+
+	if start {
+		f.watch()
+	}
+}
+
+// isWatchedDir returns true if a folder holds apps or icons, or is an icon theme or one of its size or
+// context folders. Theme folders are watched so that an updated icon-theme.cache or index.theme reloads the icons.
+func (f *fdoIconProvider) isWatchedDir(path string) bool {
+	parent := filepath.Dir(path)
+	if f.dataDirs[parent] {
+		for _, name := range watchedDataDirs {
+			if filepath.Base(path) == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	for depth := 0; depth < maxIconDepth; depth++ {
+		if filepath.Base(parent) == "icons" && f.dataDirs[filepath.Dir(parent)] {
+			return true
+		}
+		parent = filepath.Dir(parent)
+	}
+	return false
+}
+
+// notifyListeners loads the apps again, so that their icons are looked up again, then tells the listeners.
+// The MIME database and mimeapps.list files are read again when next used, as new apps may add types.
+func (f *fdoIconProvider) notifyListeners() {
+	f.cache.reload()
+	resetSharedMimeInfo()
+	resetMimeAppsLists()
+
+	f.lock.Lock()
+	listeners := append([]func(){}, f.listeners...)
+	f.lock.Unlock()
+	for _, listener := range listeners {
+		listener()
+	}
+}
+
+// scheduleReload waits for changes to stop for reloadDelay before loading apps and icons again.
+func (f *fdoIconProvider) scheduleReload() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.reloadTimer == nil {
+		f.reloadTimer = time.AfterFunc(reloadDelay, f.notifyListeners)
+		return
+	}
+	f.reloadTimer.Reset(reloadDelay)
+}
+
+// watch starts watching each data dir, including the user's, and the folders inside them that hold apps and icons.
+// Data dirs are watched so that these folders are found if they are created later.
+// The user's config folder is also watched, for changes to the default apps in its mimeapps.list files.
+func (f *fdoIconProvider) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fyne.LogError("Unable to watch for installed apps", err)
+		return
+	}
+
+	f.lock.Lock()
+	f.watcher = watcher
+	f.configDir = filepath.Clean(xdgConfigHome())
+	f.dataDirs = make(map[string]bool)
+	for _, dir := range fdoDataDirs() {
+		f.dataDirs[filepath.Clean(dir)] = true
+	}
+	f.lock.Unlock()
+
+	_ = watcher.Add(f.configDir) // the config folder may not exist yet
+
+	for dir := range f.dataDirs {
+		if err := watcher.Add(dir); err != nil {
+			continue // the data dir does not exist
+		}
+		for _, name := range watchedDataDirs {
+			f.watchTree(filepath.Join(dir, name))
+		}
+	}
+	go f.watchEvents()
+}
+
+// watchEvents reloads the apps and icons after files change in the watched folders.
+func (f *fdoIconProvider) watchEvents() {
+	for {
+		select {
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Dir(event.Name) == f.configDir {
+				if strings.HasSuffix(event.Name, "mimeapps.list") {
+					resetMimeAppsLists()
+				}
+				continue
+			}
+			if f.dataDirs[filepath.Dir(event.Name)] && !f.isWatchedDir(event.Name) {
+				continue // other files in a data dir
+			}
+
+			if event.Has(fsnotify.Create) {
+				f.watchTree(event.Name)
+			}
+			f.scheduleReload()
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+			fyne.LogError("Error watching for installed apps", err)
+		}
+	}
+}
+
+// watchTree watches a folder of apps or icons, and each icon theme inside an icons folder along with
+// the size and context folders of the theme. Paths that are not such folders are ignored.
+func (f *fdoIconProvider) watchTree(path string) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() || !f.isWatchedDir(path) {
+		return
+	}
+	if err := f.watcher.Add(path); err != nil {
+		fyne.LogError("Unable to watch folder "+path, err)
+		return
+	}
+
+	if f.dataDirs[filepath.Dir(path)] && filepath.Base(path) != "icons" {
+		return // only icon folders have sub-folders that are watched
+	}
+	children, err := os.ReadDir(path)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		if child.IsDir() || child.Type()&os.ModeSymlink != 0 {
+			f.watchTree(filepath.Join(path, child.Name()))
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package icon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFdoIconProvider_AddChangeListener(t *testing.T) {
+	dir, home, config := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("XDG_DATA_DIRS", dir)
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(config, "none"))
+	resetMimeAppsLists()
+	defer resetMimeAppsLists()
+	p := NewFDOIconProvider()
+	assert.Nil(t, p.FindAppFromName("Live"))
+
+	changed := make(chan bool, 10)
+	p.(*fdoIconProvider).AddChangeListener(func() {
+		changed <- true
+	})
+
+	apps := filepath.Join(dir, "applications")
+	writeTestFile(t, filepath.Join(apps, "live.desktop"), "[Desktop Entry]\nName=Live\nExec=live\n")
+	waitForChange(t, changed)
+	assert.NotNil(t, p.FindAppFromName("Live"))
+	assert.Equal(t, 1, len(p.AvailableApps()))
+
+	theme := filepath.Join(dir, "icons", "new_theme")
+	writeTestFile(t, filepath.Join(theme, "index.theme"), "[Icon Theme]\nName=New\n")
+	waitForChange(t, changed)
+	writeTestFile(t, filepath.Join(theme, "icon-theme.cache"), "updated")
+	waitForChange(t, changed)
+	writeTestFile(t, filepath.Join(theme, "48x48", "apps", "live.png"), "icon")
+	waitForChange(t, changed)
+	writeTestFile(t, filepath.Join(theme, "48x48", "apps", "other.png"), "icon")
+	waitForChange(t, changed)
+
+	assert.Nil(t, os.Remove(filepath.Join(apps, "live.desktop")))
+	waitForChange(t, changed)
+	assert.Nil(t, p.FindAppFromName("Live"))
+	assert.Empty(t, p.AvailableApps())
+
+	writeTestFile(t, filepath.Join(home, "applications", "user.desktop"), "[Desktop Entry]\nName=User\nExec=user\n")
+	waitForChange(t, changed)
+	assert.NotNil(t, p.FindAppFromName("User"))
+
+	assert.Nil(t, p.DefaultAppForMime("text/html"))
+	writeTestFile(t, filepath.Join(config, "mimeapps.list"), "[Default Applications]\ntext/html=user.desktop;\n")
+	assert.Eventually(t, func() bool {
+		app := p.DefaultAppForMime("text/html")
+		return app != nil && app.Name() == "User"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFdoIconProvider_IsWatchedDir(t *testing.T) {
+	p := &fdoIconProvider{dataDirs: map[string]bool{"/usr/share": true}}
+	assert.True(t, p.isWatchedDir("/usr/share/applications"))
+	assert.True(t, p.isWatchedDir("/usr/share/icons"))
+	assert.True(t, p.isWatchedDir("/usr/share/icons/hicolor"))
+	assert.False(t, p.isWatchedDir("/usr/share/doc"))
+	assert.True(t, p.isWatchedDir("/usr/share/icons/hicolor/48x48"))
+	assert.True(t, p.isWatchedDir("/usr/share/icons/hicolor/48x48/apps"))
+	assert.False(t, p.isWatchedDir("/usr/share/icons/hicolor/48x48/apps/extra"))
+	assert.False(t, p.isWatchedDir("/usr/share/applications/kde"))
+	assert.False(t, p.isWatchedDir("/opt/icons/hicolor"))
+}
+
+func waitForChange(t *testing.T, changed chan bool) {
+	select {
+	case <-changed:
+	case <-time.After(reloadDelay * 5):
+		t.Fatal("Timed out waiting for apps to reload")
+	}
+}
@@ -0,0 +1,7 @@
+package notify
+
+// BackgroundNotify allows the window manager to be informed when the desktop wallpaper changes outside of settings,
+// for example when a new virtual desktop is shown or a slideshow moves to the next image
+type BackgroundNotify interface {
+	BackgroundChangeNotify()
+}
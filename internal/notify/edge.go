@@ -0,0 +1,8 @@
+package notify
+
+import "fyshos.com/fynedesk"
+
+// EdgeNotify allows the desktop to be informed when the mouse reaches a screen edge that can reveal a hidden app bar
+type EdgeNotify interface {
+	EdgeEnterNotify(*fynedesk.Screen)
+}
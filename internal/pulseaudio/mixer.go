@@ -0,0 +1,327 @@
+package pulseaudio
+
+import (
+	"fmt"
+	"io"
+)
+
+// Profile is a configuration of a sound card, such as stereo output or headset mode.
+type Profile = profile
+
+// Device is an output sink or an input source.
+type Device struct {
+	Index       uint32
+	Name        string
+	Description string
+	Volume      float32 // the average of all channels, from 0 to 1 (or more if the volume is boosted)
+	Muted       bool
+	MonitorOf   string // for sources that record the output of a sink, the name of that sink
+	Ports       []DevicePort
+	ActivePort  string
+}
+
+// DevicePort is a connector on a sound device, such as speakers or a headset.
+type DevicePort struct {
+	Name, Description string
+	Available         bool
+}
+
+// Stream is the playback of an application.
+type Stream struct {
+	Index    uint32
+	Name     string
+	Volume   float32
+	Muted    bool
+	PropList map[string]string
+}
+
+// Sinks returns the output devices.
+func (c *Client) Sinks() ([]Device, error) {
+	sinks, err := c.sinks()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Device, len(sinks))
+	for i, s := range sinks {
+		list[i] = Device{Index: s.Index, Name: s.Name, Description: s.Description, Volume: s.Cvolume.average(),
+			Muted: s.Muted, Ports: devicePorts(s.Ports), ActivePort: s.ActivePortName}
+	}
+	return list, nil
+}
+
+// Sources returns the input devices, including the monitors that record the output of each sink.
+func (c *Client) Sources() ([]Device, error) {
+	b, err := c.request(commandGetSourceInfoList)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Device
+	for b.Len() > 0 {
+		var s source
+		if err = bread(b, &s); err != nil {
+			return nil, err
+		}
+		list = append(list, Device{Index: s.Index, Name: s.Name, Description: s.Description,
+			Volume: s.Cvolume.average(), Muted: s.Muted, MonitorOf: string(s.MonitorOfSinkName),
+			Ports: devicePorts(s.Ports), ActivePort: s.ActivePortName})
+	}
+	return list, nil
+}
+
+// SinkInputs returns the streams that applications are playing.
+func (c *Client) SinkInputs() ([]Stream, error) {
+	b, err := c.request(commandGetSinkInputInfoList)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Stream
+	for b.Len() > 0 {
+		var s sinkInput
+		if err = bread(b, &s); err != nil {
+			return nil, err
+		}
+		list = append(list, Stream{Index: s.Index, Name: string(s.Name), Volume: s.Cvolume.average(), Muted: s.Muted,
+			PropList: s.PropList})
+	}
+	return list, nil
+}
+
+// SetDefaultSink sets the output that new streams play to.
+func (c *Client) SetDefaultSink(sinkName string) error {
+	return c.setDefaultSink(sinkName)
+}
+
+// SetDefaultSource sets the input that new streams record from.
+func (c *Client) SetDefaultSource(sourceName string) error {
+	_, err := c.request(commandSetDefaultSource,
+		stringTag, []byte(sourceName), byte(0))
+	return err
+}
+
+// SetSinkInputMute mutes or unmutes the stream of an application.
+func (c *Client) SetSinkInputMute(index uint32, mute bool) error {
+	_, err := c.request(commandSetSinkInputMute,
+		uint32Tag, index,
+		boolTag(mute))
+	return err
+}
+
+// SetSinkInputVolume sets the volume of the stream of an application, from 0 to 1.
+func (c *Client) SetSinkInputVolume(index uint32, volume float32) error {
+	_, err := c.request(commandSetSinkInputVolume,
+		uint32Tag, index,
+		cvolume{uint32(volume * pulseVolumeMax)})
+	return err
+}
+
+// SetSinkPort chooses the connector that a sink plays through, such as speakers or headphones.
+func (c *Client) SetSinkPort(sinkName, portName string) error {
+	_, err := c.request(commandSetSinkPort,
+		uint32Tag, uint32(0xffffffff),
+		stringTag, []byte(sinkName), byte(0),
+		stringTag, []byte(portName), byte(0))
+	return err
+}
+
+// SetSourceMute mutes or unmutes an input.
+func (c *Client) SetSourceMute(sourceName string, mute bool) error {
+	_, err := c.request(commandSetSourceMute,
+		uint32Tag, uint32(0xffffffff),
+		stringTag, []byte(sourceName), byte(0),
+		boolTag(mute))
+	return err
+}
+
+// SetSourceVolume sets the recording level of an input, from 0 to 1.
+func (c *Client) SetSourceVolume(sourceName string, volume float32) error {
+	_, err := c.request(commandSetSourceVolume,
+		uint32Tag, uint32(0xffffffff),
+		stringTag, []byte(sourceName), byte(0),
+		cvolume{uint32(volume * pulseVolumeMax)})
+	return err
+}
+
+func (v cvolume) average() float32 {
+	if len(v) == 0 {
+		return 0
+	}
+
+	total := uint64(0)
+	for _, channel := range v {
+		total += uint64(channel)
+	}
+	return float32(total) / float32(len(v)) / pulseVolumeMax
+}
+
+func boolTag(b bool) tagType {
+	if b {
+		return trueTag
+	}
+	return falseTag
+}
+
+func devicePorts(ports []sinkPort) []DevicePort {
+	list := make([]DevicePort, len(ports))
+	for i, p := range ports {
+		list[i] = DevicePort{Name: p.Name, Description: p.Description, Available: p.Available != 1} // 1 is "no"
+	}
+	return list
+}
+
+// readPorts reads the ports of a sink or source, and the name of the one that is active.
+func readPorts(r io.Reader) ([]sinkPort, string, error) {
+	var portCount uint32
+	if err := bread(r, uint32Tag, &portCount); err != nil {
+		return nil, "", err
+	}
+	ports := make([]sinkPort, portCount)
+	for i := range ports {
+		if err := bread(r, &ports[i]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var active optionalString
+	if err := bread(r, &active); err != nil {
+		return nil, "", err
+	}
+	return ports, string(active), nil
+}
+
+// readFormats skips over the list of formats that a sink or source supports.
+func readFormats(r io.Reader) error {
+	var formatCount uint8
+	if err := bread(r, uint8Tag, &formatCount); err != nil {
+		return err
+	}
+	for i := uint8(0); i < formatCount; i++ {
+		var f formatInfo
+		if err := bread(r, &f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// optionalString is a string that may be sent as null.
+type optionalString string
+
+func (s *optionalString) ReadFrom(r io.Reader) (int64, error) {
+	var t tagType
+	if err := bread(r, &t); err != nil {
+		return 0, err
+	}
+
+	switch t {
+	case stringNullTag:
+		*s = ""
+		return 0, nil
+	case stringTag:
+		var str string
+		err := bread(r, &str)
+		*s = optionalString(str)
+		return 0, err
+	}
+	return 0, fmt.Errorf("Protocol error: Got type %s but expected a string", t)
+}
+
+type source struct {
+	Index              uint32
+	Name               string
+	Description        string
+	SampleSpec         sampleSpec
+	ChannelMap         channelMap
+	ModuleIndex        uint32
+	Cvolume            cvolume
+	Muted              bool
+	MonitorOfSinkIndex uint32
+	MonitorOfSinkName  optionalString
+	Latency            uint64
+	Driver             optionalString
+	Flags              uint32
+	PropList           map[string]string
+	ConfiguredLatency  uint64
+	BaseVolume         uint32
+	SourceState        uint32
+	NVolumeSteps       uint32
+	CardIndex          uint32
+	Ports              []sinkPort
+	ActivePortName     string
+}
+
+func (s *source) ReadFrom(r io.Reader) (int64, error) {
+	err := bread(r,
+		uint32Tag, &s.Index,
+		stringTag, &s.Name,
+		stringTag, &s.Description,
+		&s.SampleSpec,
+		&s.ChannelMap,
+		uint32Tag, &s.ModuleIndex,
+		&s.Cvolume,
+		&s.Muted,
+		uint32Tag, &s.MonitorOfSinkIndex,
+		&s.MonitorOfSinkName,
+		usecTag, &s.Latency,
+		&s.Driver,
+		uint32Tag, &s.Flags,
+		&s.PropList,
+		usecTag, &s.ConfiguredLatency,
+		volumeTag, &s.BaseVolume,
+		uint32Tag, &s.SourceState,
+		uint32Tag, &s.NVolumeSteps,
+		uint32Tag, &s.CardIndex)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.Ports, s.ActivePortName, err = readPorts(r); err != nil {
+		return 0, err
+	}
+	return 0, readFormats(r)
+}
+
+type sinkInput struct {
+	Index          uint32
+	Name           optionalString
+	ModuleIndex    uint32
+	ClientIndex    uint32
+	SinkIndex      uint32
+	SampleSpec     sampleSpec
+	ChannelMap     channelMap
+	Cvolume        cvolume
+	BufferLatency  uint64
+	SinkLatency    uint64
+	ResampleMethod optionalString
+	Driver         optionalString
+	Muted          bool
+	PropList       map[string]string
+	Corked         bool
+	HasVolume      bool
+	VolumeWritable bool
+	Format         formatInfo
+}
+
+func (s *sinkInput) ReadFrom(r io.Reader) (int64, error) {
+	return 0, bread(r,
+		uint32Tag, &s.Index,
+		&s.Name,
+		uint32Tag, &s.ModuleIndex,
+		uint32Tag, &s.ClientIndex,
+		uint32Tag, &s.SinkIndex,
+		&s.SampleSpec,
+		&s.ChannelMap,
+		&s.Cvolume,
+		usecTag, &s.BufferLatency,
+		usecTag, &s.SinkLatency,
+		&s.ResampleMethod,
+		&s.Driver,
+		&s.Muted,
+		&s.PropList,
+		&s.Corked,
+		&s.HasVolume,
+		&s.VolumeWritable,
+		&s.Format)
+}
@@ -0,0 +1,81 @@
+package pulseaudio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource_ReadFrom(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, bwrite(&b,
+		uint32Tag, uint32(2),
+		stringTag, []byte("alsa_input.analog"), byte(0),
+		stringTag, []byte("Built-in Microphone"), byte(0),
+		sampleSpecTag, byte(3), byte(2), uint32(48000),
+		channelMapTag, byte(2), []byte{1, 2},
+		uint32Tag, uint32(7),
+		cvolume{0x8000, 0x4000},
+		trueTag,
+		uint32Tag, uint32(0xffffffff),
+		stringNullTag,
+		usecTag, uint64(0),
+		stringTag, []byte("alsa"), byte(0),
+		uint32Tag, uint32(0),
+		map[string]string{"device.class": "sound"},
+		usecTag, uint64(0),
+		volumeTag, uint32(pulseVolumeMax),
+		uint32Tag, uint32(0),
+		uint32Tag, uint32(65537),
+		uint32Tag, uint32(1),
+		uint32Tag, uint32(1),
+		stringTag, []byte("analog-input-mic"), byte(0),
+		stringTag, []byte("Microphone"), byte(0),
+		uint32Tag, uint32(100),
+		uint32Tag, uint32(2),
+		stringTag, []byte("analog-input-mic"), byte(0),
+		uint8Tag, uint8(0)))
+
+	var s source
+	assert.Nil(t, bread(&b, &s))
+	assert.Zero(t, b.Len())
+	assert.Equal(t, "Built-in Microphone", s.Description)
+	assert.True(t, s.Muted)
+	assert.Equal(t, "", string(s.MonitorOfSinkName))
+	assert.InDelta(t, 0.375, s.Cvolume.average(), 0.001)
+	assert.Equal(t, []DevicePort{{Name: "analog-input-mic", Description: "Microphone", Available: true}},
+		devicePorts(s.Ports))
+	assert.Equal(t, "analog-input-mic", s.ActivePortName)
+}
+
+func TestSinkInput_ReadFrom(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, bwrite(&b,
+		uint32Tag, uint32(9),
+		stringTag, []byte("Song"), byte(0),
+		uint32Tag, uint32(1),
+		uint32Tag, uint32(4),
+		uint32Tag, uint32(0),
+		sampleSpecTag, byte(3), byte(2), uint32(44100),
+		channelMapTag, byte(2), []byte{1, 2},
+		cvolume{pulseVolumeMax, pulseVolumeMax},
+		usecTag, uint64(1000),
+		usecTag, uint64(2000),
+		stringNullTag,
+		stringTag, []byte("protocol-native.c"), byte(0),
+		falseTag,
+		map[string]string{"application.name": "Music Player"},
+		falseTag,
+		trueTag,
+		trueTag,
+		formatInfoTag, uint8Tag, uint8(1), map[string]string{}))
+
+	var s sinkInput
+	assert.Nil(t, bread(&b, &s))
+	assert.Zero(t, b.Len())
+	assert.Equal(t, "Song", string(s.Name))
+	assert.Equal(t, "Music Player", s.PropList["application.name"])
+	assert.False(t, s.Muted)
+	assert.Equal(t, float32(1), s.Cvolume.average())
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	annotationWidth    = 4
+	annotationTextSize = 24
+	arrowHeadSize      = 18
+	blurBlockSize      = 12
+)
+
+var (
+	annotationColor = color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+
+	textFace     font.Face
+	textFaceOnce sync.Once
+)
+
+// annotation is a mark that the user has added to a screenshot.
+type annotation interface {
+	draw(*image.NRGBA)
+}
+
+type arrowAnnotation struct {
+	from, to image.Point
+}
+
+func (a *arrowAnnotation) draw(img *image.NRGBA) {
+	drawLine(img, a.from, a.to)
+	if a.from == a.to {
+		return
+	}
+
+	angle := math.Atan2(float64(a.to.Y-a.from.Y), float64(a.to.X-a.from.X))
+	for _, side := range []float64{-1, 1} {
+		head := angle + math.Pi + side*math.Pi/6
+		end := a.to.Add(image.Pt(int(math.Round(math.Cos(head)*arrowHeadSize)),
+			int(math.Round(math.Sin(head)*arrowHeadSize))))
+		drawLine(img, a.to, end)
+	}
+}
+
+type blurAnnotation struct {
+	rect image.Rectangle
+}
+
+// draw hides the content of the area by replacing blocks of pixels with their average colour.
+func (b *blurAnnotation) draw(img *image.NRGBA) {
+	area := b.rect.Canon().Intersect(img.Bounds())
+	for y := area.Min.Y; y < area.Max.Y; y += blurBlockSize {
+		for x := area.Min.X; x < area.Max.X; x += blurBlockSize {
+			block := image.Rect(x, y, x+blurBlockSize, y+blurBlockSize).Intersect(area)
+			draw.Draw(img, block, image.NewUniform(averageColor(img, block)), image.Point{}, draw.Src)
+		}
+	}
+}
+
+type boxAnnotation struct {
+	rect image.Rectangle
+}
+
+func (b *boxAnnotation) draw(img *image.NRGBA) {
+	r := b.rect.Canon()
+	drawLine(img, r.Min, image.Pt(r.Max.X, r.Min.Y))
+	drawLine(img, image.Pt(r.Max.X, r.Min.Y), r.Max)
+	drawLine(img, r.Max, image.Pt(r.Min.X, r.Max.Y))
+	drawLine(img, image.Pt(r.Min.X, r.Max.Y), r.Min)
+}
+
+type textAnnotation struct {
+	pos  image.Point // the top left of the text
+	text string
+}
+
+func (t *textAnnotation) draw(img *image.NRGBA) {
+	face := annotationFace()
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(annotationColor), Face: face,
+		Dot: fixed.P(t.pos.X, t.pos.Y).Add(fixed.Point26_6{Y: face.Metrics().Ascent})}
+	d.DrawString(t.text)
+}
+
+// annotationFace returns the font used for text annotations, loaded from the current theme.
+func annotationFace() font.Face {
+	textFaceOnce.Do(func() {
+		textFace = basicfont.Face7x13
+		parsed, err := opentype.Parse(theme.DefaultTheme().Font(fyne.TextStyle{Bold: true}).Content())
+		if err != nil {
+			fyne.LogError("Failed to load annotation font", err)
+			return
+		}
+		face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: annotationTextSize, DPI: 72,
+			Hinting: font.HintingFull})
+		if err != nil {
+			fyne.LogError("Failed to load annotation font", err)
+			return
+		}
+		textFace = face
+	})
+
+	return textFace
+}
+
+func averageColor(img *image.NRGBA, area image.Rectangle) color.NRGBA {
+	var r, g, b, a, count int
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			r += int(c.R)
+			g += int(c.G)
+			b += int(c.B)
+			a += int(c.A)
+			count++
+		}
+	}
+	if count == 0 {
+		return color.NRGBA{}
+	}
+
+	return color.NRGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: uint8(a / count)}
+}
+
+// drawLine draws a thick line between two points.
+func drawLine(img *image.NRGBA, from, to image.Point) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	steps := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	if steps == 0 {
+		steps = 1
+	}
+
+	pen := image.NewUniform(annotationColor)
+	for i := 0; i <= steps; i++ {
+		x := from.X + dx*i/steps
+		y := from.Y + dy*i/steps
+		dot := image.Rect(x-annotationWidth/2, y-annotationWidth/2, x+annotationWidth-annotationWidth/2,
+			y+annotationWidth-annotationWidth/2)
+		draw.Draw(img, dot, pen, image.Point{}, draw.Src)
+	}
+}
+
+// renderAnnotations returns a copy of the image passed with the annotations drawn over it.
+func renderAnnotations(img image.Image, annotations []annotation) *image.NRGBA {
+	out := image.NewNRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+	for _, a := range annotations {
+		a.draw(out)
+	}
+	return out
+}
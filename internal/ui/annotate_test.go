@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrowAnnotation(t *testing.T) {
+	img := renderAnnotations(image.NewNRGBA(image.Rect(0, 0, 100, 100)),
+		[]annotation{&arrowAnnotation{from: image.Pt(10, 50), to: image.Pt(90, 50)}})
+
+	assert.Equal(t, annotationColor, img.NRGBAAt(10, 50))
+	assert.Equal(t, annotationColor, img.NRGBAAt(50, 50))
+	assert.Equal(t, annotationColor, img.NRGBAAt(74, 41)) // the head points back from the end
+	assert.Equal(t, annotationColor, img.NRGBAAt(74, 59))
+	assert.Equal(t, color.NRGBA{}, img.NRGBAAt(50, 40))
+}
+
+func TestBlurAnnotation(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 24, 24))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 0xff, A: 0xff})
+
+	(&blurAnnotation{rect: image.Rect(2, 12, 0, 0)}).draw(img)
+	assert.Equal(t, img.NRGBAAt(0, 0), img.NRGBAAt(1, 11))
+	assert.Equal(t, uint8(0xff/24), img.NRGBAAt(0, 0).R)
+	assert.Equal(t, color.NRGBA{}, img.NRGBAAt(2, 0))
+}
+
+func TestBoxAnnotation(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 50, 50))
+	(&boxAnnotation{rect: image.Rect(40, 40, 10, 10)}).draw(img)
+
+	assert.Equal(t, annotationColor, img.NRGBAAt(10, 25))
+	assert.Equal(t, annotationColor, img.NRGBAAt(25, 40))
+	assert.Equal(t, color.NRGBA{}, img.NRGBAAt(25, 25))
+}
+
+func TestRenderAnnotations(t *testing.T) {
+	base := image.NewNRGBA(image.Rect(0, 0, 200, 60))
+	draw.Draw(base, base.Bounds(), image.White, image.Point{}, draw.Src)
+	img := renderAnnotations(base, []annotation{&textAnnotation{pos: image.Pt(4, 4), text: "Bug"}})
+
+	assert.Equal(t, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, base.NRGBAAt(10, 15)) // original untouched
+	found := false
+	for y := 4; y < 4+annotationTextSize; y++ {
+		for x := 4; x < 60; x++ {
+			if img.NRGBAAt(x, y) == annotationColor {
+				found = true
+			}
+		}
+	}
+	assert.True(t, found)
+}
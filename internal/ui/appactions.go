@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"net/url"
+	"path"
+
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+)
+
+// recentFilesApp is implemented by apps that can list and reopen the files they opened recently.
+type recentFilesApp interface {
+	RecentFiles() []string
+	RunWithFiles(env, files []string) error
+}
+
+// appMenuItems returns menu items for the actions of an app, followed by a submenu of its recent files.
+// The done function, if set, is called before an item starts so that a menu or window can be closed.
+func appMenuItems(desk *desktop, app fynedesk.AppData, done func()) []*fyne.MenuItem {
+	var items []*fyne.MenuItem
+	for _, action := range app.Actions() {
+		appAction := action // capture for callback below
+		items = append(items, fyne.NewMenuItem(action.Name(), func() {
+			if done != nil {
+				done()
+			}
+			if err := desk.runAppAction(app, appAction); err != nil {
+				fyne.LogError("Failed to start app action", err)
+			}
+		}))
+	}
+
+	opener, ok := app.(recentFilesApp)
+	if !ok {
+		return items
+	}
+	var recent []*fyne.MenuItem
+	for _, file := range opener.RecentFiles() {
+		uri := file // capture for callback below
+		recent = append(recent, fyne.NewMenuItem(recentFileName(uri), func() {
+			if done != nil {
+				done()
+			}
+			if err := desk.runAppWithFiles(app, opener, []string{uri}); err != nil {
+				fyne.LogError("Failed to open recent file", err)
+			}
+		}))
+	}
+	if len(recent) > 0 {
+		item := fyne.NewMenuItem("Recent Files", nil)
+		item.ChildMenu = fyne.NewMenu("", recent...)
+		items = append(items, item)
+	}
+	return items
+}
+
+// recentFileName returns the name of a recent file to show in a menu.
+func recentFileName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Path == "" || u.Path == "/" {
+		return uri
+	}
+
+	return path.Base(u.Path)
+}
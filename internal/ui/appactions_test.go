@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+
+	"fyshos.com/fynedesk"
+	wmTest "fyshos.com/fynedesk/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyAction struct {
+	name string
+	ran  bool
+}
+
+func (d *dummyAction) Name() string {
+	return d.name
+}
+
+func (d *dummyAction) Icon(string, int) fyne.Resource {
+	return nil
+}
+
+func (d *dummyAction) Run([]string) error {
+	d.ran = true
+	return nil
+}
+
+type dummyActionsApp struct {
+	dummyIcon
+	actions []fynedesk.AppAction
+	recent  []string
+	opened  []string
+}
+
+func (d *dummyActionsApp) Actions() []fynedesk.AppAction {
+	return d.actions
+}
+
+func (d *dummyActionsApp) RecentFiles() []string {
+	return d.recent
+}
+
+func (d *dummyActionsApp) RunWithFiles(_, files []string) error {
+	d.opened = files
+	return nil
+}
+
+func TestAppMenuItems(t *testing.T) {
+	test.NewApp()
+	l := &desktop{screens: wmTest.NewScreensProvider(&fynedesk.Screen{Name: "Screen0", Width: 1000, Height: 1000,
+		Scale: 1.0}), settings: &deskSettings{}}
+	fynedesk.SetInstance(l)
+
+	private := &dummyAction{name: "New Private Window"}
+	app := &dummyActionsApp{dummyIcon: dummyIcon{name: "Browser"}, actions: []fynedesk.AppAction{private},
+		recent: []string{"file:///home/user/My%20Page.html", "https://fyne.io"}}
+	closed := false
+	items := appMenuItems(l, app, func() {
+		closed = true
+	})
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "New Private Window", items[0].Label)
+	assert.Equal(t, "Recent Files", items[1].Label)
+
+	items[0].Action()
+	assert.True(t, private.ran)
+	assert.True(t, closed)
+	assert.Equal(t, fynedesk.AppData(app), l.recent[0])
+
+	recent := items[1].ChildMenu.Items
+	assert.Equal(t, 2, len(recent))
+	assert.Equal(t, "My Page.html", recent[0].Label)
+	assert.Equal(t, "https://fyne.io", recent[1].Label)
+	recent[0].Action()
+	assert.Equal(t, []string{"file:///home/user/My%20Page.html"}, app.opened)
+
+	assert.Empty(t, appMenuItems(l, &dummyIcon{name: "Plain"}, nil))
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"image"
+	"time"
+
+	"fyshos.com/fynedesk"
+)
+
+// barHideDelay is how long a revealed bar stays shown if the pointer does not move into it.
+var barHideDelay = 2 * time.Second
+
+// barRevealer is implemented by window managers that can show an auto hiding app bar above application windows.
+// Edge areas are in root window pixels, revealed areas are in pixels relative to the screen.
+type barRevealer interface {
+	RevealDesktopArea(screen *fynedesk.Screen, area image.Rectangle)
+	HideDesktopArea(screen *fynedesk.Screen)
+	SetEdgeTriggers(areas []image.Rectangle)
+}
+
+// EdgeEnterNotify can be called by the window manager to alert the desktop that the cursor reached the edge of a screen.
+// If app bars hide automatically then the bar on that screen will be shown above other windows,
+// and hidden again after barHideDelay unless the pointer moves into it.
+func (l *desktop) EdgeEnterNotify(screen *fynedesk.Screen) {
+	revealer, ok := l.wm.(barRevealer)
+	if !ok || !l.Settings().LauncherAutoHide() || !l.showsBarOnScreen(screen) {
+		return
+	}
+
+	b := l.barForScreen(screen)
+	b.revealed = true
+	revealer.RevealDesktopArea(screen, barAreaPixels(b, screen))
+
+	b.hideLock.Lock()
+	defer b.hideLock.Unlock()
+	if b.hideTimer != nil {
+		b.hideTimer.Stop()
+	}
+	b.hideTimer = time.AfterFunc(barHideDelay, func() {
+		l.hideBar(b)
+	})
+}
+
+// cancelHide stops a bar that was revealed from hiding again, as the pointer has moved into it.
+func (b *bar) cancelHide() {
+	b.hideLock.Lock()
+	defer b.hideLock.Unlock()
+
+	if b.hideTimer != nil {
+		b.hideTimer.Stop()
+		b.hideTimer = nil
+	}
+}
+
+func (l *desktop) hideBar(b *bar) {
+	b.cancelHide()
+	b.revealed = false
+	if revealer, ok := l.wm.(barRevealer); ok {
+		revealer.HideDesktopArea(l.screenForBar(b))
+	}
+}
+
+func (l *desktop) screenForBar(b *bar) *fynedesk.Screen {
+	for _, root := range l.screenRoots {
+		if root.bar == b {
+			return root.screen
+		}
+	}
+
+	return l.screens.Primary()
+}
+
+// updateEdgeTriggers tells the window manager which screen edges should reveal an auto hiding app bar.
+func (l *desktop) updateEdgeTriggers() {
+	revealer, ok := l.wm.(barRevealer)
+	if !ok {
+		return
+	}
+
+	var areas []image.Rectangle
+	if l.Settings().LauncherAutoHide() {
+		edge := launcherEdge(l.Settings())
+		for _, screen := range l.screens.Screens() {
+			if l.showsBarOnScreen(screen) {
+				areas = append(areas, edgeArea(screen, edge))
+			}
+		}
+	}
+	revealer.SetEdgeTriggers(areas)
+}
+
+// barAreaPixels returns the space that a bar takes up on its screen, in pixels.
+func barAreaPixels(b *bar, screen *fynedesk.Screen) image.Rectangle {
+	scale := screen.CanvasScale()
+	pos, size := b.Position(), b.Size()
+	return image.Rect(int(pos.X*scale), int(pos.Y*scale),
+		int((pos.X+size.Width)*scale), int((pos.Y+size.Height)*scale))
+}
+
+// edgeArea returns a one pixel strip along the specified edge of a screen, in root window pixels.
+func edgeArea(screen *fynedesk.Screen, edge string) image.Rectangle {
+	switch edge {
+	case edgeLeft:
+		return image.Rect(screen.X, screen.Y, screen.X+1, screen.Y+screen.Height)
+	case edgeRight:
+		return image.Rect(screen.X+screen.Width-1, screen.Y, screen.X+screen.Width, screen.Y+screen.Height)
+	case edgeTop:
+		return image.Rect(screen.X, screen.Y, screen.X+screen.Width, screen.Y+1)
+	default:
+		return image.Rect(screen.X, screen.Y+screen.Height-1, screen.X+screen.Width, screen.Y+screen.Height)
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk"
+	wmTest "fyshos.com/fynedesk/test"
+)
+
+func TestDesktop_EdgeEnterNotify(t *testing.T) {
+	barHideDelay = 50 * time.Millisecond
+	defer func() { barHideDelay = 2 * time.Second }()
+	test.NewApp()
+	screen := &fynedesk.Screen{Name: "Screen0", X: 0, Y: 0, Width: 2000, Height: 1000, Scale: 1.0}
+	settings := wmTest.NewSettings()
+	settings.SetLauncherAutoHide(true)
+	mgr := &revealerWM{}
+	l := &desktop{screens: wmTest.NewScreensProvider(screen), settings: settings, wm: mgr}
+	fynedesk.SetInstance(l)
+	l.bar = newBar(l)
+
+	l.EdgeEnterNotify(screen)
+	assert.True(t, mgr.isRevealed())
+	assert.Eventually(t, func() bool {
+		return !mgr.isRevealed()
+	}, time.Second, 10*time.Millisecond)
+
+	l.EdgeEnterNotify(screen)
+	l.bar.MouseIn(nil)
+	time.Sleep(barHideDelay * 2)
+	assert.True(t, mgr.isRevealed())
+	l.bar.MouseOut()
+	assert.False(t, mgr.isRevealed())
+}
+
+// revealerWM is a window manager that records whether the bar area is revealed.
+type revealerWM struct {
+	embededWM
+	lock     sync.Mutex
+	revealed bool
+}
+
+func (w *revealerWM) RevealDesktopArea(*fynedesk.Screen, image.Rectangle) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.revealed = true
+}
+
+func (w *revealerWM) HideDesktopArea(*fynedesk.Screen) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.revealed = false
+}
+
+func (w *revealerWM) SetEdgeTriggers([]image.Rectangle) {
+}
+
+func (w *revealerWM) isRevealed() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.revealed
+}
@@ -1,21 +1,19 @@
 package ui
 
 import (
-	"os"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 
 	"fyshos.com/fynedesk"
-	"github.com/FyshOS/backgrounds/builtin"
 )
 
 type background struct {
 	widget.BaseWidget
 
-	wallpaper *fyne.Container
+	wallpaper     *fyne.Container
+	wallpaperOnly bool // set for secondary screens so that modules are only loaded on the primary
 }
 
 func (b *background) CreateRenderer() fyne.WidgetRenderer {
@@ -25,6 +23,9 @@ func (b *background) CreateRenderer() fyne.WidgetRenderer {
 
 func (b *background) loadModules() []fyne.CanvasObject {
 	objects := []fyne.CanvasObject{b.wallpaper}
+	if b.wallpaperOnly {
+		return objects
+	}
 
 	for _, m := range fynedesk.Instance().Modules() {
 		if deskMod, ok := m.(fynedesk.ScreenAreaModule); ok {
@@ -40,49 +41,15 @@ func (b *background) loadModules() []fyne.CanvasObject {
 	return objects
 }
 
-func (b *background) updateBackground(path string) {
-	_, err := os.Stat(path)
-	if path == "" || os.IsNotExist(err) {
-		set := fyne.CurrentApp().Settings()
-		src := &builtin.Builtin{}
-		b.wallpaper.Objects[0] = src.Load(set.Theme(), set.ThemeVariant())
-	} else {
-		bg := canvas.NewImageFromFile(path)
-		bg.ScaleMode = canvas.ImageScaleFastest
-		b.wallpaper.Objects[0] = bg
-	}
+func (b *background) updateBackground(wallpaper fyne.CanvasObject) {
+	b.wallpaper.Objects[0] = wallpaper
 	b.loadModules()
 	canvas.Refresh(b.wallpaper)
 	b.Refresh()
 }
 
-func backgroundPath() string {
-	pathEnv := fynedesk.Instance().Settings().Background()
-	if pathEnv == "" {
-		return ""
-	}
-
-	if stat, err := os.Stat(pathEnv); os.IsNotExist(err) || !stat.Mode().IsRegular() {
-		return ""
-	}
-
-	return pathEnv
-}
-
-func newBackground() *background {
-	var bg fyne.CanvasObject
-	imagePath := backgroundPath()
-	if imagePath != "" {
-		img := canvas.NewImageFromFile(imagePath)
-		img.ScaleMode = canvas.ImageScaleFastest
-		bg = img
-	} else {
-		set := fyne.CurrentApp().Settings()
-		b := &builtin.Builtin{}
-		bg = b.Load(set.Theme(), set.ThemeVariant())
-	}
-
-	ret := &background{wallpaper: container.NewStack(bg)}
+func newBackground(wallpaper fyne.CanvasObject) *background {
+	ret := &background{wallpaper: container.NewStack(wallpaper)}
 	ret.ExtendBaseWidget(ret)
 	return ret
 }
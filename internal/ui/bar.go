@@ -2,6 +2,8 @@ package ui
 
 import (
 	"image/color"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -26,12 +28,16 @@ type bar struct {
 	iconScale      float32
 	disableTaskbar bool
 	disableZoom    bool
+	revealed       bool // Is an auto hiding bar currently shown above other windows?
+	hideTimer      *time.Timer
+	hideLock       sync.Mutex
 	icons          []*barIcon
 	separator      *canvas.Rectangle
 }
 
 // MouseIn alerts the widget that the mouse has entered
 func (b *bar) MouseIn(*deskDriver.MouseEvent) {
+	b.cancelHide()
 	if b.desk.Settings().LauncherDisableZoom() {
 		return
 	}
@@ -41,6 +47,11 @@ func (b *bar) MouseIn(*deskDriver.MouseEvent) {
 
 // MouseOut alerts the widget that the mouse has left
 func (b *bar) MouseOut() {
+	if b.revealed {
+		if desk, ok := b.desk.(*desktop); ok {
+			desk.hideBar(b)
+		}
+	}
 	if b.desk.Settings().LauncherDisableZoom() {
 		return
 	}
@@ -136,7 +147,7 @@ func (b *bar) taskbarIconTapped(win fynedesk.Window) {
 }
 
 func (b *bar) WindowAdded(win fynedesk.Window) {
-	if win.Properties().SkipTaskbar() || b.desk.Settings().LauncherDisableTaskbar() {
+	if win.Properties().SkipTaskbar() || b.desk.Settings().LauncherDisableTaskbar() || !b.showsWindow(win) {
 		return
 	}
 	icon := b.createIcon(nil, win)
@@ -148,24 +159,69 @@ func (b *bar) WindowAdded(win fynedesk.Window) {
 	}
 }
 
-func (b *bar) WindowMoved(_ fynedesk.Window) {}
+func (b *bar) WindowMoved(win fynedesk.Window) {
+	if win.Properties().SkipTaskbar() || b.desk.Settings().LauncherDisableTaskbar() {
+		return
+	}
+
+	index := b.taskIconIndex(win)
+	show := b.showsWindow(win)
+	if show && index == -1 {
+		b.WindowAdded(win)
+	} else if !show && index != -1 {
+		b.removeFromTaskbar(b.icons[index])
+		b.icons = append(b.icons[:index], b.icons[index+1:]...)
+	}
+}
 
-func (b *bar) WindowOrderChanged() {}
+func (b *bar) WindowOrderChanged() {
+	if !b.windowList() {
+		return
+	}
+
+	// window titles may have changed while they were in the background
+	for _, icon := range b.icons {
+		if icon.windowData != nil {
+			icon.Refresh()
+		}
+	}
+}
 
 func (b *bar) WindowRemoved(win fynedesk.Window) {
 	if win.Properties().SkipTaskbar() || b.desk.Settings().LauncherDisableTaskbar() {
 		return
 	}
+	i := b.taskIconIndex(win)
+	if i == -1 || win.Iconic() {
+		return
+	}
+	b.removeFromTaskbar(b.icons[i])
+	b.icons = append(b.icons[:i], b.icons[i+1:]...)
+}
+
+// showsWindow returns true if this bar should list the window in its taskbar.
+// When taskbars are limited to their own screen a window is shown on the bar of the screen it is on,
+// or on the primary bar if that screen has no bar.
+func (b *bar) showsWindow(win fynedesk.Window) bool {
+	if !b.desk.Settings().LauncherTaskbarScreenOnly() {
+		return true
+	}
+	desk, ok := b.desk.(*desktop)
+	if !ok {
+		return true
+	}
+
+	return desk.barForScreen(desk.Screens().ScreenForWindow(win)) == b
+}
+
+func (b *bar) taskIconIndex(win fynedesk.Window) int {
 	for i, icon := range b.icons {
-		if icon.windowData == nil || win != icon.windowData.win {
-			continue
-		}
-		if !win.Iconic() {
-			b.removeFromTaskbar(icon)
-			b.icons = append(b.icons[:i], b.icons[i+1:]...)
+		if icon.windowData != nil && win == icon.windowData.win {
+			return i
 		}
-		break
 	}
+
+	return -1
 }
 
 func (b *bar) updateTaskbar() {
@@ -184,6 +240,17 @@ func (b *bar) updateTaskbar() {
 	}
 }
 
+// updateTaskbarWindows adds or removes task icons so the taskbar lists the windows that this bar should show.
+func (b *bar) updateTaskbarWindows() {
+	if b.disableTaskbar {
+		return
+	}
+
+	for _, win := range b.desk.WindowManager().Windows() {
+		b.WindowMoved(win)
+	}
+}
+
 func (b *bar) updateIconOrder() {
 	var index = 0
 	for i, obj := range b.children {
@@ -264,13 +331,51 @@ func (b *bar) appendLauncherIcons() {
 
 // CreateRenderer creates the renderer that will be responsible for painting the widget
 func (b *bar) CreateRenderer() fyne.WidgetRenderer {
-	var bg fyne.CanvasObject
-	if fynedesk.Instance().Settings().NarrowLeftLauncher() {
-		bg = canvas.NewRectangle(wmTheme.WidgetPanelBackground())
-	} else {
-		bg = canvas.NewLinearGradient(theme.BackgroundColor(), color.Transparent, 180)
+	return &barRenderer{objects: b.children, background: b.newBackground(), layout: newBarLayout(b), appBar: b}
+}
+
+// edge returns the edge of the screen that this bar is placed on.
+func (b *bar) edge() string {
+	return launcherEdge(b.desk.Settings())
+}
+
+func (b *bar) newBackground() fyne.CanvasObject {
+	if b.desk.Settings().NarrowLeftLauncher() {
+		return canvas.NewRectangle(wmTheme.WidgetPanelBackground())
+	}
+
+	angle := 180.0
+	switch b.edge() {
+	case edgeTop:
+		angle = 0
+	case edgeLeft:
+		angle = 270
+	case edgeRight:
+		angle = 90
+	}
+	return canvas.NewLinearGradient(theme.BackgroundColor(), color.Transparent, angle)
+}
+
+// vertical returns true if the bar is placed on the left or right edge of the screen.
+func (b *bar) vertical() bool {
+	edge := b.edge()
+	return edge == edgeLeft || edge == edgeRight
+}
+
+// windowList returns true if the taskbar should show window titles instead of just icons.
+// This is only possible for bars at the top or bottom of the screen.
+func (b *bar) windowList() bool {
+	return b.desk.Settings().LauncherWindowList() && !b.vertical()
+}
+
+// launcherEdge returns the configured edge for app bars, defaulting to the bottom of the screen.
+func launcherEdge(s fynedesk.DeskSettings) string {
+	switch pos := s.LauncherPosition(); pos {
+	case edgeLeft, edgeRight, edgeTop:
+		return pos
+	default:
+		return edgeBottom
 	}
-	return &barRenderer{objects: b.children, background: bg, layout: newBarLayout(b), appBar: b}
 }
 
 // newBar creates a new application launcher and taskbar
@@ -322,11 +427,7 @@ func (b *barRenderer) Objects() []fyne.CanvasObject {
 
 // Refresh will recalculate the widget and repaint it
 func (b *barRenderer) Refresh() {
-	if fynedesk.Instance().Settings().NarrowLeftLauncher() {
-		b.background = canvas.NewRectangle(wmTheme.WidgetPanelBackground())
-	} else {
-		b.background = canvas.NewLinearGradient(theme.BackgroundColor(), color.Transparent, 180)
-	}
+	b.background = b.appBar.newBackground()
 	if b.appBar.separator != nil {
 		b.appBar.separator.FillColor = theme.ForegroundColor()
 	}
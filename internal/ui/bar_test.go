@@ -9,6 +9,7 @@ import (
 	"fyne.io/fyne/v2/test"
 	"fyne.io/fyne/v2/theme"
 
+	"fyshos.com/fynedesk"
 	wmTest "fyshos.com/fynedesk/test"
 	wmTheme "fyshos.com/fynedesk/theme"
 
@@ -19,6 +20,10 @@ type dummyIcon struct {
 	name string
 }
 
+func (d *dummyIcon) Actions() []fynedesk.AppAction {
+	return nil
+}
+
 func (d *dummyIcon) Categories() []string {
 	return []string{}
 }
@@ -237,3 +242,22 @@ func TestIconTaskbarDisabled(t *testing.T) {
 	}
 	assert.Equal(t, true, taskbarIconTest)
 }
+
+func TestAppBar_TaskbarScreenOnly(t *testing.T) {
+	primary := &fynedesk.Screen{Name: "Screen0", X: 0, Y: 0, Width: 2000, Height: 1000, Scale: 1.0}
+	second := &fynedesk.Screen{Name: "Screen1", X: 2000, Y: 0, Width: 1000, Height: 1000, Scale: 1.0}
+	settings := wmTest.NewSettings()
+	settings.SetLauncherScreens([]string{"Screen1"})
+	l := &desktop{screens: wmTest.NewScreensProvider(primary, second), settings: settings, wm: &embededWM{}}
+	fynedesk.SetInstance(l)
+	l.bar = newBar(l)
+	l.screenRoots = map[string]*screenRoot{"Screen1": {desk: l, screen: second, bar: newBar(l)}}
+
+	win := wmTest.NewWindow("Test")
+	assert.True(t, l.bar.showsWindow(win))
+	assert.True(t, l.screenRoots["Screen1"].bar.showsWindow(win))
+
+	settings.SetLauncherTaskbarScreenOnly(true)
+	assert.True(t, l.bar.showsWindow(win)) // test screens place every window on the first screen
+	assert.False(t, l.screenRoots["Screen1"].bar.showsWindow(win))
+}
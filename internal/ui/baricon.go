@@ -32,6 +32,7 @@ type barIconRenderer struct {
 	objects []fyne.CanvasObject
 
 	image *barIcon
+	title *widget.Label
 }
 
 func (bi *barIconRenderer) MinSize() fyne.Size {
@@ -44,7 +45,14 @@ func (bi *barIconRenderer) Layout(size fyne.Size) {
 		return
 	}
 
-	bi.objects[0].Resize(size)
+	if len(bi.objects) == 1 {
+		bi.objects[0].Resize(size)
+		return
+	}
+
+	bi.objects[0].Resize(fyne.NewSquareSize(size.Height))
+	bi.title.Move(fyne.NewPos(size.Height, (size.Height-bi.title.MinSize().Height)/2))
+	bi.title.Resize(fyne.NewSize(size.Width-size.Height, bi.title.MinSize().Height))
 }
 
 func (bi *barIconRenderer) Objects() []fyne.CanvasObject {
@@ -63,6 +71,10 @@ func (bi *barIconRenderer) Refresh() {
 		raster.FillMode = canvas.ImageFillContain
 
 		bi.objects = []fyne.CanvasObject{raster}
+		if bi.image.showsTitle() {
+			bi.title.SetText(bi.image.windowData.win.Properties().Title())
+			bi.objects = append(bi.objects, bi.title)
+		}
 	}
 	bi.Layout(bi.image.Size())
 
@@ -136,20 +148,36 @@ func (bi *barIcon) TappedSecondary(*fyne.PointEvent) {
 		addRemove.Label = "Pin " + app.Name()
 	}
 
+	var items []*fyne.MenuItem
+	if desk, ok := fynedesk.Instance().(*desktop); ok {
+		items = appMenuItems(desk, app, nil)
+	}
+	if len(items) > 0 {
+		items = append(items, fyne.NewMenuItemSeparator())
+	}
+	items = append(items, addRemove)
+
 	c := fyne.CurrentApp().Driver().CanvasForObject(bi)
 	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(bi)
-	menu = widget.NewPopUpMenu(fyne.NewMenu("", []*fyne.MenuItem{addRemove}...), c)
+	menu = widget.NewPopUpMenu(fyne.NewMenu("", items...), c)
 	menu.ShowAtPosition(pos)
 }
 
 // CreateRenderer is a private method to fyne which links this widget to its renderer
 func (bi *barIcon) CreateRenderer() fyne.WidgetRenderer {
-	render := &barIconRenderer{image: bi}
+	title := widget.NewLabel("")
+	title.Truncation = fyne.TextTruncateEllipsis
+	render := &barIconRenderer{image: bi, title: title}
 	render.Refresh()
 
 	return render
 }
 
+// showsTitle returns true if this icon represents a window on a bar that lists window titles.
+func (bi *barIcon) showsTitle() bool {
+	return bi.windowData != nil && bi.windowData.bar.windowList()
+}
+
 func newBarIcon(res fyne.Resource, appData fynedesk.AppData, winData *appWindow) *barIcon {
 	barIcon := &barIcon{resource: res, appData: appData, windowData: winData}
 	barIcon.ExtendBaseWidget(barIcon)
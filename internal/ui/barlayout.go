@@ -16,6 +16,12 @@ var _ fyne.Layout = (*barLayout)(nil)
 const (
 	iconZoomDistance = 2.5
 	separatorWidth   = 2
+	windowListWidth  = 160
+
+	edgeBottom = "Bottom"
+	edgeLeft   = "Left"
+	edgeRight  = "Right"
+	edgeTop    = "Top"
 )
 
 // barLayout returns a layout used for zooming linear groups of icons
@@ -38,49 +44,31 @@ func (bl *barLayout) setPointerPosition(position fyne.Position) {
 
 // Layout is called to pack all icons into a specified size.  It also handles the zooming effect of the icons.
 func (bl *barLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
-	narrow := fynedesk.Instance().Settings().NarrowLeftLauncher()
-	zoom := false
 	bg := objects[0]
 	objects = objects[1:]
-	x := theme.Padding()
-	if narrow {
-		bl.layoutNarrowBar(objects)
-	} else {
-		x, zoom = bl.layoutFullBar(size, objects)
+	if fynedesk.Instance().Settings().NarrowLeftLauncher() {
+		bl.layoutNarrowBar(bg, objects, size)
+		return
 	}
 
+	x, zoom := bl.layoutFullBar(size, objects)
 	zoomLeft := x
 	tallHeight := bl.bar.iconSize * bl.bar.iconScale
+	farEdge := bl.bar.edge() == edgeBottom || bl.bar.edge() == edgeRight
 	for _, child := range objects {
-		width := child.Size().Width
-		height := child.Size().Height
-
-		if narrow {
-			child.Move(fyne.NewPos(theme.Padding(), x))
-			x += height + theme.Padding()
-		} else {
-			if zoom {
-				if _, ok := child.(*canvas.Rectangle); ok {
-					child.Move(fyne.NewPos(x, bl.bar.iconSize))
-				} else {
-					child.Move(fyne.NewPos(x, tallHeight-height))
-				}
-			} else {
-				child.Move(fyne.NewPos(x, 0))
-			}
-			x += width + theme.Padding()
+		cross := float32(0)
+		if zoom && farEdge {
+			cross = tallHeight - bl.cross(child.Size())
 		}
+		child.Move(bl.position(x, cross))
+		x += bl.main(child.Size()) + theme.Padding()
 	}
-	if narrow {
-		bg.Move(fyne.NewPos(0, 0))
-		bg.Resize(fyne.NewSize(wmtheme.NarrowBarWidth, size.Height))
+
+	bg.Resize(bl.size(x-zoomLeft+theme.Padding(), bl.bar.iconSize))
+	if zoom && farEdge {
+		bg.Move(bl.position(zoomLeft-theme.Padding(), tallHeight-bl.bar.iconSize))
 	} else {
-		bg.Resize(fyne.NewSize(x-zoomLeft+theme.Padding(), bl.bar.iconSize))
-		if zoom {
-			bg.Move(fyne.NewPos(zoomLeft-theme.Padding(), bl.bar.iconSize))
-		} else {
-			bg.Move(fyne.NewPos(zoomLeft-theme.Padding(), 0))
-		}
+		bg.Move(bl.position(zoomLeft-theme.Padding(), 0))
 	}
 }
 
@@ -91,99 +79,149 @@ func (bl *barLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
 	barWidth := bl.calculateBarWidth(objects)
 
 	if fynedesk.Instance().Settings().NarrowLeftLauncher() {
-		return fyne.NewSize(wmtheme.NarrowBarWidth, barWidth)
+		return bl.size(barWidth, wmtheme.NarrowBarWidth)
 	}
 
-	barLeft := (bl.bar.Size().Width - barWidth) / 2
-	mouseX := bl.mousePosition.X
-	if !bl.bar.disableZoom && bl.mouseInside && mouseX >= barLeft && mouseX < barLeft+barWidth {
-		return fyne.NewSize(barWidth, bl.bar.iconSize*bl.bar.iconScale)
+	barLeft := (bl.main(bl.bar.Size()) - barWidth) / 2
+	mouseX := bl.mouseOffset()
+	if bl.zooms() && bl.mouseInside && mouseX >= barLeft && mouseX < barLeft+barWidth {
+		return bl.size(barWidth, bl.bar.iconSize*bl.bar.iconScale)
 	}
 
-	return fyne.NewSize(barWidth, bl.bar.iconSize)
+	return bl.size(barWidth, bl.bar.iconSize)
 }
 
 func (bl *barLayout) calculateBarWidth(objects []fyne.CanvasObject) float32 {
-	iconCount := float32(len(objects))
-	if !bl.bar.disableTaskbar {
-		iconCount--
-		return (iconCount * (bl.bar.iconSize + theme.Padding())) + separatorWidth
+	width := float32(0)
+	for _, child := range objects {
+		if _, ok := child.(*canvas.Rectangle); ok {
+			width += separatorWidth
+			continue
+		}
+
+		width += bl.itemLength(child, bl.bar.iconSize) + theme.Padding()
+	}
+
+	return width
+}
+
+// cross returns the size of an object across the bar (the height of a horizontal bar).
+func (bl *barLayout) cross(s fyne.Size) float32 {
+	if bl.bar.vertical() {
+		return s.Width
+	}
+	return s.Height
+}
+
+// itemLength returns the length of an item along the bar.
+// This is the icon size unless the item is a window in a window list.
+func (bl *barLayout) itemLength(child fyne.CanvasObject, iconSize float32) float32 {
+	if _, ok := child.(*canvas.Rectangle); ok {
+		return separatorWidth
+	}
+	if icon, ok := child.(*barIcon); ok && icon.windowData != nil && bl.bar.windowList() {
+		return windowListWidth
 	}
 
-	return iconCount * (bl.bar.iconSize + theme.Padding())
+	return iconSize
 }
 
 func (bl *barLayout) layoutFullBar(size fyne.Size, icons []fyne.CanvasObject) (x float32, zoom bool) {
 	offset := float32(0.0)
 	barWidth := bl.calculateBarWidth(icons)
-	barLeft := (size.Width - barWidth) / 2
+	barLeft := (bl.main(size) - barWidth) / 2
 	iconLeft := barLeft
 
-	mouseX := bl.mousePosition.X
-	zoom = !bl.bar.disableZoom && bl.mouseInside && mouseX >= barLeft && mouseX < barLeft+barWidth
+	mouseX := bl.mouseOffset()
+	zoom = bl.zooms() && bl.mouseInside && mouseX >= barLeft && mouseX < barLeft+barWidth
 	for _, child := range icons {
-		if zoom {
-			if _, ok := child.(*canvas.Rectangle); ok {
-				child.Resize(fyne.NewSize(separatorWidth, bl.bar.iconSize))
+		length := bl.itemLength(child, bl.bar.iconSize)
+		if _, ok := child.(*canvas.Rectangle); ok {
+			child.Resize(bl.size(separatorWidth, bl.bar.iconSize))
+			if zoom {
 				if iconLeft+separatorWidth+theme.Padding() < mouseX {
 					offset += separatorWidth
 				} else if iconLeft < mouseX {
 					offset += separatorWidth + theme.Padding()
 				}
-			} else {
-				iconCenter := iconLeft + bl.bar.iconSize/2
-				offsetX := float64(mouseX - iconCenter)
-
-				scale := bl.bar.iconScale - (float32(math.Abs(offsetX)) / (bl.bar.iconSize * iconZoomDistance))
-				newSize := bl.bar.iconSize * scale
-				if newSize < bl.bar.iconSize {
-					newSize = bl.bar.iconSize
-				}
-				child.Resize(fyne.NewSize(newSize, newSize))
-
-				if iconLeft+bl.bar.iconSize+theme.Padding() < mouseX {
-					offset += newSize - bl.bar.iconSize
-				} else if iconLeft < mouseX {
-					ratio := (mouseX - iconLeft) / (bl.bar.iconSize + theme.Padding())
-					offset += (newSize-bl.bar.iconSize)*ratio + theme.Padding()
-				}
 			}
-		} else {
-			if _, ok := child.(*canvas.Rectangle); ok {
-				child.Resize(fyne.NewSize(separatorWidth, bl.bar.iconSize))
-			} else {
-				child.Resize(fyne.NewSize(bl.bar.iconSize, bl.bar.iconSize))
+		} else if zoom {
+			iconCenter := iconLeft + bl.bar.iconSize/2
+			offsetX := float64(mouseX - iconCenter)
+
+			scale := bl.bar.iconScale - (float32(math.Abs(offsetX)) / (bl.bar.iconSize * iconZoomDistance))
+			newSize := bl.bar.iconSize * scale
+			if newSize < bl.bar.iconSize {
+				newSize = bl.bar.iconSize
+			}
+			child.Resize(fyne.NewSize(newSize, newSize))
+
+			if iconLeft+bl.bar.iconSize+theme.Padding() < mouseX {
+				offset += newSize - bl.bar.iconSize
+			} else if iconLeft < mouseX {
+				ratio := (mouseX - iconLeft) / (bl.bar.iconSize + theme.Padding())
+				offset += (newSize-bl.bar.iconSize)*ratio + theme.Padding()
 			}
-		}
-		if _, ok := child.(*canvas.Rectangle); ok {
-			iconLeft += separatorWidth
 		} else {
-			iconLeft += bl.bar.iconSize
+			child.Resize(bl.size(length, bl.bar.iconSize))
 		}
-		iconLeft += theme.Padding()
+
+		iconLeft += length + theme.Padding()
 	}
 
 	return barLeft - offset, zoom
 }
 
-func (bl *barLayout) layoutNarrowBar(icons []fyne.CanvasObject) {
+func (bl *barLayout) layoutNarrowBar(bg fyne.CanvasObject, icons []fyne.CanvasObject, size fyne.Size) {
 	iconSize := wmtheme.NarrowBarWidth - theme.Padding()*2
 	iconLeft := theme.Padding()
 
 	for _, child := range icons {
-		if _, ok := child.(*canvas.Rectangle); ok {
-			child.Resize(fyne.NewSize(iconSize, separatorWidth))
-		} else {
-			child.Resize(fyne.NewSize(iconSize, iconSize))
-		}
+		length := bl.itemLength(child, iconSize)
+		child.Resize(bl.size(length, iconSize))
+		child.Move(bl.position(iconLeft, theme.Padding()))
 
-		if _, ok := child.(*canvas.Rectangle); ok {
-			iconLeft += separatorWidth
-		} else {
-			iconLeft += iconSize
-		}
-		iconLeft += theme.Padding()
+		iconLeft += length + theme.Padding()
+	}
+
+	bg.Move(fyne.NewPos(0, 0))
+	bg.Resize(bl.size(bl.main(size), wmtheme.NarrowBarWidth))
+}
+
+// main returns the size of an object along the bar (the width of a horizontal bar).
+func (bl *barLayout) main(s fyne.Size) float32 {
+	if bl.bar.vertical() {
+		return s.Height
+	}
+	return s.Width
+}
+
+// mouseOffset returns the position of the mouse along the bar.
+func (bl *barLayout) mouseOffset() float32 {
+	if bl.bar.vertical() {
+		return bl.mousePosition.Y
+	}
+	return bl.mousePosition.X
+}
+
+// position returns a position for the given offsets along and across the bar.
+func (bl *barLayout) position(main, cross float32) fyne.Position {
+	if bl.bar.vertical() {
+		return fyne.NewPos(cross, main)
 	}
+	return fyne.NewPos(main, cross)
+}
+
+// size returns a size for the given lengths along and across the bar.
+func (bl *barLayout) size(main, cross float32) fyne.Size {
+	if bl.bar.vertical() {
+		return fyne.NewSize(cross, main)
+	}
+	return fyne.NewSize(main, cross)
+}
+
+func (bl *barLayout) zooms() bool {
+	return !bl.bar.disableZoom && !bl.bar.windowList()
 }
 
 // newBarLayout returns a horizontal icon bar
@@ -1,9 +1,13 @@
 package ui
 
 import (
+	"image"
 	"math"
+	"os"
 	"os/exec"
 	"strconv"
+	"sync"
+	"time"
 
 	"fyshos.com/fynedesk/internal/notify"
 
@@ -24,12 +28,17 @@ const (
 	SkipTaskbarHint = "FyneDesk:skip"
 )
 
+// appChangeNotifier is implemented by application providers that can tell when apps or icon themes change.
+type appChangeNotifier interface {
+	AddChangeListener(func())
+}
+
 type desktop struct {
 	wm.ShortcutHandler
 	app      fyne.App
 	wm       fynedesk.WindowManager
 	icons    fynedesk.ApplicationProvider
-	recent   []fynedesk.AppData
+	usage    *launchUsage
 	screens  fynedesk.ScreenList
 	settings fynedesk.DeskSettings
 
@@ -37,11 +46,18 @@ type desktop struct {
 	showMenu    func(*fyne.Menu, fyne.Position)
 	moduleCache []fynedesk.Module
 
-	bar     *bar
-	widgets *widgetPanel
-	mouse   fyne.CanvasObject
-	root    fyne.Window
-	desk    int
+	recent     []fynedesk.AppData
+	recentLock sync.RWMutex
+	// refreshLock stops bars being updated for app and settings changes at the same time
+	refreshLock sync.Mutex
+
+	bar         *bar
+	widgets     *widgetPanel
+	mouse       fyne.CanvasObject
+	root        fyne.Window
+	screenRoots map[string]*screenRoot
+	desk        int
+	inhibit     *portalInhibit
 }
 
 func (l *desktop) Desktop() int {
@@ -85,25 +101,69 @@ func (l *desktop) SetDesktop(id int) {
 			desk.DesktopChangeNotify(id)
 		}
 	}
+	if l.root != nil {
+		l.notifyBackgroundChange()
+	}
 }
 
 func (l *desktop) Layout(objects []fyne.CanvasObject, size fyne.Size) {
 	bg := objects[0].(*background)
 	bg.Resize(size)
-	if l.Settings().NarrowLeftLauncher() {
-		l.bar.Resize(size)
-		l.bar.Move(fyne.NewPos(0, 0))
-	} else {
-		barHeight := l.bar.MinSize().Height
-		l.bar.Resize(fyne.NewSize(size.Width, barHeight+1)) // add 1 so rounding cannot trigger mouse out on bottom edge
-		l.bar.Move(fyne.NewPos(0, size.Height-barHeight))
-	}
-	l.bar.Refresh()
 
 	widgetsWidth := l.widgets.MinSize().Width
 	l.widgets.Resize(fyne.NewSize(widgetsWidth, size.Height))
-	l.widgets.Move(fyne.NewPos(size.Width-widgetsWidth, 0))
+	if l.widgetPanelLeft() {
+		l.widgets.Move(fyne.NewPos(0, 0))
+	} else {
+		l.widgets.Move(fyne.NewPos(size.Width-widgetsWidth, 0))
+	}
 	l.widgets.Refresh()
+	l.layoutBar(l.bar, size, widgetsWidth)
+}
+
+// layoutBar positions a bar on the configured edge of a desktop window.
+// The panel width is the space used by the widget panel, which vertical and narrow bars will not overlap.
+func (l *desktop) layoutBar(b *bar, size fyne.Size, panel float32) {
+	narrow := l.Settings().NarrowLeftLauncher()
+	thickness := wmtheme.NarrowBarWidth
+	if !narrow {
+		if b.vertical() {
+			thickness = b.MinSize().Width
+		} else {
+			thickness = b.MinSize().Height
+		}
+	}
+	before, after := float32(0), panel
+	if l.widgetPanelLeft() {
+		before, after = panel, 0
+	}
+
+	switch b.edge() {
+	case edgeLeft:
+		b.Resize(fyne.NewSize(thickness, size.Height))
+		b.Move(fyne.NewPos(before, 0))
+	case edgeRight:
+		b.Resize(fyne.NewSize(thickness+1, size.Height)) // add 1 so rounding cannot trigger mouse out on right edge
+		b.Move(fyne.NewPos(size.Width-after-thickness, 0))
+	default:
+		x, width := float32(0), size.Width
+		if narrow {
+			x, width = before, size.Width-before-after
+		}
+		if b.edge() == edgeTop {
+			b.Resize(fyne.NewSize(width, thickness))
+			b.Move(fyne.NewPos(x, 0))
+		} else {
+			b.Resize(fyne.NewSize(width, thickness+1)) // add 1 so rounding cannot trigger mouse out on bottom edge
+			b.Move(fyne.NewPos(x, size.Height-thickness))
+		}
+	}
+	b.Refresh()
+}
+
+// widgetPanelLeft returns true if the widget panel should be shown on the left of the primary screen.
+func (l *desktop) widgetPanelLeft() bool {
+	return l.Settings().WidgetPanelPosition() == edgeLeft
 }
 
 func (l *desktop) MinSize(_ []fyne.CanvasObject) fyne.Size {
@@ -114,8 +174,48 @@ func (l *desktop) ShowMenuAt(menu *fyne.Menu, pos fyne.Position) {
 	l.showMenu(menu, pos)
 }
 
-func (l *desktop) updateBackgrounds(path string) {
-	l.root.Content().(*fyne.Container).Objects[0].(*background).updateBackground(path)
+func (l *desktop) updateBackgrounds() {
+	l.root.Content().(*fyne.Container).Objects[0].(*background).updateBackground(l.wallpaper(l.screens.Primary()))
+	for _, root := range l.screenRoots {
+		root.bg.updateBackground(l.wallpaper(root.screen))
+	}
+}
+
+// notifyBackgroundChange updates our wallpapers and lets the window manager know, for changes that are not settings.
+func (l *desktop) notifyBackgroundChange() {
+	l.updateBackgrounds()
+	if bg, ok := l.wm.(notify.BackgroundNotify); ok {
+		bg.BackgroundChangeNotify()
+	}
+}
+
+// runSlideshow refreshes the wallpapers each time that a slideshow should move to its next image.
+func (l *desktop) runSlideshow() {
+	for {
+		next := nextSlideshowChange(time.Now(), l.Settings().BackgroundSlideshowInterval())
+		time.Sleep(time.Until(next))
+
+		if l.root != nil && l.showsSlideshow() {
+			l.notifyBackgroundChange()
+		}
+	}
+}
+
+// showsSlideshow returns true if any screen has a directory of images as its wallpaper on the current desktop.
+func (l *desktop) showsSlideshow() bool {
+	for _, screen := range l.screens.Screens() {
+		spec := l.Settings().BackgroundFor(screen.Name, l.desk)
+		if stat, err := os.Stat(spec); err == nil && stat.IsDir() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *desktop) wallpaper(screen *fynedesk.Screen) fyne.CanvasObject {
+	w, h := l.RootSizePixels()
+	return newWallpaper(l.Settings(), screen, l.desk, image.Rect(0, 0, int(w), int(h)))
 }
 
 func (l *desktop) createPrimaryContent() fyne.CanvasObject {
@@ -124,7 +224,7 @@ func (l *desktop) createPrimaryContent() fyne.CanvasObject {
 	l.mouse = newMouse()
 	l.mouse.Hide()
 
-	return container.New(l, newBackground(), l.bar, l.widgets, l.mouse)
+	return container.New(l, newBackground(l.wallpaper(l.screens.Primary())), l.bar, l.widgets, l.mouse)
 }
 
 func (l *desktop) createRoot(screens fynedesk.ScreenList) fyne.Window {
@@ -142,10 +242,46 @@ func (l *desktop) setupRoot() {
 
 	scale := l.screens.Primary().CanvasScale()
 	l.root.Resize(fyne.NewSize(float32(l.screens.Primary().Width)/scale, float32(l.screens.Primary().Height)/scale))
+	l.setupScreenRoots()
+	l.updateEdgeTriggers()
+}
+
+// appsChanged is called when apps are installed or removed, or an icon theme changes.
+// Recent apps that were removed are dropped and the bars look up their apps and icons again.
+// It is called from the app watcher, so it takes the same lock as the settings listener to update the bars.
+func (l *desktop) appsChanged() {
+	l.refreshLock.Lock()
+	defer l.refreshLock.Unlock()
+
+	var recent []fynedesk.AppData
+	for _, app := range l.RecentApps() {
+		if found := l.icons.FindAppFromName(app.Name()); found != nil {
+			recent = append(recent, found)
+		}
+	}
+	l.setRecent(recent)
+	if l.bar == nil {
+		return
+	}
+
+	for _, b := range l.bars() {
+		b.updateIconOrder()
+		b.updateIcons()
+	}
 }
 
 func (l *desktop) RecentApps() []fynedesk.AppData {
-	return l.recent
+	l.recentLock.RLock()
+	defer l.recentLock.RUnlock()
+
+	return append([]fynedesk.AppData{}, l.recent...)
+}
+
+func (l *desktop) setRecent(apps []fynedesk.AppData) {
+	l.recentLock.Lock()
+	defer l.recentLock.Unlock()
+
+	l.recent = apps
 }
 
 func (l *desktop) Run() {
@@ -153,30 +289,66 @@ func (l *desktop) Run() {
 	l.run() // use the configured run method
 }
 
+// AppEnvironment returns the environment variables that apps are started with, so they scale to the active screen.
+func (l *desktop) AppEnvironment() []string {
+	return l.scaleVars(l.Screens().Active().CanvasScale())
+}
+
 func (l *desktop) RunApp(app fynedesk.AppData) error {
-	vars := l.scaleVars(l.Screens().Active().CanvasScale())
-	err := app.Run(vars)
+	err := app.Run(l.AppEnvironment())
 
 	if err == nil {
-		l.recent = append([]fynedesk.AppData{app}, l.recent...)
-		// remove if it was already on the list
-		for i := 1; i < len(l.recent); i++ {
-			if l.recent[i] == app {
-				if i == len(l.recent)-1 {
-					l.recent = l.recent[:i]
-				} else {
-					l.recent = append(l.recent[:i], l.recent[i+1:]...)
-				}
-				break
+		l.addRecent(app)
+	}
+	return err
+}
+
+// runAppAction starts one of the additional actions of an app, with the same environment as RunApp.
+func (l *desktop) runAppAction(app fynedesk.AppData, action fynedesk.AppAction) error {
+	err := action.Run(l.AppEnvironment())
+
+	if err == nil {
+		l.addRecent(app)
+	}
+	return err
+}
+
+// runAppWithFiles starts an app that can open files, passing the URIs of the files to open.
+func (l *desktop) runAppWithFiles(app fynedesk.AppData, opener recentFilesApp, files []string) error {
+	err := opener.RunWithFiles(l.AppEnvironment(), files)
+
+	if err == nil {
+		l.addRecent(app)
+	}
+	return err
+}
+
+// addRecent moves an app to the top of the recently used list and counts the launch for ranking.
+func (l *desktop) addRecent(app fynedesk.AppData) {
+	if l.usage != nil {
+		l.usage.record(app.Name(), time.Now())
+		go l.usage.persist()
+	}
+
+	l.recentLock.Lock()
+	l.recent = append([]fynedesk.AppData{app}, l.recent...)
+	// remove if it was already on the list
+	for i := 1; i < len(l.recent); i++ {
+		if l.recent[i] == app {
+			if i == len(l.recent)-1 {
+				l.recent = l.recent[:i]
+			} else {
+				l.recent = append(l.recent[:i], l.recent[i+1:]...)
 			}
+			break
 		}
-		// limit to 5 items
-		if len(l.recent) > 5 {
-			l.recent = l.recent[:5]
-		}
-		l.settings.(*deskSettings).saveRecents()
 	}
-	return err
+	// limit to 5 items
+	if len(l.recent) > 5 {
+		l.recent = l.recent[:5]
+	}
+	l.recentLock.Unlock()
+	l.settings.(*deskSettings).saveRecents()
 }
 
 func (l *desktop) Settings() fynedesk.DeskSettings {
@@ -184,21 +356,34 @@ func (l *desktop) Settings() fynedesk.DeskSettings {
 }
 
 func (l *desktop) ContentBoundsPixels(screen *fynedesk.Screen) (x, y, w, h uint32) {
-	screenW := uint32(screen.Width)
-	screenH := uint32(screen.Height)
-	pad := wmtheme.WidgetPanelWidth
-	if fynedesk.Instance().Settings().NarrowWidgetPanel() {
-		pad = wmtheme.NarrowBarWidth
-	}
+	var left, top, right, bottom float32
 	if l.screens.Primary() == screen {
-		bar := uint32(0)
-		if l.Settings().NarrowLeftLauncher() {
-			bar = uint32(wmtheme.NarrowBarWidth * screen.CanvasScale())
+		pad := wmtheme.WidgetPanelWidth
+		if l.Settings().NarrowWidgetPanel() {
+			pad = wmtheme.NarrowBarWidth
+		}
+		if l.widgetPanelLeft() {
+			left = pad
+		} else {
+			right = pad
+		}
+	}
+	if l.reservesBarSpace(screen) {
+		switch launcherEdge(l.Settings()) {
+		case edgeLeft:
+			left += wmtheme.NarrowBarWidth
+		case edgeRight:
+			right += wmtheme.NarrowBarWidth
+		case edgeTop:
+			top = wmtheme.NarrowBarWidth
+		default:
+			bottom = wmtheme.NarrowBarWidth
 		}
-		wid := uint32(pad * screen.CanvasScale())
-		return bar, 0, screenW - bar - wid, screenH
 	}
-	return 0, 0, screenW, screenH
+
+	scale := screen.CanvasScale()
+	x, y = uint32(left*scale), uint32(top*scale)
+	return x, y, uint32(screen.Width) - x - uint32(right*scale), uint32(screen.Height) - y - uint32(bottom*scale)
 }
 
 func (l *desktop) RootSizePixels() (w, h uint32) {
@@ -301,27 +486,49 @@ func (l *desktop) MouseOutNotify() {
 	if l.bar == nil {
 		return
 	}
-	l.bar.MouseOut()
+	for _, b := range l.bars() {
+		b.MouseOut()
+	}
 }
 
 func (l *desktop) startSettingsChangeListener(settings chan fynedesk.DeskSettings) {
-	for s := range settings {
+	for range settings {
+		l.refreshLock.Lock()
 		l.clearModuleCache()
-		l.updateBackgrounds(s.Background())
+		if l.screenRoots != nil {
+			l.setupScreenRoots()
+			l.updateEdgeTriggers()
+		}
+		l.updateBackgrounds()
 		l.widgets.reloadModules(l.Modules())
 
-		l.bar.iconSize = float32(l.Settings().LauncherIconSize())
-		l.bar.iconScale = float32(l.Settings().LauncherZoomScale())
-		l.bar.disableZoom = l.Settings().LauncherDisableZoom()
-		l.bar.updateIcons()
-		l.bar.updateIconOrder()
-		l.bar.updateTaskbar()
+		for _, b := range l.bars() {
+			b.iconSize = float32(l.Settings().LauncherIconSize())
+			b.iconScale = float32(l.Settings().LauncherZoomScale())
+			b.disableZoom = l.Settings().LauncherDisableZoom()
+			b.updateIcons()
+			b.updateIconOrder()
+			b.updateTaskbar()
+			b.updateTaskbarWindows()
+		}
+		l.refreshLayout()
+		l.refreshLock.Unlock()
+	}
+}
+
+// refreshLayout lays out the content of each desktop window again, so that bars and panels follow the settings.
+func (l *desktop) refreshLayout() {
+	if l.root != nil {
+		l.root.Content().Refresh()
+	}
+	for _, root := range l.screenRoots {
+		root.win.Content().Refresh()
 	}
 }
 
 func (l *desktop) startFyneSettingsChangeListener(settings chan fyne.Settings) {
 	for range settings {
-		l.updateBackgrounds(l.Settings().Background())
+		l.updateBackgrounds()
 	}
 }
 
@@ -350,6 +557,14 @@ func (l *desktop) registerShortcuts() {
 		l.screenshotWindow)
 	l.AddShortcut(fynedesk.NewShortcut("Print Screen", deskDriver.KeyPrintScreen, 0),
 		l.screenshot)
+	l.AddShortcut(fynedesk.NewShortcut("Print Region", deskDriver.KeyPrintScreen, fynedesk.UserModifier),
+		l.screenshotRegion)
+	l.AddShortcut(fynedesk.NewShortcut("Print Screen After Delay", deskDriver.KeyPrintScreen, fyne.KeyModifierAlt),
+		l.screenshotDelayed)
+	l.AddShortcut(fynedesk.NewShortcut("Copy Screen", deskDriver.KeyPrintScreen, fyne.KeyModifierControl),
+		l.screenshotToClipboard)
+	l.AddShortcut(fynedesk.NewShortcut("Copy Region", deskDriver.KeyPrintScreen,
+		fyne.KeyModifierControl|fynedesk.UserModifier), l.screenshotRegionToClipboard)
 	l.AddShortcut(fynedesk.NewShortcut("Calculator", fynedesk.KeyCalculator, 0),
 		l.calculator)
 	l.AddShortcut(fynedesk.NewShortcut("Lock screen", fyne.KeyL, fynedesk.UserModifier),
@@ -381,8 +596,15 @@ func NewDesktop(app fyne.App, mgr fynedesk.WindowManager, icons fynedesk.Applica
 	desk.run = desk.runFull
 	screenProvider.AddChangeListener(desk.setupRoot)
 	desk.screens = screenProvider
+	if apps, ok := icons.(appChangeNotifier); ok {
+		apps.AddChangeListener(desk.appsChanged)
+	}
 
 	desk.setupRoot()
+	startOSD(desk)
+	go startScreenshotService(desk)
+	desk.inhibit = &portalInhibit{}
+	go startPortal(desk)
 	wm.StartAuthAgent()
 	go desk.startXscreensaver()
 	return desk
@@ -408,7 +630,9 @@ func newDesktop(app fyne.App, wm fynedesk.WindowManager, icons fynedesk.Applicat
 
 	fynedesk.SetInstance(desk)
 	desk.settings = newDeskSettings()
+	desk.usage = loadLaunchUsage()
 	desk.addSettingsChangeListener()
+	go desk.runSlideshow()
 
 	desk.registerShortcuts()
 	return desk
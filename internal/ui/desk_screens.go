@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+
+	"fyshos.com/fynedesk"
+)
+
+// stackListenerRemover is implemented by window managers that can stop telling a listener about window changes.
+type stackListenerRemover interface {
+	RemoveStackListener(fynedesk.StackListener)
+}
+
+// screenRoot is a desktop window for a secondary screen, it holds a background and an app bar.
+// The widget panel, and the status modules within it, are only shown on the primary screen.
+type screenRoot struct {
+	desk   *desktop
+	screen *fynedesk.Screen
+	win    fyne.Window
+
+	bar *bar
+	bg  *background
+}
+
+func (r *screenRoot) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	objects[0].Resize(size)
+	r.desk.layoutBar(r.bar, size, 0)
+}
+
+func (r *screenRoot) MinSize(_ []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(640, 480) // tiny - window manager will scale up to screen size
+}
+
+// close removes the window for a screen that no longer shows a bar, and stops its bar listening for windows.
+func (r *screenRoot) close() {
+	if remover, ok := r.desk.WindowManager().(stackListenerRemover); ok {
+		remover.RemoveStackListener(r.bar)
+	}
+	r.win.Close()
+}
+
+func (r *screenRoot) resize() {
+	scale := r.screen.CanvasScale()
+	r.win.Resize(fyne.NewSize(float32(r.screen.Width)/scale, float32(r.screen.Height)/scale))
+}
+
+// barForScreen returns the bar that is shown on the specified screen.
+// If the screen does not have its own bar then the bar on the primary screen is returned.
+func (l *desktop) barForScreen(screen *fynedesk.Screen) *bar {
+	if screen == nil || screen == l.screens.Primary() {
+		return l.bar
+	}
+
+	if root, ok := l.screenRoots[screen.Name]; ok && l.showsBarOnScreen(screen) {
+		return root.bar
+	}
+	return l.bar
+}
+
+func (l *desktop) bars() []*bar {
+	bars := []*bar{l.bar}
+	for _, root := range l.screenRoots {
+		if l.showsBarOnScreen(root.screen) {
+			bars = append(bars, root.bar)
+		}
+	}
+
+	return bars
+}
+
+func (l *desktop) createScreenRoot(screen *fynedesk.Screen) *screenRoot {
+	root := &screenRoot{desk: l, screen: screen}
+	root.win = l.app.NewWindow(RootWindowName + screen.Name)
+	root.win.SetPadded(false)
+
+	root.bar = newBar(l)
+	root.bg = newBackground(l.wallpaper(screen))
+	root.bg.wallpaperOnly = true
+	root.win.SetContent(container.New(root, root.bg, root.bar))
+	root.bar.updateTaskbarWindows()
+	return root
+}
+
+// setupScreenRoots makes sure that every secondary screen configured to have an app bar has a root window.
+// Windows for screens that are no longer in use are closed, so that their bars stop listening for windows.
+func (l *desktop) setupScreenRoots() {
+	if l.screenRoots == nil {
+		l.screenRoots = make(map[string]*screenRoot)
+	}
+
+	inUse := make(map[string]bool)
+	for _, screen := range l.screens.Screens() {
+		if screen == l.screens.Primary() || !l.showsBarOnScreen(screen) {
+			continue
+		}
+
+		inUse[screen.Name] = true
+		root, ok := l.screenRoots[screen.Name]
+		if !ok {
+			root = l.createScreenRoot(screen)
+			l.screenRoots[screen.Name] = root
+		}
+		root.screen = screen
+		root.resize()
+		root.win.Show()
+	}
+
+	for name, root := range l.screenRoots {
+		if !inUse[name] {
+			root.close()
+			delete(l.screenRoots, name)
+		}
+	}
+}
+
+// reservesBarSpace returns true if windows on the given screen should not overlap the app bar.
+// Only narrow bars reserve space, and only when they are not set to hide automatically.
+func (l *desktop) reservesBarSpace(screen *fynedesk.Screen) bool {
+	s := l.Settings()
+	return s.NarrowLeftLauncher() && !s.LauncherAutoHide() && l.showsBarOnScreen(screen)
+}
+
+// showsBarOnScreen returns true if the user has requested that an app bar is shown on the given screen.
+// The primary screen will always show a bar.
+func (l *desktop) showsBarOnScreen(screen *fynedesk.Screen) bool {
+	if screen == l.screens.Primary() || l.Settings().LauncherAllScreens() {
+		return true
+	}
+
+	for _, name := range l.Settings().LauncherScreens() {
+		if name == screen.Name {
+			return true
+		}
+	}
+	return false
+}
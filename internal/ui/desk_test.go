@@ -39,6 +39,30 @@ func TestDeskLayout_Layout(t *testing.T) {
 	}
 }
 
+func TestDeskLayout_LayoutEdges(t *testing.T) {
+	l := &desktop{screens: wmTest.NewScreensProvider(&fynedesk.Screen{Name: "Screen0", X: 0, Y: 0,
+		Width: 2000, Height: 1000, Scale: 1.0})}
+	l.bar = testBar([]string{"fyne"})
+	settings := l.bar.desk.Settings().(*wmTest.Settings)
+	l.settings = settings
+	l.widgets = newWidgetPanel(l)
+	bg := &background{wallpaper: container.NewStack(canvas.NewImageFromResource(wmTheme.AppIcon))}
+	deskSize := fyne.NewSize(2000, 1000)
+
+	settings.SetNarrowLeftLauncher(true)
+	settings.SetLauncherPosition("Right")
+	settings.SetWidgetPanelPosition("Left")
+	l.Layout([]fyne.CanvasObject{bg, l.bar, l.widgets}, deskSize)
+	assert.Equal(t, float32(0), l.widgets.Position().X)
+	assert.Equal(t, deskSize.Width-wmTheme.NarrowBarWidth, l.bar.Position().X)
+	assert.Equal(t, deskSize.Height, l.bar.Size().Height)
+
+	settings.SetLauncherPosition("Top")
+	l.Layout([]fyne.CanvasObject{bg, l.bar, l.widgets}, deskSize)
+	assert.Equal(t, fyne.NewPos(l.widgets.Size().Width, 0), l.bar.Position())
+	assert.Equal(t, fyne.NewSize(deskSize.Width-l.widgets.Size().Width, wmTheme.NarrowBarWidth), l.bar.Size())
+}
+
 func TestScaleVars_Up(t *testing.T) {
 	l := &desktop{}
 	l.screens = wmTest.NewScreensProvider()
@@ -75,6 +99,92 @@ func TestBackgroundChange(t *testing.T) {
 	}
 
 	l.settings.(*wmTest.Settings).SetBackground(filepath.Join(workingDir, "testdata", "fyne.png"))
-	l.updateBackgrounds(l.Settings().Background())
+	l.updateBackgrounds()
 	assert.Equal(t, l.settings.Background(), bg.wallpaper.Objects[0].(*canvas.Image).File)
 }
+
+func TestDesktop_ShowsBarOnScreen(t *testing.T) {
+	primary := &fynedesk.Screen{Name: "Screen0", X: 0, Y: 0, Width: 2000, Height: 1000, Scale: 1.0}
+	second := &fynedesk.Screen{Name: "Screen1", X: 2000, Y: 0, Width: 1000, Height: 1000, Scale: 1.0}
+	settings := wmTest.NewSettings()
+	l := &desktop{screens: wmTest.NewScreensProvider(primary, second), settings: settings}
+
+	assert.True(t, l.showsBarOnScreen(primary))
+	assert.False(t, l.showsBarOnScreen(second))
+	_, _, w, _ := l.ContentBoundsPixels(second)
+	assert.Equal(t, uint32(1000), w)
+
+	settings.SetLauncherScreens([]string{"Screen1"})
+	assert.True(t, l.showsBarOnScreen(second))
+
+	settings.SetLauncherScreens(nil)
+	settings.SetLauncherAllScreens(true)
+	assert.True(t, l.showsBarOnScreen(second))
+
+	settings.SetNarrowLeftLauncher(true)
+	x, _, w, _ := l.ContentBoundsPixels(second)
+	assert.Equal(t, uint32(wmTheme.NarrowBarWidth), x)
+	assert.Equal(t, 1000-uint32(wmTheme.NarrowBarWidth), w)
+}
+
+func TestDesktop_SetupScreenRoots(t *testing.T) {
+	primary := &fynedesk.Screen{Name: "Screen0", X: 0, Y: 0, Width: 2000, Height: 1000, Scale: 1.0}
+	second := &fynedesk.Screen{Name: "Screen1", X: 2000, Y: 0, Width: 1000, Height: 1000, Scale: 1.0}
+	settings := wmTest.NewSettings()
+	settings.SetLauncherScreens([]string{"Screen1"})
+	mgr := &listenerWM{}
+	l := &desktop{app: test.NewApp(), screens: wmTest.NewScreensProvider(primary, second), settings: settings, wm: mgr}
+	fynedesk.SetInstance(l)
+
+	l.setupScreenRoots()
+	assert.Equal(t, 1, len(l.screenRoots))
+	assert.Equal(t, []fynedesk.StackListener{l.screenRoots["Screen1"].bar}, mgr.listeners)
+
+	settings.SetLauncherScreens(nil)
+	l.setupScreenRoots()
+	assert.Empty(t, l.screenRoots)
+	assert.Empty(t, mgr.listeners)
+}
+
+func TestDesktop_ContentBoundsPixels(t *testing.T) {
+	primary := &fynedesk.Screen{Name: "Screen0", X: 0, Y: 0, Width: 2000, Height: 1000, Scale: 1.0}
+	settings := wmTest.NewSettings()
+	l := &desktop{screens: wmTest.NewScreensProvider(primary), settings: settings}
+	narrow := uint32(wmTheme.NarrowBarWidth)
+
+	settings.SetNarrowLeftLauncher(true)
+	settings.SetNarrowWidgetPanel(true)
+	settings.SetLauncherPosition("Top")
+	settings.SetWidgetPanelPosition("Left")
+	x, y, w, h := l.ContentBoundsPixels(primary)
+	assert.Equal(t, narrow, x)
+	assert.Equal(t, narrow, y)
+	assert.Equal(t, 2000-narrow, w)
+	assert.Equal(t, 1000-narrow, h)
+
+	settings.SetLauncherAutoHide(true)
+	x, y, w, h = l.ContentBoundsPixels(primary)
+	assert.Equal(t, narrow, x)
+	assert.Equal(t, uint32(0), y)
+	assert.Equal(t, 2000-narrow, w)
+	assert.Equal(t, uint32(1000), h)
+}
+
+// listenerWM is a window manager that keeps track of its stack listeners.
+type listenerWM struct {
+	embededWM
+	listeners []fynedesk.StackListener
+}
+
+func (w *listenerWM) AddStackListener(l fynedesk.StackListener) {
+	w.listeners = append(w.listeners, l)
+}
+
+func (w *listenerWM) RemoveStackListener(l fynedesk.StackListener) {
+	for i, listener := range w.listeners {
+		if listener == l {
+			w.listeners = append(w.listeners[:i], w.listeners[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Scores given to each character of a fuzzy match, a perfect match scores every bonus for every character.
+const (
+	fuzzyCharScore        = 1.0
+	fuzzyStartBonus       = 1.0 // the match begins the text
+	fuzzyWordBonus        = 2.0 // the character starts a word
+	fuzzyConsecutiveBonus = 2.0 // the character follows the previous match
+	fuzzyGapPenalty       = 0.2 // for each character skipped between matches
+)
+
+// fuzzyMatch scores how well a pattern matches some text, ignoring case.
+// The characters of the pattern must all appear in the text in order, but they can be separated.
+// The score is 0 if the pattern does not match and 1 if the text is the pattern, with matches at the
+// start of words or in a run scoring more highly. The rune positions of the matched text are returned so
+// that they can be highlighted.
+func fuzzyMatch(pattern, text string) (float64, []int) {
+	find := []rune(strings.ToLower(pattern))
+	in := []rune(text)
+	if len(find) == 0 || len(find) > len(in) {
+		return 0, nil
+	}
+
+	// best[i][j] is the highest score of matching find[:i+1] with find[i] at in[j].
+	// from[i][j] is the position of find[i-1] in that match.
+	noMatch := math.Inf(-1)
+	best := make([][]float64, len(find))
+	from := make([][]int, len(find))
+	for i := range find {
+		best[i] = make([]float64, len(in))
+		from[i] = make([]int, len(in))
+		carry, carryFrom := noMatch, -1 // the best earlier match of find[i-1], less the gap to here
+		for j := range in {
+			best[i][j] = noMatch
+			if i > 0 && j > 1 {
+				carry -= fuzzyGapPenalty
+				if gapped := best[i-1][j-2] - fuzzyGapPenalty; gapped > carry {
+					carry, carryFrom = gapped, j-2
+				}
+			}
+			if unicode.ToLower(in[j]) != find[i] {
+				continue
+			}
+
+			char := fuzzyCharScore
+			if isWordStart(in, j) {
+				char += fuzzyWordBonus
+			}
+			if i == 0 {
+				if j == 0 {
+					char += fuzzyStartBonus
+				}
+				best[i][j] = char
+				continue
+			}
+
+			if j > 0 && best[i-1][j-1]+fuzzyConsecutiveBonus >= carry {
+				best[i][j] = best[i-1][j-1] + char + fuzzyConsecutiveBonus
+				from[i][j] = j - 1
+			} else {
+				best[i][j] = carry + char
+				from[i][j] = carryFrom
+			}
+		}
+	}
+
+	last, end := len(find)-1, -1
+	for j, score := range best[last] {
+		if !math.IsInf(score, -1) && (end == -1 || score > best[last][end]) {
+			end = j
+		}
+	}
+	if end == -1 {
+		return 0, nil
+	}
+
+	matched := make([]int, len(find))
+	for i, j := last, end; i >= 0; i-- {
+		matched[i] = j
+		j = from[i][j]
+	}
+
+	perfect := fuzzyCharScore + fuzzyWordBonus + fuzzyStartBonus +
+		float64(len(find)-1)*(fuzzyCharScore+fuzzyConsecutiveBonus)
+	score := best[last][end] / perfect
+	// prefer shorter texts when matches are otherwise equal
+	score *= 0.9 + 0.1*float64(len(find))/float64(len(in))
+	return clampScore(score), matched
+}
+
+// clampScore keeps a match score between 0 and 1, but never 0 as that means no match.
+func clampScore(score float64) float64 {
+	if score > 1 {
+		return 1
+	} else if score < 0.01 {
+		return 0.01
+	}
+
+	return score
+}
+
+// isWordStart returns true if the rune at pos begins a word, either after a separator or a change of case.
+func isWordStart(text []rune, pos int) bool {
+	if pos == 0 {
+		return true
+	}
+
+	prev, r := text[pos-1], text[pos]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(r)
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	score, matched := fuzzyMatch("fire", "Firefox")
+	assert.Equal(t, []int{0, 1, 2, 3}, matched)
+	exact, _ := fuzzyMatch("firefox", "Firefox")
+	assert.Equal(t, 1.0, exact)
+	assert.Less(t, score, exact)
+
+	score, matched = fuzzyMatch("xyz", "Firefox")
+	assert.Zero(t, score)
+	assert.Nil(t, matched)
+	score, _ = fuzzyMatch("", "Firefox")
+	assert.Zero(t, score)
+}
+
+func TestFuzzyMatch_WordStarts(t *testing.T) {
+	_, matched := fuzzyMatch("ge", "Gnome Text Editor")
+	assert.Equal(t, []int{0, 11}, matched)
+	_, matched = fuzzyMatch("te", "Gnome Text Editor")
+	assert.Equal(t, []int{6, 7}, matched)
+
+	initials, _ := fuzzyMatch("vsc", "VisualStudioCode")
+	scattered, _ := fuzzyMatch("vsc", "Visual Basics")
+	assert.Greater(t, initials, scattered)
+
+	prefix, _ := fuzzyMatch("term", "Terminal")
+	middle, _ := fuzzyMatch("term", "Xterm")
+	assert.Greater(t, prefix, middle)
+
+	short, _ := fuzzyMatch("files", "Files")
+	long, _ := fuzzyMatch("files", "Files and Folders")
+	assert.Greater(t, short, long)
+}
@@ -1,6 +1,10 @@
 package ui
 
 import (
+	"math"
+	"sort"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	deskDriver "fyne.io/fyne/v2/driver/desktop"
@@ -8,11 +12,70 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"fyshos.com/fynedesk"
-	wmTheme "fyshos.com/fynedesk/theme"
+)
+
+const (
+	// actionScoreWeight ranks app actions below the app that they belong to.
+	actionScoreWeight = 0.9
+	// defaultSuggestionScore ranks module suggestions that do not have a score.
+	// Modules usually only suggest items for input that was meant for them, so these are a strong match.
+	defaultSuggestionScore = 1.0
+	// maxLauncherResults limits the list, as the worst fuzzy matches are rarely useful.
+	maxLauncherResults = 30
+
+	// Match scores of the details that an app may be searched by, relative to its name.
+	genericNameWeight = 0.8
+	keywordWeight     = 0.7
+	executableWeight  = 0.6
 )
 
 var appExec *picker
 
+// iconSource is an app or action that can load an icon for the launcher list.
+type iconSource interface {
+	Icon(theme string, size int) fyne.Resource
+}
+
+// searchableApp is implemented by apps that have details to search as well as their name.
+type searchableApp interface {
+	GenericName() string
+	Keywords() []string
+	Executable() string
+}
+
+// launchResult is an app, app action or module suggestion that matched the launcher input.
+type launchResult struct {
+	title   string
+	matched []int // rune positions of the title that matched the input
+	score   float64
+	launch  func()
+
+	copyText string // the text to copy if picked with Alt-Return
+	reveal   func() // shows where the item is if picked with Control-Return
+
+	icon       fyne.Resource
+	iconSource iconSource // loads the icon in the background, if set
+}
+
+// appMatch returns the best fuzzy match score of the input for the details of an app.
+// Only the name is shown, so the positions to highlight are returned if the name matched.
+func appMatch(input string, app fynedesk.AppData) (float64, []int) {
+	score, matched := fuzzyMatch(input, app.Name())
+	search, ok := app.(searchableApp)
+	if !ok {
+		return score, matched
+	}
+
+	generic, _ := fuzzyMatch(input, search.GenericName())
+	score = math.Max(score, generic*genericNameWeight)
+	for _, key := range search.Keywords() {
+		keyScore, _ := fuzzyMatch(input, key)
+		score = math.Max(score, keyScore*keywordWeight)
+	}
+	command, _ := fuzzyMatch(input, search.Executable())
+	return math.Max(score, command*executableWeight), matched
+}
+
 type appEntry struct {
 	widget.Entry
 
@@ -34,12 +97,31 @@ func (e *appEntry) TypedKey(ev *fyne.KeyEvent) {
 	}
 }
 
+// TypedShortcut picks the selected item in other ways when Return is pressed with a modifier.
+// Control reveals the item, for example in the file manager, and Alt copies its text.
+func (e *appEntry) TypedShortcut(s fyne.Shortcut) {
+	if custom, ok := s.(*deskDriver.CustomShortcut); ok &&
+		(custom.KeyName == fyne.KeyReturn || custom.KeyName == fyne.KeyEnter) {
+		switch custom.Modifier {
+		case fyne.KeyModifierControl:
+			e.pick.revealSelected()
+			return
+		case fyne.KeyModifierAlt:
+			e.pick.copySelected()
+			return
+		}
+	}
+
+	e.Entry.TypedShortcut(s)
+}
+
 type picker struct {
 	win      fyne.Window
 	desk     fynedesk.Desktop
 	callback func(data fynedesk.AppData)
 	showMods bool
 
+	apps        []fynedesk.AppData
 	entry       *appEntry
 	appList     *fyne.Container
 	activeIndex int
@@ -54,7 +136,35 @@ func (l *picker) pickSelected() {
 		return
 	}
 
-	l.appList.Objects[l.activeIndex].(*widget.Button).OnTapped()
+	l.appList.Objects[l.activeIndex].(*launchItem).Tapped(nil)
+}
+
+// copySelected puts the text of the selected item on the clipboard, if it has any.
+func (l *picker) copySelected() {
+	if len(l.appList.Objects) == 0 {
+		return
+	}
+	text := l.appList.Objects[l.activeIndex].(*launchItem).result.copyText
+	if text == "" {
+		return
+	}
+
+	l.win.Clipboard().SetContent(text)
+	l.win.Close()
+}
+
+// revealSelected shows where the selected item is, if it can be revealed.
+func (l *picker) revealSelected() {
+	if len(l.appList.Objects) == 0 {
+		return
+	}
+	reveal := l.appList.Objects[l.activeIndex].(*launchItem).result.reveal
+	if reveal == nil {
+		return
+	}
+
+	l.win.Close()
+	reveal()
 }
 
 func (l *picker) setActiveIndex(index int) {
@@ -62,60 +172,117 @@ func (l *picker) setActiveIndex(index int) {
 		return
 	}
 
-	l.appList.Objects[l.activeIndex].(*widget.Button).Importance = widget.MediumImportance
-	l.appList.Objects[index].(*widget.Button).Importance = widget.HighImportance
+	l.appList.Objects[l.activeIndex].(*launchItem).setActive(false)
+	l.appList.Objects[index].(*launchItem).setActive(true)
 	l.activeIndex = index
 	l.appList.Refresh()
 }
 
 func (l *picker) updateAppListMatching(input string) {
 	l.activeIndex = 0
-	l.appList.Objects = l.appButtonListMatching(input)
+	l.appList.Objects = l.resultListMatching(input)
 	l.appList.Refresh()
 }
 
-func (l *picker) appButtonListMatching(input string) []fyne.CanvasObject {
-	var appList []fyne.CanvasObject
-	var iconList = []fynedesk.AppData{}
-
-	dataRange := l.desk.IconProvider().FindAppsMatching(input)
-	for _, data := range dataRange {
-		if data == nil || data.Hidden() {
-			continue
-		}
-		appData := data // capture for goroutine below
-		app := widget.NewButtonWithIcon(appData.Name(), wmTheme.BrokenImageIcon, func() {
-			l.callback(appData)
-			l.win.Close()
-		})
-		app.Alignment = widget.ButtonAlignLeading
+// resultListMatching returns the items to show in the list for the input, best match first.
+func (l *picker) resultListMatching(input string) []fyne.CanvasObject {
+	results := l.rankedResults(input)
+	items := make([]fyne.CanvasObject, len(results))
+	for i, result := range results {
+		items[i] = newLaunchItem(result)
+	}
+	go l.loadIcons(items)
 
-		appList = append(appList, app)
-		iconList = append(iconList, data)
+	if len(items) > 0 {
+		items[0].(*launchItem).setActive(true)
 	}
-	go l.loadIcons(iconList, appList)
+	return items
+}
 
-	appList = append(appList, l.loadSuggestionsMatching(input)...)
-	if len(appList) > 0 {
-		appList[0].(*widget.Button).Importance = widget.HighImportance
+// rankedResults matches the input against apps, sorted by score.
+// If module suggestions are shown then open windows and desktop actions are matched as well.
+func (l *picker) rankedResults(input string) []*launchResult {
+	results := l.appResultsMatching(input)
+	if l.showMods {
+		results = append(results, l.windowResultsMatching(input)...)
+		results = append(results, l.deskActionResultsMatching(input)...)
+		results = append(results, l.suggestionResultsMatching(input)...)
 	}
 
-	return appList
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	if len(results) > maxLauncherResults {
+		results = results[:maxLauncherResults]
+	}
+	return results
 }
 
-func (l *picker) loadIcons(dataRange []fynedesk.AppData, appList []fyne.CanvasObject) {
-	iconTheme := l.desk.Settings().IconTheme()
+// appResultsMatching returns apps that fuzzy match the input, boosted by how often and recently they were used.
+// If the launcher shows module suggestions the app actions are matched as well.
+func (l *picker) appResultsMatching(input string) []*launchResult {
+	if l.apps == nil {
+		l.apps = l.desk.IconProvider().AvailableApps()
+	}
+	var usage *launchUsage
+	if desk, ok := l.desk.(*desktop); ok {
+		usage = desk.usage
+	}
+
+	now := time.Now()
+	var results []*launchResult
+	for _, data := range l.apps {
+		if data == nil || data.Hidden() {
+			continue
+		}
+		appData := data // capture for callback below
+		boost := usage.boost(appData.Name(), now)
+		if score, matched := appMatch(input, appData); score > 0 {
+			results = append(results, &launchResult{title: appData.Name(), matched: matched, score: score + boost,
+				iconSource: appData, launch: func() {
+					l.callback(appData)
+					l.win.Close()
+				}})
+		}
+		if !l.showMods {
+			continue
+		}
 
-	for i, data := range dataRange {
-		app := appList[i].(*widget.Button)
-		icon := data.Icon(iconTheme, 32)
-		app.SetIcon(icon)
+		for _, action := range appData.Actions() {
+			title := appData.Name() + ": " + action.Name()
+			score, matched := fuzzyMatch(input, title)
+			if score == 0 {
+				continue
+			}
+
+			appAction := action // capture for callback below
+			results = append(results, &launchResult{title: title, matched: matched,
+				score: score*actionScoreWeight + boost, iconSource: action, launch: func() {
+					l.win.Close()
+					l.runAction(appData, appAction)
+				}})
+		}
 	}
+	return results
 }
 
-func (l *picker) loadSuggestionsMatching(input string) []fyne.CanvasObject {
-	var suggestList []fyne.CanvasObject
+// runAction starts an app action using the desktop environment, if it is available.
+func (l *picker) runAction(app fynedesk.AppData, action fynedesk.AppAction) {
+	var err error
+	if desk, ok := l.desk.(*desktop); ok {
+		err = desk.runAppAction(app, action)
+	} else {
+		err = action.Run(nil)
+	}
+	if err != nil {
+		fyne.LogError("Failed to start app action", err)
+	}
+}
 
+// suggestionResultsMatching returns the suggestions of modules for the input.
+// Suggestions that do not report a score are ranked as a strong match.
+func (l *picker) suggestionResultsMatching(input string) []*launchResult {
+	var results []*launchResult
 	for _, m := range l.desk.Modules() {
 		suggest, ok := m.(fynedesk.LaunchSuggestionModule)
 		if !ok {
@@ -123,17 +290,41 @@ func (l *picker) loadSuggestionsMatching(input string) []fyne.CanvasObject {
 		}
 
 		for _, item := range suggest.LaunchSuggestions(input) {
-			launchData := item // capture for goroutine below
-			button := widget.NewButtonWithIcon(item.Title(), item.Icon(), func() {
-				l.win.Close()
-				launchData.Launch()
-			})
-
-			suggestList = append(suggestList, button)
+			launchData := item // capture for callback below
+			score := defaultSuggestionScore
+			if scored, ok := item.(fynedesk.ScoredLaunchSuggestion); ok {
+				score = scored.Score()
+			}
+			_, matched := fuzzyMatch(input, item.Title())
+
+			result := &launchResult{title: item.Title(), matched: matched, score: score, icon: item.Icon(),
+				launch: func() {
+					l.win.Close()
+					launchData.Launch()
+				}}
+			if copyable, ok := item.(fynedesk.CopyLaunchSuggestion); ok {
+				result.copyText = copyable.CopyText()
+			}
+			if revealable, ok := item.(fynedesk.RevealLaunchSuggestion); ok {
+				result.reveal = revealable.Reveal
+			}
+			results = append(results, result)
 		}
 	}
 
-	return suggestList
+	return results
+}
+
+func (l *picker) loadIcons(items []fyne.CanvasObject) {
+	iconTheme := l.desk.Settings().IconTheme()
+
+	for _, obj := range items {
+		item := obj.(*launchItem)
+		if item.result.iconSource == nil {
+			continue
+		}
+		item.setIcon(item.result.iconSource.Icon(iconTheme, 32))
+	}
 }
 
 func (l *picker) Show() {
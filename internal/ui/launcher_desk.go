@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"fyshos.com/fynedesk"
+	wmTheme "fyshos.com/fynedesk/theme"
+	"fyshos.com/fynedesk/wm"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// windowBoost ranks open windows above the app that they belong to, even after its usage boost,
+	// so that picking a running app focuses it instead of starting it again.
+	windowBoost = usageBoost + 0.1
+	// windowClassWeight is the match score of a window class, relative to its title.
+	windowClassWeight = 0.9
+
+	// minDeskActionMatch leaves out desktop actions that match the input poorly, so that the many actions
+	// do not crowd the list for short input.
+	minDeskActionMatch = 0.7
+	// deskActionDelay gives the launcher time to close before an action such as a screenshot runs.
+	deskActionDelay = time.Second / 10
+)
+
+// launcherSkipShortcuts are registered shortcuts that make no sense to pick from the launcher.
+var launcherSkipShortcuts = map[string]bool{
+	"Show Launcher":       true,
+	"Switch App Next":     true,
+	"Switch App Previous": true,
+}
+
+// deskAction is something the desktop can do that is offered as a launcher result.
+type deskAction struct {
+	name string
+	icon fyne.Resource
+	run  func()
+}
+
+// deskActions returns the built in actions of the desktop and every registered shortcut, sorted by name.
+// Registered shortcuts are preferred over built in actions of the same name, as modules may track their state.
+func (l *picker) deskActions() []*deskAction {
+	var actions []*deskAction
+	names := make(map[string]bool)
+	if shorts, ok := l.desk.(wm.ShortcutManager); ok {
+		for _, short := range shorts.Shortcuts() {
+			if launcherSkipShortcuts[short.Name] || names[strings.ToLower(short.Name)] {
+				continue
+			}
+
+			shortcut := short // capture for callback below
+			names[strings.ToLower(short.Name)] = true
+			actions = append(actions, &deskAction{name: short.Name, icon: wmTheme.KeyboardIcon, run: func() {
+				shorts.TypedShortcut(shortcut)
+			}})
+		}
+	}
+
+	for _, action := range l.builtinDeskActions() {
+		if !names[strings.ToLower(action.name)] {
+			actions = append(actions, action)
+		}
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].name < actions[j].name
+	})
+	return actions
+}
+
+// builtinDeskActions returns the actions that are always available in the full desktop.
+func (l *picker) builtinDeskActions() []*deskAction {
+	desk, ok := l.desk.(*desktop)
+	if !ok {
+		return nil
+	}
+
+	actions := []*deskAction{
+		{name: "Lock Screen", icon: wmTheme.LockIcon, run: desk.LockScreen},
+		{name: "Suspend", icon: wmTheme.PowerIcon, run: suspend},
+		{name: "Take Screenshot", icon: wmTheme.DisplayIcon, run: desk.screenshot},
+	}
+	if desk.widgets != nil {
+		actions = append(actions,
+			&deskAction{name: "Log Out", icon: theme.LogoutIcon(), run: desk.widgets.askLogout},
+			&deskAction{name: "Settings", icon: theme.SettingsIcon(), run: desk.widgets.showSettings})
+	}
+	for i := 0; i < desktopCount; i++ {
+		id := i // capture for callback below
+		actions = append(actions, &deskAction{name: "Switch to Desktop " + strconv.Itoa(i+1),
+			icon: theme.ComputerIcon(), run: func() {
+				desk.SetDesktop(id)
+			}})
+	}
+	return actions
+}
+
+// deskActionResultsMatching returns the desktop actions that closely fuzzy match the input.
+func (l *picker) deskActionResultsMatching(input string) []*launchResult {
+	var results []*launchResult
+	for _, action := range l.deskActions() {
+		score, matched := fuzzyMatch(input, action.name)
+		if score < minDeskActionMatch {
+			continue
+		}
+
+		run := action.run // capture for callback below
+		results = append(results, &launchResult{title: action.name, matched: matched,
+			score: score * actionScoreWeight, icon: action.icon, launch: func() {
+				l.win.Close()
+				go func() {
+					time.Sleep(deskActionDelay)
+					run()
+				}()
+			}})
+	}
+	return results
+}
+
+// windowResultsMatching returns the open windows, on any desktop, whose title or class fuzzy match the input.
+func (l *picker) windowResultsMatching(input string) []*launchResult {
+	mgr := l.desk.WindowManager()
+	if mgr == nil {
+		return nil
+	}
+
+	var results []*launchResult
+	for _, win := range mgr.Windows() {
+		props := win.Properties()
+		if props.SkipTaskbar() || props.Title() == "" {
+			continue
+		}
+
+		score, matched := fuzzyMatch(input, props.Title())
+		for _, class := range props.Class() {
+			classScore, _ := fuzzyMatch(input, class)
+			score = math.Max(score, classScore*windowClassWeight)
+		}
+		if score == 0 {
+			continue
+		}
+
+		title := props.Title()
+		if win.Desktop() != l.desk.Desktop() {
+			title += "  (Desktop " + strconv.Itoa(win.Desktop()+1) + ")"
+		}
+		window := win // capture for callback below
+		result := &launchResult{title: title, matched: matched, score: score + windowBoost, launch: func() {
+			l.win.Close()
+			focusWindow(l.desk, window)
+		}}
+		if app := l.desk.IconProvider().FindAppFromWinInfo(win); app != nil {
+			result.iconSource = app
+		} else {
+			result.icon = props.Icon()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// focusWindow shows a window, switching to its desktop and restoring it if it was iconified.
+func focusWindow(desk fynedesk.Desktop, win fynedesk.Window) {
+	if win.Desktop() != desk.Desktop() {
+		desk.SetDesktop(win.Desktop())
+	}
+	if win.Iconic() {
+		win.Uniconify()
+	}
+	win.RaiseToTop()
+	win.Focus()
+}
+
+// suspend asks logind to put the computer to sleep.
+func suspend() {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		fyne.LogError("Unable to connect to system bus", err)
+		return
+	}
+
+	err = conn.Object("org.freedesktop.login1", "/org/freedesktop/login1").Call(
+		"org.freedesktop.login1.Manager.Suspend", 0, true).Err
+	if err != nil {
+		fyne.LogError("Unable to suspend", err)
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	deskDriver "fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	wmTheme "fyshos.com/fynedesk/theme"
+)
+
+// launchItem is a row of the launcher list, showing the icon and title of a result.
+// The parts of the title that matched the input are shown in bold.
+type launchItem struct {
+	widget.BaseWidget
+
+	result          *launchResult
+	active, hovered bool
+
+	background *canvas.Rectangle
+	icon       *widget.Icon
+}
+
+func (i *launchItem) CreateRenderer() fyne.WidgetRenderer {
+	i.background = canvas.NewRectangle(color.Transparent)
+	i.updateBackground()
+	res := i.result.icon
+	if res == nil {
+		res = wmTheme.BrokenImageIcon
+	}
+	i.icon = widget.NewIcon(res)
+
+	title := widget.NewRichText(highlightSegments(i.result.title, i.result.matched)...)
+	title.Truncation = fyne.TextTruncateEllipsis
+	return widget.NewSimpleRenderer(container.NewStack(i.background,
+		container.NewBorder(nil, nil, container.NewPadded(i.icon), nil, title)))
+}
+
+func (i *launchItem) MouseIn(*deskDriver.MouseEvent) {
+	i.hovered = true
+	i.Refresh()
+}
+
+func (i *launchItem) MouseMoved(*deskDriver.MouseEvent) {
+}
+
+func (i *launchItem) MouseOut() {
+	i.hovered = false
+	i.Refresh()
+}
+
+func (i *launchItem) Refresh() {
+	i.updateBackground()
+	i.BaseWidget.Refresh()
+}
+
+func (i *launchItem) Tapped(*fyne.PointEvent) {
+	i.result.launch()
+}
+
+// setActive marks this item as the one that will be launched if return is pressed.
+func (i *launchItem) setActive(active bool) {
+	i.active = active
+	i.Refresh()
+}
+
+func (i *launchItem) setIcon(res fyne.Resource) {
+	i.result.icon = res
+	if i.icon != nil {
+		i.icon.SetResource(res)
+	}
+}
+
+func (i *launchItem) updateBackground() {
+	if i.background == nil {
+		return
+	}
+
+	switch {
+	case i.active:
+		i.background.FillColor = theme.SelectionColor()
+	case i.hovered:
+		i.background.FillColor = theme.HoverColor()
+	default:
+		i.background.FillColor = color.Transparent
+	}
+	i.background.Refresh()
+}
+
+func newLaunchItem(result *launchResult) *launchItem {
+	item := &launchItem{result: result}
+	item.ExtendBaseWidget(item)
+	return item
+}
+
+// highlightSegments splits a title into text segments, with the runes at the matched positions in bold.
+func highlightSegments(title string, matched []int) []widget.RichTextSegment {
+	isMatch := make(map[int]bool, len(matched))
+	for _, pos := range matched {
+		isMatch[pos] = true
+	}
+
+	var segments []widget.RichTextSegment
+	runes := []rune(title)
+	start := 0
+	for pos := 1; pos <= len(runes); pos++ {
+		if pos < len(runes) && isMatch[pos] == isMatch[start] {
+			continue
+		}
+
+		style := widget.RichTextStyleInline
+		if isMatch[start] {
+			style = widget.RichTextStyleStrong
+		}
+		segments = append(segments, &widget.TextSegment{Text: string(runes[start:pos]), Style: style})
+		start = pos
+	}
+	return segments
+}
@@ -2,11 +2,13 @@ package ui
 
 import (
 	"testing"
+	"time"
 
 	"fyshos.com/fynedesk"
 	wmTest "fyshos.com/fynedesk/test"
 
 	"fyne.io/fyne/v2"
+	deskDriver "fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/test"
 	"fyne.io/fyne/v2/widget"
 	"github.com/stretchr/testify/assert"
@@ -16,16 +18,16 @@ func TestLauncher_ListMatches(t *testing.T) {
 	setupIcons("App 1", "App 2", "Another")
 	launcher := newAppPicker("Test", func(data fynedesk.AppData) {})
 
-	apps := launcher.appButtonListMatching("App")
+	apps := launcher.resultListMatching("App")
 	assert.Equal(t, 2, len(apps))
-	assert.Equal(t, "App 1", apps[0].(*widget.Button).Text)
-	assert.Equal(t, "App 2", apps[1].(*widget.Button).Text)
+	assert.Equal(t, "App 1", apps[0].(*launchItem).result.title)
+	assert.Equal(t, "App 2", apps[1].(*launchItem).result.title)
 
-	apps = launcher.appButtonListMatching("ano")
+	apps = launcher.resultListMatching("ano")
 	assert.Equal(t, 1, len(apps))
-	assert.Equal(t, "Another", apps[0].(*widget.Button).Text)
+	assert.Equal(t, "Another", apps[0].(*launchItem).result.title)
 
-	apps = launcher.appButtonListMatching("miss")
+	apps = launcher.resultListMatching("miss")
 	assert.Equal(t, 0, len(apps))
 }
 
@@ -49,15 +51,15 @@ func TestLauncher_ListActive(t *testing.T) {
 	test.Type(launcher.entry, "App")
 	launcher.entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
 	assert.Equal(t, 1, launcher.activeIndex)
-	assert.Equal(t, widget.MediumImportance, launcher.appList.Objects[0].(*widget.Button).Importance)
-	assert.Equal(t, widget.HighImportance, launcher.appList.Objects[1].(*widget.Button).Importance)
+	assert.False(t, launcher.appList.Objects[0].(*launchItem).active)
+	assert.True(t, launcher.appList.Objects[1].(*launchItem).active)
 }
 
 func TestLauncher_setActiveIndex(t *testing.T) {
 	setupIcons("App 1", "App 2", "Another")
 	launcher := newAppPicker("Test", func(data fynedesk.AppData) {})
 
-	launcher.appList.Objects = launcher.appButtonListMatching("App")
+	launcher.appList.Objects = launcher.resultListMatching("App")
 	assert.Equal(t, 0, launcher.activeIndex)
 
 	launcher.setActiveIndex(1)
@@ -75,3 +77,175 @@ func setupIcons(icons ...string) {
 	desk.SetIconProvider(wmTest.NewAppProvider(icons...))
 	fynedesk.SetInstance(desk)
 }
+
+type scoredSuggestion struct {
+	title string
+	score float64
+}
+
+func (s *scoredSuggestion) Icon() fyne.Resource {
+	return nil
+}
+
+func (s *scoredSuggestion) Title() string {
+	return s.title
+}
+
+func (s *scoredSuggestion) Launch() {
+}
+
+func (s *scoredSuggestion) Score() float64 {
+	return s.score
+}
+
+type suggestModule struct {
+	items []fynedesk.LaunchSuggestion
+}
+
+func (s *suggestModule) Destroy() {
+}
+
+func (s *suggestModule) LaunchSuggestions(string) []fynedesk.LaunchSuggestion {
+	return s.items
+}
+
+func (s *suggestModule) Metadata() fynedesk.ModuleMetadata {
+	return fynedesk.ModuleMetadata{Name: "Suggest"}
+}
+
+func TestLauncher_Ranking(t *testing.T) {
+	test.NewApp()
+	mod := &suggestModule{items: []fynedesk.LaunchSuggestion{&scoredSuggestion{title: "Terminal Colours", score: 0.2},
+		&scoredSuggestion{title: "Terminal Tab", score: 0.95}}}
+	desk := &desktop{icons: wmTest.NewAppProvider("Text Editor", "Terminal", "Files"), settings: &deskSettings{},
+		moduleCache: []fynedesk.Module{mod}, usage: &launchUsage{}}
+	fynedesk.SetInstance(desk)
+	launcher := newAppPicker("Test", func(data fynedesk.AppData) {})
+	launcher.showMods = true
+
+	results := launcher.rankedResults("te")
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.title)
+	}
+	assert.Equal(t, []string{"Terminal Tab", "Terminal", "Text Editor", "Terminal Colours"}, titles)
+	assert.Equal(t, []int{0, 1}, results[1].matched)
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		desk.usage.record("Text Editor", now)
+	}
+	results = launcher.rankedResults("te")
+	assert.Equal(t, "Text Editor", results[0].title)
+
+	mod.items = nil
+	results = launcher.rankedResults("fls")
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "Files", results[0].title)
+	assert.Equal(t, []int{0, 2, 4}, results[0].matched)
+
+	launcher.showMods = false
+	assert.Equal(t, 2, len(launcher.rankedResults("te")))
+}
+
+type fileSuggestion struct {
+	scoredSuggestion
+	revealed bool
+}
+
+func (f *fileSuggestion) CopyText() string {
+	return "/home/user/" + f.title
+}
+
+func (f *fileSuggestion) Reveal() {
+	f.revealed = true
+}
+
+func TestLauncher_RevealAndCopy(t *testing.T) {
+	test.NewApp()
+	file := &fileSuggestion{scoredSuggestion: scoredSuggestion{title: "notes.txt", score: 0.5}}
+	desk := &desktop{icons: wmTest.NewAppProvider(), settings: &deskSettings{},
+		moduleCache: []fynedesk.Module{&suggestModule{items: []fynedesk.LaunchSuggestion{file}}}}
+	fynedesk.SetInstance(desk)
+	launcher := newAppPicker("Test", func(data fynedesk.AppData) {})
+	launcher.showMods = true
+
+	test.Type(launcher.entry, "notes")
+	launcher.entry.TypedShortcut(&deskDriver.CustomShortcut{KeyName: fyne.KeyReturn, Modifier: fyne.KeyModifierAlt})
+	assert.Equal(t, "/home/user/notes.txt", launcher.win.Clipboard().Content())
+
+	launcher = newAppPicker("Test", func(data fynedesk.AppData) {})
+	launcher.showMods = true
+	test.Type(launcher.entry, "notes")
+	launcher.entry.TypedShortcut(&deskDriver.CustomShortcut{KeyName: fyne.KeyReturn,
+		Modifier: fyne.KeyModifierControl})
+	assert.True(t, file.revealed)
+}
+
+func TestHighlightSegments(t *testing.T) {
+	segments := highlightSegments("Files", []int{0, 2, 4})
+	assert.Equal(t, 5, len(segments))
+	assert.Equal(t, "F", segments[0].(*widget.TextSegment).Text)
+	assert.True(t, segments[0].(*widget.TextSegment).Style.TextStyle.Bold)
+	assert.Equal(t, "i", segments[1].(*widget.TextSegment).Text)
+	assert.False(t, segments[1].(*widget.TextSegment).Style.TextStyle.Bold)
+
+	segments = highlightSegments("Text Editor", []int{0, 1})
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, "Te", segments[0].(*widget.TextSegment).Text)
+	assert.Equal(t, "xt Editor", segments[1].(*widget.TextSegment).Text)
+}
+
+func TestLauncher_Windows(t *testing.T) {
+	test.NewApp()
+	slack := wmTest.NewWindow("general - Team")
+	slack.SetClass([]string{"slack", "Slack"})
+	other := wmTest.NewWindow("Slack notes")
+	other.SetDesktop(2)
+	desk := wmTest.NewDesktopWithWM(&embededWM{windows: []fynedesk.Window{slack, other}})
+	desk.SetIconProvider(wmTest.NewAppProvider("Slack"))
+	launcher := newAppPicker("Test", func(data fynedesk.AppData) {})
+	launcher.showMods = true
+
+	results := launcher.rankedResults("slack")
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.title)
+	}
+	assert.Equal(t, []string{"Slack notes  (Desktop 3)", "general - Team", "Slack"}, titles)
+
+	results[1].launch()
+	assert.True(t, slack.Focused())
+	assert.False(t, other.Focused())
+
+	launcher.showMods = false
+	assert.Equal(t, 1, len(launcher.rankedResults("slack")))
+}
+
+func TestLauncher_DeskActions(t *testing.T) {
+	test.NewApp()
+	desk := &desktop{icons: wmTest.NewAppProvider(), settings: &deskSettings{}}
+	locked := make(chan bool, 1)
+	desk.AddShortcut(fynedesk.NewShortcut("Lock screen", fyne.KeyL, fynedesk.UserModifier), func() {
+		locked <- true
+	})
+	desk.AddShortcut(fynedesk.NewShortcut("Show Launcher", fyne.KeySpace, fynedesk.UserModifier), func() {})
+	fynedesk.SetInstance(desk)
+	launcher := newAppPicker("Test", func(data fynedesk.AppData) {})
+	launcher.showMods = true
+
+	results := launcher.rankedResults("lock")
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "Lock screen", results[0].title)
+	results[0].launch()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Error("Lock screen action did not run")
+	}
+
+	assert.Equal(t, 0, len(launcher.rankedResults("launcher")))
+	results = launcher.rankedResults("desktop 2")
+	assert.Equal(t, "Switch to Desktop 2", results[0].title)
+	assert.Equal(t, "Suspend", launcher.rankedResults("susp")[0].title)
+}
@@ -4,6 +4,7 @@ import (
 	"image/color"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -76,7 +77,7 @@ func (w *widgetPanel) askLogout() {
 				container.NewGridWithColumns(2, cancel, logout),
 				layout.NewSpacer()), bottomPad),
 		nil, nil,
-		widget.NewLabel("Are you sure you want to log out?"))
+		widget.NewLabel(w.logoutMessage()))
 
 	r, g, b, _ := theme.OverlayBackgroundColor().RGBA()
 	bgCol := &color.NRGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 230}
@@ -90,11 +91,25 @@ func (w *widgetPanel) askLogout() {
 		iconBox, bg,
 		container.NewPadded(content)))
 
-	win.Resize(fyne.NewSize(280, 150))
+	win.Resize(fyne.NewSize(280, fyne.Max(150, content.MinSize().Height+theme.Padding()*2)))
 	win.CenterOnScreen()
 	win.Show()
 }
 
+// logoutMessage asks the user to confirm logging out, listing any apps that have asked to prevent it.
+func (w *widgetPanel) logoutMessage() string {
+	msg := "Are you sure you want to log out?"
+	desk, ok := w.desk.(*desktop)
+	if !ok || desk.inhibit == nil {
+		return msg
+	}
+
+	if reasons := desk.inhibit.reasons(inhibitLogout); len(reasons) > 0 {
+		msg += "\n\nApps are preventing logout:\n" + strings.Join(reasons, "\n")
+	}
+	return msg
+}
+
 func (w *widgetPanel) showAccountMenu(_ fyne.CanvasObject) {
 	w2 := fyne.CurrentApp().Driver().(deskDriver.Driver).CreateSplashWindow()
 	w2.Canvas().SetOnTypedKey(func(k *fyne.KeyEvent) {
@@ -156,16 +171,43 @@ func (w *widgetPanel) showAccountMenu(_ fyne.CanvasObject) {
 	w.desk.WindowManager().ShowOverlay(w2, fyne.NewSize(300, 360), pos)
 }
 
-func (w *widgetPanel) newAppButton(app fynedesk.AppData, w2 fyne.Window) *widget.Button {
-	b := widget.NewButtonWithIcon(app.Name(), wmtheme.BrokenImageIcon, func() {
+// appButton is an entry in the app menu that lists the actions and recent files of the app on secondary tap.
+type appButton struct {
+	widget.Button
+
+	app  fynedesk.AppData
+	desk fynedesk.Desktop
+	win  fyne.Window
+}
+
+func (b *appButton) TappedSecondary(ev *fyne.PointEvent) {
+	desk, ok := b.desk.(*desktop)
+	if !ok {
+		return
+	}
+	items := appMenuItems(desk, b.app, b.win.Close)
+	if len(items) == 0 {
+		return
+	}
+
+	c := fyne.CurrentApp().Driver().CanvasForObject(b)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), c, ev.AbsolutePosition)
+}
+
+func (w *widgetPanel) newAppButton(app fynedesk.AppData, w2 fyne.Window) *appButton {
+	b := &appButton{app: app, desk: w.desk, win: w2}
+	b.Text = app.Name()
+	b.Icon = wmtheme.BrokenImageIcon
+	b.Alignment = widget.ButtonAlignLeading
+	b.OnTapped = func() {
 		w2.Close()
 		_ = w.desk.RunApp(app)
-	})
-	b.Alignment = widget.ButtonAlignLeading
+	}
+	b.ExtendBaseWidget(b)
 	return b
 }
 
-func (w *widgetPanel) loadIcon(app fynedesk.AppData, btn *widget.Button) {
+func (w *widgetPanel) loadIcon(app fynedesk.AppData, btn *appButton) {
 	iconRes := app.Icon(w.desk.Settings().IconTheme(), int(64*w.desk.Screens().Primary().CanvasScale()))
 
 	btn.SetIcon(iconRes)
@@ -33,8 +33,12 @@ func (n *notification) show(list *fyne.Container) {
 		n.popup = fyne.CurrentApp().Driver().(deskDriver.Driver).CreateSplashWindow()
 		n.popup.SetContent(n.renderer)
 
-		winSize := fynedesk.Instance().(*desktop).root.Canvas().Size()
+		desk := fynedesk.Instance().(*desktop)
+		winSize := desk.root.Canvas().Size()
 		pos := fyne.NewPos(winSize.Width-280-wmtheme.NarrowBarWidth, 10)
+		if desk.widgetPanelLeft() {
+			pos = fyne.NewPos(wmtheme.NarrowBarWidth+10, 10)
+		}
 		fynedesk.Instance().WindowManager().ShowOverlay(n.popup, fyne.NewSize(270, 120), pos)
 	} else {
 		list.Objects = append(list.Objects, n.renderer)
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	deskDriver "fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/wm"
+)
+
+const (
+	osdBottomOffset = 120
+	osdTimeout      = time.Second * 2
+)
+
+var osdSize = fyne.NewSize(240, 64)
+
+// osd shows on screen indicators near the bottom of the primary screen.
+type osd struct {
+	desk *desktop
+
+	win  fyne.Window
+	icon *widget.Icon
+	bar  *widget.ProgressBar
+
+	lock   sync.Mutex
+	hideAt time.Time
+}
+
+func (o *osd) show(item *wm.OnScreenDisplay) {
+	if o.win == nil {
+		o.win = fyne.CurrentApp().Driver().(deskDriver.Driver).CreateSplashWindow()
+		o.icon = widget.NewIcon(item.Icon)
+		o.bar = &widget.ProgressBar{TextFormatter: func() string { return "" }}
+		o.win.SetContent(container.NewPadded(container.NewBorder(nil, nil, o.icon, nil, o.bar)))
+	}
+	o.icon.SetResource(item.Icon)
+	o.bar.SetValue(item.Value)
+
+	o.lock.Lock()
+	visible := time.Now().Before(o.hideAt)
+	o.hideAt = time.Now().Add(osdTimeout)
+	o.lock.Unlock()
+	if visible {
+		return // already showing, the timer below will hide it later
+	}
+
+	winSize := o.desk.root.Canvas().Size()
+	pos := fyne.NewPos((winSize.Width-osdSize.Width)/2, winSize.Height-osdSize.Height-osdBottomOffset)
+	fynedesk.Instance().WindowManager().ShowOverlay(o.win, osdSize.Add(fyne.NewSize(theme.Padding(), 0)), pos)
+	go o.hideLater()
+}
+
+func (o *osd) hideLater() {
+	for {
+		o.lock.Lock()
+		wait := time.Until(o.hideAt)
+		o.lock.Unlock()
+		if wait <= 0 {
+			break
+		}
+		time.Sleep(wait)
+	}
+
+	o.win.Hide()
+}
+
+func startOSD(desk *desktop) {
+	o := &osd{desk: desk}
+	wm.SetOnScreenDisplayListener(o.show)
+}
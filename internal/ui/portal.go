@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+
+	"fyshos.com/fynedesk/wm"
+)
+
+const (
+	portalName = "org.freedesktop.impl.portal.desktop.fynedesk"
+	portalPath = "/org/freedesktop/portal/desktop"
+
+	portalFileChooserIface  = "org.freedesktop.impl.portal.FileChooser"
+	portalInhibitIface      = "org.freedesktop.impl.portal.Inhibit"
+	portalNotificationIface = "org.freedesktop.impl.portal.Notification"
+	portalRequestIface      = "org.freedesktop.impl.portal.Request"
+	portalScreenshotIface   = "org.freedesktop.impl.portal.Screenshot"
+	portalSettingsIface     = "org.freedesktop.impl.portal.Settings"
+)
+
+// The response codes that portal requests return.
+const (
+	portalSuccess uint32 = iota
+	portalCancelled
+	portalFailed
+)
+
+// portalColor is a colour as sent over D-Bus, with each channel ranging from 0 to 1.
+type portalColor struct {
+	R, G, B float64
+}
+
+func newPortalColor(c color.Color) portalColor {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return portalColor{R: float64(n.R) / 0xff, G: float64(n.G) / 0xff, B: float64(n.B) / 0xff}
+}
+
+// portalNotification shows notifications from sandboxed apps in our notification area.
+type portalNotification struct{}
+
+// AddNotification shows the title and body of the notification passed.
+func (p *portalNotification) AddNotification(appID, _ string, notification map[string]dbus.Variant) *dbus.Error {
+	title, _ := notification["title"].Value().(string)
+	body, _ := notification["body"].Value().(string)
+	if title == "" {
+		title = appID
+	}
+
+	wm.SendNotification(wm.NewNotification(title, body))
+	return nil
+}
+
+// RemoveNotification is accepted but does nothing, as our notifications are removed after a short time.
+func (p *portalNotification) RemoveNotification(_, _ string) *dbus.Error {
+	return nil
+}
+
+// portalRequest is exported at the handle of a request so that xdg-desktop-portal can cancel it.
+type portalRequest struct {
+	conn    *dbus.Conn
+	handle  dbus.ObjectPath
+	onClose func()
+}
+
+func exportRequest(conn *dbus.Conn, handle dbus.ObjectPath, onClose func()) *portalRequest {
+	r := &portalRequest{conn: conn, handle: handle, onClose: onClose}
+	if conn == nil {
+		return r
+	}
+
+	if err := conn.Export(r, handle, portalRequestIface); err != nil {
+		fyne.LogError("Unable to export portal request", err)
+	}
+	return r
+}
+
+// Close is called when the app no longer wants the result of the request.
+func (r *portalRequest) Close() *dbus.Error {
+	r.unexport()
+	if r.onClose != nil {
+		r.onClose()
+	}
+	return nil
+}
+
+func (r *portalRequest) unexport() {
+	if r.conn == nil {
+		return
+	}
+
+	_ = r.conn.Export(nil, r.handle, portalRequestIface)
+}
+
+// portalScreenshot lets sandboxed apps take screenshots and pick colours from the screen.
+type portalScreenshot struct {
+	conn *dbus.Conn
+	desk *desktop
+}
+
+// PickColor asks the user to choose a pixel from the screen, returning its colour.
+func (p *portalScreenshot) PickColor(handle dbus.ObjectPath, _, _ string, _ map[string]dbus.Variant) (uint32,
+	map[string]dbus.Variant, *dbus.Error) {
+	img := p.desk.wm.Capture()
+	if img == nil {
+		return portalFailed, map[string]dbus.Variant{}, nil
+	}
+
+	result := make(chan image.Rectangle, 1)
+	cancel := p.desk.selectPoint(img, func(area image.Rectangle) {
+		result <- area
+	})
+	req := exportRequest(p.conn, handle, cancel)
+	defer req.unexport()
+	area := <-result
+	if area.Empty() {
+		return portalCancelled, map[string]dbus.Variant{}, nil
+	}
+
+	return portalSuccess, map[string]dbus.Variant{
+		"color": dbus.MakeVariant(newPortalColor(img.At(area.Min.X, area.Min.Y))),
+	}, nil
+}
+
+// Screenshot captures the screen, or a region chosen by the user if the request is interactive.
+// The image is saved in the pictures folder and its URI returned.
+func (p *portalScreenshot) Screenshot(handle dbus.ObjectPath, _, _ string, options map[string]dbus.Variant) (uint32,
+	map[string]dbus.Variant, *dbus.Error) {
+	opts := p.desk.screenshotOptions(captureScreen)
+	if interactive, ok := options["interactive"].Value().(bool); ok && interactive {
+		opts.mode = captureRegion
+	}
+
+	result := make(chan image.Image, 1)
+	cancel := p.desk.capture(opts, func(img image.Image) {
+		result <- img
+	})
+	req := exportRequest(p.conn, handle, cancel)
+	img := <-result
+	req.unexport()
+	if img == nil {
+		return portalCancelled, map[string]dbus.Variant{}, nil
+	}
+
+	dir, err := getPicturesDir()
+	if err != nil {
+		fyne.LogError("Unable to find pictures folder for screenshot", err)
+		return portalFailed, map[string]dbus.Variant{}, nil
+	}
+	path := filepath.Join(dir.Path(), screenshotFileName())
+	if err = writePNG(path, img); err != nil {
+		fyne.LogError("Unable to save screenshot", err)
+		return portalFailed, map[string]dbus.Variant{}, nil
+	}
+
+	return portalSuccess, map[string]dbus.Variant{
+		"uri": dbus.MakeVariant(storage.NewFileURI(path).String()),
+	}, nil
+}
+
+// startPortal registers the xdg-desktop-portal backends that FyneDesk provides.
+func startPortal(desk *desktop) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		fyne.LogError("Unable to connect to session bus for portal", err)
+		return
+	}
+
+	desk.inhibit.conn = conn
+	settings := &portalSettings{conn: conn}
+	backends := []struct {
+		obj   interface{}
+		iface string
+	}{
+		{&portalFileChooser{conn: conn}, portalFileChooserIface},
+		{desk.inhibit, portalInhibitIface},
+		{&portalNotification{}, portalNotificationIface},
+		{&portalScreenshot{conn: conn, desk: desk}, portalScreenshotIface},
+		{settings, portalSettingsIface},
+	}
+	for _, b := range backends {
+		if err = wm.RegisterNamedService(b.obj, portalPath, b.iface, portalName); err != nil {
+			fyne.LogError("Could not start portal backend "+b.iface, err)
+		}
+	}
+
+	_, err = prop.Export(conn, portalPath, prop.Map{
+		portalScreenshotIface: {"version": {Value: uint32(2)}},
+		portalSettingsIface:   {"version": {Value: uint32(1)}},
+	})
+	if err != nil {
+		fyne.LogError("Could not export portal properties", err)
+	}
+	settings.watch()
+}
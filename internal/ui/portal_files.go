@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// portalFilter is a named list of rules that files can match, as sent by xdg-desktop-portal.
+type portalFilter struct {
+	Name  string
+	Rules []portalFilterRule
+}
+
+// portalFilterRule matches a glob pattern if the type is 0 or a mime type if it is 1.
+type portalFilterRule struct {
+	Type    uint32
+	Pattern string
+}
+
+// patternFilter matches files by name or mime type, ignoring the case of file names.
+type patternFilter struct {
+	globs, mimes []string
+}
+
+func (f *patternFilter) Matches(u fyne.URI) bool {
+	name := strings.ToLower(u.Name())
+	for _, glob := range f.globs {
+		if ok, _ := filepath.Match(strings.ToLower(glob), name); ok {
+			return true
+		}
+	}
+
+	return len(f.mimes) > 0 && storage.NewMimeTypeFileFilter(f.mimes).Matches(u)
+}
+
+// portalFileChooser shows our file dialogs to sandboxed apps.
+// Fyne dialogs choose a single item, so requests for multiple files collect the files from a dialog one at a time.
+type portalFileChooser struct {
+	conn *dbus.Conn
+}
+
+// OpenFile asks the user to choose a file, or a folder if the "directory" option is set.
+// If the "multiple" option is set the user can choose more than one file.
+func (p *portalFileChooser) OpenFile(handle dbus.ObjectPath, _, _, title string,
+	options map[string]dbus.Variant) (uint32, map[string]dbus.Variant, *dbus.Error) {
+	return p.choose(handle, title, options, false)
+}
+
+// SaveFile asks the user where a file should be saved.
+func (p *portalFileChooser) SaveFile(handle dbus.ObjectPath, _, _, title string,
+	options map[string]dbus.Variant) (uint32, map[string]dbus.Variant, *dbus.Error) {
+	return p.choose(handle, title, options, true)
+}
+
+func (p *portalFileChooser) choose(handle dbus.ObjectPath, title string, options map[string]dbus.Variant,
+	save bool) (uint32, map[string]dbus.Variant, *dbus.Error) {
+	if title == "" {
+		title = "Choose File"
+	}
+	w := fyne.CurrentApp().NewWindow(title)
+	result := make(chan []fyne.URI, 1)
+	finish := func(chosen []fyne.URI) {
+		select {
+		case result <- chosen:
+		default: // already chosen
+		}
+	}
+	req := exportRequest(p.conn, handle, w.Close)
+	defer req.unexport()
+	w.SetOnClosed(func() {
+		finish(nil)
+	})
+
+	directory, _ := options["directory"].Value().(bool)
+	multiple, _ := options["multiple"].Value().(bool)
+	var d *dialog.FileDialog
+	if save {
+		d = dialog.NewFileSave(func(write fyne.URIWriteCloser, err error) {
+			if err != nil {
+				fyne.LogError("Unable to choose file to save", err)
+			}
+			if write != nil {
+				finish([]fyne.URI{write.URI()})
+				_ = write.Close()
+			}
+			w.Close()
+		}, w)
+	} else if directory {
+		d = dialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil {
+				fyne.LogError("Unable to choose folder", err)
+			}
+			if dir != nil {
+				finish([]fyne.URI{dir})
+			}
+			w.Close()
+		}, w)
+	} else if multiple {
+		list := newFileList(w, options, func(chosen []fyne.URI) {
+			finish(chosen)
+			w.Close()
+		})
+		d = list.dialog
+	} else {
+		d = dialog.NewFileOpen(func(read fyne.URIReadCloser, err error) {
+			if err != nil {
+				fyne.LogError("Unable to choose file", err)
+			}
+			if read != nil {
+				finish([]fyne.URI{read.URI()})
+				_ = read.Close()
+			}
+			w.Close()
+		}, w)
+	}
+	configureFileDialog(d, options)
+
+	size := fyne.NewSize(800, 600)
+	w.Resize(size)
+	w.Show()
+	d.Resize(size)
+	d.Show()
+
+	chosen := <-result
+	if len(chosen) == 0 {
+		return portalCancelled, map[string]dbus.Variant{}, nil
+	}
+	uris := make([]string, len(chosen))
+	for i, u := range chosen {
+		uris[i] = u.String()
+	}
+	return portalSuccess, map[string]dbus.Variant{
+		"uris": dbus.MakeVariant(uris),
+	}, nil
+}
+
+// fileList collects files for a request that allows more than one, showing those chosen so far.
+// Each file is picked with a file dialog, which opens again in the same folder to add another.
+type fileList struct {
+	chosen []fyne.URI
+	dialog *dialog.FileDialog
+	list   *widget.List
+}
+
+func newFileList(w fyne.Window, options map[string]dbus.Variant, onDone func([]fyne.URI)) *fileList {
+	f := &fileList{}
+	f.list = widget.NewList(func() int {
+		return len(f.chosen)
+	}, func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}, func(id widget.ListItemID, o fyne.CanvasObject) {
+		o.(*widget.Label).SetText(f.chosen[id].Name())
+	})
+	f.list.OnSelected = func(id widget.ListItemID) {
+		f.list.UnselectAll()
+		f.remove(id)
+	}
+
+	accept := "Open"
+	if label, ok := options["accept_label"].Value().(string); ok && label != "" {
+		accept = strings.ReplaceAll(label, "_", "")
+	}
+	done := &widget.Button{Text: accept, Importance: widget.HighImportance, OnTapped: func() {
+		onDone(f.chosen)
+	}}
+	add := widget.NewButtonWithIcon("Add File", theme.ContentAddIcon(), func() {
+		f.dialog.Show()
+	})
+	hint := widget.NewLabel("Tap a file to remove it")
+	buttons := container.NewHBox(add, layout.NewSpacer(), widget.NewButton("Cancel", w.Close), done)
+	w.SetContent(container.NewBorder(hint, buttons, nil, nil, f.list))
+
+	f.dialog = dialog.NewFileOpen(func(read fyne.URIReadCloser, err error) {
+		if err != nil {
+			fyne.LogError("Unable to choose file", err)
+		}
+		if read == nil {
+			return
+		}
+
+		f.add(read.URI())
+		_ = read.Close()
+	}, w)
+	return f
+}
+
+// add includes a file in the list, if it was not already chosen, and opens the dialog in its folder next time.
+func (f *fileList) add(u fyne.URI) {
+	if parent, err := storage.Parent(u); err == nil {
+		if dir, err := storage.ListerForURI(parent); err == nil {
+			f.dialog.SetLocation(dir)
+		}
+	}
+
+	for _, existing := range f.chosen {
+		if existing.String() == u.String() {
+			return
+		}
+	}
+	f.chosen = append(f.chosen, u)
+	f.list.Refresh()
+}
+
+func (f *fileList) remove(id int) {
+	f.chosen = append(f.chosen[:id], f.chosen[id+1:]...)
+	f.list.Refresh()
+}
+
+// configureFileDialog applies the labels, location and filters requested to a file dialog.
+func configureFileDialog(d *dialog.FileDialog, options map[string]dbus.Variant) {
+	if label, ok := options["accept_label"].Value().(string); ok && label != "" {
+		d.SetConfirmText(strings.ReplaceAll(label, "_", ""))
+	}
+	if name, ok := options["current_name"].Value().(string); ok && name != "" {
+		d.SetFileName(name)
+	}
+
+	folder := optionPath(options, "current_folder")
+	if file := optionPath(options, "current_file"); file != "" {
+		folder = filepath.Dir(file)
+		d.SetFileName(filepath.Base(file))
+	}
+	if folder != "" {
+		if dir, err := storage.ListerForURI(storage.NewFileURI(folder)); err == nil {
+			d.SetLocation(dir)
+		}
+	}
+
+	if filter := optionFilter(options); filter != nil {
+		d.SetFilter(filter)
+	}
+}
+
+// optionFilter returns the filter that should be applied first, or nil if the request has none.
+func optionFilter(options map[string]dbus.Variant) storage.FileFilter {
+	var chosen portalFilter
+	if current, ok := options["current_filter"]; !ok || current.Store(&chosen) != nil || len(chosen.Rules) == 0 {
+		var filters []portalFilter
+		all, ok := options["filters"]
+		if !ok || all.Store(&filters) != nil || len(filters) == 0 {
+			return nil
+		}
+		chosen = filters[0]
+	}
+
+	filter := &patternFilter{}
+	for _, rule := range chosen.Rules {
+		if rule.Type == 1 {
+			filter.mimes = append(filter.mimes, rule.Pattern)
+		} else {
+			filter.globs = append(filter.globs, rule.Pattern)
+		}
+	}
+	return filter
+}
+
+// optionPath returns a file path that was sent as a nul terminated byte array.
+func optionPath(options map[string]dbus.Variant, key string) string {
+	data, ok := options[key].Value().([]byte)
+	if !ok {
+		return ""
+	}
+
+	return strings.TrimRight(string(data), "\x00")
+}
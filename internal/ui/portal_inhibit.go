@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// The actions that an app can ask to inhibit.
+const (
+	inhibitLogout uint32 = 1 << iota
+	inhibitUserSwitch
+	inhibitSuspend
+	inhibitIdle
+)
+
+// idleResetInterval is how often the screensaver is reset while an app is preventing idle.
+const idleResetInterval = 30 * time.Second
+
+type inhibitor struct {
+	appID, reason string
+	flags         uint32
+	sleepLock     io.Closer
+}
+
+// describe returns the app and reason for an inhibitor that can be shown to the user.
+func (i *inhibitor) describe() string {
+	app := i.appID
+	if app == "" {
+		app = "An application"
+	}
+	if i.reason == "" {
+		return app
+	}
+
+	return app + ": " + i.reason
+}
+
+// portalInhibit lets apps prevent logging out, suspending or the screensaver starting.
+type portalInhibit struct {
+	conn *dbus.Conn
+
+	lock     sync.Mutex
+	active   map[dbus.ObjectPath]*inhibitor
+	stopIdle chan bool
+}
+
+// Inhibit prevents the actions in flags until the request at the handle is closed.
+func (p *portalInhibit) Inhibit(handle dbus.ObjectPath, appID, _ string, flags uint32,
+	options map[string]dbus.Variant) *dbus.Error {
+	reason, _ := options["reason"].Value().(string)
+	item := &inhibitor{appID: appID, reason: reason, flags: flags}
+	if flags&inhibitSuspend != 0 {
+		item.sleepLock = takeSleepLock(item.describe())
+	}
+
+	p.add(handle, item)
+	exportRequest(p.conn, handle, func() {
+		p.remove(handle)
+	})
+	return nil
+}
+
+func (p *portalInhibit) add(handle dbus.ObjectPath, item *inhibitor) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.active == nil {
+		p.active = make(map[dbus.ObjectPath]*inhibitor)
+	}
+	p.active[handle] = item
+
+	if item.flags&inhibitIdle != 0 && p.stopIdle == nil {
+		p.stopIdle = make(chan bool)
+		go keepAwake(p.stopIdle)
+	}
+}
+
+// reasons returns a description of each app that is currently inhibiting the action passed.
+func (p *portalInhibit) reasons(flag uint32) []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var ret []string
+	for _, item := range p.active {
+		if item.flags&flag != 0 {
+			ret = append(ret, item.describe())
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (p *portalInhibit) remove(handle dbus.ObjectPath) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	item, ok := p.active[handle]
+	if !ok {
+		return
+	}
+	delete(p.active, handle)
+
+	if item.sleepLock != nil {
+		_ = item.sleepLock.Close()
+	}
+	for _, other := range p.active {
+		if other.flags&inhibitIdle != 0 {
+			return
+		}
+	}
+	if p.stopIdle != nil {
+		close(p.stopIdle)
+		p.stopIdle = nil
+	}
+}
+
+// keepAwake resets the screensaver regularly until the channel is closed.
+func keepAwake(stop chan bool) {
+	command, err := exec.LookPath("xscreensaver-command")
+	if err != nil {
+		fyne.LogError("xscreensaver-command not found, unable to inhibit idle", err)
+		return
+	}
+
+	ticker := time.NewTicker(idleResetInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := exec.Command(command, "-deactivate").Run(); err != nil {
+				fyne.LogError("Failed to reset screensaver", err)
+			}
+		}
+	}
+}
+
+// takeSleepLock asks logind to block suspend until the returned lock is closed.
+func takeSleepLock(why string) io.Closer {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		fyne.LogError("Unable to connect to system bus", err)
+		return nil
+	}
+
+	var fd dbus.UnixFD
+	err = conn.Object("org.freedesktop.login1", "/org/freedesktop/login1").Call(
+		"org.freedesktop.login1.Manager.Inhibit", 0, "sleep", "FyneDesk", why, "block").Store(&fd)
+	if err != nil {
+		fyne.LogError("Unable to inhibit suspend", err)
+		return nil
+	}
+	return os.NewFile(uintptr(fd), "sleep-inhibitor")
+}
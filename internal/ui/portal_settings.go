@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const appearanceNamespace = "org.freedesktop.appearance"
+
+// The values of the appearance color-scheme setting.
+const (
+	colorSchemeDefault uint32 = iota
+	colorSchemeDark
+	colorSchemeLight
+)
+
+var errSettingNotFound = &dbus.Error{Name: "org.freedesktop.portal.Error.NotFound",
+	Body: []interface{}{"Requested setting not found"}}
+
+// portalSettings shares our appearance with sandboxed apps and tells them when it changes.
+type portalSettings struct {
+	conn *dbus.Conn
+
+	lock sync.Mutex
+	last map[string]map[string]dbus.Variant
+}
+
+// Read returns a single setting.
+func (s *portalSettings) Read(namespace, key string) (dbus.Variant, *dbus.Error) {
+	if val, ok := s.values()[namespace][key]; ok {
+		return val, nil
+	}
+
+	return dbus.Variant{}, errSettingNotFound
+}
+
+// ReadAll returns the settings in the namespaces requested, which may end with a "*" wildcard.
+// If no namespaces are passed then all settings are returned.
+func (s *portalSettings) ReadAll(namespaces []string) (map[string]map[string]dbus.Variant, *dbus.Error) {
+	all := s.values()
+	ret := make(map[string]map[string]dbus.Variant)
+	for ns, values := range all {
+		if matchNamespace(namespaces, ns) {
+			ret[ns] = values
+		}
+	}
+	return ret, nil
+}
+
+// changed emits a signal for each setting that is different to the last time it was called.
+func (s *portalSettings) changed() {
+	current := s.values()
+	s.lock.Lock()
+	last := s.last
+	s.last = current
+	s.lock.Unlock()
+
+	for ns, values := range current {
+		for key, val := range values {
+			if old, ok := last[ns][key]; ok && old.String() == val.String() {
+				continue
+			}
+
+			err := s.conn.Emit(portalPath, portalSettingsIface+".SettingChanged", ns, key, val)
+			if err != nil {
+				fyne.LogError("Unable to send portal setting change", err)
+			}
+		}
+	}
+}
+
+func (s *portalSettings) values() map[string]map[string]dbus.Variant {
+	scheme := colorSchemeLight
+	if fyne.CurrentApp().Settings().ThemeVariant() == theme.VariantDark {
+		scheme = colorSchemeDark
+	}
+
+	return map[string]map[string]dbus.Variant{
+		appearanceNamespace: {
+			"accent-color": dbus.MakeVariant(newPortalColor(theme.PrimaryColor())),
+			"color-scheme": dbus.MakeVariant(scheme),
+			"contrast":     dbus.MakeVariant(uint32(0)),
+		},
+	}
+}
+
+func (s *portalSettings) watch() {
+	s.lock.Lock()
+	s.last = s.values()
+	s.lock.Unlock()
+
+	listener := make(chan fyne.Settings)
+	fyne.CurrentApp().Settings().AddChangeListener(listener)
+	go func() {
+		for range listener {
+			s.changed()
+		}
+	}()
+}
+
+func matchNamespace(patterns []string, namespace string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, p := range patterns {
+		if p == "" || p == namespace {
+			return true
+		}
+		if strings.HasSuffix(p, "*") && strings.HasPrefix(namespace, strings.TrimSuffix(p, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileList(t *testing.T) {
+	test.NewApp()
+	w := test.NewWindow(nil)
+	defer w.Close()
+	var done []fyne.URI
+	f := newFileList(w, map[string]dbus.Variant{}, func(chosen []fyne.URI) {
+		done = chosen
+	})
+
+	first := storage.NewFileURI(t.TempDir() + "/first.txt")
+	second := storage.NewFileURI(t.TempDir() + "/second.txt")
+	f.add(first)
+	f.add(second)
+	f.add(first)
+	assert.Equal(t, []fyne.URI{first, second}, f.chosen)
+
+	f.remove(0)
+	assert.Equal(t, []fyne.URI{second}, f.chosen)
+
+	test.Tap(w.Content().(*fyne.Container).Objects[2].(*fyne.Container).Objects[3].(*widget.Button))
+	assert.Equal(t, []fyne.URI{second}, done)
+}
+
+func TestMatchNamespace(t *testing.T) {
+	assert.True(t, matchNamespace(nil, appearanceNamespace))
+	assert.True(t, matchNamespace([]string{""}, appearanceNamespace))
+	assert.True(t, matchNamespace([]string{appearanceNamespace}, appearanceNamespace))
+	assert.True(t, matchNamespace([]string{"org.freedesktop.*"}, appearanceNamespace))
+	assert.False(t, matchNamespace([]string{"org.gnome.*"}, appearanceNamespace))
+}
+
+func TestNewPortalColor(t *testing.T) {
+	assert.Equal(t, portalColor{R: 1, G: 0, B: 1}, newPortalColor(color.NRGBA{R: 0xff, B: 0xff, A: 0xff}))
+	assert.Equal(t, portalColor{R: 1, G: 1, B: 1}, newPortalColor(color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x80}))
+}
+
+func TestOptionFilter(t *testing.T) {
+	assert.Nil(t, optionFilter(map[string]dbus.Variant{}))
+
+	images := portalFilter{Name: "Images", Rules: []portalFilterRule{{Type: 0, Pattern: "*.PNG"},
+		{Type: 1, Pattern: "image/jpeg"}}}
+	text := portalFilter{Name: "Text", Rules: []portalFilterRule{{Type: 0, Pattern: "*.txt"}}}
+	filter := optionFilter(sendOptions(t, map[string]dbus.Variant{
+		"filters": dbus.MakeVariant([]portalFilter{images, text})}))
+	assert.NotNil(t, filter)
+	assert.True(t, filter.Matches(storage.NewFileURI("/tmp/picture.png")))
+	assert.True(t, filter.Matches(storage.NewFileURI("/tmp/photo.jpg")))
+	assert.False(t, filter.Matches(storage.NewFileURI("/tmp/notes.txt")))
+
+	filter = optionFilter(sendOptions(t, map[string]dbus.Variant{
+		"filters":        dbus.MakeVariant([]portalFilter{images, text}),
+		"current_filter": dbus.MakeVariant(text)}))
+	assert.True(t, filter.Matches(storage.NewFileURI("/tmp/notes.txt")))
+	assert.False(t, filter.Matches(storage.NewFileURI("/tmp/picture.png")))
+}
+
+func TestOptionPath(t *testing.T) {
+	options := map[string]dbus.Variant{"current_folder": dbus.MakeVariant([]byte("/home/user\x00"))}
+	assert.Equal(t, "/home/user", optionPath(options, "current_folder"))
+	assert.Equal(t, "", optionPath(options, "current_file"))
+}
+
+func TestPortalInhibit(t *testing.T) {
+	p := &portalInhibit{}
+	assert.Nil(t, p.Inhibit("/request/1", "org.example.Player", "", inhibitLogout|inhibitIdle,
+		map[string]dbus.Variant{"reason": dbus.MakeVariant("Playing video")}))
+	assert.Nil(t, p.Inhibit("/request/2", "", "", inhibitLogout, map[string]dbus.Variant{}))
+
+	assert.Equal(t, []string{"An application", "org.example.Player: Playing video"}, p.reasons(inhibitLogout))
+	assert.Equal(t, []string{"org.example.Player: Playing video"}, p.reasons(inhibitIdle))
+	assert.Nil(t, p.reasons(inhibitSuspend))
+	assert.NotNil(t, p.stopIdle)
+
+	p.remove("/request/1")
+	assert.Equal(t, []string{"An application"}, p.reasons(inhibitLogout))
+	assert.Nil(t, p.stopIdle)
+}
+
+func TestPortalSettings(t *testing.T) {
+	test.NewApp()
+	s := &portalSettings{}
+
+	val, err := s.Read(appearanceNamespace, "color-scheme")
+	assert.Nil(t, err)
+	scheme := colorSchemeLight
+	if test.NewApp().Settings().ThemeVariant() == theme.VariantDark {
+		scheme = colorSchemeDark
+	}
+	assert.Equal(t, scheme, val.Value())
+
+	_, err = s.Read(appearanceNamespace, "missing")
+	assert.Equal(t, errSettingNotFound, err)
+
+	all, err := s.ReadAll([]string{"org.freedesktop.*"})
+	assert.Nil(t, err)
+	assert.Contains(t, all[appearanceNamespace], "accent-color")
+	all, _ = s.ReadAll([]string{"org.gnome.desktop.interface"})
+	assert.Empty(t, all)
+}
+
+// sendOptions encodes and decodes options as a D-Bus message, so they arrive as portal requests would.
+func sendOptions(t *testing.T, options map[string]dbus.Variant) map[string]dbus.Variant {
+	msg := &dbus.Message{Type: dbus.TypeSignal, Headers: map[dbus.HeaderField]dbus.Variant{
+		dbus.FieldPath:      dbus.MakeVariant(dbus.ObjectPath(portalPath)),
+		dbus.FieldInterface: dbus.MakeVariant(portalFileChooserIface),
+		dbus.FieldMember:    dbus.MakeVariant("Test"),
+		dbus.FieldSignature: dbus.MakeVariant(dbus.SignatureOf(options)),
+	}, Body: []interface{}{options}}
+
+	data := &bytes.Buffer{}
+	assert.Nil(t, msg.EncodeTo(data, binary.LittleEndian))
+	decoded, err := dbus.DecodeMessage(data)
+	assert.Nil(t, err)
+	return decoded.Body[0].(map[string]dbus.Variant)
+}
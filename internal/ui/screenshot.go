@@ -1,58 +1,164 @@
 package ui
 
 import (
+	"bytes"
+	"errors"
 	"image"
+	"image/draw"
 	"image/png"
 	"time"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
-	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
-	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
-	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk/wm"
+)
+
+const screenshotDefaultDelay = 5 // seconds
+
+type captureMode int
+
+const (
+	captureScreen captureMode = iota
+	captureWindow
+	captureRegion
 )
 
+// captureOptions describe how a screenshot should be taken.
+type captureOptions struct {
+	mode    captureMode
+	delay   time.Duration
+	pointer bool
+}
+
+// clipboardSetter is implemented by window managers that can place content on the clipboard.
+type clipboardSetter interface {
+	SetClipboard(mime string, data []byte) error
+}
+
+// cursorCapturer is implemented by window managers that can capture the mouse pointer.
+type cursorCapturer interface {
+	CaptureCursor() (image.Image, image.Point)
+}
+
 func (l *desktop) screenshot() {
-	bg := l.wm.Capture()
-	l.showCaptureSave(bg)
+	l.capture(l.screenshotOptions(captureScreen), l.showCaptureSave)
+}
+
+func (l *desktop) screenshotDelayed() {
+	opts := l.screenshotOptions(captureScreen)
+	opts.delay = time.Duration(fyne.CurrentApp().Preferences().IntWithFallback("screenshot.delay",
+		screenshotDefaultDelay)) * time.Second
+	l.capture(opts, l.showCaptureSave)
+}
+
+func (l *desktop) screenshotRegion() {
+	l.capture(l.screenshotOptions(captureRegion), l.showCaptureSave)
+}
+
+func (l *desktop) screenshotRegionToClipboard() {
+	l.capture(l.screenshotOptions(captureRegion), l.copyScreenshot)
+}
+
+func (l *desktop) screenshotToClipboard() {
+	l.capture(l.screenshotOptions(captureScreen), l.copyScreenshot)
 }
 
 func (l *desktop) screenshotWindow() {
-	win := l.wm.TopWindow()
-	if win == nil {
-		fyne.LogError("Unable to print window with no window visible", nil)
+	l.capture(l.screenshotOptions(captureWindow), l.showCaptureSave)
+}
+
+// capture takes a screenshot as described by the options and passes it to done.
+// If the capture fails, or the user cancels selecting a region, done is called with nil.
+// The function returned cancels selecting a region, it does nothing for other captures.
+func (l *desktop) capture(opts captureOptions, done func(image.Image)) func() {
+	time.Sleep(opts.delay)
+
+	if opts.mode == captureWindow {
+		win := l.wm.TopWindow()
+		if win == nil {
+			fyne.LogError("Unable to print window with no window visible", nil)
+			done(nil)
+			return func() {}
+		}
+
+		done(win.Capture())
+		return func() {}
+	}
+
+	img := l.wm.Capture()
+	if img == nil {
+		done(nil)
+		return func() {}
+	}
+	if opts.pointer {
+		if cursor, ok := l.wm.(cursorCapturer); ok {
+			if pointer, pos := cursor.CaptureCursor(); pointer != nil {
+				img = drawCursor(img, pointer, pos)
+			}
+		}
+	}
+
+	if opts.mode == captureRegion {
+		return l.selectRegion(img, func(area image.Rectangle) {
+			if area.Empty() {
+				done(nil)
+				return
+			}
+
+			done(cropImage(img, area))
+		})
+	}
+	done(img)
+	return func() {}
+}
+
+// copyScreenshot places the image on the clipboard, notifying the user once it is done.
+func (l *desktop) copyScreenshot(img image.Image) {
+	if img == nil {
 		return
 	}
 
-	if img := win.Capture(); img != nil {
-		l.showCaptureSave(img)
+	if err := l.copyImage(img); err != nil {
+		fyne.LogError("Failed to copy screenshot", err)
+		return
 	}
+	wm.SendNotification(wm.NewNotification("Screenshot copied", "The screenshot is ready to paste"))
 }
 
-func (l *desktop) showCaptureSave(img image.Image) {
-	w := fyne.CurrentApp().NewWindow("Screenshot")
+func (l *desktop) copyImage(img image.Image) error {
+	clip, ok := l.wm.(clipboardSetter)
+	if !ok {
+		return errors.New("window manager does not support copying images")
+	}
 
-	save := &widget.Button{Text: "Save...",
-		Importance: widget.HighImportance,
-		OnTapped: func() {
-			saveImage(img, w)
-		},
+	data := &bytes.Buffer{}
+	if err := png.Encode(data, img); err != nil {
+		return err
 	}
+	return clip.SetClipboard("image/png", data.Bytes())
+}
 
-	buttons := container.NewHBox(
-		layout.NewSpacer(),
-		widget.NewButton("Cancel", w.Close),
-		save,
-	)
+func (l *desktop) screenshotOptions(mode captureMode) captureOptions {
+	return captureOptions{mode: mode, pointer: fyne.CurrentApp().Preferences().Bool("screenshot.pointer")}
+}
 
-	preview := canvas.NewImageFromImage(img)
-	preview.FillMode = canvas.ImageFillContain
+// cropImage returns the area of an image passed, with the top left moved to 0,0.
+func cropImage(img image.Image, area image.Rectangle) *image.NRGBA {
+	area = area.Canon().Intersect(img.Bounds())
+	out := image.NewNRGBA(image.Rect(0, 0, area.Dx(), area.Dy()))
+	draw.Draw(out, out.Bounds(), img, area.Min, draw.Src)
+	return out
+}
 
-	w.SetContent(container.NewBorder(nil, buttons, nil, nil, preview))
-	w.Resize(fyne.NewSize(480, 360))
-	w.Show()
+// drawCursor returns a copy of the screenshot with the mouse pointer drawn with its top left at the position passed.
+func drawCursor(img, cursor image.Image, pos image.Point) *image.NRGBA {
+	out := image.NewNRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+	area := cursor.Bounds().Sub(cursor.Bounds().Min).Add(pos)
+	draw.Draw(out, area, cursor, cursor.Bounds().Min, draw.Over)
+	return out
 }
 
 func saveImage(pix image.Image, w fyne.Window) {
@@ -76,9 +182,7 @@ func saveImage(pix image.Image, w fyne.Window) {
 	}, w)
 
 	d.SetFilter(storage.NewMimeTypeFileFilter([]string{"image/png"}))
-
-	now := time.Now().Format("20060102T150405") // YYYYMMDD"T"HHMMSS
-	d.SetFileName("screenshot-" + now + ".png")
+	d.SetFileName(screenshotFileName())
 
 	if dir, err := getPicturesDir(); err == nil {
 		d.SetLocation(dir)
@@ -88,3 +192,8 @@ func saveImage(pix image.Image, w fyne.Window) {
 
 	d.Show()
 }
+
+func screenshotFileName() string {
+	now := time.Now().Format("20060102T150405") // YYYYMMDD"T"HHMMSS
+	return "screenshot-" + now + ".png"
+}
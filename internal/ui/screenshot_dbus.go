@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/godbus/dbus/v5"
+
+	"fyshos.com/fynedesk/wm"
+)
+
+const (
+	screenshotPath  = "/io/fyshos/FyneDesk/Screenshot"
+	screenshotIface = "io.fyshos.FyneDesk.Screenshot"
+
+	// screenshotToClipboard is the destination that asks for a screenshot to be copied instead of saved.
+	screenshotToClipboard = "clipboard"
+)
+
+// screenshotService lets other tools take screenshots over D-Bus.
+type screenshotService struct {
+	desk *desktop
+}
+
+// Screenshot captures the "screen", the focused "window" or a "region" that the user selects, after waiting for
+// the delay in seconds. The image is copied to the clipboard if the destination is "clipboard", otherwise it is
+// saved as a PNG file in the pictures folder, using the destination as the file name unless it is empty.
+// The path of the saved file is returned.
+func (s *screenshotService) Screenshot(mode string, delay uint32, pointer bool, dest string) (string, *dbus.Error) {
+	opts, err := parseCaptureOptions(mode, delay, pointer)
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+
+	result := make(chan image.Image, 1)
+	s.desk.capture(opts, func(img image.Image) {
+		result <- img
+	})
+	img := <-result
+	if img == nil {
+		return "", dbus.MakeFailedError(errors.New("screenshot was cancelled or failed"))
+	}
+
+	if dest == screenshotToClipboard {
+		if err = s.desk.copyImage(img); err != nil {
+			return "", dbus.MakeFailedError(err)
+		}
+		return "", nil
+	}
+
+	dir, err := getPicturesDir()
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	path, err := screenshotDest(dir.Path(), dest)
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	if err = writePNG(path, img); err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	return path, nil
+}
+
+// screenshotDest returns the path to save a screenshot to in the pictures folder.
+// Only a file name is accepted, so that other apps on the session bus cannot have files written anywhere else.
+func screenshotDest(dir, name string) (string, error) {
+	if name == "" {
+		return filepath.Join(dir, screenshotFileName()), nil
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", errors.New("screenshot destination must be a file name, not a path")
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+func parseCaptureOptions(mode string, delay uint32, pointer bool) (captureOptions, error) {
+	opts := captureOptions{delay: time.Duration(delay) * time.Second, pointer: pointer}
+	switch mode {
+	case "", "screen":
+		opts.mode = captureScreen
+	case "window":
+		opts.mode = captureWindow
+	case "region":
+		opts.mode = captureRegion
+	default:
+		return opts, errors.New("unknown screenshot mode " + mode)
+	}
+
+	return opts, nil
+}
+
+func startScreenshotService(desk *desktop) {
+	err := wm.RegisterService(&screenshotService{desk: desk}, screenshotPath, screenshotIface)
+	if err != nil {
+		fyne.LogError("Could not start DBus screenshot service", err)
+	}
+}
+
+// writePNG saves the image to a new file at path, it fails if the file already exists rather than replacing it.
+func writePNG(path string, img image.Image) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err = png.Encode(f, img); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
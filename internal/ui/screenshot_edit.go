@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+type annotationTool int
+
+const (
+	toolArrow annotationTool = iota
+	toolBox
+	toolBlur
+	toolText
+)
+
+// annotator shows a screenshot and lets the user draw arrows, boxes, blurred areas and text over it.
+type annotator struct {
+	widget.BaseWidget
+	img         image.Image
+	annotations []annotation
+	tool        annotationTool
+	askText     func(func(string))
+
+	start, end fyne.Position
+	dragging   bool
+	preview    *canvas.Image
+}
+
+func newAnnotator(img image.Image, askText func(func(string))) *annotator {
+	a := &annotator{img: img, askText: askText}
+	a.preview = &canvas.Image{Image: img, FillMode: canvas.ImageFillContain}
+	a.ExtendBaseWidget(a)
+	return a
+}
+
+func (a *annotator) CreateRenderer() fyne.WidgetRenderer {
+	line := canvas.NewLine(annotationColor)
+	line.StrokeWidth = 2
+	box := &canvas.Rectangle{StrokeColor: annotationColor, StrokeWidth: 2}
+	return &annotatorRenderer{a: a, line: line, box: box,
+		objects: []fyne.CanvasObject{a.preview, line, box}}
+}
+
+func (a *annotator) DragEnd() {
+	if !a.dragging {
+		return
+	}
+	a.dragging = false
+
+	from, to := a.pixelPoint(a.start), a.pixelPoint(a.end)
+	switch a.tool {
+	case toolArrow:
+		a.add(&arrowAnnotation{from: from, to: to})
+	case toolBox:
+		a.add(&boxAnnotation{rect: image.Rectangle{Min: from, Max: to}})
+	case toolBlur:
+		a.add(&blurAnnotation{rect: image.Rectangle{Min: from, Max: to}})
+	default:
+		a.Refresh()
+	}
+}
+
+func (a *annotator) Dragged(ev *fyne.DragEvent) {
+	if a.tool == toolText {
+		return
+	}
+	if !a.dragging {
+		a.dragging = true
+		a.start = ev.Position.Subtract(ev.Dragged)
+	}
+	a.end = ev.Position
+	a.Refresh()
+}
+
+func (a *annotator) Tapped(ev *fyne.PointEvent) {
+	if a.tool != toolText || a.askText == nil {
+		return
+	}
+
+	pos := a.pixelPoint(ev.Position)
+	a.askText(func(text string) {
+		if text == "" {
+			return
+		}
+		a.add(&textAnnotation{pos: pos, text: text})
+	})
+}
+
+func (a *annotator) add(item annotation) {
+	a.annotations = append(a.annotations, item)
+	a.render()
+}
+
+// image returns the screenshot with all annotations applied.
+func (a *annotator) image() image.Image {
+	if len(a.annotations) == 0 {
+		return a.img
+	}
+
+	return renderAnnotations(a.img, a.annotations)
+}
+
+// imageArea returns the position and size of the screenshot within the widget, and its scale.
+func (a *annotator) imageArea() (fyne.Position, fyne.Size, float32) {
+	size := a.Size()
+	bounds := a.img.Bounds()
+	if bounds.Empty() {
+		return fyne.NewPos(0, 0), size, 1
+	}
+
+	scale := fyne.Min(size.Width/float32(bounds.Dx()), size.Height/float32(bounds.Dy()))
+	shown := fyne.NewSize(float32(bounds.Dx())*scale, float32(bounds.Dy())*scale)
+	return fyne.NewPos((size.Width-shown.Width)/2, (size.Height-shown.Height)/2), shown, scale
+}
+
+// pixelPoint converts a position in the widget to a pixel location in the screenshot.
+func (a *annotator) pixelPoint(pos fyne.Position) image.Point {
+	offset, _, scale := a.imageArea()
+	if scale == 0 {
+		return a.img.Bounds().Min
+	}
+
+	p := pos.Subtract(offset)
+	return image.Pt(int(p.X/scale), int(p.Y/scale)).Add(a.img.Bounds().Min)
+}
+
+func (a *annotator) render() {
+	a.preview.Image = a.image()
+	a.Refresh()
+}
+
+func (a *annotator) undo() {
+	if len(a.annotations) == 0 {
+		return
+	}
+
+	a.annotations = a.annotations[:len(a.annotations)-1]
+	a.render()
+}
+
+type annotatorRenderer struct {
+	a       *annotator
+	line    *canvas.Line
+	box     *canvas.Rectangle
+	objects []fyne.CanvasObject
+}
+
+func (r *annotatorRenderer) Destroy() {
+}
+
+func (r *annotatorRenderer) Layout(size fyne.Size) {
+	r.a.preview.Resize(size)
+
+	r.line.Hide()
+	r.box.Hide()
+	if !r.a.dragging {
+		return
+	}
+
+	start, end := r.a.start, r.a.end
+	if r.a.tool == toolArrow {
+		r.line.Position1 = start
+		r.line.Position2 = end
+		r.line.Show()
+		return
+	}
+	topLeft := fyne.NewPos(fyne.Min(start.X, end.X), fyne.Min(start.Y, end.Y))
+	r.box.Move(topLeft)
+	r.box.Resize(fyne.NewSize(fyne.Max(start.X, end.X)-topLeft.X, fyne.Max(start.Y, end.Y)-topLeft.Y))
+	r.box.Show()
+}
+
+func (r *annotatorRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(240, 160)
+}
+
+func (r *annotatorRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *annotatorRenderer) Refresh() {
+	r.Layout(r.a.Size())
+	canvas.Refresh(r.a)
+}
+
+// showCaptureSave opens a window where a screenshot can be annotated, then saved or copied.
+func (l *desktop) showCaptureSave(img image.Image) {
+	if img == nil {
+		return
+	}
+	w := fyne.CurrentApp().NewWindow("Screenshot")
+
+	edit := newAnnotator(img, func(add func(string)) {
+		text := widget.NewEntry()
+		dialog.ShowForm("Add text", "Add", "Cancel", []*widget.FormItem{widget.NewFormItem("Text", text)},
+			func(ok bool) {
+				if ok {
+					add(text.Text)
+				}
+			}, w)
+		w.Canvas().Focus(text)
+	})
+
+	var tools []*widget.Button
+	toolButton := func(name string, tool annotationTool) *widget.Button {
+		b := &widget.Button{Text: name}
+		b.OnTapped = func() {
+			edit.tool = tool
+			for _, t := range tools {
+				t.Importance = widget.MediumImportance
+				t.Refresh()
+			}
+			b.Importance = widget.HighImportance
+			b.Refresh()
+		}
+		tools = append(tools, b)
+		return b
+	}
+	arrow := toolButton("Arrow", toolArrow)
+	arrow.Importance = widget.HighImportance
+	toolbar := container.NewHBox(arrow, toolButton("Box", toolBox), toolButton("Blur", toolBlur),
+		toolButton("Text", toolText), widget.NewButtonWithIcon("", theme.ContentUndoIcon(), edit.undo))
+
+	save := &widget.Button{Text: "Save...",
+		Importance: widget.HighImportance,
+		OnTapped: func() {
+			saveImage(edit.image(), w)
+		},
+	}
+	copyImage := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		if err := l.copyImage(edit.image()); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		w.Close()
+	})
+
+	buttons := container.NewHBox(
+		layout.NewSpacer(),
+		widget.NewButton("Cancel", w.Close),
+		copyImage,
+		save,
+	)
+
+	w.SetContent(container.NewBorder(toolbar, buttons, nil, nil, edit))
+	w.Resize(fyne.NewSize(640, 480))
+	w.Show()
+}
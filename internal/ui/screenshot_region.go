@@ -0,0 +1,317 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	deskDriver "fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk"
+)
+
+const (
+	magnifierPixels = 15 // the number of screen pixels across the magnifier
+	magnifierSize   = float32(120)
+	magnifierOffset = float32(24)
+)
+
+var regionShade = color.NRGBA{A: 0x80}
+
+// regionSelector shows a captured screen so the user can drag out the area they want to keep.
+// In point mode the user instead taps a single pixel, which is selected as a 1x1 area.
+// A magnifier follows the pointer to help with precise selection.
+type regionSelector struct {
+	widget.BaseWidget
+	img        image.Image
+	point      bool
+	onSelected func(image.Rectangle) // called with an empty rectangle if cancelled
+
+	pointer, start, end fyne.Position
+	dragging, hovered   bool
+}
+
+func newRegionSelector(img image.Image, point bool, onSelected func(image.Rectangle)) *regionSelector {
+	r := &regionSelector{img: img, point: point, onSelected: onSelected}
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+func (r *regionSelector) CreateRenderer() fyne.WidgetRenderer {
+	bg := &canvas.Image{Image: r.img, FillMode: canvas.ImageFillStretch}
+	var shades []*canvas.Rectangle
+	objects := []fyne.CanvasObject{bg}
+	for i := 0; i < 4; i++ {
+		shade := canvas.NewRectangle(regionShade)
+		shades = append(shades, shade)
+		objects = append(objects, shade)
+	}
+
+	outline := &canvas.Rectangle{StrokeColor: theme.PrimaryColor(), StrokeWidth: 1}
+	zoom := &canvas.Image{FillMode: canvas.ImageFillStretch, ScaleMode: canvas.ImageScalePixels}
+	zoomBorder := &canvas.Rectangle{StrokeColor: theme.ForegroundColor(), StrokeWidth: 2}
+	cross := canvas.NewRectangle(color.Transparent)
+	cross.StrokeColor = theme.PrimaryColor()
+	cross.StrokeWidth = 1
+	labelBG := canvas.NewRectangle(theme.OverlayBackgroundColor())
+	label := canvas.NewText("", theme.ForegroundColor())
+	label.TextSize = theme.CaptionTextSize()
+	objects = append(objects, outline, zoom, zoomBorder, cross, labelBG, label)
+
+	return &regionSelectorRenderer{sel: r, objects: objects, bg: bg, shades: shades, outline: outline,
+		zoom: zoom, zoomBorder: zoomBorder, cross: cross, labelBG: labelBG, label: label}
+}
+
+func (r *regionSelector) Cursor() deskDriver.Cursor {
+	return deskDriver.CrosshairCursor
+}
+
+func (r *regionSelector) DragEnd() {
+	if !r.dragging {
+		return
+	}
+	r.dragging = false
+	area := r.pixelRect(r.start, r.end)
+	r.onSelected(area)
+}
+
+func (r *regionSelector) Dragged(ev *fyne.DragEvent) {
+	if r.point {
+		r.MouseMoved(&deskDriver.MouseEvent{PointEvent: fyne.PointEvent{Position: ev.Position}})
+		return
+	}
+	if !r.dragging {
+		r.dragging = true
+		r.start = ev.Position.Subtract(ev.Dragged)
+	}
+	r.end = ev.Position
+	r.pointer = ev.Position
+	r.Refresh()
+}
+
+func (r *regionSelector) MouseIn(ev *deskDriver.MouseEvent) {
+	r.hovered = true
+	r.MouseMoved(ev)
+}
+
+func (r *regionSelector) MouseMoved(ev *deskDriver.MouseEvent) {
+	r.pointer = ev.Position
+	r.Refresh()
+}
+
+func (r *regionSelector) MouseOut() {
+	r.hovered = false
+	r.Refresh()
+}
+
+func (r *regionSelector) Tapped(ev *fyne.PointEvent) {
+	if !r.point {
+		return
+	}
+
+	pix := r.pixelPoint(ev.Position)
+	r.onSelected(image.Rectangle{Min: pix, Max: pix.Add(image.Pt(1, 1))})
+}
+
+// TappedSecondary cancels the selection.
+func (r *regionSelector) TappedSecondary(*fyne.PointEvent) {
+	r.onSelected(image.Rectangle{})
+}
+
+// pixelPoint converts a position in the widget to a pixel location in the captured image.
+func (r *regionSelector) pixelPoint(pos fyne.Position) image.Point {
+	size := r.Size()
+	bounds := r.img.Bounds()
+	if size.Width == 0 || size.Height == 0 {
+		return bounds.Min
+	}
+
+	x := int(pos.X / size.Width * float32(bounds.Dx()))
+	y := int(pos.Y / size.Height * float32(bounds.Dy()))
+	return image.Pt(x, y).Add(bounds.Min)
+}
+
+func (r *regionSelector) pixelRect(from, to fyne.Position) image.Rectangle {
+	return image.Rectangle{Min: r.pixelPoint(from), Max: r.pixelPoint(to)}.Canon().Intersect(r.img.Bounds())
+}
+
+type regionSelectorRenderer struct {
+	sel     *regionSelector
+	objects []fyne.CanvasObject
+
+	bg                *canvas.Image
+	shades            []*canvas.Rectangle
+	outline           *canvas.Rectangle
+	zoom              *canvas.Image
+	zoomBorder, cross *canvas.Rectangle
+	labelBG           *canvas.Rectangle
+	label             *canvas.Text
+}
+
+func (r *regionSelectorRenderer) Destroy() {
+}
+
+func (r *regionSelectorRenderer) Layout(size fyne.Size) {
+	r.bg.Resize(size)
+
+	minPos, maxPos := fyne.NewPos(0, 0), fyne.NewPos(0, 0)
+	if r.sel.dragging {
+		minPos = fyne.NewPos(fyne.Min(r.sel.start.X, r.sel.end.X), fyne.Min(r.sel.start.Y, r.sel.end.Y))
+		maxPos = fyne.NewPos(fyne.Max(r.sel.start.X, r.sel.end.X), fyne.Max(r.sel.start.Y, r.sel.end.Y))
+		r.outline.Show()
+	} else {
+		r.outline.Hide()
+	}
+
+	// shade all of the screen apart from the selection
+	r.shades[0].Move(fyne.NewPos(0, 0))
+	r.shades[0].Resize(fyne.NewSize(size.Width, minPos.Y))
+	r.shades[1].Move(fyne.NewPos(0, maxPos.Y))
+	r.shades[1].Resize(fyne.NewSize(size.Width, size.Height-maxPos.Y))
+	r.shades[2].Move(fyne.NewPos(0, minPos.Y))
+	r.shades[2].Resize(fyne.NewSize(minPos.X, maxPos.Y-minPos.Y))
+	r.shades[3].Move(fyne.NewPos(maxPos.X, minPos.Y))
+	r.shades[3].Resize(fyne.NewSize(size.Width-maxPos.X, maxPos.Y-minPos.Y))
+	r.outline.Move(minPos)
+	r.outline.Resize(fyne.NewSize(maxPos.X-minPos.X, maxPos.Y-minPos.Y))
+
+	r.layoutMagnifier(size)
+}
+
+func (r *regionSelectorRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(0, 0)
+}
+
+func (r *regionSelectorRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *regionSelectorRenderer) Refresh() {
+	r.outline.StrokeColor = theme.PrimaryColor()
+	r.cross.StrokeColor = theme.PrimaryColor()
+	r.label.Color = theme.ForegroundColor()
+	r.labelBG.FillColor = theme.OverlayBackgroundColor()
+	r.Layout(r.sel.Size())
+	canvas.Refresh(r.sel)
+}
+
+func (r *regionSelectorRenderer) layoutMagnifier(size fyne.Size) {
+	parts := []fyne.CanvasObject{r.zoom, r.zoomBorder, r.cross, r.labelBG, r.label}
+	if !r.sel.hovered && !r.sel.dragging {
+		for _, o := range parts {
+			o.Hide()
+		}
+		return
+	}
+	for _, o := range parts {
+		o.Show()
+	}
+
+	pix := r.sel.pixelPoint(r.sel.pointer)
+	if r.sel.dragging {
+		area := r.sel.pixelRect(r.sel.start, r.sel.end)
+		r.label.Text = fmt.Sprintf("%d × %d", area.Dx(), area.Dy())
+	} else {
+		r.label.Text = fmt.Sprintf("%d, %d", pix.X, pix.Y)
+	}
+	half := magnifierPixels / 2
+	r.zoom.Image = cropImage(r.sel.img, image.Rect(pix.X-half, pix.Y-half, pix.X+half+1, pix.Y+half+1))
+	r.zoom.Refresh()
+
+	// keep the magnifier on screen, flipping it to the other side of the pointer near the edges
+	labelSize := r.label.MinSize()
+	height := magnifierSize + labelSize.Height
+	pos := r.sel.pointer.Add(fyne.NewPos(magnifierOffset, magnifierOffset))
+	if pos.X+magnifierSize > size.Width {
+		pos.X = r.sel.pointer.X - magnifierOffset - magnifierSize
+	}
+	if pos.Y+height > size.Height {
+		pos.Y = r.sel.pointer.Y - magnifierOffset - height
+	}
+
+	r.zoom.Move(pos)
+	r.zoom.Resize(fyne.NewSquareSize(magnifierSize))
+	r.zoomBorder.Move(pos)
+	r.zoomBorder.Resize(fyne.NewSquareSize(magnifierSize))
+	pixel := magnifierSize / magnifierPixels
+	r.cross.Move(pos.Add(fyne.NewPos(pixel*float32(half), pixel*float32(half))))
+	r.cross.Resize(fyne.NewSquareSize(pixel))
+	r.labelBG.Move(pos.Add(fyne.NewPos(0, magnifierSize)))
+	r.labelBG.Resize(fyne.NewSize(magnifierSize, labelSize.Height))
+	r.label.Move(pos.Add(fyne.NewPos((magnifierSize-labelSize.Width)/2, magnifierSize)))
+	r.label.Resize(labelSize)
+}
+
+// selectPoint shows a captured screen over the desktop so that the user can pick a pixel from it.
+// The chosen pixel is passed to the callback as a 1x1 area, or an empty rectangle if the user cancels.
+// The function returned closes the selector, as if the user cancelled.
+func (l *desktop) selectPoint(img image.Image, onSelected func(image.Rectangle)) func() {
+	return l.showSelector(img, true, onSelected)
+}
+
+// selectRegion shows a captured screen over the desktop so that the user can select an area of it.
+// The chosen area is passed to the callback, which receives an empty rectangle if the user cancels.
+// The function returned closes the selector, as if the user cancelled.
+func (l *desktop) selectRegion(img image.Image, onSelected func(image.Rectangle)) func() {
+	return l.showSelector(img, false, onSelected)
+}
+
+func (l *desktop) showSelector(img image.Image, point bool, onSelected func(image.Rectangle)) func() {
+	var win fyne.Window
+	if d, ok := fyne.CurrentApp().Driver().(deskDriver.Driver); ok {
+		win = d.CreateSplashWindow()
+	} else {
+		win = fyne.CurrentApp().NewWindow("Select Region")
+	}
+
+	var once sync.Once
+	finish := func(area image.Rectangle) {
+		once.Do(func() { // the selector may also be closed by the app that asked for it
+			win.Close()
+			go onSelected(area)
+		})
+	}
+	win.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		if ev.Name == fyne.KeyEscape {
+			finish(image.Rectangle{})
+		}
+	})
+	win.SetOnClosed(func() {
+		finish(image.Rectangle{})
+	})
+	win.SetPadded(false)
+	win.SetContent(newRegionSelector(img, point, finish))
+
+	scale := float32(1)
+	if l.screens != nil && l.screens.Primary() != nil {
+		scale = l.screens.Primary().CanvasScale()
+	}
+	bounds := img.Bounds()
+	l.wm.ShowOverlay(win, fyne.NewSize(float32(bounds.Dx())/scale, float32(bounds.Dy())/scale), fyne.NewPos(0, 0))
+	win.RequestFocus()
+	return func() {
+		finish(image.Rectangle{})
+	}
+}
+
+// SelectScreenRegion lets the user drag out an area of the screen, which is passed to the callback in pixels.
+// The rectangle will be empty if the user cancelled or the screen could not be captured.
+func SelectScreenRegion(onSelected func(image.Rectangle)) {
+	desk, ok := fynedesk.Instance().(*desktop)
+	if !ok {
+		onSelected(image.Rectangle{})
+		return
+	}
+
+	img := desk.wm.Capture()
+	if img == nil {
+		onSelected(image.Rectangle{})
+		return
+	}
+	desk.selectRegion(img, onSelected)
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotator_Drag(t *testing.T) {
+	test.NewApp()
+	a := newAnnotator(image.NewNRGBA(image.Rect(0, 0, 200, 100)), nil)
+	a.Resize(fyne.NewSize(100, 100)) // image is shown at half size, with 25 above and below
+
+	assert.Equal(t, image.Pt(20, 10), a.pixelPoint(fyne.NewPos(10, 30)))
+
+	a.tool = toolBox
+	a.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(20, 35)},
+		Dragged: fyne.NewDelta(10, 5)})
+	a.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(60, 75)},
+		Dragged: fyne.NewDelta(40, 40)})
+	a.DragEnd()
+	assert.Equal(t, 1, len(a.annotations))
+	assert.Equal(t, &boxAnnotation{rect: image.Rect(20, 10, 120, 100)}, a.annotations[0])
+
+	a.undo()
+	assert.Equal(t, 0, len(a.annotations))
+	assert.Equal(t, a.img, a.image())
+}
+
+func TestAnnotator_Text(t *testing.T) {
+	test.NewApp()
+	a := newAnnotator(image.NewNRGBA(image.Rect(0, 0, 100, 100)), func(add func(string)) {
+		add("Hello")
+	})
+	a.Resize(fyne.NewSize(100, 100))
+
+	a.Tapped(&fyne.PointEvent{Position: fyne.NewPos(5, 5)})
+	assert.Equal(t, 0, len(a.annotations))
+	a.tool = toolText
+	a.Tapped(&fyne.PointEvent{Position: fyne.NewPos(5, 5)})
+	assert.Equal(t, []annotation{&textAnnotation{pos: image.Pt(5, 5), text: "Hello"}}, a.annotations)
+}
+
+func TestCropImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.SetNRGBA(4, 3, color.NRGBA{R: 0xff, A: 0xff})
+
+	out := cropImage(img, image.Rect(8, 8, 4, 3))
+	assert.Equal(t, image.Rect(0, 0, 4, 5), out.Bounds())
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, out.NRGBAAt(0, 0))
+
+	out = cropImage(img, image.Rect(8, 8, 20, 20))
+	assert.Equal(t, image.Rect(0, 0, 2, 2), out.Bounds())
+}
+
+func TestDrawCursor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	cursor := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	cursor.SetNRGBA(1, 1, color.NRGBA{G: 0xff, A: 0xff})
+
+	out := drawCursor(img, cursor, image.Pt(5, 6))
+	assert.Equal(t, color.NRGBA{G: 0xff, A: 0xff}, out.NRGBAAt(6, 7))
+	assert.Equal(t, color.NRGBA{}, out.NRGBAAt(5, 6))
+	assert.Equal(t, color.NRGBA{}, img.NRGBAAt(6, 7))
+}
+
+func TestParseCaptureOptions(t *testing.T) {
+	opts, err := parseCaptureOptions("region", 3, true)
+	assert.Nil(t, err)
+	assert.Equal(t, captureOptions{mode: captureRegion, delay: 3 * time.Second, pointer: true}, opts)
+
+	opts, err = parseCaptureOptions("", 0, false)
+	assert.Nil(t, err)
+	assert.Equal(t, captureScreen, opts.mode)
+
+	_, err = parseCaptureOptions("everything", 0, false)
+	assert.NotNil(t, err)
+}
+
+func TestScreenshotDest(t *testing.T) {
+	dir := t.TempDir()
+	path, err := screenshotDest(dir, "")
+	assert.Nil(t, err)
+	assert.Equal(t, dir, filepath.Dir(path))
+
+	path, err = screenshotDest(dir, "shot.png")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "shot.png"), path)
+
+	_, err = screenshotDest(dir, "/home/user/.bashrc")
+	assert.NotNil(t, err)
+	_, err = screenshotDest(dir, "../shot.png")
+	assert.NotNil(t, err)
+	_, err = screenshotDest(dir, "..")
+	assert.NotNil(t, err)
+}
+
+func TestWritePNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shot.png")
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	assert.Nil(t, writePNG(path, img))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.NotZero(t, info.Size())
+
+	assert.Nil(t, os.WriteFile(path, []byte("keep"), 0600))
+	assert.NotNil(t, writePNG(path, img)) // existing files are not replaced
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, "keep", string(data))
+
+	link := filepath.Join(filepath.Dir(path), "link.png")
+	assert.Nil(t, os.Symlink(filepath.Join(filepath.Dir(path), "target"), link))
+	assert.NotNil(t, writePNG(link, img)) // nor are files created through links
+	_, err = os.Stat(filepath.Join(filepath.Dir(path), "target"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRegionSelector(t *testing.T) {
+	test.NewApp()
+	var selected image.Rectangle
+	r := newRegionSelector(image.NewNRGBA(image.Rect(0, 0, 400, 200)), false, func(area image.Rectangle) {
+		selected = area
+	})
+	r.Resize(fyne.NewSize(200, 100))
+	test.WidgetRenderer(r) // check it can draw
+
+	r.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(50, 40)},
+		Dragged: fyne.NewDelta(-10, -10)})
+	r.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(10, 20)},
+		Dragged: fyne.NewDelta(-40, -20)})
+	r.DragEnd()
+	assert.Equal(t, image.Rect(20, 40, 120, 100), selected)
+
+	r.TappedSecondary(&fyne.PointEvent{})
+	assert.True(t, selected.Empty())
+
+	r.Tapped(&fyne.PointEvent{Position: fyne.NewPos(10, 10)})
+	assert.True(t, selected.Empty())
+}
+
+func TestRegionSelector_Point(t *testing.T) {
+	test.NewApp()
+	var selected image.Rectangle
+	r := newRegionSelector(image.NewNRGBA(image.Rect(0, 0, 400, 200)), true, func(area image.Rectangle) {
+		selected = area
+	})
+	r.Resize(fyne.NewSize(200, 100))
+
+	r.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(50, 40)},
+		Dragged: fyne.NewDelta(-10, -10)})
+	r.DragEnd()
+	assert.True(t, selected.Empty())
+
+	r.Tapped(&fyne.PointEvent{Position: fyne.NewPos(10, 20)})
+	assert.Equal(t, image.Rect(20, 40, 21, 41), selected)
+}
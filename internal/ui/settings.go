@@ -3,8 +3,10 @@ package ui
 import (
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"fyshos.com/fynedesk"
 
@@ -13,12 +15,21 @@ import (
 
 type deskSettings struct {
 	background             string
+	backgrounds            map[string]string
+	backgroundMode         string
+	backgroundInterval     time.Duration
 	iconTheme              string
 	launcherIcons          []string
 	launcherIconSize       float32
 	launcherDisableTaskbar bool
 	launcherDisableZoom    bool
 	launcherZoomScale      float32
+	launcherPosition       string
+	launcherAutoHide       bool
+	launcherWindowList     bool
+	launcherAllScreens     bool
+	launcherScreens        []string
+	launcherScreenTaskbar  bool
 	borderButtonPosition   string
 	clockFormatting        string
 
@@ -26,6 +37,7 @@ type deskSettings struct {
 	moduleNames []string
 
 	narrowPanel, narrowLeftLauncher bool
+	widgetPanelPosition             string
 
 	listenerLock    sync.Mutex
 	changeListeners []chan fynedesk.DeskSettings
@@ -35,6 +47,35 @@ func (d *deskSettings) Background() string {
 	return d.background
 }
 
+// BackgroundFor returns the wallpaper configured for a screen and virtual desktop.
+// A setting for the screen and desktop is preferred, then the screen, then the desktop, falling back to Background.
+func (d *deskSettings) BackgroundFor(screen string, desk int) string {
+	for _, key := range []string{backgroundKey(screen, desk), backgroundKey(screen, -1), backgroundKey("", desk)} {
+		if bg, ok := d.backgrounds[key]; ok {
+			return bg
+		}
+	}
+
+	return d.background
+}
+
+// backgroundSetting returns the wallpaper stored for exactly this screen and desktop, without any fallback.
+func (d *deskSettings) backgroundSetting(screen string, desk int) string {
+	if screen == "" && desk < 0 {
+		return d.background
+	}
+
+	return d.backgrounds[backgroundKey(screen, desk)]
+}
+
+func (d *deskSettings) BackgroundMode() string {
+	return d.backgroundMode
+}
+
+func (d *deskSettings) BackgroundSlideshowInterval() time.Duration {
+	return d.backgroundInterval
+}
+
 func (d *deskSettings) IconTheme() string {
 	return d.iconTheme
 }
@@ -59,6 +100,30 @@ func (d *deskSettings) LauncherZoomScale() float32 {
 	return d.launcherZoomScale
 }
 
+func (d *deskSettings) LauncherPosition() string {
+	return d.launcherPosition
+}
+
+func (d *deskSettings) LauncherAutoHide() bool {
+	return d.launcherAutoHide
+}
+
+func (d *deskSettings) LauncherWindowList() bool {
+	return d.launcherWindowList
+}
+
+func (d *deskSettings) LauncherAllScreens() bool {
+	return d.launcherAllScreens
+}
+
+func (d *deskSettings) LauncherScreens() []string {
+	return d.launcherScreens
+}
+
+func (d *deskSettings) LauncherTaskbarScreenOnly() bool {
+	return d.launcherScreenTaskbar
+}
+
 func (d *deskSettings) KeyboardModifier() fyne.KeyModifier {
 	return d.modifier
 }
@@ -75,6 +140,10 @@ func (d *deskSettings) NarrowLeftLauncher() bool {
 	return d.narrowLeftLauncher
 }
 
+func (d *deskSettings) WidgetPanelPosition() string {
+	return d.widgetPanelPosition
+}
+
 func (d *deskSettings) BorderButtonPosition() string {
 	return d.borderButtonPosition
 }
@@ -103,6 +172,29 @@ func (d *deskSettings) apply() {
 	}
 }
 
+// backgroundKey returns the key used to store a wallpaper for a screen and desktop.
+// An empty screen or negative desktop is stored as an empty part that matches all.
+func backgroundKey(screen string, desk int) string {
+	if desk < 0 {
+		return screen + "/"
+	}
+	return screen + "/" + strconv.Itoa(desk)
+}
+
+// parseBackgroundKey returns the screen and desktop that a stored wallpaper key refers to.
+func parseBackgroundKey(key string) (string, int) {
+	screen, desk, _ := strings.Cut(key, "/")
+	if desk == "" {
+		return screen, -1
+	}
+
+	id, err := strconv.Atoi(desk)
+	if err != nil {
+		return screen, -1
+	}
+	return screen, id
+}
+
 func isModuleEnabled(name string, settings fynedesk.DeskSettings) bool {
 	for _, mod := range settings.ModuleNames() {
 		if mod == name {
@@ -119,6 +211,43 @@ func (d *deskSettings) setBackground(name string) {
 	d.apply()
 }
 
+// setBackgroundFor stores the wallpaper for a screen and desktop, an empty screen name or negative desktop matches all.
+// Passing an empty background removes the setting so that the default is used.
+func (d *deskSettings) setBackgroundFor(screen string, desk int, bg string) {
+	if screen == "" && desk < 0 {
+		d.setBackground(bg)
+		return
+	}
+
+	if d.backgrounds == nil {
+		d.backgrounds = make(map[string]string)
+	}
+	if bg == "" {
+		delete(d.backgrounds, backgroundKey(screen, desk))
+	} else {
+		d.backgrounds[backgroundKey(screen, desk)] = bg
+	}
+
+	var items []string
+	for key, path := range d.backgrounds {
+		items = append(items, key+"="+path)
+	}
+	fyne.CurrentApp().Preferences().SetString("backgrounds", strings.Join(items, "|"))
+	d.apply()
+}
+
+func (d *deskSettings) setBackgroundMode(mode string) {
+	d.backgroundMode = mode
+	fyne.CurrentApp().Preferences().SetString("backgroundmode", d.backgroundMode)
+	d.apply()
+}
+
+func (d *deskSettings) setBackgroundSlideshowInterval(interval time.Duration) {
+	d.backgroundInterval = interval
+	fyne.CurrentApp().Preferences().SetInt("backgroundinterval", int(d.backgroundInterval.Minutes()))
+	d.apply()
+}
+
 func (d *deskSettings) setIconTheme(name string) {
 	d.iconTheme = name
 	fyne.CurrentApp().Preferences().SetString("icontheme", d.iconTheme)
@@ -156,6 +285,42 @@ func (d *deskSettings) setLauncherZoomScale(scale float32) {
 	d.apply()
 }
 
+func (d *deskSettings) setLauncherPosition(pos string) {
+	d.launcherPosition = pos
+	fyne.CurrentApp().Preferences().SetString("launcherposition", d.launcherPosition)
+	d.apply()
+}
+
+func (d *deskSettings) setLauncherAutoHide(hide bool) {
+	d.launcherAutoHide = hide
+	fyne.CurrentApp().Preferences().SetBool("launcherautohide", d.launcherAutoHide)
+	d.apply()
+}
+
+func (d *deskSettings) setLauncherWindowList(list bool) {
+	d.launcherWindowList = list
+	fyne.CurrentApp().Preferences().SetBool("launcherwindowlist", d.launcherWindowList)
+	d.apply()
+}
+
+func (d *deskSettings) setLauncherAllScreens(all bool) {
+	d.launcherAllScreens = all
+	fyne.CurrentApp().Preferences().SetBool("launcherallscreens", d.launcherAllScreens)
+	d.apply()
+}
+
+func (d *deskSettings) setLauncherScreens(names []string) {
+	d.launcherScreens = names
+	fyne.CurrentApp().Preferences().SetString("launcherscreens", strings.Join(names, "|"))
+	d.apply()
+}
+
+func (d *deskSettings) setLauncherTaskbarScreenOnly(only bool) {
+	d.launcherScreenTaskbar = only
+	fyne.CurrentApp().Preferences().SetBool("launchertaskbarscreenonly", d.launcherScreenTaskbar)
+	d.apply()
+}
+
 func (d *deskSettings) setKeyboardModifier(mod fyne.KeyModifier) {
 	d.modifier = mod
 	fyne.CurrentApp().Preferences().SetInt("keyboardmodifier", int(d.modifier))
@@ -181,6 +346,12 @@ func (d *deskSettings) setNarrowWidgetPanel(narrow bool) {
 	d.apply()
 }
 
+func (d *deskSettings) setWidgetPanelPosition(pos string) {
+	d.widgetPanelPosition = pos
+	fyne.CurrentApp().Preferences().SetString("widgetpanelposition", d.widgetPanelPosition)
+	d.apply()
+}
+
 func (d *deskSettings) setBorderButtonPosition(pos string) {
 	d.borderButtonPosition = pos
 	fyne.CurrentApp().Preferences().SetString("borderbuttonposition", d.borderButtonPosition)
@@ -200,6 +371,16 @@ func (d *deskSettings) load() {
 	} else {
 		d.background = fyne.CurrentApp().Preferences().String("background")
 	}
+	d.backgrounds = make(map[string]string)
+	if backgrounds := fyne.CurrentApp().Preferences().String("backgrounds"); backgrounds != "" {
+		for _, item := range strings.Split(backgrounds, "|") {
+			if key, path, ok := strings.Cut(item, "="); ok {
+				d.backgrounds[key] = path
+			}
+		}
+	}
+	d.backgroundMode = fyne.CurrentApp().Preferences().StringWithFallback("backgroundmode", wallpaperStretch)
+	d.backgroundInterval = time.Duration(fyne.CurrentApp().Preferences().IntWithFallback("backgroundinterval", 10)) * time.Minute
 
 	env = os.Getenv("FYNEDESK_ICONTHEME")
 	if env != "" {
@@ -235,6 +416,13 @@ func (d *deskSettings) load() {
 		d.launcherZoomScale = 2.0
 	}
 
+	d.launcherAllScreens = fyne.CurrentApp().Preferences().Bool("launcherallscreens")
+	launcherScreens := fyne.CurrentApp().Preferences().String("launcherscreens")
+	if launcherScreens != "" {
+		d.launcherScreens = strings.Split(launcherScreens, "|")
+	}
+	d.launcherScreenTaskbar = fyne.CurrentApp().Preferences().Bool("launchertaskbarscreenonly")
+
 	defaultModules := "Battery|Brightness|Compositor|Sound|Launcher: Calculate|Launcher: Open URLs|Network|Virtual Desktops|SystemTray"
 	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" { // testing
 		defaultModules = "Battery|Brightness|Sound|Launcher: Calculate|Launcher: Open URLs|Network|Virtual Desktops"
@@ -246,6 +434,15 @@ func (d *deskSettings) load() {
 	d.modifier = fyne.KeyModifier(fyne.CurrentApp().Preferences().IntWithFallback("keyboardmodifier", int(fyne.KeyModifierSuper)))
 	d.narrowLeftLauncher = fyne.CurrentApp().Preferences().BoolWithFallback("launchernarrowleft", true)
 	d.narrowPanel = fyne.CurrentApp().Preferences().BoolWithFallback("narrowpanel", true)
+	d.widgetPanelPosition = fyne.CurrentApp().Preferences().StringWithFallback("widgetpanelposition", edgeRight)
+
+	defaultPosition := edgeBottom
+	if d.narrowLeftLauncher {
+		defaultPosition = edgeLeft
+	}
+	d.launcherPosition = fyne.CurrentApp().Preferences().StringWithFallback("launcherposition", defaultPosition)
+	d.launcherAutoHide = fyne.CurrentApp().Preferences().Bool("launcherautohide")
+	d.launcherWindowList = fyne.CurrentApp().Preferences().Bool("launcherwindowlist")
 
 	d.borderButtonPosition = fyne.CurrentApp().Preferences().StringWithFallback("borderbuttonposition", "Left")
 
@@ -268,13 +465,13 @@ func (d *deskSettings) loadRecents() {
 		apps = append(apps, app)
 	}
 
-	desk.recent = apps
+	desk.setRecent(apps)
 }
 
 func (d *deskSettings) saveRecents() {
 	var list []string
 
-	for _, a := range fynedesk.Instance().(*desktop).recent {
+	for _, a := range fynedesk.Instance().(*desktop).RecentApps() {
 		list = append(list, a.Name())
 	}
 
@@ -17,3 +17,25 @@ func TestDeskSettings_IsModuleEnabled(t *testing.T) {
 	assert.False(t, isModuleEnabled("Maybe", s))
 	assert.False(t, isModuleEnabled("No", s))
 }
+
+func TestDeskSettings_BackgroundFor(t *testing.T) {
+	s := &deskSettings{background: "default"}
+	assert.Equal(t, "default", s.BackgroundFor("Screen0", 0))
+
+	s.backgrounds = map[string]string{
+		backgroundKey("", 1):         "desk1",
+		backgroundKey("Screen1", -1): "screen1",
+		backgroundKey("Screen1", 1):  "screen1desk1",
+	}
+	assert.Equal(t, "default", s.BackgroundFor("Screen0", 0))
+	assert.Equal(t, "desk1", s.BackgroundFor("Screen0", 1))
+	assert.Equal(t, "screen1", s.BackgroundFor("Screen1", 0))
+	assert.Equal(t, "screen1desk1", s.BackgroundFor("Screen1", 1))
+
+	screen, desk := parseBackgroundKey(backgroundKey("Screen1", 1))
+	assert.Equal(t, "Screen1", screen)
+	assert.Equal(t, 1, desk)
+	screen, desk = parseBackgroundKey(backgroundKey("", -1))
+	assert.Equal(t, "", screen)
+	assert.Equal(t, -1, desk)
+}
@@ -1,12 +1,15 @@
 package ui
 
 import (
+	"fmt"
+	"image/color"
 	"os"
 	"os/exec"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -19,11 +22,33 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/modules/launcher"
 	wmtheme "fyshos.com/fynedesk/theme"
 	"fyshos.com/fynedesk/wm"
 )
 
-const randrHelper = "arandr"
+const (
+	randrHelper = "arandr"
+
+	// desktopCount matches the number of virtual desktops offered by the desktops module
+	desktopCount = 4
+)
+
+// defaultAppTypes are the kinds of app that can be chosen on the default apps screen,
+// with the MIME types that each one opens.
+var defaultAppTypes = []struct {
+	name      string
+	mimeTypes []string
+}{
+	{"Web Browser", []string{"x-scheme-handler/http", "x-scheme-handler/https", "text/html"}},
+	{"Mail Client", []string{"x-scheme-handler/mailto"}},
+	{"File Manager", []string{"inode/directory"}},
+}
+
+// mimeDefaultSetter is implemented by application providers that can change the default app for MIME types.
+type mimeDefaultSetter interface {
+	SetDefaultAppForMime(app fynedesk.AppData, mimeTypes ...string) error
+}
 
 type settingsUI struct {
 	settings *deskSettings
@@ -47,54 +72,19 @@ func (d *settingsUI) populateThemeIcons(box *fyne.Container, theme string) {
 }
 
 func (d *settingsUI) loadAppearanceScreen() fyne.CanvasObject {
-	var bgPathClear *widget.Button
-	bgPath := widget.NewEntry()
-	bgPath.SetPlaceHolder("Choose an image")
-	bgPathClear = widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
-		bgPath.SetText("")
-		bgPathClear.Disable()
-	})
-
-	if fyne.CurrentApp().Preferences().String("background") != "" {
-		bgPath.SetText(fyne.CurrentApp().Preferences().String("background"))
-	} else {
-		bgPathClear.Disable()
-	}
-	bgLabel := widget.NewLabelWithStyle("Background", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
-	bgDialog := dialog.NewFileOpen(func(file fyne.URIReadCloser, err error) {
-		if err != nil || file == nil {
-			return
-		}
-
-		// not advisable for cross-platform but we are desktop only
-		path := file.URI().String()[7:]
-		// TODO add a nice preview :)
-		_ = file.Close()
-
-		bgPath.SetText(path)
-		bgPathClear.Enable()
-	}, d.win)
-	bgDialog.SetFilter(storage.NewExtensionFileFilter([]string{".jpg", ".jpeg", ".png", ".svg"}))
-	if dir, err := getPicturesDir(); err == nil {
-		bgDialog.SetLocation(dir)
-	} else {
-		fyne.LogError("error finding pictures dir, falling back to home directory", err)
-	}
-
-	bgButtons := container.NewHBox(bgPathClear,
-		widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
-			bgDialog.Show()
-		}))
+	bg, applyBackground := d.loadBackgroundGroup()
 
 	clockLabel := widget.NewLabelWithStyle("Clock Format", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
 	clockFormat := &widget.RadioGroup{Options: []string{"12h", "24h"}, Required: true, Horizontal: true}
 	clockFormat.SetSelected(d.settings.ClockFormatting())
 
 	layoutLabel := widget.NewLabelWithStyle("Layout", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
-	narrowBar := widget.NewCheck("Narrow Side App Bar", nil)
+	narrowBar := widget.NewCheck("Narrow App Bar", nil)
 	narrowBar.Checked = d.settings.NarrowLeftLauncher()
 	narrowWidget := widget.NewCheck("Narrow Widget Bar", nil)
 	narrowWidget.Checked = d.settings.NarrowWidgetPanel()
+	widgetPosition := &widget.Select{Options: []string{edgeLeft, edgeRight}}
+	widgetPosition.SetSelected(d.settings.WidgetPanelPosition())
 
 	borderButtonLabel := widget.NewLabelWithStyle("Border Button Position", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
 	borderButton := &widget.Select{Options: []string{"Left", "Right"}}
@@ -113,10 +103,10 @@ func (d *settingsUI) loadAppearanceScreen() fyne.CanvasObject {
 		themeList.Add(themeButton)
 	}
 
-	bg := container.NewBorder(nil, nil, bgLabel, bgButtons, bgPath)
 	time := container.NewBorder(nil, nil, clockLabel, clockFormat)
 	lay := container.NewBorder(nil, nil, layoutLabel,
-		container.NewGridWithColumns(2, narrowBar, narrowWidget))
+		container.NewGridWithColumns(2, narrowBar, narrowWidget,
+			widget.NewLabel("Widget Panel Position"), widgetPosition))
 	border := container.NewBorder(nil, nil, borderButtonLabel, borderButton)
 	top := container.NewVBox(bg, time, lay, border)
 
@@ -126,17 +116,155 @@ func (d *settingsUI) loadAppearanceScreen() fyne.CanvasObject {
 
 	applyButton := container.NewHBox(layout.NewSpacer(),
 		&widget.Button{Text: "Apply", Importance: widget.HighImportance, OnTapped: func() {
-			d.settings.setBackground(bgPath.Text)
+			applyBackground()
 			d.settings.setIconTheme(themeLabel.Text)
 			d.settings.setClockFormatting(clockFormat.Selected)
 			d.settings.setBorderButtonPosition(borderButton.Selected)
 			d.settings.setNarrowLeftLauncher(narrowBar.Checked)
 			d.settings.setNarrowWidgetPanel(narrowWidget.Checked)
+			d.settings.setWidgetPanelPosition(widgetPosition.Selected)
 		}})
 
 	return container.NewBorder(top, applyButton, nil, nil, bottom)
 }
 
+// loadBackgroundGroup returns the wallpaper settings and a function that will apply any changes.
+func (d *settingsUI) loadBackgroundGroup() (fyne.CanvasObject, func()) {
+	var bgPathClear *widget.Button
+	bgPath := widget.NewEntry()
+	bgPath.SetPlaceHolder("Choose an image, folder or colour")
+	bgPath.OnChanged = func(path string) {
+		if path == "" {
+			bgPathClear.Disable()
+		} else {
+			bgPathClear.Enable()
+		}
+	}
+	bgPathClear = widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		bgPath.SetText("")
+	})
+
+	bgLabel := widget.NewLabelWithStyle("Background", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	bgDialog := dialog.NewFileOpen(func(file fyne.URIReadCloser, err error) {
+		if err != nil || file == nil {
+			return
+		}
+
+		// not advisable for cross-platform but we are desktop only
+		path := file.URI().String()[7:]
+		// TODO add a nice preview :)
+		_ = file.Close()
+
+		bgPath.SetText(path)
+	}, d.win)
+	bgDialog.SetFilter(storage.NewExtensionFileFilter([]string{".jpg", ".jpeg", ".png", ".svg"}))
+	folderDialog := dialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+		if err != nil || dir == nil {
+			return
+		}
+
+		bgPath.SetText(dir.Path())
+	}, d.win)
+	if dir, err := getPicturesDir(); err == nil {
+		bgDialog.SetLocation(dir)
+		folderDialog.SetLocation(dir)
+	} else {
+		fyne.LogError("error finding pictures dir, falling back to home directory", err)
+	}
+
+	colorButton := widget.NewButtonWithIcon("", theme.ColorPaletteIcon(), func() {
+		d.pickColor("Background Colour", func(c color.Color) {
+			bgPath.SetText(colorHex(c))
+		})
+	})
+	gradientButton := widget.NewButton("Gradient", func() {
+		d.pickColor("Gradient Top", func(top color.Color) {
+			d.pickColor("Gradient Bottom", func(bottom color.Color) {
+				bgPath.SetText(colorHex(top) + "," + colorHex(bottom))
+			})
+		})
+	})
+	bgButtons := container.NewHBox(bgPathClear,
+		widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+			bgDialog.Show()
+		}),
+		widget.NewButtonWithIcon("", theme.FolderIcon(), func() {
+			folderDialog.Show()
+		}), colorButton, gradientButton)
+
+	screenNames := []string{"All Screens"}
+	for _, screen := range fynedesk.Instance().Screens().Screens() {
+		screenNames = append(screenNames, screen.Name)
+	}
+	deskNames := []string{"All Desktops"}
+	for i := 1; i <= desktopCount; i++ {
+		deskNames = append(deskNames, "Desktop "+strconv.Itoa(i))
+	}
+	screenSelect := widget.NewSelect(screenNames, nil)
+	deskSelect := widget.NewSelect(deskNames, nil)
+	selected := func() (string, int) {
+		screen := ""
+		if screenSelect.SelectedIndex() > 0 {
+			screen = screenSelect.Selected
+		}
+		return screen, deskSelect.SelectedIndex() - 1
+	}
+	// keep edits for each screen and desktop until they are applied
+	edits := make(map[string]string)
+	current := backgroundKey("", -1)
+	showSelected := func(string) {
+		edits[current] = bgPath.Text
+		screen, desk := selected()
+		current = backgroundKey(screen, desk)
+		if path, ok := edits[current]; ok {
+			bgPath.SetText(path)
+		} else {
+			bgPath.SetText(d.settings.backgroundSetting(screen, desk))
+		}
+	}
+	bgPath.SetText(d.settings.backgroundSetting("", -1))
+	screenSelect.SetSelectedIndex(0)
+	deskSelect.SetSelectedIndex(0)
+	screenSelect.OnChanged = showSelected
+	deskSelect.OnChanged = showSelected
+
+	mode := widget.NewSelect(wallpaperModes, nil)
+	mode.SetSelected(d.settings.BackgroundMode())
+	interval := widget.NewEntry()
+	interval.Wrapping = fyne.TextWrapOff
+	interval.SetText(strconv.Itoa(int(d.settings.BackgroundSlideshowInterval().Minutes())))
+
+	path := container.NewBorder(nil, nil, bgLabel, bgButtons, bgPath)
+	target := container.NewGridWithColumns(2, screenSelect, deskSelect)
+	options := container.NewGridWithColumns(2,
+		container.NewBorder(nil, nil, widget.NewLabel("Scaling"), nil, mode),
+		container.NewBorder(nil, nil, widget.NewLabel("Slideshow Minutes"), nil, interval))
+
+	return container.NewVBox(path, target, options), func() {
+		edits[current] = bgPath.Text
+		for key, path := range edits {
+			screen, desk := parseBackgroundKey(key)
+			if path != d.settings.backgroundSetting(screen, desk) {
+				d.settings.setBackgroundFor(screen, desk, path)
+			}
+		}
+		d.settings.setBackgroundMode(mode.Selected)
+
+		minutes, err := strconv.Atoi(interval.Text)
+		if err != nil || minutes < 1 {
+			fyne.LogError("Invalid slideshow interval", err)
+			minutes = 10
+		}
+		d.settings.setBackgroundSlideshowInterval(time.Duration(minutes) * time.Minute)
+	}
+}
+
+func (d *settingsUI) pickColor(title string, picked func(color.Color)) {
+	picker := dialog.NewColorPicker(title, "", picked, d.win)
+	picker.Advanced = true
+	picker.Show()
+}
+
 func (d *settingsUI) populateOrderList(list *fyne.Container, add fyne.CanvasObject) {
 	var icons []fyne.CanvasObject
 	iconSize := float32(fynedesk.Instance().Settings().LauncherIconSize())
@@ -222,8 +350,51 @@ func (d *settingsUI) loadBarScreen() fyne.CanvasObject {
 	disableZoom := widget.NewCheck("Disable Zoom", nil)
 	disableZoom.SetChecked(d.settings.LauncherDisableZoom())
 
+	position := &widget.Select{Options: []string{edgeLeft, edgeRight, edgeTop, edgeBottom}}
+	position.SetSelected(launcherEdge(d.settings))
+	positionCell := container.NewHBox(widget.NewLabel("Position:"), position)
+
+	autoHide := widget.NewCheck("Auto Hide", nil)
+	autoHide.SetChecked(d.settings.LauncherAutoHide())
+
+	windowList := widget.NewCheck("Show Window Titles", nil)
+	windowList.SetChecked(d.settings.LauncherWindowList())
+
 	details := widget.NewCard("Configuration", "",
-		container.NewGridWithColumns(2, sizeCell, zoomCell, disableTaskbar, disableZoom))
+		container.NewGridWithColumns(2, sizeCell, zoomCell, disableTaskbar, disableZoom,
+			positionCell, autoHide, windowList))
+
+	var screenChecks []*widget.Check
+	screenList := container.NewHBox()
+	for _, screen := range fynedesk.Instance().Screens().Screens() {
+		if screen == fynedesk.Instance().Screens().Primary() {
+			continue
+		}
+		check := widget.NewCheck(screen.Name, nil)
+		for _, name := range d.settings.LauncherScreens() {
+			if name == screen.Name {
+				check.Checked = true
+				break
+			}
+		}
+		screenChecks = append(screenChecks, check)
+		screenList.Add(check)
+	}
+	allScreens := widget.NewCheck("Show on All Screens", func(all bool) {
+		for _, check := range screenChecks {
+			if all {
+				check.Disable()
+			} else {
+				check.Enable()
+			}
+		}
+	})
+	allScreens.SetChecked(d.settings.LauncherAllScreens())
+	screenTaskbar := widget.NewCheck("Only List Windows on the Same Screen", nil)
+	screenTaskbar.SetChecked(d.settings.LauncherTaskbarScreenOnly())
+
+	screens := widget.NewCard("Screens", "",
+		container.NewVBox(container.NewHBox(allScreens, screenList), screenTaskbar))
 
 	applyButton := container.NewHBox(layout.NewSpacer(),
 		&widget.Button{Text: "Apply", Importance: widget.HighImportance, OnTapped: func() {
@@ -242,12 +413,25 @@ func (d *settingsUI) loadBarScreen() fyne.CanvasObject {
 			d.settings.setLauncherZoomScale(float32(scale))
 			d.settings.setLauncherDisableTaskbar(disableTaskbar.Checked)
 			d.settings.setLauncherDisableZoom(disableZoom.Checked)
+			d.settings.setLauncherPosition(position.Selected)
+			d.settings.setLauncherAutoHide(autoHide.Checked)
+			d.settings.setLauncherWindowList(windowList.Checked)
+
+			var names []string
+			for _, check := range screenChecks {
+				if check.Checked {
+					names = append(names, check.Text)
+				}
+			}
+			d.settings.setLauncherScreens(names)
+			d.settings.setLauncherAllScreens(allScreens.Checked)
+			d.settings.setLauncherTaskbarScreenOnly(screenTaskbar.Checked)
 
 			d.settings.setLauncherIcons(d.launcherIcons)
 		}})
 
 	return container.NewBorder(nil, applyButton, nil, nil,
-		widget.NewCard("Icons", "", container.NewVBox(bar, details)))
+		container.NewVBox(widget.NewCard("Icons", "", container.NewVBox(bar, details)), screens))
 }
 
 func (d *settingsUI) loadAdvancedScreen() fyne.CanvasObject {
@@ -261,11 +445,29 @@ func (d *settingsUI) loadAdvancedScreen() fyne.CanvasObject {
 		check.SetChecked(enabled)
 		modules = append(modules, check)
 	}
+	include := widget.NewEntry()
+	include.SetText(fyne.CurrentApp().Preferences().StringWithFallback(launcher.IncludePreference,
+		launcher.DefaultInclude))
+	exclude := widget.NewEntry()
+	exclude.SetText(fyne.CurrentApp().Preferences().StringWithFallback(launcher.ExcludePreference,
+		launcher.DefaultExclude))
+	fileSearch := widget.NewForm(widget.NewFormItem("Include", include), widget.NewFormItem("Exclude", exclude))
+	keywords := widget.NewMultiLineEntry()
+	keywords.SetMinRowsVisible(4)
+	keywords.SetText(fyne.CurrentApp().Preferences().StringWithFallback(launcher.KeywordsPreference,
+		launcher.DefaultKeywords))
 	content := container.NewHBox(d.loadScreensGroup(),
-		widget.NewCard("Modules", "", container.NewVBox(modules...)))
+		container.NewVBox(widget.NewCard("Modules", "", container.NewVBox(modules...)),
+			widget.NewCard("File Search", "Comma separated patterns in your home folder", fileSearch),
+			widget.NewCard("Search Keywords", "A keyword then a URL or command per line, %s is the search",
+				keywords)))
 
 	applyButton := container.NewHBox(layout.NewSpacer(),
 		&widget.Button{Text: "Apply", Importance: widget.HighImportance, OnTapped: func() {
+			fyne.CurrentApp().Preferences().SetString(launcher.IncludePreference, include.Text)
+			fyne.CurrentApp().Preferences().SetString(launcher.ExcludePreference, exclude.Text)
+			fyne.CurrentApp().Preferences().SetString(launcher.KeywordsPreference, keywords.Text)
+
 			var names []string
 			for _, item := range modules {
 				check := item.(*widget.Check)
@@ -280,6 +482,69 @@ func (d *settingsUI) loadAdvancedScreen() fyne.CanvasObject {
 	return container.NewBorder(nil, applyButton, nil, nil, content)
 }
 
+func (d *settingsUI) loadDefaultAppsScreen() fyne.CanvasObject {
+	provider := fynedesk.Instance().IconProvider()
+	setter, canSet := provider.(mimeDefaultSetter)
+
+	form := widget.NewForm()
+	var apply []func()
+	for _, appType := range defaultAppTypes {
+		mimeTypes := appType.mimeTypes
+		apps := make(map[string]fynedesk.AppData)
+		var names []string
+		for _, app := range provider.AppsForMime(mimeTypes[0]) {
+			if _, found := apps[app.Name()]; !found {
+				apps[app.Name()] = app
+				names = append(names, app.Name())
+			}
+		}
+
+		choice := widget.NewSelect(names, nil)
+		choice.PlaceHolder = "None installed"
+		if app := provider.DefaultAppForMime(mimeTypes[0]); app != nil {
+			choice.SetSelected(app.Name())
+		}
+		if !canSet || len(names) == 0 {
+			choice.Disable()
+		}
+		form.Append(appType.name, choice)
+
+		apply = append(apply, func() {
+			app, ok := apps[choice.Selected]
+			if !canSet || !ok {
+				return
+			}
+			if err := setter.SetDefaultAppForMime(app, mimeTypes...); err != nil {
+				fyne.LogError("Failed to set default app", err)
+			}
+		})
+	}
+
+	var terminals []string
+	for _, app := range provider.AvailableApps() {
+		search, ok := app.(searchableApp)
+		if ok && containsString(app.Categories(), "TerminalEmulator") && !containsString(terminals, search.Executable()) {
+			terminals = append(terminals, search.Executable())
+		}
+	}
+	sort.Strings(terminals)
+	terminal := widget.NewSelectEntry(terminals)
+	terminal.SetPlaceHolder("Automatic")
+	terminal.SetText(fyne.CurrentApp().Preferences().String("terminal"))
+	form.Append("Terminal", terminal)
+
+	applyButton := container.NewHBox(layout.NewSpacer(),
+		&widget.Button{Text: "Apply", Importance: widget.HighImportance, OnTapped: func() {
+			fyne.CurrentApp().Preferences().SetString("terminal", terminal.Text)
+			for _, f := range apply {
+				f()
+			}
+		}})
+
+	return container.NewBorder(nil, applyButton, nil, nil,
+		widget.NewCard("Default Apps", "Used to open web links, email, folders and terminal apps", form))
+}
+
 func (d *settingsUI) loadKeyboardScreen() fyne.CanvasObject {
 	var names, mods, keys []fyne.CanvasObject
 	shortcuts := fynedesk.Instance().(wm.ShortcutManager).Shortcuts()
@@ -405,6 +670,7 @@ func (w *widgetPanel) showSettings() {
 		&container.TabItem{Text: "Appearance", Icon: fyneSettings.AppearanceIcon(),
 			Content: ui.loadAppearanceScreen()},
 		&container.TabItem{Text: "App Bar", Icon: wmtheme.IconifyIcon, Content: ui.loadBarScreen()},
+		&container.TabItem{Text: "Default Apps", Icon: theme.ComputerIcon(), Content: ui.loadDefaultAppsScreen()},
 		&container.TabItem{Text: "Keyboard", Icon: wmtheme.KeyboardIcon, Content: ui.loadKeyboardScreen()},
 		&container.TabItem{Text: "Advanced", Icon: theme.SettingsIcon(),
 			Content: ui.loadAdvancedScreen()},
@@ -470,3 +736,17 @@ func getPicturesDir() (fyne.ListableURI, error) {
 
 	return storage.ListerForURI(uri)
 }
+
+func colorHex(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func containsString(list []string, item string) bool {
+	for _, each := range list {
+		if each == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+const (
+	usageFile = "launcher-usage.json"
+
+	// usageHalfLife is the time after which a launch counts half as much towards ranking an app.
+	usageHalfLife = 7 * 24 * time.Hour
+	// usageBoost is the most that frequent, recent use can add to the match score of an app.
+	usageBoost = 0.5
+	// usageSaturation is the decayed launch count at which an app gets half of the usage boost.
+	usageSaturation = 5.0
+)
+
+// appUsage records how often and how recently an app was launched.
+type appUsage struct {
+	Launches float64   `json:"launches"` // the launch count, decayed to the time of the last launch
+	Last     time.Time `json:"last"`
+}
+
+// launchUsage remembers the apps that are launched so that the launcher can rank them first.
+// Each launch counts less as it gets older, so apps used often and recently are ranked highest.
+type launchUsage struct {
+	lock sync.Mutex
+	apps map[string]*appUsage
+
+	saveLock sync.Mutex
+}
+
+// boost returns the amount that the usage of an app should add to its score in the launcher.
+func (u *launchUsage) boost(name string, now time.Time) float64 {
+	if u == nil {
+		return 0
+	}
+
+	launches := u.frecency(name, now)
+	return usageBoost * launches / (launches + usageSaturation)
+}
+
+// frecency returns the number of launches of an app, with older launches counting for less.
+func (u *launchUsage) frecency(name string, now time.Time) float64 {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	used, ok := u.apps[name]
+	if !ok {
+		return 0
+	}
+	return used.Launches * decay(now.Sub(used.Last))
+}
+
+func (u *launchUsage) load(r io.Reader) error {
+	apps := make(map[string]*appUsage)
+	if err := json.NewDecoder(r).Decode(&apps); err != nil {
+		return err
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.apps = apps
+	return nil
+}
+
+// record counts a launch of an app at the time passed.
+func (u *launchUsage) record(name string, now time.Time) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.apps == nil {
+		u.apps = make(map[string]*appUsage)
+	}
+	used, ok := u.apps[name]
+	if !ok {
+		u.apps[name] = &appUsage{Launches: 1, Last: now}
+		return
+	}
+
+	used.Launches = used.Launches*decay(now.Sub(used.Last)) + 1
+	used.Last = now
+}
+
+func (u *launchUsage) save(w io.Writer) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	return json.NewEncoder(w).Encode(u.apps)
+}
+
+// decay returns the weight of a launch that happened the duration passed ago.
+func decay(age time.Duration) float64 {
+	if age < 0 {
+		return 1
+	}
+
+	return math.Pow(0.5, float64(age)/float64(usageHalfLife))
+}
+
+// loadLaunchUsage reads the launch history from the app storage.
+func loadLaunchUsage() *launchUsage {
+	usage := &launchUsage{}
+	r, err := fyne.CurrentApp().Storage().Open(usageFile)
+	if err != nil {
+		return usage // no history yet
+	}
+	defer r.Close()
+
+	if err = usage.load(r); err != nil {
+		fyne.LogError("Failed to read launcher history", err)
+	}
+	return usage
+}
+
+// persist writes the launch history to the app storage.
+func (u *launchUsage) persist() {
+	u.saveLock.Lock()
+	defer u.saveLock.Unlock()
+
+	store := fyne.CurrentApp().Storage()
+	w, err := store.Save(usageFile)
+	if errors.Is(err, storage.ErrNotExists) {
+		w, err = store.Create(usageFile)
+	}
+	if err != nil {
+		fyne.LogError("Failed to save launcher history", err)
+		return
+	}
+	defer w.Close()
+
+	if err = u.save(w); err != nil {
+		fyne.LogError("Failed to save launcher history", err)
+	}
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaunchUsage_Frecency(t *testing.T) {
+	usage := &launchUsage{}
+	now := time.Now()
+	assert.Zero(t, usage.frecency("Terminal", now))
+	assert.Zero(t, usage.boost("Terminal", now))
+
+	usage.record("Terminal", now.Add(-usageHalfLife))
+	assert.InDelta(t, 0.5, usage.frecency("Terminal", now), 0.001)
+	usage.record("Terminal", now)
+	assert.InDelta(t, 1.5, usage.frecency("Terminal", now), 0.001)
+	assert.InDelta(t, 0.75, usage.frecency("Terminal", now.Add(usageHalfLife)), 0.001)
+
+	for i := 0; i < 20; i++ {
+		usage.record("Files", now)
+	}
+	assert.Greater(t, usage.boost("Files", now), usage.boost("Terminal", now))
+	assert.Less(t, usage.boost("Files", now), usageBoost)
+
+	var missing *launchUsage
+	assert.Zero(t, missing.boost("Files", now))
+}
+
+func TestLaunchUsage_SaveLoad(t *testing.T) {
+	usage := &launchUsage{}
+	now := time.Now()
+	usage.record("Terminal", now)
+	usage.record("Terminal", now)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, usage.save(buf))
+	loaded := &launchUsage{}
+	assert.Nil(t, loaded.load(buf))
+	assert.InDelta(t, 2, loaded.frecency("Terminal", now), 0.001)
+}
@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // support JPEG wallpapers
+	_ "image/png"  // support PNG wallpapers
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"github.com/FyshOS/backgrounds/builtin"
+	"github.com/nfnt/resize"
+
+	"fyshos.com/fynedesk"
+)
+
+const (
+	wallpaperCentre  = "Centre"
+	wallpaperFill    = "Fill"
+	wallpaperFit     = "Fit"
+	wallpaperSpan    = "Span"
+	wallpaperStretch = "Stretch"
+	wallpaperTile    = "Tile"
+)
+
+var wallpaperModes = []string{wallpaperStretch, wallpaperFill, wallpaperFit, wallpaperCentre, wallpaperTile, wallpaperSpan}
+
+// Wallpaper returns a canvas object that draws the configured wallpaper for a screen on the current desktop.
+// The window manager uses this to paint the root window so that pseudo-transparent apps match the desktop.
+func Wallpaper(screen *fynedesk.Screen) fyne.CanvasObject {
+	desk := fynedesk.Instance()
+	w, h := desk.RootSizePixels()
+	return newWallpaper(desk.Settings(), screen, desk.Desktop(), image.Rect(0, 0, int(w), int(h)))
+}
+
+func builtinWallpaper() fyne.CanvasObject {
+	set := fyne.CurrentApp().Settings()
+	src := &builtin.Builtin{}
+	return src.Load(set.Theme(), set.ThemeVariant())
+}
+
+// fillImage scales an image so that it covers the whole of the requested size, cropping the overflow.
+func fillImage(src image.Image, size image.Point) *image.NRGBA {
+	b := src.Bounds()
+	scale := math.Max(float64(size.X)/float64(b.Dx()), float64(size.Y)/float64(b.Dy()))
+	scaled := resize.Resize(uint(math.Ceil(float64(b.Dx())*scale)), uint(math.Ceil(float64(b.Dy())*scale)),
+		src, resize.Lanczos3)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+	sb := scaled.Bounds()
+	offset := image.Pt((sb.Dx()-size.X)/2, (sb.Dy()-size.Y)/2)
+	draw.Draw(dst, dst.Bounds(), scaled, sb.Min.Add(offset), draw.Src)
+	return dst
+}
+
+func isWallpaperFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".svg":
+		return true
+	}
+
+	return false
+}
+
+func loadWallpaperImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+func newWallpaper(s fynedesk.DeskSettings, screen *fynedesk.Screen, desk int, root image.Rectangle) fyne.CanvasObject {
+	spec := s.BackgroundFor(screen.Name, desk)
+	if start, end, ok := wallpaperColors(spec); ok {
+		if end == nil {
+			return canvas.NewRectangle(start)
+		}
+		return canvas.NewLinearGradient(start, end, 0)
+	}
+
+	path := wallpaperSource(spec, time.Now(), s.BackgroundSlideshowInterval())
+	if path == "" {
+		return builtinWallpaper()
+	}
+
+	mode := s.BackgroundMode()
+	if mode == wallpaperFit || mode == wallpaperStretch || mode == "" || filepath.Ext(path) == ".svg" {
+		bg := canvas.NewImageFromFile(path)
+		bg.ScaleMode = canvas.ImageScaleFastest
+		if mode == wallpaperFit {
+			bg.FillMode = canvas.ImageFillContain
+		}
+		return bg
+	}
+
+	src, err := loadWallpaperImage(path)
+	if err != nil {
+		fyne.LogError("Failed to read background image", err)
+		return builtinWallpaper()
+	}
+	area := image.Rect(screen.X, screen.Y, screen.X+screen.Width, screen.Y+screen.Height)
+	bg := canvas.NewImageFromImage(renderWallpaper(src, mode, area, root))
+	bg.ScaleMode = canvas.ImageScaleFastest
+	return bg
+}
+
+func parseHexColor(hex string) (color.Color, bool) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return nil, false
+	}
+	val, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+
+	return color.NRGBA{R: uint8(val >> 16), G: uint8(val >> 8), B: uint8(val), A: 0xff}, true
+}
+
+// renderWallpaper draws an image for a screen using the given scaling mode.
+// The screen and root areas are in pixels, the root area is needed to span an image across all screens.
+func renderWallpaper(src image.Image, mode string, screen, root image.Rectangle) image.Image {
+	size := screen.Size()
+	b := src.Bounds()
+
+	switch mode {
+	case wallpaperCentre:
+		dst := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+		offset := image.Pt((size.X-b.Dx())/2, (size.Y-b.Dy())/2)
+		draw.Draw(dst, b.Sub(b.Min).Add(offset), src, b.Min, draw.Src)
+		return dst
+	case wallpaperTile:
+		dst := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+		for y := 0; y < size.Y; y += b.Dy() {
+			for x := 0; x < size.X; x += b.Dx() {
+				draw.Draw(dst, image.Rect(x, y, x+b.Dx(), y+b.Dy()), src, b.Min, draw.Src)
+			}
+		}
+		return dst
+	case wallpaperSpan:
+		whole := fillImage(src, root.Size())
+		return whole.SubImage(screen.Sub(root.Min))
+	default:
+		return fillImage(src, size)
+	}
+}
+
+// nextSlideshowChange returns the time at which a slideshow should next move to a new image.
+func nextSlideshowChange(now time.Time, interval time.Duration) time.Time {
+	return time.Unix((slideshowSlot(now, interval)+1)*slideshowSeconds(interval), 0)
+}
+
+func slideshowSeconds(interval time.Duration) int64 {
+	if interval < time.Minute {
+		return 60
+	}
+
+	return int64(interval.Seconds())
+}
+
+// slideshowSlot returns the number of whole intervals since the epoch, used to pick the current slideshow image.
+func slideshowSlot(now time.Time, interval time.Duration) int64 {
+	return now.Unix() / slideshowSeconds(interval)
+}
+
+// wallpaperColors parses a solid colour ("#rrggbb") or vertical gradient ("#rrggbb,#rrggbb") background.
+// If the background is a single colour then the end colour will be nil.
+func wallpaperColors(spec string) (start, end color.Color, ok bool) {
+	if !strings.HasPrefix(spec, "#") {
+		return nil, nil, false
+	}
+
+	from, to, gradient := strings.Cut(spec, ",")
+	start, ok = parseHexColor(from)
+	if !ok || !gradient {
+		return start, nil, ok
+	}
+
+	end, ok = parseHexColor(to)
+	return start, end, ok
+}
+
+// wallpaperSource returns the image file that should be shown for a background setting.
+// If the setting is a directory then each image within it is shown in turn, changing after the interval passed.
+func wallpaperSource(spec string, now time.Time, interval time.Duration) string {
+	if spec == "" {
+		return ""
+	}
+	stat, err := os.Stat(spec)
+	if err != nil {
+		return ""
+	}
+	if stat.Mode().IsRegular() {
+		return spec
+	}
+	if !stat.IsDir() {
+		return ""
+	}
+
+	files, err := os.ReadDir(spec)
+	if err != nil {
+		fyne.LogError("Failed to read slideshow directory", err)
+		return ""
+	}
+	var images []string
+	for _, file := range files {
+		if !file.IsDir() && isWallpaperFile(file.Name()) {
+			images = append(images, file.Name())
+		}
+	}
+	if len(images) == 0 {
+		return ""
+	}
+
+	return filepath.Join(spec, images[slideshowSlot(now, interval)%int64(len(images))])
+}
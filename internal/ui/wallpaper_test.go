@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderWallpaper(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	src.Set(0, 0, color.White)
+	screen := image.Rect(0, 0, 40, 40)
+
+	for _, mode := range []string{wallpaperFill, wallpaperCentre, wallpaperTile} {
+		img := renderWallpaper(src, mode, screen, screen)
+		assert.Equal(t, screen.Size(), img.Bounds().Size(), mode)
+	}
+
+	tiled := renderWallpaper(src, wallpaperTile, screen, screen)
+	assert.Equal(t, color.NRGBAModel.Convert(color.White), tiled.At(20, 10))
+	centred := renderWallpaper(src, wallpaperCentre, screen, screen)
+	assert.Equal(t, color.NRGBAModel.Convert(color.White), centred.At(10, 15))
+
+	second := image.Rect(40, 0, 80, 40)
+	spanned := renderWallpaper(src, wallpaperSpan, second, image.Rect(0, 0, 80, 40))
+	assert.Equal(t, second, spanned.Bounds())
+}
+
+func TestWallpaperColors(t *testing.T) {
+	start, end, ok := wallpaperColors("#ff0000")
+	assert.True(t, ok)
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, start)
+	assert.Nil(t, end)
+
+	start, end, ok = wallpaperColors("#000000,#0000ff")
+	assert.True(t, ok)
+	assert.Equal(t, color.NRGBA{A: 0xff}, start)
+	assert.Equal(t, color.NRGBA{B: 0xff, A: 0xff}, end)
+
+	_, _, ok = wallpaperColors("/home/user/wallpaper.png")
+	assert.False(t, ok)
+	_, _, ok = wallpaperColors("#zzzzzz")
+	assert.False(t, ok)
+}
+
+func TestWallpaperSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "b.jpg", "notes.txt"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0600))
+	}
+
+	file := filepath.Join(dir, "a.png")
+	assert.Equal(t, file, wallpaperSource(file, time.Now(), time.Minute))
+	assert.Equal(t, "", wallpaperSource(filepath.Join(dir, "missing.png"), time.Now(), time.Minute))
+
+	start := time.Unix(0, 0)
+	assert.Equal(t, file, wallpaperSource(dir, start, time.Minute))
+	assert.Equal(t, filepath.Join(dir, "b.jpg"), wallpaperSource(dir, start.Add(time.Minute), time.Minute))
+	assert.Equal(t, file, wallpaperSource(dir, start.Add(2*time.Minute), time.Minute))
+	assert.Equal(t, start.Add(time.Minute), nextSlideshowChange(start.Add(time.Second), time.Minute))
+}
@@ -0,0 +1,486 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+// Package clipboard provides a clipboard manager for X11.
+// It copies the CLIPBOARD selection each time it changes and takes ownership when the owning app exits,
+// so that copied content is not lost.
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xfixes"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	// MimeText is the type of plain text content, which is always UTF-8.
+	MimeText = "text/plain"
+	// MimePNG is the type of image content.
+	MimePNG = "image/png"
+)
+
+// Manager watches the CLIPBOARD selection and can serve content on behalf of apps.
+type Manager struct {
+	conn  *xgb.Conn
+	root  xproto.Window
+	win   xproto.Window
+	chunk int
+
+	atomClipboard, atomTargets, atomIncr, atomProperty   xproto.Atom
+	atomUTF8, atomText, atomPlain, atomPNG, atomPassword xproto.Atom
+	atomClass, atomActive                                xproto.Atom
+
+	lock     sync.Mutex
+	changed  func(mime string, data []byte, class string) bool
+	mime     string // the content that we will serve if the owner exits
+	data     []byte
+	owned    bool
+	owner    xproto.Window
+	incoming *transfer
+	outgoing map[xproto.Window]*transfer
+}
+
+// transfer is a selection being sent or received in chunks with the INCR protocol.
+type transfer struct {
+	win      xproto.Window
+	property xproto.Atom
+	target   xproto.Atom
+	data     []byte
+	mime     string
+}
+
+// New connects to the X server and creates the window used to exchange selections.
+// Content that is copied by apps is only kept once Watch has been called.
+func New() (*Manager, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	if err = xfixes.Init(conn); err != nil {
+		conn.Close()
+		return nil, errors.New("xfixes extension is not available")
+	}
+	if _, err = xfixes.QueryVersion(conn, 5, 0).Reply(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	setup := xproto.Setup(conn)
+	m := &Manager{conn: conn, root: setup.DefaultScreen(conn).Root,
+		chunk: int(setup.MaximumRequestLength)*4 - 64, outgoing: make(map[xproto.Window]*transfer)}
+	m.atomClipboard = m.atom("CLIPBOARD")
+	m.atomTargets = m.atom("TARGETS")
+	m.atomIncr = m.atom("INCR")
+	m.atomProperty = m.atom("FYNEDESK_CLIPBOARD")
+	m.atomUTF8 = m.atom("UTF8_STRING")
+	m.atomText = m.atom("TEXT")
+	m.atomPlain = m.atom("text/plain;charset=utf-8")
+	m.atomPNG = m.atom(MimePNG)
+	m.atomPassword = m.atom("x-kde-passwordManagerHint")
+	m.atomClass = m.atom("WM_CLASS")
+	m.atomActive = m.atom("_NET_ACTIVE_WINDOW")
+
+	m.win, err = xproto.NewWindowId(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	xproto.CreateWindow(conn, 0, m.win, m.root, -1, -1, 1, 1, 0, xproto.WindowClassInputOnly, 0,
+		xproto.CwOverrideRedirect|xproto.CwEventMask, []uint32{1, xproto.EventMaskPropertyChange})
+	name := "FyneDesk Clipboard"
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, m.win, xproto.AtomWmName, xproto.AtomString,
+		8, uint32(len(name)), []byte(name))
+
+	mask := uint32(xfixes.SelectionEventMaskSetSelectionOwner | xfixes.SelectionEventMaskSelectionWindowDestroy |
+		xfixes.SelectionEventMaskSelectionClientClose)
+	if err = xfixes.SelectSelectionInputChecked(conn, m.win, m.atomClipboard, mask).Check(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go m.run()
+	return m, nil
+}
+
+// Set makes the content passed the current clipboard, serving it to apps that paste.
+func (m *Manager) Set(mime string, data []byte) error {
+	m.lock.Lock()
+	m.mime, m.data = mime, data
+	m.lock.Unlock()
+
+	return m.own(xproto.TimeCurrentTime)
+}
+
+// Watch asks for each new clipboard item to be passed to the changed function with the window class of the app
+// that copied it. If the function returns true the item is kept, to be served if the app exits.
+// Passing nil stops watching, so that new items are left with the app that copied them.
+func (m *Manager) Watch(changed func(mime string, data []byte, class string) bool) {
+	m.lock.Lock()
+	m.changed = changed
+	m.lock.Unlock()
+}
+
+// Stop releases the clipboard and disconnects from the X server.
+func (m *Manager) Stop() {
+	xproto.DestroyWindow(m.conn, m.win)
+	m.conn.Sync()
+	m.conn.Close()
+}
+
+func (m *Manager) atom(name string) xproto.Atom {
+	reply, err := xproto.InternAtom(m.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		fyne.LogError("Failed to look up atom "+name, err)
+		return xproto.AtomNone
+	}
+
+	return reply.Atom
+}
+
+// chooseTarget picks the best format offered by an app, returning false if the content should not be copied.
+func (m *Manager) chooseTarget(targets []xproto.Atom) (xproto.Atom, bool) {
+	best := xproto.Atom(xproto.AtomNone)
+	for _, t := range targets {
+		switch t {
+		case m.atomPassword:
+			return xproto.AtomNone, false
+		case m.atomUTF8:
+			best = t
+		case m.atomPNG:
+			if best != m.atomUTF8 {
+				best = t
+			}
+		case xproto.AtomString:
+			if best == xproto.AtomNone {
+				best = t
+			}
+		}
+	}
+
+	return best, best != xproto.AtomNone
+}
+
+func (m *Manager) handleEvent(ev xgb.Event) {
+	switch ev := ev.(type) {
+	case xfixes.SelectionNotifyEvent:
+		m.handleOwnerChange(ev)
+	case xproto.SelectionNotifyEvent:
+		m.handleConverted(ev)
+	case xproto.SelectionRequestEvent:
+		m.handleRequest(ev)
+	case xproto.SelectionClearEvent:
+		m.lock.Lock()
+		m.owned = false
+		m.lock.Unlock()
+	case xproto.PropertyNotifyEvent:
+		if ev.State == xproto.PropertyNewValue && ev.Window == m.win {
+			m.readChunk(ev.Atom)
+		} else if ev.State == xproto.PropertyDelete {
+			m.sendChunk(ev.Window, ev.Atom)
+		}
+	}
+}
+
+func (m *Manager) handleConverted(ev xproto.SelectionNotifyEvent) {
+	if ev.Requestor != m.win || ev.Selection != m.atomClipboard || ev.Property == xproto.AtomNone {
+		return
+	}
+
+	if ev.Target == m.atomTargets {
+		reply, err := m.readProperty(ev.Property)
+		if err != nil {
+			fyne.LogError("Failed to read clipboard formats", err)
+			return
+		}
+		var targets []xproto.Atom
+		for i := 0; i+4 <= len(reply.Value); i += 4 {
+			targets = append(targets, xproto.Atom(xgb.Get32(reply.Value[i:])))
+		}
+		if target, ok := m.chooseTarget(targets); ok {
+			xproto.ConvertSelection(m.conn, m.win, m.atomClipboard, target, m.atomProperty, ev.Time)
+		}
+		return
+	}
+
+	reply, err := m.readProperty(ev.Property)
+	if err != nil {
+		fyne.LogError("Failed to read clipboard content", err)
+		return
+	}
+	mime := MimeText
+	if ev.Target == m.atomPNG {
+		mime = MimePNG
+	}
+	if reply.Type == m.atomIncr {
+		m.lock.Lock()
+		m.incoming = &transfer{win: m.win, property: ev.Property, target: ev.Target, mime: mime}
+		m.lock.Unlock()
+		return
+	}
+
+	data := reply.Value
+	if ev.Target == xproto.AtomString {
+		data = latin1ToUTF8(data)
+	}
+	m.received(mime, data)
+}
+
+// handleOwnerChange copies new clipboard content and takes over the clipboard if the owner goes away.
+func (m *Manager) handleOwnerChange(ev xfixes.SelectionNotifyEvent) {
+	switch ev.Subtype {
+	case xfixes.SelectionEventSetSelectionOwner:
+		if ev.Owner == m.win || ev.Owner == xproto.WindowNone {
+			return
+		}
+
+		m.lock.Lock()
+		m.owner = ev.Owner
+		m.incoming = nil
+		m.mime, m.data = "", nil // our copy is out of date until the new content is received
+		watching := m.changed != nil
+		m.lock.Unlock()
+		if !watching {
+			return
+		}
+		xproto.ConvertSelection(m.conn, m.win, m.atomClipboard, m.atomTargets, m.atomProperty, ev.Timestamp)
+	case xfixes.SelectionEventSelectionWindowDestroy, xfixes.SelectionEventSelectionClientClose:
+		m.lock.Lock()
+		empty := m.data == nil
+		m.lock.Unlock()
+		if empty {
+			return
+		}
+
+		if err := m.own(ev.Timestamp); err != nil {
+			fyne.LogError("Failed to take over the clipboard", err)
+		}
+	}
+}
+
+func (m *Manager) handleRequest(ev xproto.SelectionRequestEvent) {
+	property := ev.Property
+	if property == xproto.AtomNone {
+		property = ev.Target // obsolete clients expect the target to be used
+	}
+
+	m.lock.Lock()
+	mime, data, owned := m.mime, m.data, m.owned
+	m.lock.Unlock()
+	if !owned || ev.Selection != m.atomClipboard || !m.reply(ev.Requestor, property, ev.Target, mime, data) {
+		property = xproto.AtomNone
+	}
+
+	notify := xproto.SelectionNotifyEvent{Time: ev.Time, Requestor: ev.Requestor, Selection: ev.Selection,
+		Target: ev.Target, Property: property}
+	xproto.SendEvent(m.conn, false, ev.Requestor, xproto.EventMaskNoEvent, string(notify.Bytes()))
+}
+
+func (m *Manager) own(time xproto.Timestamp) error {
+	err := xproto.SetSelectionOwnerChecked(m.conn, m.win, m.atomClipboard, time).Check()
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	m.owned = true
+	m.lock.Unlock()
+	return nil
+}
+
+// readChunk appends the next part of a selection sent with the INCR protocol.
+func (m *Manager) readChunk(property xproto.Atom) {
+	m.lock.Lock()
+	in := m.incoming
+	m.lock.Unlock()
+	if in == nil || in.property != property {
+		return
+	}
+
+	reply, err := m.readProperty(property)
+	if err != nil {
+		fyne.LogError("Failed to read clipboard content", err)
+		return
+	}
+	if len(reply.Value) > 0 {
+		in.data = append(in.data, reply.Value...)
+		return
+	}
+
+	m.lock.Lock()
+	m.incoming = nil
+	m.lock.Unlock()
+	if in.target == xproto.AtomString {
+		in.data = latin1ToUTF8(in.data)
+	}
+	m.received(in.mime, in.data)
+}
+
+// readProperty reads and deletes a property of our window, in parts if required.
+func (m *Manager) readProperty(property xproto.Atom) (*xproto.GetPropertyReply, error) {
+	var data []byte
+	offset := uint32(0)
+	for {
+		reply, err := xproto.GetProperty(m.conn, false, m.win, property, xproto.GetPropertyTypeAny,
+			offset, uint32(m.chunk/4)).Reply()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, reply.Value...)
+		offset += uint32(len(reply.Value) / 4)
+		if reply.BytesAfter == 0 {
+			reply.Value = data
+			xproto.DeleteProperty(m.conn, m.win, property)
+			return reply, nil
+		}
+	}
+}
+
+func (m *Manager) received(mime string, data []byte) {
+	m.lock.Lock()
+	owner, changed := m.owner, m.changed
+	m.lock.Unlock()
+	if len(data) == 0 || changed == nil || !changed(mime, data, m.windowClass(owner)) {
+		return
+	}
+
+	m.lock.Lock()
+	m.mime, m.data = mime, data
+	m.lock.Unlock()
+}
+
+// reply stores the content requested on the requesting window, returning false if the target is not supported.
+func (m *Manager) reply(win xproto.Window, property, target xproto.Atom, mime string, data []byte) bool {
+	if target == m.atomTargets {
+		targets := []xproto.Atom{m.atomTargets}
+		if mime == MimePNG {
+			targets = append(targets, m.atomPNG)
+		} else {
+			targets = append(targets, m.atomUTF8, xproto.AtomString, m.atomText, m.atomPlain)
+		}
+		buf := make([]byte, len(targets)*4)
+		for i, t := range targets {
+			xgb.Put32(buf[i*4:], uint32(t))
+		}
+		xproto.ChangeProperty(m.conn, xproto.PropModeReplace, win, property, xproto.AtomAtom, 32,
+			uint32(len(targets)), buf)
+		return true
+	}
+
+	switch target {
+	case m.atomUTF8, xproto.AtomString, m.atomText, m.atomPlain:
+		if mime != MimeText {
+			return false
+		}
+	case m.atomPNG:
+		if mime != MimePNG {
+			return false
+		}
+	default:
+		return false
+	}
+	if target == m.atomText {
+		target = m.atomUTF8
+	}
+
+	if len(data) <= m.chunk {
+		xproto.ChangeProperty(m.conn, xproto.PropModeReplace, win, property, target, 8, uint32(len(data)), data)
+		return true
+	}
+
+	// too large for one request, so send it in parts as the requestor deletes each one
+	m.lock.Lock()
+	m.outgoing[win] = &transfer{win: win, property: property, target: target, data: data}
+	m.lock.Unlock()
+	xproto.ChangeWindowAttributes(m.conn, win, xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange})
+	size := make([]byte, 4)
+	xgb.Put32(size, uint32(len(data)))
+	xproto.ChangeProperty(m.conn, xproto.PropModeReplace, win, property, m.atomIncr, 32, 1, size)
+	return true
+}
+
+func (m *Manager) run() {
+	for {
+		ev, err := m.conn.WaitForEvent()
+		if ev == nil && err == nil {
+			return
+		}
+		if err != nil {
+			continue // errors for windows that have gone away are expected
+		}
+
+		m.handleEvent(ev)
+	}
+}
+
+// sendChunk continues sending a selection with the INCR protocol once the requestor has read the last part.
+func (m *Manager) sendChunk(win xproto.Window, property xproto.Atom) {
+	m.lock.Lock()
+	out := m.outgoing[win]
+	if out == nil || out.property != property {
+		m.lock.Unlock()
+		return
+	}
+	size := len(out.data)
+	if size > m.chunk {
+		size = m.chunk
+	}
+	data := out.data[:size]
+	out.data = out.data[size:]
+	if size == 0 {
+		delete(m.outgoing, win)
+	}
+	m.lock.Unlock()
+
+	if size == 0 {
+		xproto.ChangeWindowAttributes(m.conn, win, xproto.CwEventMask, []uint32{xproto.EventMaskNoEvent})
+	}
+	xproto.ChangeProperty(m.conn, xproto.PropModeReplace, win, property, out.target, 8, uint32(size), data)
+}
+
+// windowClass returns the class of the app that owns a window, or of the active window if that is not set.
+func (m *Manager) windowClass(win xproto.Window) string {
+	for win != xproto.WindowNone && win != m.root {
+		reply, err := xproto.GetProperty(m.conn, false, win, m.atomClass, xproto.AtomString, 0, 64).Reply()
+		if err == nil && len(reply.Value) > 0 {
+			return parseClass(reply.Value)
+		}
+
+		tree, err := xproto.QueryTree(m.conn, win).Reply()
+		if err != nil {
+			break
+		}
+		win = tree.Parent
+	}
+
+	reply, err := xproto.GetProperty(m.conn, false, m.root, m.atomActive, xproto.AtomWindow, 0, 1).Reply()
+	if err != nil || len(reply.Value) < 4 {
+		return ""
+	}
+	active := xproto.Window(xgb.Get32(reply.Value))
+	reply, err = xproto.GetProperty(m.conn, false, active, m.atomClass, xproto.AtomString, 0, 64).Reply()
+	if err != nil {
+		return ""
+	}
+	return parseClass(reply.Value)
+}
+
+// parseClass returns the class from a WM_CLASS value, which holds the instance and class names.
+func parseClass(value []byte) string {
+	parts := bytes.Split(bytes.TrimRight(value, "\x00"), []byte{0})
+	return string(parts[len(parts)-1])
+}
+
+// latin1ToUTF8 converts the encoding of STRING selections to the UTF-8 we store.
+func latin1ToUTF8(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return []byte(string(runes))
+}
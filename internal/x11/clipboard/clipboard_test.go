@@ -0,0 +1,39 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package clipboard
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_ChooseTarget(t *testing.T) {
+	m := &Manager{atomTargets: 1, atomUTF8: 2, atomPNG: 3, atomPassword: 4}
+
+	target, ok := m.chooseTarget([]xproto.Atom{1, xproto.AtomString, 3, 2})
+	assert.True(t, ok)
+	assert.Equal(t, m.atomUTF8, target)
+	target, ok = m.chooseTarget([]xproto.Atom{1, xproto.AtomString, 3})
+	assert.True(t, ok)
+	assert.Equal(t, m.atomPNG, target)
+	target, ok = m.chooseTarget([]xproto.Atom{xproto.AtomString})
+	assert.True(t, ok)
+	assert.Equal(t, xproto.Atom(xproto.AtomString), target)
+
+	_, ok = m.chooseTarget([]xproto.Atom{2, 4})
+	assert.False(t, ok)
+	_, ok = m.chooseTarget([]xproto.Atom{1})
+	assert.False(t, ok)
+}
+
+func TestLatin1ToUTF8(t *testing.T) {
+	assert.Equal(t, "café", string(latin1ToUTF8([]byte{'c', 'a', 'f', 0xe9})))
+}
+
+func TestParseClass(t *testing.T) {
+	assert.Equal(t, "Firefox", parseClass([]byte("Navigator\x00Firefox\x00")))
+	assert.Equal(t, "xterm", parseClass([]byte("xterm")))
+}
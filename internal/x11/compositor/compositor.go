@@ -0,0 +1,394 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+// Package compositor provides a software compositing manager for X11.
+// It uses the Composite, Damage, XFixes and Render extensions so that it works without a GPU.
+package compositor
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/composite"
+	"github.com/BurntSushi/xgb/damage"
+	"github.com/BurntSushi/xgb/render"
+	"github.com/BurntSushi/xgb/shape"
+	"github.com/BurntSushi/xgb/xfixes"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"fyne.io/fyne/v2"
+)
+
+const frameTime = time.Second / 60
+
+// Compositor draws the windows of an X11 screen to the composite overlay window.
+// Windows are painted with drop shadows, their requested opacity and fade in or out as they are shown and hidden.
+type Compositor struct {
+	conn   *xgb.Conn
+	root   xproto.Window
+	screen int
+	width  uint16
+	height uint16
+
+	overlay, selection xproto.Window
+	overlayPicture     render.Picture
+	buffer             xproto.Pixmap
+	bufferPicture      render.Picture
+	background         render.Picture
+	formats            *formats
+	alphas, shadows    map[uint8]render.Picture
+
+	atomOpacity, atomRootPixmap, atomType, atomTypeDesktop xproto.Atom
+
+	windows []*window // in stacking order, bottom first
+	dirty   xfixes.Region
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New connects to the X server and claims the compositing manager selection for the default screen.
+// An error is returned if the required extensions are missing or another compositor is running.
+func New() (*Compositor, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Compositor{conn: conn, alphas: make(map[uint8]render.Picture), shadows: make(map[uint8]render.Picture)}
+	if err = c.initExtensions(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	setup := xproto.Setup(conn)
+	c.screen = conn.DefaultScreen
+	c.root = setup.DefaultScreen(conn).Root
+	c.width = setup.DefaultScreen(conn).WidthInPixels
+	c.height = setup.DefaultScreen(conn).HeightInPixels
+	if err = c.claimSelection(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c.atomOpacity = c.atom("_NET_WM_WINDOW_OPACITY")
+	c.atomRootPixmap = c.atom("_XROOTPMAP_ID")
+	c.atomType = c.atom("_NET_WM_WINDOW_TYPE")
+	c.atomTypeDesktop = c.atom("_NET_WM_WINDOW_TYPE_DESKTOP")
+	c.formats, err = loadFormats(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Start redirects all windows off screen and begins painting them to the overlay window.
+func (c *Compositor) Start() error {
+	xproto.GrabServer(c.conn)
+	err := composite.RedirectSubwindowsChecked(c.conn, c.root, composite.RedirectManual).Check()
+	if err != nil {
+		xproto.UngrabServer(c.conn)
+		return err
+	}
+	xproto.ChangeWindowAttributes(c.conn, c.root, xproto.CwEventMask, []uint32{xproto.EventMaskSubstructureNotify |
+		xproto.EventMaskExposure | xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange})
+
+	tree, err := xproto.QueryTree(c.conn, c.root).Reply()
+	if err == nil {
+		for _, child := range tree.Children {
+			c.addWindow(child, false)
+		}
+	}
+	xproto.UngrabServer(c.conn)
+
+	if err = c.createOverlay(); err != nil {
+		composite.UnredirectSubwindows(c.conn, c.root, composite.RedirectManual)
+		return err
+	}
+	c.createBuffer()
+	c.updateBackground()
+	c.damageAll()
+
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go c.run(c.stop, c.done)
+	return nil
+}
+
+// Stop returns windows to being drawn directly by the X server and releases the compositing selection.
+func (c *Compositor) Stop() {
+	if c.stop == nil {
+		c.conn.Close() // never started
+		return
+	}
+
+	select {
+	case <-c.done: // already stopped
+	default:
+		close(c.stop)
+		<-c.done
+	}
+}
+
+func (c *Compositor) atom(name string) xproto.Atom {
+	reply, err := xproto.InternAtom(c.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		fyne.LogError("Failed to look up atom "+name, err)
+		return xproto.AtomNone
+	}
+
+	return reply.Atom
+}
+
+func (c *Compositor) claimSelection() error {
+	selection := c.atom("_NET_WM_CM_S" + strconv.Itoa(c.screen))
+	owner, err := xproto.GetSelectionOwner(c.conn, selection).Reply()
+	if err != nil {
+		return err
+	}
+	if owner.Owner != xproto.WindowNone {
+		return errors.New("another compositing manager is running")
+	}
+
+	c.selection, err = xproto.NewWindowId(c.conn)
+	if err != nil {
+		return err
+	}
+	xproto.CreateWindow(c.conn, 0, c.selection, c.root, -1, -1, 1, 1, 0,
+		xproto.WindowClassInputOnly, 0, xproto.CwOverrideRedirect, []uint32{1})
+	name := "FyneDesk Compositor"
+	xproto.ChangeProperty(c.conn, xproto.PropModeReplace, c.selection, xproto.AtomWmName, xproto.AtomString,
+		8, uint32(len(name)), []byte(name))
+	return xproto.SetSelectionOwnerChecked(c.conn, c.selection, selection, xproto.TimeCurrentTime).Check()
+}
+
+func (c *Compositor) createBuffer() {
+	if c.bufferPicture != 0 {
+		render.FreePicture(c.conn, c.bufferPicture)
+		xproto.FreePixmap(c.conn, c.buffer)
+	}
+
+	screen := xproto.Setup(c.conn).DefaultScreen(c.conn)
+	c.buffer, _ = xproto.NewPixmapId(c.conn)
+	xproto.CreatePixmap(c.conn, screen.RootDepth, c.buffer, xproto.Drawable(c.root), c.width, c.height)
+	c.bufferPicture, _ = render.NewPictureId(c.conn)
+	render.CreatePicture(c.conn, c.bufferPicture, xproto.Drawable(c.buffer), c.formats.forVisual(screen.RootVisual), 0, nil)
+}
+
+// createOverlay gets the composite overlay window and lets input pass through it to the windows below.
+func (c *Compositor) createOverlay() error {
+	reply, err := composite.GetOverlayWindow(c.conn, c.root).Reply()
+	if err != nil {
+		return err
+	}
+	c.overlay = reply.OverlayWin
+
+	empty, err := xfixes.NewRegionId(c.conn)
+	if err != nil {
+		return err
+	}
+	xfixes.CreateRegion(c.conn, empty, nil)
+	xfixes.SetWindowShapeRegion(c.conn, c.overlay, shape.SkInput, 0, 0, empty)
+	xfixes.DestroyRegion(c.conn, empty)
+
+	screen := xproto.Setup(c.conn).DefaultScreen(c.conn)
+	c.overlayPicture, err = render.NewPictureId(c.conn)
+	if err != nil {
+		return err
+	}
+	render.CreatePicture(c.conn, c.overlayPicture, xproto.Drawable(c.overlay), c.formats.forVisual(screen.RootVisual),
+		render.CpSubwindowMode, []uint32{xproto.SubwindowModeIncludeInferiors})
+	return nil
+}
+
+func (c *Compositor) findWindow(id xproto.Window) (int, *window) {
+	for i, w := range c.windows {
+		if w.id == id && !w.destroyed {
+			return i, w
+		}
+	}
+
+	return -1, nil
+}
+
+func (c *Compositor) handleEvent(ev xgb.Event) {
+	switch ev := ev.(type) {
+	case xproto.CreateNotifyEvent:
+		if ev.Parent == c.root {
+			c.addWindow(ev.Window, true)
+		}
+	case xproto.ConfigureNotifyEvent:
+		if ev.Window == c.root {
+			c.width, c.height = ev.Width, ev.Height
+			c.createBuffer()
+			c.damageAll()
+			return
+		}
+		c.configureWindow(ev)
+	case xproto.DestroyNotifyEvent:
+		c.destroyWindow(ev.Window)
+	case xproto.MapNotifyEvent:
+		c.mapWindow(ev.Window)
+	case xproto.UnmapNotifyEvent:
+		c.unmapWindow(ev.Window)
+	case xproto.ReparentNotifyEvent:
+		if ev.Parent == c.root {
+			c.addWindow(ev.Window, true)
+		} else {
+			c.removeWindow(ev.Window)
+		}
+	case xproto.CirculateNotifyEvent:
+		c.circulateWindow(ev)
+	case xproto.ExposeEvent:
+		if ev.Window == c.root || ev.Window == c.overlay {
+			c.damageArea(xproto.Rectangle{X: int16(ev.X), Y: int16(ev.Y), Width: ev.Width, Height: ev.Height})
+		}
+	case xproto.PropertyNotifyEvent:
+		c.handlePropertyChange(ev)
+	case damage.NotifyEvent:
+		c.damageWindow(ev.Damage)
+	}
+}
+
+func (c *Compositor) handlePropertyChange(ev xproto.PropertyNotifyEvent) {
+	if ev.Window == c.root && ev.Atom == c.atomRootPixmap {
+		c.updateBackground()
+		c.damageAll()
+		return
+	}
+
+	if ev.Atom != c.atomOpacity {
+		return
+	}
+	if _, w := c.findWindow(ev.Window); w != nil {
+		w.opacity = c.windowOpacity(w.id)
+		c.damageArea(w.extents())
+	}
+}
+
+func (c *Compositor) initExtensions() error {
+	if err := composite.Init(c.conn); err != nil {
+		return errors.New("composite extension is not available")
+	}
+	if err := damage.Init(c.conn); err != nil {
+		return errors.New("damage extension is not available")
+	}
+	if err := xfixes.Init(c.conn); err != nil {
+		return errors.New("xfixes extension is not available")
+	}
+	if err := render.Init(c.conn); err != nil {
+		return errors.New("render extension is not available")
+	}
+
+	// versions must be negotiated before extension requests are used
+	if _, err := composite.QueryVersion(c.conn, 0, 4).Reply(); err != nil {
+		return err
+	}
+	if _, err := damage.QueryVersion(c.conn, 1, 1).Reply(); err != nil {
+		return err
+	}
+	if _, err := xfixes.QueryVersion(c.conn, 5, 0).Reply(); err != nil {
+		return err
+	}
+	_, err := render.QueryVersion(c.conn, 0, 11).Reply()
+	return err
+}
+
+func (c *Compositor) run(stop, done chan struct{}) {
+	defer close(done)
+	events := make(chan xgb.Event)
+	go func() {
+		for {
+			ev, err := c.conn.WaitForEvent()
+			if ev == nil && err == nil {
+				close(events)
+				return
+			}
+			if err != nil {
+				continue // errors for windows that have gone away are expected
+			}
+
+			select {
+			case events <- ev:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(frameTime)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			c.shutdown()
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			c.handleEvent(ev)
+		case <-ticker.C:
+			c.paint()
+		}
+	}
+}
+
+func (c *Compositor) shutdown() {
+	for _, w := range c.windows {
+		c.freeWindow(w)
+	}
+	c.windows = nil
+
+	composite.UnredirectSubwindows(c.conn, c.root, composite.RedirectManual)
+	composite.ReleaseOverlayWindow(c.conn, c.root)
+	xproto.DestroyWindow(c.conn, c.selection)
+	c.conn.Sync()
+	c.conn.Close()
+}
+
+func (c *Compositor) updateBackground() {
+	if c.background != 0 {
+		render.FreePicture(c.conn, c.background)
+		c.background = 0
+	}
+
+	c.background, _ = render.NewPictureId(c.conn)
+	prop, err := xproto.GetProperty(c.conn, false, c.root, c.atomRootPixmap, xproto.AtomPixmap, 0, 1).Reply()
+	if err == nil && prop.ValueLen == 1 {
+		pix := xproto.Pixmap(xgb.Get32(prop.Value))
+		screen := xproto.Setup(c.conn).DefaultScreen(c.conn)
+		err = render.CreatePictureChecked(c.conn, c.background, xproto.Drawable(pix), c.formats.forVisual(screen.RootVisual),
+			render.CpRepeat, []uint32{render.RepeatNormal}).Check()
+		if err == nil {
+			return
+		}
+	}
+
+	render.CreateSolidFill(c.conn, c.background, render.Color{Red: 0x3333, Green: 0x3333, Blue: 0x3333, Alpha: 0xffff})
+}
+
+func (c *Compositor) windowOpacity(id xproto.Window) float64 {
+	prop, err := xproto.GetProperty(c.conn, false, id, c.atomOpacity, xproto.AtomCardinal, 0, 1).Reply()
+	if err != nil || prop.ValueLen != 1 {
+		return 1
+	}
+
+	return float64(xgb.Get32(prop.Value)) / float64(0xffffffff)
+}
+
+func (c *Compositor) windowIsDesktop(id xproto.Window) bool {
+	prop, err := xproto.GetProperty(c.conn, false, id, c.atomType, xproto.AtomAtom, 0, 32).Reply()
+	if err != nil {
+		return false
+	}
+
+	for i := 0; i+4 <= len(prop.Value); i += 4 {
+		if xproto.Atom(xgb.Get32(prop.Value[i:])) == c.atomTypeDesktop {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package compositor
+
+import (
+	"errors"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/render"
+	"github.com/BurntSushi/xgb/xfixes"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+const (
+	fadeDelta = 0.1 // the change in fade level each frame, so a fade lasts one sixth of a second
+
+	// maxRequestData keeps image uploads well within the maximum request length of the server.
+	maxRequestData = 0x10000
+)
+
+// formats looks up the render picture formats used for windows and masks.
+type formats struct {
+	alpha   render.Pictformat
+	visuals map[xproto.Visualid]render.Pictformat
+	opaque  map[render.Pictformat]bool
+}
+
+func loadFormats(conn *xgb.Conn) (*formats, error) {
+	reply, err := render.QueryPictFormats(conn).Reply()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &formats{visuals: make(map[xproto.Visualid]render.Pictformat), opaque: make(map[render.Pictformat]bool)}
+	for _, info := range reply.Formats {
+		if info.Type != render.PictTypeDirect {
+			continue
+		}
+		f.opaque[info.Id] = info.Direct.AlphaMask == 0
+		if info.Depth == 8 && info.Direct.AlphaMask == 0xff && info.Direct.RedMask == 0 {
+			f.alpha = info.Id
+		}
+	}
+	for _, screen := range reply.Screens {
+		for _, depth := range screen.Depths {
+			for _, visual := range depth.Visuals {
+				f.visuals[visual.Visual] = visual.Format
+			}
+		}
+	}
+
+	if f.alpha == 0 {
+		return nil, errors.New("no alpha picture format is available")
+	}
+	return f, nil
+}
+
+func (f *formats) forVisual(visual xproto.Visualid) render.Pictformat {
+	return f.visuals[visual]
+}
+
+func (f *formats) isOpaque(visual xproto.Visualid) bool {
+	opaque, ok := f.opaque[f.visuals[visual]]
+	return !ok || opaque
+}
+
+// alphaPicture returns a solid picture with the given alpha, used as a mask for translucent windows.
+func (c *Compositor) alphaPicture(alpha uint8) render.Picture {
+	return c.solidPicture(c.alphas, alpha, render.Color{Alpha: uint16(alpha) * 0x101})
+}
+
+func (c *Compositor) damageAll() {
+	c.damageArea(xproto.Rectangle{Width: c.width, Height: c.height})
+}
+
+func (c *Compositor) damageArea(r xproto.Rectangle) {
+	region, err := xfixes.NewRegionId(c.conn)
+	if err != nil {
+		return
+	}
+	xfixes.CreateRegion(c.conn, region, []xproto.Rectangle{r})
+	c.damageRegion(region)
+	xfixes.DestroyRegion(c.conn, region)
+}
+
+// damageRegion adds an area of the screen to the region that will be repainted on the next frame.
+func (c *Compositor) damageRegion(region xfixes.Region) {
+	if c.dirty == 0 {
+		c.dirty, _ = xfixes.NewRegionId(c.conn)
+		xfixes.CreateRegion(c.conn, c.dirty, nil)
+	}
+
+	xfixes.UnionRegion(c.conn, c.dirty, region, c.dirty)
+}
+
+// paint draws all damaged parts of the screen into a buffer and then copies that to the screen.
+// Updating the screen in a single operation avoids showing partly drawn frames.
+func (c *Compositor) paint() {
+	c.stepFades()
+	if c.dirty == 0 {
+		return
+	}
+
+	xfixes.SetPictureClipRegion(c.conn, c.bufferPicture, c.dirty, 0, 0)
+	render.Composite(c.conn, render.PictOpSrc, c.background, 0, c.bufferPicture, 0, 0, 0, 0, 0, 0, c.width, c.height)
+	for _, w := range c.windows {
+		if w.visible() {
+			c.paintWindow(w)
+		}
+	}
+
+	xfixes.SetPictureClipRegion(c.conn, c.overlayPicture, c.dirty, 0, 0)
+	render.Composite(c.conn, render.PictOpSrc, c.bufferPicture, 0, c.overlayPicture, 0, 0, 0, 0, 0, 0, c.width, c.height)
+	xfixes.DestroyRegion(c.conn, c.dirty)
+	c.dirty = 0
+}
+
+func (c *Compositor) paintWindow(w *window) {
+	pic := c.windowPicture(w)
+	if pic == 0 {
+		return
+	}
+
+	alpha := w.opacity * w.fade
+	if !w.noShadow {
+		c.paintShadow(w, alpha)
+	}
+
+	width, height := w.width+w.border*2, w.height+w.border*2
+	if w.opaque && alpha >= 1 {
+		render.Composite(c.conn, render.PictOpSrc, pic, 0, c.bufferPicture, 0, 0, 0, 0, w.x, w.y, width, height)
+		return
+	}
+
+	mask := render.Picture(0)
+	if alpha < 1 {
+		mask = c.alphaPicture(uint8(alpha * 0xff))
+	}
+	render.Composite(c.conn, render.PictOpOver, pic, mask, c.bufferPicture, 0, 0, 0, 0, w.x, w.y, width, height)
+}
+
+func (c *Compositor) paintShadow(w *window, alpha float64) {
+	width, height := w.width+w.border*2, w.height+w.border*2
+	if w.shadow == 0 || w.shadowWidth != width || w.shadowHeight != height {
+		if w.shadow != 0 {
+			render.FreePicture(c.conn, w.shadow)
+		}
+		w.shadow = c.createShadow(width, height)
+		w.shadowWidth, w.shadowHeight = width, height
+	}
+	if w.shadow == 0 {
+		return
+	}
+
+	ext := w.extents()
+	color := c.shadowPicture(uint8(alpha * shadowOpacity * 0xff))
+	render.Composite(c.conn, render.PictOpOver, color, w.shadow, c.bufferPicture, 0, 0, 0, 0, ext.X, ext.Y,
+		ext.Width, ext.Height)
+}
+
+// createShadow uploads a shadow mask for a window of the given size.
+func (c *Compositor) createShadow(width, height uint16) render.Picture {
+	w, h := int(width)+shadowRadius*2, int(height)+shadowRadius*2
+	if w > 0x7fff || h > 0x7fff {
+		return 0
+	}
+
+	pix, err := xproto.NewPixmapId(c.conn)
+	if err != nil {
+		return 0
+	}
+	xproto.CreatePixmap(c.conn, 8, pix, xproto.Drawable(c.root), uint16(w), uint16(h))
+	gc, err := xproto.NewGcontextId(c.conn)
+	if err != nil {
+		xproto.FreePixmap(c.conn, pix)
+		return 0
+	}
+	xproto.CreateGC(c.conn, gc, xproto.Drawable(pix), 0, nil)
+
+	data := shadowImage(int(width), int(height), shadowRadius)
+	stride := len(data) / h
+	rows := maxRequestData / stride
+	if rows < 1 {
+		rows = 1
+	}
+	for y := 0; y < h; y += rows {
+		count := rows
+		if y+count > h {
+			count = h - y
+		}
+		xproto.PutImage(c.conn, xproto.ImageFormatZPixmap, xproto.Drawable(pix), gc, uint16(w), uint16(count),
+			0, int16(y), 0, 8, data[y*stride:(y+count)*stride])
+	}
+	xproto.FreeGC(c.conn, gc)
+
+	pic, err := render.NewPictureId(c.conn)
+	if err == nil {
+		render.CreatePicture(c.conn, pic, xproto.Drawable(pix), c.formats.alpha, 0, nil)
+	}
+	xproto.FreePixmap(c.conn, pix) // the picture holds a reference
+	return pic
+}
+
+// shadowPicture returns a solid black picture with the given alpha, used to colour shadows.
+func (c *Compositor) shadowPicture(alpha uint8) render.Picture {
+	return c.solidPicture(c.shadows, alpha, render.Color{Alpha: uint16(alpha) * 0x101})
+}
+
+func (c *Compositor) solidPicture(cache map[uint8]render.Picture, alpha uint8, col render.Color) render.Picture {
+	if pic, ok := cache[alpha]; ok {
+		return pic
+	}
+
+	pic, err := render.NewPictureId(c.conn)
+	if err != nil {
+		return 0
+	}
+	render.CreateSolidFill(c.conn, pic, col)
+	cache[alpha] = pic
+	return pic
+}
+
+// stepFades moves the fade level of each window towards shown or hidden.
+// Windows that have finished fading out after being destroyed are no longer tracked.
+func (c *Compositor) stepFades() {
+	var done []*window
+	for _, w := range c.windows {
+		if w.inputOnly {
+			continue
+		}
+
+		target := 0.0
+		if w.mapped {
+			target = 1
+		}
+		if w.fade == target {
+			continue
+		}
+
+		w.fade = nextFade(w.fade, target)
+		c.damageArea(w.extents())
+		if w.fade == 0 {
+			if w.destroyed {
+				done = append(done, w)
+			} else {
+				c.releasePixmap(w)
+			}
+		}
+	}
+
+	for _, w := range done {
+		c.dropWindow(w)
+	}
+}
+
+func nextFade(fade, target float64) float64 {
+	if fade < target {
+		fade += fadeDelta
+		if fade > target {
+			fade = target
+		}
+	} else {
+		fade -= fadeDelta
+		if fade < target {
+			fade = target
+		}
+	}
+
+	return fade
+}
@@ -0,0 +1,16 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package compositor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextFade(t *testing.T) {
+	assert.InDelta(t, fadeDelta, nextFade(0, 1), 0.0001)
+	assert.Equal(t, 1.0, nextFade(0.95, 1))
+	assert.Equal(t, 0.0, nextFade(0.05, 0))
+}
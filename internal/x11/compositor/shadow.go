@@ -0,0 +1,64 @@
+package compositor
+
+import "math"
+
+const (
+	shadowOffset  = 4   // how far down and right shadows are drawn
+	shadowOpacity = 0.5 // the darkest shadow, under the middle of a window
+	shadowRadius  = 12  // the blur radius of shadows
+)
+
+// shadowImage returns an 8 bit alpha mask for the shadow of a window with the given size.
+// The image is padded by radius on all sides and each row is padded to a multiple of 4 bytes.
+func shadowImage(width, height, radius int) []byte {
+	cols := shadowProfile(width, radius)
+	rows := shadowProfile(height, radius)
+
+	stride := (len(cols) + 3) &^ 3
+	data := make([]byte, stride*len(rows))
+	for y, row := range rows {
+		for x, col := range cols {
+			data[y*stride+x] = uint8(math.Round(row * col * 0xff))
+		}
+	}
+	return data
+}
+
+// shadowProfile returns the coverage along one axis of a blurred edge of the given length.
+// The result has length+2*radius values between 0 and 1.
+func shadowProfile(length, radius int) []float64 {
+	kernel := gaussianKernel(radius)
+	profile := make([]float64, length+radius*2)
+	for i := range profile {
+		sum := 0.0
+		for k, weight := range kernel {
+			pos := i + k - radius*2 // the source sample, offset into the unpadded edge
+			if pos >= 0 && pos < length {
+				sum += weight
+			}
+		}
+		profile[i] = math.Min(sum, 1)
+	}
+
+	return profile
+}
+
+// gaussianKernel returns normalised weights for a blur spanning radius pixels each side.
+func gaussianKernel(radius int) []float64 {
+	if radius <= 0 {
+		return []float64{1}
+	}
+
+	sigma := float64(radius) / 2
+	kernel := make([]float64, radius*2+1)
+	total := 0.0
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-(d * d) / (2 * sigma * sigma))
+		total += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= total
+	}
+	return kernel
+}
@@ -0,0 +1,32 @@
+package compositor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShadowImage(t *testing.T) {
+	data := shadowImage(10, 10, 4)
+	stride := 20 // 18 pixels padded to a multiple of 4
+	assert.Equal(t, stride*18, len(data))
+
+	assert.Equal(t, uint8(0), data[0])
+	assert.Equal(t, uint8(0xff), data[9*stride+9])
+	assert.Less(t, data[9*stride+2], data[9*stride+4])
+}
+
+func TestShadowProfile(t *testing.T) {
+	profile := shadowProfile(20, 5)
+	assert.Equal(t, 30, len(profile))
+
+	assert.Less(t, profile[0], 0.05)
+	assert.InDelta(t, 1.0, profile[15], 0.001)
+	assert.InDelta(t, 0.5, profile[5], 0.1)
+	for i := 1; i < 15; i++ {
+		assert.GreaterOrEqual(t, profile[i], profile[i-1])
+	}
+	for i := range profile {
+		assert.InDelta(t, profile[i], profile[len(profile)-1-i], 0.0001)
+	}
+}
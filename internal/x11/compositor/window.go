@@ -0,0 +1,259 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package compositor
+
+import (
+	"github.com/BurntSushi/xgb/composite"
+	"github.com/BurntSushi/xgb/damage"
+	"github.com/BurntSushi/xgb/render"
+	"github.com/BurntSushi/xgb/xfixes"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// window holds the compositing state of a top level window.
+type window struct {
+	id                  xproto.Window
+	x, y                int16
+	width, height       uint16
+	border              uint16
+	visual              xproto.Visualid
+	mapped, inputOnly   bool
+	destroyed, noShadow bool
+	opaque              bool
+
+	damage  damage.Damage
+	pixmap  xproto.Pixmap
+	picture render.Picture
+	opacity float64
+	fade    float64 // the current fade level, 0 is hidden and 1 is fully shown
+
+	shadow                    render.Picture
+	shadowWidth, shadowHeight uint16
+}
+
+// extents returns the area of the screen that this window, including its shadow, draws to.
+func (w *window) extents() xproto.Rectangle {
+	r := xproto.Rectangle{X: w.x, Y: w.y, Width: w.width + w.border*2, Height: w.height + w.border*2}
+	if w.noShadow {
+		return r
+	}
+
+	r.X += shadowOffset - shadowRadius
+	r.Y += shadowOffset - shadowRadius
+	r.Width += shadowRadius * 2
+	r.Height += shadowRadius * 2
+	return r
+}
+
+// visible returns true if the window needs to be painted in the next frame.
+func (w *window) visible() bool {
+	return !w.inputOnly && w.fade > 0 && w.width > 0 && w.height > 0
+}
+
+func (c *Compositor) addWindow(id xproto.Window, fade bool) {
+	if id == c.overlay || id == c.selection {
+		return
+	}
+	if _, w := c.findWindow(id); w != nil {
+		return
+	}
+
+	attrs, err := xproto.GetWindowAttributes(c.conn, id).Reply()
+	if err != nil {
+		return
+	}
+	geom, err := xproto.GetGeometry(c.conn, xproto.Drawable(id)).Reply()
+	if err != nil {
+		return
+	}
+
+	w := &window{id: id, x: geom.X, y: geom.Y, width: geom.Width, height: geom.Height, border: geom.BorderWidth,
+		visual: attrs.Visual, inputOnly: attrs.Class == xproto.WindowClassInputOnly}
+	c.windows = append(c.windows, w)
+	if w.inputOnly {
+		return
+	}
+
+	xproto.ChangeWindowAttributes(c.conn, id, xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange})
+	w.damage, _ = damage.NewDamageId(c.conn)
+	damage.Create(c.conn, w.damage, xproto.Drawable(id), damage.ReportLevelNonEmpty)
+	w.opacity = c.windowOpacity(id)
+	w.noShadow = c.windowIsDesktop(id)
+	w.opaque = c.formats.isOpaque(w.visual)
+	if attrs.MapState == xproto.MapStateViewable {
+		w.mapped = true
+		w.fade = 1
+		if fade {
+			w.fade = 0
+		}
+	}
+}
+
+func (c *Compositor) circulateWindow(ev xproto.CirculateNotifyEvent) {
+	i, w := c.findWindow(ev.Window)
+	if w == nil {
+		return
+	}
+
+	c.windows = append(c.windows[:i], c.windows[i+1:]...)
+	if ev.Place == xproto.PlaceOnTop {
+		c.windows = append(c.windows, w)
+	} else {
+		c.windows = append([]*window{w}, c.windows...)
+	}
+	c.damageArea(w.extents())
+}
+
+func (c *Compositor) configureWindow(ev xproto.ConfigureNotifyEvent) {
+	i, w := c.findWindow(ev.Window)
+	if w == nil {
+		return
+	}
+
+	c.damageArea(w.extents())
+	if w.width != ev.Width || w.height != ev.Height || w.border != ev.BorderWidth {
+		c.releasePixmap(w) // the server allocates a new pixmap when a redirected window is resized
+	}
+	w.x, w.y = ev.X, ev.Y
+	w.width, w.height, w.border = ev.Width, ev.Height, ev.BorderWidth
+	c.damageArea(w.extents())
+
+	c.windows = append(c.windows[:i], c.windows[i+1:]...)
+	pos := 0
+	if ev.AboveSibling != xproto.WindowNone {
+		for j, above := range c.windows {
+			if above.id == ev.AboveSibling && !above.destroyed {
+				pos = j + 1
+				break
+			}
+		}
+	}
+	c.windows = append(c.windows[:pos], append([]*window{w}, c.windows[pos:]...)...)
+}
+
+func (c *Compositor) damageWindow(id damage.Damage) {
+	for _, w := range c.windows {
+		if w.damage != id || w.destroyed {
+			continue
+		}
+
+		parts, err := xfixes.NewRegionId(c.conn)
+		if err != nil {
+			return
+		}
+		xfixes.CreateRegion(c.conn, parts, nil)
+		damage.Subtract(c.conn, w.damage, xfixes.RegionNone, parts)
+		xfixes.TranslateRegion(c.conn, parts, w.x+int16(w.border), w.y+int16(w.border))
+		c.damageRegion(parts)
+		xfixes.DestroyRegion(c.conn, parts)
+		return
+	}
+}
+
+func (c *Compositor) destroyWindow(id xproto.Window) {
+	_, w := c.findWindow(id)
+	if w == nil {
+		return
+	}
+
+	w.destroyed = true
+	if w.damage != 0 {
+		// the server frees the damage object with the window
+		w.damage = 0
+	}
+	if !w.mapped || w.picture == 0 {
+		c.dropWindow(w)
+		return
+	}
+	w.mapped = false
+	c.damageArea(w.extents())
+}
+
+func (c *Compositor) freeWindow(w *window) {
+	c.releasePixmap(w)
+	if w.shadow != 0 {
+		render.FreePicture(c.conn, w.shadow)
+		w.shadow = 0
+	}
+	if w.damage != 0 {
+		damage.Destroy(c.conn, w.damage)
+		w.damage = 0
+	}
+}
+
+func (c *Compositor) mapWindow(id xproto.Window) {
+	_, w := c.findWindow(id)
+	if w == nil || w.inputOnly {
+		return
+	}
+
+	w.mapped = true
+	w.opacity = c.windowOpacity(id)
+	c.releasePixmap(w)
+	c.damageArea(w.extents())
+}
+
+// releasePixmap frees the named pixmap of a window so that it will be looked up again when next painted.
+func (c *Compositor) releasePixmap(w *window) {
+	if w.picture != 0 {
+		render.FreePicture(c.conn, w.picture)
+		w.picture = 0
+	}
+	if w.pixmap != 0 {
+		xproto.FreePixmap(c.conn, w.pixmap)
+		w.pixmap = 0
+	}
+}
+
+// dropWindow stops tracking a window and frees the server resources used to paint it.
+func (c *Compositor) dropWindow(w *window) {
+	for i, tracked := range c.windows {
+		if tracked != w {
+			continue
+		}
+
+		c.damageArea(w.extents())
+		c.freeWindow(w)
+		c.windows = append(c.windows[:i], c.windows[i+1:]...)
+		return
+	}
+}
+
+func (c *Compositor) removeWindow(id xproto.Window) {
+	if _, w := c.findWindow(id); w != nil {
+		c.dropWindow(w)
+	}
+}
+
+func (c *Compositor) unmapWindow(id xproto.Window) {
+	_, w := c.findWindow(id)
+	if w == nil {
+		return
+	}
+
+	// the pixmap is kept until the window has faded out
+	w.mapped = false
+	c.damageArea(w.extents())
+}
+
+// windowPicture returns a render picture for the current contents of a window, creating it if required.
+func (c *Compositor) windowPicture(w *window) render.Picture {
+	if w.picture != 0 || w.destroyed {
+		return w.picture
+	}
+
+	pix, err := xproto.NewPixmapId(c.conn)
+	if err != nil {
+		return 0
+	}
+	if err = composite.NameWindowPixmapChecked(c.conn, w.id, pix).Check(); err != nil {
+		return 0
+	}
+	w.pixmap = pix
+
+	w.picture, _ = render.NewPictureId(c.conn)
+	render.CreatePicture(c.conn, w.picture, xproto.Drawable(pix), c.formats.forVisual(w.visual),
+		render.CpSubwindowMode, []uint32{xproto.SubwindowModeIncludeInferiors})
+	return w.picture
+}
@@ -9,9 +9,28 @@ import (
 
 	"fyne.io/fyne/v2"
 	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xfixes"
 	"github.com/BurntSushi/xgb/xproto"
 )
 
+// CaptureCursor returns the image of the mouse pointer and the position of its top left corner on the root window.
+func CaptureCursor(conn *xgb.Conn) (*image.NRGBA, image.Point, error) {
+	if err := xfixes.Init(conn); err != nil {
+		return nil, image.Point{}, err
+	}
+	if _, err := xfixes.QueryVersion(conn, 4, 0).Reply(); err != nil {
+		return nil, image.Point{}, err
+	}
+
+	cursor, err := xfixes.GetCursorImage(conn).Reply()
+	if err != nil {
+		return nil, image.Point{}, err
+	}
+
+	img := cursorImage(int(cursor.Width), int(cursor.Height), cursor.CursorImage)
+	return img, image.Pt(int(cursor.X)-int(cursor.Xhot), int(cursor.Y)-int(cursor.Yhot)), nil
+}
+
 // CaptureWindow allows x11 code to get the image representation of a screen area.
 // The window specified will be captured according to its bounds.
 func CaptureWindow(conn *xgb.Conn, win xproto.Window) *image.NRGBA {
@@ -47,3 +66,23 @@ func copyPixel(in []byte, out []uint8, i int) {
 	out[i+2] = b
 	out[i+3] = 0xff // some colour maps / depths don't include alpha
 }
+
+// cursorImage converts the premultiplied ARGB pixels of a cursor to an image.
+func cursorImage(width, height int, argb []uint32) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, pix := range argb {
+		if i*4 >= len(img.Pix) {
+			break
+		}
+		a := pix >> 24
+		if a == 0 {
+			continue
+		}
+
+		img.Pix[i*4] = uint8(((pix >> 16) & 0xff) * 0xff / a)
+		img.Pix[i*4+1] = uint8(((pix >> 8) & 0xff) * 0xff / a)
+		img.Pix[i*4+2] = uint8((pix & 0xff) * 0xff / a)
+		img.Pix[i*4+3] = uint8(a)
+	}
+	return img
+}
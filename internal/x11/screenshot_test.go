@@ -19,3 +19,10 @@ func TestCopyPixel(t *testing.T) {
 	assert.Equal(t, uint8(0xff), out[2])
 	assert.Equal(t, uint8(0xff), out[3])
 }
+
+func TestCursorImage(t *testing.T) {
+	img := cursorImage(2, 1, []uint32{0x80400000, 0})
+
+	assert.Equal(t, []uint8{0x7f, 0, 0, 0x80}, img.Pix[:4])
+	assert.Equal(t, []uint8{0, 0, 0, 0}, img.Pix[4:])
+}
@@ -69,7 +69,10 @@ func NewClient(win xproto.Window, wm x11.XWM) x11.XWin {
 }
 
 func (c *client) Capture() image.Image {
-	return x11.CaptureWindow(c.wm.Conn(), c.FrameID())
+	if img := x11.CaptureWindow(c.wm.Conn(), c.FrameID()); img != nil {
+		return img
+	}
+	return nil // avoid returning a nil pointer inside a non-nil interface
 }
 
 func (c *client) ChildID() xproto.Window {
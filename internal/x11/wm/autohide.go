@@ -0,0 +1,107 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package wm
+
+import (
+	"image"
+
+	"github.com/BurntSushi/xgb/shape"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/internal/notify"
+)
+
+// HideDesktopArea returns the desktop window for a screen to the bottom of the stack after it was revealed.
+func (x *x11WM) HideDesktopArea(screen *fynedesk.Screen) {
+	win := x.rootForScreen(screen)
+	if win == 0 {
+		return
+	}
+
+	xproto.ConfigureWindow(x.x.Conn(), win, xproto.ConfigWindowStackMode, []uint32{xproto.StackModeBelow})
+	if x.canShape {
+		shape.Mask(x.x.Conn(), shape.SoSet, shape.SkBounding, win, 0, 0, xproto.PixmapNone)
+		shape.Mask(x.x.Conn(), shape.SoSet, shape.SkInput, win, 0, 0, xproto.PixmapNone)
+	}
+}
+
+// RevealDesktopArea raises the desktop window for a screen above application windows.
+// The window is shaped to the area passed so that only the app bar is visible.
+func (x *x11WM) RevealDesktopArea(screen *fynedesk.Screen, area image.Rectangle) {
+	win := x.rootForScreen(screen)
+	if win == 0 || !x.canShape {
+		return
+	}
+
+	rect := []xproto.Rectangle{{X: int16(area.Min.X), Y: int16(area.Min.Y),
+		Width: uint16(area.Dx()), Height: uint16(area.Dy())}}
+	shape.Rectangles(x.x.Conn(), shape.SoSet, shape.SkBounding, xproto.ClipOrderingUnsorted, win, 0, 0, rect)
+	shape.Rectangles(x.x.Conn(), shape.SoSet, shape.SkInput, xproto.ClipOrderingUnsorted, win, 0, 0, rect)
+	xproto.ConfigureWindow(x.x.Conn(), win, xproto.ConfigWindowStackMode, []uint32{xproto.StackModeAbove})
+}
+
+// SetEdgeTriggers replaces the areas of the screen that will reveal an auto hiding app bar when the mouse enters.
+func (x *x11WM) SetEdgeTriggers(areas []image.Rectangle) {
+	for _, win := range x.edgeWindows {
+		xproto.DestroyWindow(x.x.Conn(), win)
+	}
+	x.edgeWindows = nil
+
+	for _, area := range areas {
+		win, err := xproto.NewWindowId(x.x.Conn())
+		if err != nil {
+			fyne.LogError("Failed to create screen edge window", err)
+			continue
+		}
+
+		err = xproto.CreateWindowChecked(x.x.Conn(), 0, win, x.x.RootWin(),
+			int16(area.Min.X), int16(area.Min.Y), uint16(area.Dx()), uint16(area.Dy()), 0,
+			xproto.WindowClassInputOnly, 0, xproto.CwOverrideRedirect|xproto.CwEventMask,
+			[]uint32{1, xproto.EventMaskEnterWindow}).Check()
+		if err != nil {
+			fyne.LogError("Failed to create screen edge window", err)
+			continue
+		}
+		xproto.MapWindow(x.x.Conn(), win)
+		x.edgeWindows = append(x.edgeWindows, win)
+	}
+}
+
+func (x *x11WM) handleEdgeEnter(ev xproto.EnterNotifyEvent) {
+	edgeNotify, ok := fynedesk.Instance().(notify.EdgeNotify)
+	if !ok {
+		return
+	}
+
+	screen := fynedesk.Instance().Screens().ScreenForGeometry(int(ev.RootX), int(ev.RootY), 0, 0)
+	edgeNotify.EdgeEnterNotify(screen)
+}
+
+func (x *x11WM) isEdgeWindow(win xproto.Window) bool {
+	for _, edge := range x.edgeWindows {
+		if edge == win {
+			return true
+		}
+	}
+
+	return false
+}
+
+// raiseEdgeWindows makes sure that the screen edges can be reached above any newly mapped window.
+func (x *x11WM) raiseEdgeWindows() {
+	for _, win := range x.edgeWindows {
+		xproto.ConfigureWindow(x.x.Conn(), win, xproto.ConfigWindowStackMode, []uint32{xproto.StackModeAbove})
+	}
+}
+
+func (x *x11WM) rootForScreen(screen *fynedesk.Screen) xproto.Window {
+	if screen == nil || screen == fynedesk.Instance().Screens().Primary() {
+		return x.rootID
+	}
+
+	return x.screenRoots[screen.Name]
+}
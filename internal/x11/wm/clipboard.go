@@ -0,0 +1,47 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package wm
+
+import (
+	"fyshos.com/fynedesk/internal/x11/clipboard"
+)
+
+// SetClipboard makes the content passed the current clipboard.
+// The mime type should be "text/plain" or "image/png".
+func (x *x11WM) SetClipboard(mime string, data []byte) error {
+	mgr, err := x.clipboardManager()
+	if err != nil {
+		return err
+	}
+
+	return mgr.Set(mime, data)
+}
+
+// WatchClipboard starts the clipboard manager, which keeps copied content available after the app that copied it exits.
+// The changed function is called with each new item and the class of the window it came from,
+// it should return false if the item must not be kept. Passing nil stops reporting and keeping new items.
+func (x *x11WM) WatchClipboard(changed func(mime string, data []byte, class string) bool) error {
+	mgr, err := x.clipboardManager()
+	if err != nil {
+		return err
+	}
+
+	mgr.Watch(changed)
+	return nil
+}
+
+func (x *x11WM) clipboardManager() (*clipboard.Manager, error) {
+	x.clipboardLock.Lock()
+	defer x.clipboardLock.Unlock()
+	if x.clipboard != nil {
+		return x.clipboard, nil
+	}
+
+	mgr, err := clipboard.New()
+	if err != nil {
+		return nil, err
+	}
+	x.clipboard = mgr
+	return mgr, nil
+}
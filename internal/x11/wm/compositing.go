@@ -0,0 +1,56 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xprop"
+
+	"fyshos.com/fynedesk/internal/x11"
+	"fyshos.com/fynedesk/internal/x11/compositor"
+)
+
+// StartCompositing begins drawing windows through the built in compositor.
+func (x *x11WM) StartCompositing() error {
+	if x.compositor != nil {
+		return nil
+	}
+
+	comp, err := compositor.New()
+	if err != nil {
+		return err
+	}
+	if err = comp.Start(); err != nil {
+		comp.Stop()
+		return err
+	}
+
+	x.compositor = comp
+	return nil
+}
+
+// StopCompositing returns windows to being drawn directly by the X server.
+func (x *x11WM) StopCompositing() {
+	if x.compositor == nil {
+		return
+	}
+
+	x.compositor.Stop()
+	x.compositor = nil
+}
+
+// copyOpacity applies the opacity requested by a client to its frame.
+// Compositors only read the property from top level windows, which for managed clients is the frame.
+func (x *x11WM) copyOpacity(c x11.XWin) {
+	opacity, err := ewmh.WmWindowOpacityGet(x.x, c.ChildID())
+	if err != nil {
+		if atom, err := xprop.Atm(x.x, "_NET_WM_WINDOW_OPACITY"); err == nil {
+			xproto.DeleteProperty(x.x.Conn(), c.FrameID(), atom)
+		}
+		return
+	}
+
+	_ = ewmh.WmWindowOpacitySet(x.x, c.FrameID(), opacity)
+}
@@ -11,10 +11,12 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/xgb"
 	"github.com/BurntSushi/xgb/randr"
+	"github.com/BurntSushi/xgb/shape"
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/BurntSushi/xgbutil"
 	"github.com/BurntSushi/xgbutil/ewmh"
@@ -23,8 +25,6 @@ import (
 	"github.com/BurntSushi/xgbutil/xevent"
 	"github.com/BurntSushi/xgbutil/xgraphics"
 	"github.com/BurntSushi/xgbutil/xprop"
-	"github.com/FyshOS/backgrounds/builtin"
-	"github.com/nfnt/resize"
 
 	"fyne.io/fyne/v2"
 	deskDriver "fyne.io/fyne/v2/driver/desktop"
@@ -34,6 +34,8 @@ import (
 	"fyshos.com/fynedesk"
 	"fyshos.com/fynedesk/internal/ui"
 	"fyshos.com/fynedesk/internal/x11"
+	"fyshos.com/fynedesk/internal/x11/clipboard"
+	"fyshos.com/fynedesk/internal/x11/compositor"
 	xwin "fyshos.com/fynedesk/internal/x11/win"
 	"fyshos.com/fynedesk/wm"
 )
@@ -57,10 +59,16 @@ type x11WM struct {
 	currentBindings []*fynedesk.Shortcut
 
 	died           bool
+	canShape       bool
+	clipboard      *clipboard.Manager
+	clipboardLock  sync.Mutex
+	compositor     *compositor.Compositor
+	edgeWindows    []xproto.Window
 	rootID, menuID xproto.Window
 	menuSize       fyne.Size
 	menuPos        fyne.Position
 	menuWin        fyne.Window
+	screenRoots    map[string]xproto.Window
 	transientMap   map[xproto.Window][]xproto.Window
 	oldRoot        *xgraphics.Image
 }
@@ -85,6 +93,7 @@ const (
 	keyCodeTab         = 23
 	keyCodeReturn      = 36
 	keyCodeBacktick    = 49
+	keyCodePeriod      = 60
 	keyCodeAlt         = 64
 	keyCodeSpace       = 65
 	keyCodePrintScreen = 107
@@ -104,6 +113,11 @@ const (
 	keyCodeVolumeLess = 122
 	keyCodeVolumeMore = 123
 
+	keyCodeMediaNext     = 171
+	keyCodeMediaPlay     = 172
+	keyCodeMediaPrevious = 173
+	keyCodeMediaStop     = 174
+
 	windowNameMenu = "FyneDesk Menu"
 )
 
@@ -119,6 +133,7 @@ func NewX11WindowManager(a fyne.App) (fynedesk.WindowManager, error) {
 	root := conn.RootWin()
 	mgr.takeSelectionOwnership()
 	mgr.transientMap = make(map[xproto.Window][]xproto.Window)
+	mgr.screenRoots = make(map[string]xproto.Window)
 
 	eventMask := xproto.EventMaskPropertyChange |
 		xproto.EventMaskFocusChange |
@@ -164,6 +179,11 @@ func NewX11WindowManager(a fyne.App) (fynedesk.WindowManager, error) {
 	}
 
 	x11.LoadCursors(conn)
+	if err := shape.Init(conn.Conn()); err != nil {
+		fyne.LogError("Could not initialize shape extension", err)
+	} else {
+		mgr.canShape = true
+	}
 
 	listener := make(chan fyne.Settings)
 	a.Settings().AddChangeListener(listener)
@@ -183,6 +203,21 @@ func (x *x11WM) AddStackListener(l fynedesk.StackListener) {
 	x.stack.listeners = append(x.stack.listeners, l)
 }
 
+// RemoveStackListener stops a listener that was added using AddStackListener from being told about window changes.
+func (x *x11WM) RemoveStackListener(l fynedesk.StackListener) {
+	for i, listener := range x.stack.listeners {
+		if listener == l {
+			x.stack.listeners = append(x.stack.listeners[:i:i], x.stack.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// BackgroundChangeNotify is called by the desktop when the wallpaper changes without a settings change.
+func (x *x11WM) BackgroundChangeNotify() {
+	go x.updateBackgrounds()
+}
+
 func (x *x11WM) Blank() {
 	go func() {
 		time.Sleep(time.Second / 3)
@@ -195,7 +230,21 @@ func (x *x11WM) Blank() {
 
 func (x *x11WM) Capture() image.Image {
 	root := x.x.RootWin()
-	return x11.CaptureWindow(x.x.Conn(), root)
+	if img := x11.CaptureWindow(x.x.Conn(), root); img != nil {
+		return img
+	}
+	return nil // avoid returning a nil pointer inside a non-nil interface
+}
+
+// CaptureCursor returns the image of the mouse pointer and the position of its top left corner on the desktop.
+func (x *x11WM) CaptureCursor() (image.Image, image.Point) {
+	img, pos, err := x11.CaptureCursor(x.x.Conn())
+	if err != nil {
+		fyne.LogError("Unable to capture the mouse pointer", err)
+		return nil, image.Point{}
+	}
+
+	return img, pos
 }
 
 func (x *x11WM) Close() {
@@ -206,6 +255,10 @@ func (x *x11WM) Close() {
 		// x server died, no point attempting to shut it cleanly
 		return
 	}
+	x.StopCompositing()
+	if x.clipboard != nil {
+		x.clipboard.Stop()
+	}
 
 	cancel := false
 	exit := make(chan interface{})
@@ -344,6 +397,8 @@ func (x *x11WM) keyNameToCode(n fyne.KeyName) xproto.Keycode {
 		return keyCodeTab
 	case fyne.KeyBackTick:
 		return keyCodeBacktick
+	case fyne.KeyPeriod:
+		return keyCodePeriod
 	case deskDriver.KeyPrintScreen:
 		return keyCodePrintScreen
 	case fynedesk.KeyBrightnessDown:
@@ -352,15 +407,26 @@ func (x *x11WM) keyNameToCode(n fyne.KeyName) xproto.Keycode {
 		return keyCodeBrightMore
 	case fynedesk.KeyCalculator:
 		return keyCodeCalculator
+	case fynedesk.KeyMediaNext:
+		return keyCodeMediaNext
+	case fynedesk.KeyMediaPlay:
+		return keyCodeMediaPlay
+	case fynedesk.KeyMediaPrevious:
+		return keyCodeMediaPrevious
+	case fynedesk.KeyMediaStop:
+		return keyCodeMediaStop
 	case fynedesk.KeyVolumeMute:
 		return keyCodeVolumeMute
 	case fynedesk.KeyVolumeDown:
 		return keyCodeVolumeLess
 	case fynedesk.KeyVolumeUp:
 		return keyCodeVolumeMore
-	case fyne.KeyL:
-		codes := keybind.StrToKeycodes(x.x, "L")
-		return codes[0]
+	}
+
+	if len(n) == 1 && n[0] >= 'A' && n[0] <= 'Z' {
+		if codes := keybind.StrToKeycodes(x.x, string(n)); len(codes) > 0 {
+			return codes[0]
+		}
 	}
 
 	for i := 0; i <= 9; i++ {
@@ -479,30 +545,9 @@ func (x *x11WM) configureRoots() {
 		maxY = max(maxY, screen.Y+screen.Height)
 
 		if screen == fynedesk.Instance().Screens().Primary() {
-			priX, priY, priW, priH := 0, 0, 0, 0
-			geom, err := xproto.GetGeometry(x.x.Conn(), xproto.Drawable(x.rootID)).Reply()
-			if err == nil {
-				priX, priY = int(geom.X), int(geom.Y)
-				priW, priH = int(geom.Width), int(geom.Height)
-			}
-			if screen.X == priX && screen.Y == priY && screen.Width == priW && screen.Height == priH {
-				continue
-			}
-
-			notifyEv := xproto.ConfigureNotifyEvent{Event: x.rootID, Window: x.rootID, AboveSibling: 0,
-				X: int16(screen.X), Y: int16(screen.Y), Width: uint16(screen.Width), Height: uint16(screen.Height),
-				BorderWidth: 0, OverrideRedirect: false}
-			xproto.SendEvent(x.x.Conn(), false, x.rootID, xproto.EventMaskStructureNotify, string(notifyEv.Bytes()))
-
-			// we need to trigger a move so that the correct scale is picked up
-			xproto.ConfigureWindow(x.x.Conn(), x.rootID, xproto.ConfigWindowX|xproto.ConfigWindowY|
-				xproto.ConfigWindowWidth|xproto.ConfigWindowHeight,
-				[]uint32{uint32(screen.X + 1), uint32(screen.Y + 1), uint32(screen.Width - 2), uint32(screen.Height - 2)})
-
-			// and then set the correct location
-			xproto.ConfigureWindow(x.x.Conn(), x.rootID, xproto.ConfigWindowX|xproto.ConfigWindowY|
-				xproto.ConfigWindowWidth|xproto.ConfigWindowHeight,
-				[]uint32{uint32(screen.X), uint32(screen.Y), uint32(screen.Width), uint32(screen.Height)})
+			x.configureRoot(x.rootID, screen)
+		} else if id, ok := x.screenRoots[screen.Name]; ok {
+			x.configureRoot(id, screen)
 		}
 	}
 
@@ -514,11 +559,75 @@ func (x *x11WM) configureRoots() {
 		fyne.LogError("", err)
 	}
 
-	err = ewmh.WorkareaSet(x.x, []ewmh.Workarea{{X: 0, Y: 0, Width: uint(rootWidth), Height: uint(rootHeight)}}) // The array will grow when virtual desktops are supported
+	x.updateWorkarea()
+	go x.updateBackgrounds()
+}
+
+func (x *x11WM) configureRoot(win xproto.Window, screen *fynedesk.Screen) {
+	if win == 0 {
+		return
+	}
+
+	rootX, rootY, rootW, rootH := 0, 0, 0, 0
+	geom, err := xproto.GetGeometry(x.x.Conn(), xproto.Drawable(win)).Reply()
+	if err == nil {
+		rootX, rootY = int(geom.X), int(geom.Y)
+		rootW, rootH = int(geom.Width), int(geom.Height)
+	}
+	if screen.X == rootX && screen.Y == rootY && screen.Width == rootW && screen.Height == rootH {
+		return
+	}
+
+	notifyEv := xproto.ConfigureNotifyEvent{Event: win, Window: win, AboveSibling: 0,
+		X: int16(screen.X), Y: int16(screen.Y), Width: uint16(screen.Width), Height: uint16(screen.Height),
+		BorderWidth: 0, OverrideRedirect: false}
+	xproto.SendEvent(x.x.Conn(), false, win, xproto.EventMaskStructureNotify, string(notifyEv.Bytes()))
+
+	// we need to trigger a move so that the correct scale is picked up
+	xproto.ConfigureWindow(x.x.Conn(), win, xproto.ConfigWindowX|xproto.ConfigWindowY|
+		xproto.ConfigWindowWidth|xproto.ConfigWindowHeight,
+		[]uint32{uint32(screen.X + 1), uint32(screen.Y + 1), uint32(screen.Width - 2), uint32(screen.Height - 2)})
+
+	// and then set the correct location
+	xproto.ConfigureWindow(x.x.Conn(), win, xproto.ConfigWindowX|xproto.ConfigWindowY|
+		xproto.ConfigWindowWidth|xproto.ConfigWindowHeight,
+		[]uint32{uint32(screen.X), uint32(screen.Y), uint32(screen.Width), uint32(screen.Height)})
+}
+
+// updateWorkarea sets the area of the root window that is not covered by desktop panels.
+// Like window struts, space is only removed where reserved parts of a screen touch the edge of the root.
+func (x *x11WM) updateWorkarea() {
+	desk := fynedesk.Instance()
+	minX, minY, maxX, maxY := math.MaxInt16, math.MaxInt16, 0, 0
+	for _, screen := range desk.Screens().Screens() {
+		minX = min(minX, screen.X)
+		minY = min(minY, screen.Y)
+		maxX = max(maxX, screen.X+screen.Width)
+		maxY = max(maxY, screen.Y+screen.Height)
+	}
+
+	left, top, right, bottom := minX, minY, maxX, maxY
+	for _, screen := range desk.Screens().Screens() {
+		cx, cy, cw, ch := desk.ContentBoundsPixels(screen)
+		if screen.X == minX {
+			left = max(left, screen.X+int(cx))
+		}
+		if screen.Y == minY {
+			top = max(top, screen.Y+int(cy))
+		}
+		if screen.X+screen.Width == maxX {
+			right = min(right, screen.X+int(cx+cw))
+		}
+		if screen.Y+screen.Height == maxY {
+			bottom = min(bottom, screen.Y+int(cy+ch))
+		}
+	}
+
+	err := ewmh.WorkareaSet(x.x, []ewmh.Workarea{{X: left, Y: top,
+		Width: uint(right - left), Height: uint(bottom - top)}}) // The array will grow when virtual desktops are supported
 	if err != nil {
 		fyne.LogError("", err)
 	}
-	go x.updateBackgrounds()
 }
 
 func (x *x11WM) configureWindow(win xproto.Window, ev xproto.ConfigureRequestEvent) {
@@ -553,7 +662,11 @@ func (x *x11WM) configureWindow(win xproto.Window, ev xproto.ConfigureRequestEve
 
 	name := x11.WindowName(x.x, win)
 	if x.isRootTitle(name) {
-		x.rootID = win
+		if screen := screenNameFromRootTitle(name); screen != "" {
+			x.screenRoots[screen] = win
+		} else {
+			x.rootID = win
+		}
 
 		x.configureRoots() // we added a root window, so reconfigure
 		return
@@ -602,7 +715,7 @@ func (x *x11WM) frameExisting() {
 
 	for _, child := range tree.Children {
 		name := x11.WindowName(x.x, child)
-		if x.isRootTitle(name) {
+		if x.isRootTitle(name) || x.isEdgeWindow(child) {
 			continue
 		}
 		attrs, err := xproto.GetWindowAttributes(x.x.Conn(), child).Reply()
@@ -678,6 +791,9 @@ func (x *x11WM) setupBindings() {
 		for range deskListener {
 			// this uses the state from the previous bind call
 			x.unbindShortcuts(x.rootID)
+			for _, root := range x.screenRoots {
+				x.unbindShortcuts(root)
+			}
 			for _, c := range x.clients {
 				x.unbindShortcuts(c.(x11.XWin).ChildID())
 			}
@@ -685,10 +801,14 @@ func (x *x11WM) setupBindings() {
 
 			// this call sets up the new cache of shortcuts
 			x.bindShortcuts(x.rootID)
+			for _, root := range x.screenRoots {
+				x.bindShortcuts(root)
+			}
 			for _, c := range x.clients {
 				x.bindShortcuts(c.(x11.XWin).ChildID())
 			}
 
+			x.updateWorkarea()
 			go x.updateBackgrounds()
 		}
 	}()
@@ -710,7 +830,9 @@ func (x *x11WM) setupWindow(win xproto.Window) {
 		x11.WindowExtendedHintsAdd(x.x, win, "_NET_WM_STATE_SKIP_PAGER")
 	}
 	x.AddWindow(c)
+	x.copyOpacity(c)
 	c.RaiseToTop()
+	x.raiseEdgeWindows()
 	c.Focus()
 	windowClientListUpdate(x)
 	windowClientListStackingUpdate(x)
@@ -844,28 +966,11 @@ func (x *x11WM) unbindShortcuts(win xproto.Window) {
 	}
 }
 
-func (x *x11WM) updatedBackgroundImage(w, h int) image.Image {
-	path := fynedesk.Instance().Settings().Background()
-	if path != "" {
-		file, err := os.Open(path)
-		if err != nil {
-			fyne.LogError("Failed to open background image", err)
-		}
-		img, _, err := image.Decode(file)
-		if err != nil {
-			fyne.LogError("Failed to read background image", err)
-		}
-		_ = file.Close()
-
-		return resize.Resize(uint(w), uint(h), img, resize.Lanczos3)
-	}
-
-	set := fyne.CurrentApp().Settings()
-	b := &builtin.Builtin{}
+func (x *x11WM) updatedBackgroundImage(screen *fynedesk.Screen) image.Image {
 	c := software.NewCanvas()
-	c.SetContent(b.Load(set.Theme(), set.ThemeVariant()))
+	c.SetContent(ui.Wallpaper(screen))
 	c.SetScale(1.0)
-	c.Resize(fyne.NewSize(float32(w), float32(h)))
+	c.Resize(fyne.NewSize(float32(screen.Width), float32(screen.Height)))
 	return c.Capture()
 }
 
@@ -878,7 +983,7 @@ func (x *x11WM) updateBackgrounds() {
 	root := xgraphics.New(x.x, image.Rect(0, 0, int(geom.Width), int(geom.Height)))
 
 	for _, screen := range fynedesk.Instance().Screens().Screens() {
-		scaled := x.updatedBackgroundImage(screen.Width, screen.Height)
+		scaled := x.updatedBackgroundImage(screen)
 		for y := screen.Y; y < screen.Y+screen.Height; y++ {
 			for x := screen.X; x < screen.X+screen.Width; x++ {
 				root.Set(x, y, scaled.At(x-screen.X, y-screen.Y))
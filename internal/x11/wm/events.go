@@ -224,6 +224,11 @@ func (x *x11WM) handleKeyRelease(ev xproto.KeyReleaseEvent) {
 }
 
 func (x *x11WM) handleMouseEnter(ev xproto.EnterNotifyEvent) {
+	if x.isEdgeWindow(ev.Event) {
+		x.handleEdgeEnter(ev)
+		return
+	}
+
 	xproto.ChangeWindowAttributes(x.x.Conn(), ev.Event, xproto.CwCursor,
 		[]uint32{uint32(x11.DefaultCursor)})
 	if mouseNotify, ok := fynedesk.Instance().(notify.MouseNotify); ok {
@@ -307,6 +312,8 @@ func (x *x11WM) handlePropertyChange(ev xproto.PropertyNotifyEvent) {
 		c.NotifyGeometry(x, y, w, h)
 	case "_MOTIF_WM_HINTS":
 		c.NotifyBorderChange()
+	case "_NET_WM_WINDOW_OPACITY":
+		x.copyOpacity(c)
 	}
 }
 
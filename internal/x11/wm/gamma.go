@@ -0,0 +1,49 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package wm
+
+import (
+	"math"
+
+	"github.com/BurntSushi/xgb/randr"
+
+	"fyne.io/fyne/v2"
+)
+
+// SetColorBalance scales the red, green and blue output of every screen.
+// Each value should be between 0 and 1, passing 1 for all of them restores normal colours.
+func (x *x11WM) SetColorBalance(red, green, blue float64) {
+	conn := x.x.Conn()
+	res, err := randr.GetScreenResourcesCurrent(conn, x.x.RootWin()).Reply()
+	if err != nil {
+		fyne.LogError("Unable to look up screens for colour balance", err)
+		return
+	}
+
+	for _, crtc := range res.Crtcs {
+		size, err := randr.GetCrtcGammaSize(conn, crtc).Reply()
+		if err != nil || size.Size == 0 {
+			continue
+		}
+
+		r, g, b := gammaRamp(size.Size, red), gammaRamp(size.Size, green), gammaRamp(size.Size, blue)
+		if err = randr.SetCrtcGammaChecked(conn, crtc, size.Size, r, g, b).Check(); err != nil {
+			fyne.LogError("Failed to set gamma ramp", err)
+		}
+	}
+}
+
+// gammaRamp returns a linear ramp of the given size scaled to reach a maximum of scale.
+func gammaRamp(size uint16, scale float64) []uint16 {
+	ramp := make([]uint16, size)
+	if size == 1 {
+		ramp[0] = uint16(math.Round(scale * math.MaxUint16))
+		return ramp
+	}
+
+	for i := range ramp {
+		ramp[i] = uint16(math.Round(float64(i) / float64(size-1) * scale * math.MaxUint16))
+	}
+	return ramp
+}
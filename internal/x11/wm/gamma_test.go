@@ -0,0 +1,18 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package wm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGammaRamp(t *testing.T) {
+	ramp := gammaRamp(3, 1)
+	assert.Equal(t, []uint16{0, 32768, 65535}, ramp)
+
+	ramp = gammaRamp(3, 0.5)
+	assert.Equal(t, []uint16{0, 16384, 32768}, ramp)
+}
@@ -44,3 +44,33 @@ func TestStack_RemoveWindow(t *testing.T) {
 	assert.Equal(t, 0, len(stack.Windows()))
 
 }
+
+func TestX11WM_RemoveStackListener(t *testing.T) {
+	x := &x11WM{}
+	first, second := &countingListener{}, &countingListener{}
+	x.AddStackListener(first)
+	x.AddStackListener(second)
+
+	x.RemoveStackListener(first)
+	x.AddWindow(test.NewWindow(""))
+	assert.Equal(t, 0, first.added)
+	assert.Equal(t, 1, second.added)
+}
+
+// countingListener counts the windows that it is told were added.
+type countingListener struct {
+	added int
+}
+
+func (c *countingListener) WindowAdded(fynedesk.Window) {
+	c.added++
+}
+
+func (c *countingListener) WindowRemoved(fynedesk.Window) {
+}
+
+func (c *countingListener) WindowMoved(fynedesk.Window) {
+}
+
+func (c *countingListener) WindowOrderChanged() {
+}
@@ -0,0 +1,105 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package wm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgb/xtest"
+)
+
+// typingDelay gives apps time to read the keyboard mapping before each key is pressed, and after it is released.
+const typingDelay = time.Millisecond * 20
+
+const (
+	keysymReturn = 0xff0d
+	keysymTab    = 0xff09
+)
+
+// TypeText sends the text to the focused window as though it was typed on the keyboard.
+// Each character is mapped in turn to a keycode that has no keys assigned, so any character can be typed,
+// then the keyboard mapping is put back.
+func (x *x11WM) TypeText(text string) error {
+	conn := x.x.Conn()
+	if err := xtest.Init(conn); err != nil {
+		return err
+	}
+
+	setup := xproto.Setup(conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return err
+	}
+	code, ok := spareKeycode(mapping, setup.MinKeycode)
+	if !ok {
+		return errors.New("no spare keycode to type with")
+	}
+
+	perCode := mapping.KeysymsPerKeycode
+	defer func() {
+		time.Sleep(typingDelay)
+		empty := make([]xproto.Keysym, perCode)
+		_ = xproto.ChangeKeyboardMappingChecked(conn, 1, code, perCode, empty).Check()
+	}()
+
+	root := x.x.RootWin()
+	for _, r := range text {
+		syms := make([]xproto.Keysym, perCode)
+		for i := range syms {
+			syms[i] = keysymForRune(r)
+		}
+		if err = xproto.ChangeKeyboardMappingChecked(conn, 1, code, perCode, syms).Check(); err != nil {
+			return err
+		}
+		time.Sleep(typingDelay)
+
+		if err = xtest.FakeInputChecked(conn, xproto.KeyPress, byte(code), 0, root, 0, 0, 0).Check(); err != nil {
+			return err
+		}
+		if err = xtest.FakeInputChecked(conn, xproto.KeyRelease, byte(code), 0, root, 0, 0, 0).Check(); err != nil {
+			return err
+		}
+		time.Sleep(typingDelay)
+	}
+	return nil
+}
+
+// keysymForRune returns the X keysym that types a character.
+// Latin-1 characters have keysyms of the same value and all other Unicode characters are offset by 0x1000000.
+func keysymForRune(r rune) xproto.Keysym {
+	switch {
+	case r == '\n':
+		return keysymReturn
+	case r == '\t':
+		return keysymTab
+	case (r >= 0x20 && r <= 0x7e) || (r >= 0xa0 && r <= 0xff):
+		return xproto.Keysym(r)
+	}
+	return xproto.Keysym(0x1000000 | r)
+}
+
+// spareKeycode returns the last keycode in the mapping that has no keysyms, so it can be used for typing.
+func spareKeycode(mapping *xproto.GetKeyboardMappingReply, min xproto.Keycode) (xproto.Keycode, bool) {
+	perCode := int(mapping.KeysymsPerKeycode)
+	if perCode == 0 {
+		return 0, false
+	}
+
+	for i := len(mapping.Keysyms)/perCode - 1; i >= 0; i-- {
+		empty := true
+		for _, sym := range mapping.Keysyms[i*perCode : (i+1)*perCode] {
+			if sym != 0 {
+				empty = false
+				break
+			}
+		}
+		if empty {
+			return min + xproto.Keycode(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,31 @@
+//go:build linux || openbsd || freebsd || netbsd
+// +build linux openbsd freebsd netbsd
+
+package wm
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysymForRune(t *testing.T) {
+	assert.Equal(t, xproto.Keysym('a'), keysymForRune('a'))
+	assert.Equal(t, xproto.Keysym(0xe9), keysymForRune('é'))
+	assert.Equal(t, xproto.Keysym(0x10020ac), keysymForRune('€'))
+	assert.Equal(t, xproto.Keysym(0x101f604), keysymForRune('😄'))
+	assert.Equal(t, xproto.Keysym(keysymReturn), keysymForRune('\n'))
+}
+
+func TestSpareKeycode(t *testing.T) {
+	mapping := &xproto.GetKeyboardMappingReply{KeysymsPerKeycode: 2,
+		Keysyms: []xproto.Keysym{'a', 'A', 0, 0, 'b', 'B', 0, 0, 'c', 0}}
+	code, ok := spareKeycode(mapping, 8)
+	assert.True(t, ok)
+	assert.Equal(t, xproto.Keycode(11), code)
+
+	mapping.Keysyms = []xproto.Keysym{'a', 'A', 'b', 'B'}
+	_, ok = spareKeycode(mapping, 8)
+	assert.False(t, ok)
+}
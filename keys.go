@@ -20,6 +20,15 @@ const (
 	// KeyCalculator is available on some multimedia keyboards to open a calculator
 	KeyCalculator fyne.KeyName = "Calculator"
 
+	// KeyMediaNext is the virtual keyboard key for skipping to the next track
+	KeyMediaNext fyne.KeyName = "MediaNext"
+	// KeyMediaPlay is the virtual keyboard key for playing or pausing media
+	KeyMediaPlay fyne.KeyName = "MediaPlay"
+	// KeyMediaPrevious is the virtual keyboard key for returning to the previous track
+	KeyMediaPrevious fyne.KeyName = "MediaPrevious"
+	// KeyMediaStop is the virtual keyboard key for stopping media playback
+	KeyMediaStop fyne.KeyName = "MediaStop"
+
 	// KeyVolumeMute is the virtual keyboard key for muting sound
 	KeyVolumeMute fyne.KeyName = "VolumeMute"
 	// KeyVolumeDown is the virtual keyboard key for reducing sound level
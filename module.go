@@ -27,6 +27,27 @@ type LaunchSuggestion interface {
 	Launch()
 }
 
+// CopyLaunchSuggestion is a launch suggestion with text, such as a file path, that can be copied.
+// The launcher copies the text to the clipboard if the suggestion is picked with Alt-Return.
+type CopyLaunchSuggestion interface {
+	LaunchSuggestion
+	CopyText() string
+}
+
+// RevealLaunchSuggestion is a launch suggestion that can show where its item is, such as a file in a file manager.
+// The launcher calls Reveal if the suggestion is picked with Control-Return.
+type RevealLaunchSuggestion interface {
+	LaunchSuggestion
+	Reveal()
+}
+
+// ScoredLaunchSuggestion is a launch suggestion that reports how well it matches the input.
+// This is optional, suggestions that do not implement it are ranked as a strong match.
+type ScoredLaunchSuggestion interface {
+	LaunchSuggestion
+	Score() float64 // Score is between 0 for a poor match and 1 for an exact match
+}
+
 // LaunchSuggestionModule is a module that can provide suggestions for the app launcher
 type LaunchSuggestionModule interface {
 	Module
@@ -0,0 +1,243 @@
+package clipboard
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+
+	"fyshos.com/fynedesk"
+)
+
+var clipboardMeta = fynedesk.ModuleMetadata{
+	Name:        "Clipboard Manager",
+	NewInstance: newClipboard,
+}
+
+const (
+	historyFile    = "clipboard.json"
+	historySize    = 50
+	maxItemSize    = 16 * 1024 * 1024 // larger items are not kept, to limit memory and disk use
+	maxSuggestions = 10
+
+	// defaultExclude lists the window classes of password managers, whose content is never kept.
+	defaultExclude = "KeePassXC|Bitwarden|1Password|Enpass"
+)
+
+var suggestPrefixes = []string{"clipboard ", "clip "}
+
+// clipboardOwner is implemented by window managers that can watch and set the clipboard.
+type clipboardOwner interface {
+	SetClipboard(mime string, data []byte) error
+	WatchClipboard(changed func(mime string, data []byte, class string) bool) error // nil stops watching
+}
+
+type clipboard struct {
+	history *history
+	owner   clipboardOwner
+	picker  *picker
+
+	saveLock  sync.Mutex
+	destroyed bool // once the module is turned off nothing more is recorded or saved
+}
+
+func (c *clipboard) Destroy() {
+	if c.owner != nil {
+		if err := c.owner.WatchClipboard(nil); err != nil {
+			fyne.LogError("Unable to stop watching the clipboard", err)
+		}
+	}
+
+	c.saveLock.Lock()
+	c.destroyed = true
+	c.saveLock.Unlock()
+	if c.picker != nil {
+		c.picker.close()
+	}
+}
+
+func (c *clipboard) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
+	query := ""
+	found := false
+	for _, prefix := range suggestPrefixes {
+		if strings.HasPrefix(strings.ToLower(input), prefix) {
+			query = input[len(prefix):]
+			found = true
+			break
+		}
+	}
+	if !found || c.owner == nil {
+		return nil
+	}
+
+	var list []fynedesk.LaunchSuggestion
+	for _, e := range c.history.search(query) {
+		list = append(list, &clipItem{entry: e, clip: c})
+		if len(list) == maxSuggestions {
+			break
+		}
+	}
+	return list
+}
+
+func (c *clipboard) Metadata() fynedesk.ModuleMetadata {
+	return clipboardMeta
+}
+
+func (c *clipboard) Shortcuts() map[*fynedesk.Shortcut]func() {
+	return map[*fynedesk.Shortcut]func(){
+		fynedesk.NewShortcut("Show Clipboard History", fyne.KeyV, fynedesk.UserModifier): c.togglePicker,
+	}
+}
+
+// copied is called when an app copies something, returning false if it should not be kept.
+func (c *clipboard) copied(mime string, data []byte, class string) bool {
+	if c.isDestroyed() || len(data) > maxItemSize || isExcluded(class, excludedClasses()) {
+		return false
+	}
+
+	c.history.add(mime, data)
+	go c.save()
+	return true
+}
+
+func (c *clipboard) isDestroyed() bool {
+	c.saveLock.Lock()
+	defer c.saveLock.Unlock()
+
+	return c.destroyed
+}
+
+func (c *clipboard) load() {
+	r, err := fyne.CurrentApp().Storage().Open(historyFile)
+	if err != nil {
+		return // no history yet
+	}
+	defer r.Close()
+
+	if err = c.history.load(r); err != nil {
+		fyne.LogError("Failed to read clipboard history", err)
+	}
+}
+
+// paste makes a history entry the current clipboard content.
+func (c *clipboard) paste(e *entry) {
+	if err := c.owner.SetClipboard(e.Mime, e.Data); err != nil {
+		fyne.LogError("Failed to set clipboard", err)
+		return
+	}
+
+	c.history.add(e.Mime, e.Data)
+	go c.save()
+}
+
+func (c *clipboard) remove(e *entry) {
+	c.history.remove(e)
+	go c.save()
+}
+
+func (c *clipboard) save() {
+	c.saveLock.Lock()
+	defer c.saveLock.Unlock()
+	if c.destroyed {
+		return
+	}
+
+	store := fyne.CurrentApp().Storage()
+	w, err := store.Save(historyFile)
+	if errors.Is(err, storage.ErrNotExists) {
+		w, err = store.Create(historyFile)
+	}
+	if err != nil {
+		fyne.LogError("Failed to save clipboard history", err)
+		return
+	}
+	defer w.Close()
+
+	if err = c.history.save(w); err != nil {
+		fyne.LogError("Failed to save clipboard history", err)
+	}
+}
+
+func (c *clipboard) setPinned(e *entry, pinned bool) {
+	c.history.setPinned(e, pinned)
+	go c.save()
+}
+
+func (c *clipboard) togglePicker() {
+	if c.owner == nil {
+		return
+	}
+	if c.picker != nil {
+		c.picker.close()
+		return
+	}
+
+	c.picker = newPicker(c)
+	c.picker.win.SetOnClosed(func() {
+		c.picker = nil
+	})
+	c.picker.win.Show()
+}
+
+// newClipboard creates a new module that keeps a history of the clipboard
+func newClipboard() fynedesk.Module {
+	c := &clipboard{history: newHistory(historySize)}
+	if fynedesk.Instance() == nil {
+		return c
+	}
+	owner, ok := fynedesk.Instance().WindowManager().(clipboardOwner)
+	if !ok {
+		return c
+	}
+
+	c.load()
+	if err := owner.WatchClipboard(c.copied); err != nil {
+		fyne.LogError("Unable to watch the clipboard", err)
+		return c
+	}
+	c.owner = owner
+	return c
+}
+
+// excludedClasses returns the window classes of apps whose clipboard content should not be kept.
+func excludedClasses() []string {
+	list := fyne.CurrentApp().Preferences().StringWithFallback("clipboard.exclude", defaultExclude)
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, "|")
+}
+
+func isExcluded(class string, exclude []string) bool {
+	for _, item := range exclude {
+		if strings.EqualFold(strings.TrimSpace(item), class) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type clipItem struct {
+	entry *entry
+	clip  *clipboard
+}
+
+func (i *clipItem) Icon() fyne.Resource {
+	if i.entry.Mime == mimePNG {
+		return theme.FileImageIcon()
+	}
+	return theme.ContentPasteIcon()
+}
+
+func (i *clipItem) Title() string {
+	return i.entry.summary()
+}
+
+func (i *clipItem) Launch() {
+	i.clip.paste(i.entry)
+}
@@ -0,0 +1,71 @@
+package clipboard
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOwner struct {
+	mime    string
+	data    []byte
+	watched func(string, []byte, string) bool
+}
+
+func (o *mockOwner) SetClipboard(mime string, data []byte) error {
+	o.mime, o.data = mime, data
+	return nil
+}
+
+func (o *mockOwner) WatchClipboard(changed func(string, []byte, string) bool) error {
+	o.watched = changed
+	return nil
+}
+
+func TestClipboard_Copied(t *testing.T) {
+	test.NewApp()
+	c := &clipboard{history: newHistory(historySize), owner: &mockOwner{}}
+
+	assert.True(t, c.copied(mimeText, []byte("hello"), "XTerm"))
+	assert.False(t, c.copied(mimeText, []byte("secret"), "KeePassXC"))
+	assert.Equal(t, []string{"hello"}, texts(c.history.items()))
+}
+
+func TestClipboard_Destroy(t *testing.T) {
+	test.NewApp()
+	owner := &mockOwner{}
+	c := &clipboard{history: newHistory(historySize), owner: owner}
+	assert.Nil(t, owner.WatchClipboard(c.copied))
+
+	c.Destroy()
+	assert.Nil(t, owner.watched)
+	assert.False(t, c.copied(mimeText, []byte("after"), "XTerm"))
+	assert.Equal(t, 0, len(c.history.items()))
+}
+
+func TestClipboard_LaunchSuggestions(t *testing.T) {
+	test.NewApp()
+	owner := &mockOwner{}
+	c := &clipboard{history: newHistory(historySize), owner: owner}
+	c.history.add(mimeText, []byte("first item"))
+	c.history.add(mimeText, []byte("second item"))
+
+	assert.Nil(t, c.LaunchSuggestions("first"))
+	items := c.LaunchSuggestions("clip first")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "first item", items[0].Title())
+	assert.Equal(t, 2, len(c.LaunchSuggestions("Clipboard ")))
+
+	items[0].Launch()
+	assert.Equal(t, "first item", string(owner.data))
+	assert.Equal(t, []string{"first item", "second item"}, texts(c.history.items()))
+}
+
+func TestIsExcluded(t *testing.T) {
+	exclude := []string{"KeePassXC", " Bitwarden"}
+	assert.True(t, isExcluded("keepassxc", exclude))
+	assert.True(t, isExcluded("Bitwarden", exclude))
+	assert.False(t, isExcluded("Firefox", exclude))
+	assert.False(t, isExcluded("", nil))
+}
@@ -0,0 +1,152 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mimeText = "text/plain"
+	mimePNG  = "image/png"
+
+	maxSummary = 60
+)
+
+// entry is an item that was copied to the clipboard.
+type entry struct {
+	Mime   string    `json:"mime"`
+	Data   []byte    `json:"data"`
+	Pinned bool      `json:"pinned,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// summary returns a single line describing the entry, suitable for a list or launcher item.
+func (e *entry) summary() string {
+	if e.Mime == mimePNG {
+		if conf, err := png.DecodeConfig(bytes.NewReader(e.Data)); err == nil {
+			return fmt.Sprintf("Image %d×%d", conf.Width, conf.Height)
+		}
+		return "Image"
+	}
+
+	text := strings.Join(strings.Fields(string(e.Data)), " ")
+	if runes := []rune(text); len(runes) > maxSummary {
+		return string(runes[:maxSummary-1]) + "…"
+	}
+	return text
+}
+
+// history is the list of copied items, newest first.
+// Pinned items are never removed to make space for new ones.
+type history struct {
+	lock    sync.RWMutex
+	entries []*entry
+	max     int
+}
+
+func newHistory(max int) *history {
+	return &history{max: max}
+}
+
+// add moves content to the top of the history, creating a new entry if it was not already present.
+func (h *history) add(mime string, data []byte) *entry {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	e := &entry{Mime: mime, Data: data}
+	for i, old := range h.entries {
+		if old.Mime == mime && bytes.Equal(old.Data, data) {
+			e = old
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	e.Time = time.Now()
+	h.entries = append([]*entry{e}, h.entries...)
+
+	unpinned := 0
+	for i := 0; i < len(h.entries); i++ {
+		if h.entries[i].Pinned {
+			continue
+		}
+		unpinned++
+		if unpinned > h.max {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			i--
+		}
+	}
+	return e
+}
+
+func (h *history) items() []*entry {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return append([]*entry{}, h.entries...)
+}
+
+func (h *history) load(r io.Reader) error {
+	var entries []*entry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+
+	h.lock.Lock()
+	h.entries = entries
+	h.lock.Unlock()
+	return nil
+}
+
+func (h *history) remove(e *entry) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for i, old := range h.entries {
+		if old == e {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (h *history) save(w io.Writer) error {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return json.NewEncoder(w).Encode(h.entries)
+}
+
+// search returns the entries whose text contains the query, ignoring case, or all entries for an empty query.
+// Images match a search for "image".
+func (h *history) search(query string) []*entry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return h.items()
+	}
+
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	var matches []*entry
+	for _, e := range h.entries {
+		text := "image"
+		if e.Mime != mimePNG {
+			text = strings.ToLower(string(e.Data))
+		}
+		if strings.Contains(text, query) {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+func (h *history) setPinned(e *entry, pinned bool) {
+	h.lock.Lock()
+	e.Pinned = pinned
+	h.lock.Unlock()
+}
@@ -0,0 +1,88 @@
+package clipboard
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntry_Summary(t *testing.T) {
+	text := &entry{Mime: mimeText, Data: []byte("  hello\n\tworld  ")}
+	assert.Equal(t, "hello world", text.summary())
+
+	long := &entry{Mime: mimeText, Data: bytes.Repeat([]byte("a"), 100)}
+	assert.Equal(t, maxSummary, len([]rune(long.summary())))
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 4, 3))))
+	img := &entry{Mime: mimePNG, Data: buf.Bytes()}
+	assert.Equal(t, "Image 4×3", img.summary())
+}
+
+func TestHistory_Add(t *testing.T) {
+	h := newHistory(2)
+	one := h.add(mimeText, []byte("one"))
+	h.add(mimeText, []byte("two"))
+	assert.Equal(t, []string{"two", "one"}, texts(h.items()))
+
+	assert.Equal(t, one, h.add(mimeText, []byte("one")))
+	assert.Equal(t, []string{"one", "two"}, texts(h.items()))
+
+	h.add(mimeText, []byte("three"))
+	assert.Equal(t, []string{"three", "one"}, texts(h.items()))
+}
+
+func TestHistory_Pinned(t *testing.T) {
+	h := newHistory(1)
+	pinned := h.add(mimeText, []byte("keep"))
+	h.setPinned(pinned, true)
+	h.add(mimeText, []byte("two"))
+	h.add(mimeText, []byte("three"))
+	assert.Equal(t, []string{"three", "keep"}, texts(h.items()))
+
+	h.remove(pinned)
+	assert.Equal(t, []string{"three"}, texts(h.items()))
+}
+
+func TestHistory_SaveLoad(t *testing.T) {
+	h := newHistory(5)
+	h.add(mimePNG, []byte{1, 2, 3})
+	h.setPinned(h.add(mimeText, []byte("text")), true)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, h.save(buf))
+	loaded := newHistory(5)
+	assert.Nil(t, loaded.load(buf))
+
+	items := loaded.items()
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "text", string(items[0].Data))
+	assert.True(t, items[0].Pinned)
+	assert.Equal(t, mimePNG, items[1].Mime)
+	assert.Equal(t, []byte{1, 2, 3}, items[1].Data)
+}
+
+func TestHistory_Search(t *testing.T) {
+	h := newHistory(5)
+	h.add(mimeText, []byte("Hello World"))
+	h.add(mimePNG, []byte{1})
+	h.add(mimeText, []byte("goodbye"))
+
+	assert.Equal(t, 3, len(h.search(" ")))
+	assert.Equal(t, []string{"Hello World"}, texts(h.search("world")))
+	matches := h.search("image")
+	assert.Equal(t, 1, len(matches))
+	assert.Equal(t, mimePNG, matches[0].Mime)
+	assert.Equal(t, 0, len(h.search("missing")))
+}
+
+func texts(entries []*entry) []string {
+	var list []string
+	for _, e := range entries {
+		list = append(list, string(e.Data))
+	}
+	return list
+}
@@ -0,0 +1,7 @@
+package clipboard
+
+import "fyshos.com/fynedesk"
+
+func init() {
+	fynedesk.RegisterModule(clipboardMeta)
+}
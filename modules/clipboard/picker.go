@@ -0,0 +1,149 @@
+package clipboard
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	deskDriver "fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk/internal/ui"
+	wmTheme "fyshos.com/fynedesk/theme"
+)
+
+const pickerTitle = "Clipboard History " + ui.SkipTaskbarHint
+
+type pickerEntry struct {
+	widget.Entry
+
+	pick *picker
+}
+
+func (e *pickerEntry) TypedKey(ev *fyne.KeyEvent) {
+	switch ev.Name {
+	case fyne.KeyEscape:
+		e.pick.close()
+	case fyne.KeyReturn:
+		e.pick.pickSelected()
+	case fyne.KeyUp:
+		e.pick.setActiveIndex(e.pick.activeIndex - 1)
+	case fyne.KeyDown:
+		e.pick.setActiveIndex(e.pick.activeIndex + 1)
+	default:
+		e.Entry.TypedKey(ev)
+	}
+}
+
+// picker is a popup window that lists the clipboard history so that an item can be copied again.
+type picker struct {
+	clip *clipboard
+	win  fyne.Window
+
+	entry       *pickerEntry
+	list        *fyne.Container
+	buttons     []*widget.Button
+	activeIndex int
+}
+
+func (p *picker) close() {
+	p.win.Close()
+}
+
+func (p *picker) pickSelected() {
+	if len(p.buttons) == 0 {
+		return
+	}
+
+	p.buttons[p.activeIndex].OnTapped()
+}
+
+func (p *picker) row(e *entry) (fyne.CanvasObject, *widget.Button) {
+	icon := theme.ContentPasteIcon()
+	if e.Mime == mimePNG {
+		icon = fyne.NewStaticResource("clipboard.png", e.Data)
+	}
+	item := widget.NewButtonWithIcon(e.summary(), icon, func() {
+		p.close()
+		p.clip.paste(e)
+	})
+	item.Alignment = widget.ButtonAlignLeading
+
+	pin := &widget.Button{Icon: wmTheme.PinIcon, Importance: widget.LowImportance}
+	if e.Pinned {
+		pin.Importance = widget.HighImportance
+	}
+	pin.OnTapped = func() {
+		p.clip.setPinned(e, !e.Pinned)
+		p.update(p.entry.Text)
+	}
+	remove := &widget.Button{Icon: theme.DeleteIcon(), Importance: widget.LowImportance, OnTapped: func() {
+		p.clip.remove(e)
+		p.update(p.entry.Text)
+	}}
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(pin, remove), item), item
+}
+
+func (p *picker) setActiveIndex(index int) {
+	if index < 0 || index >= len(p.buttons) {
+		return
+	}
+
+	p.buttons[p.activeIndex].Importance = widget.MediumImportance
+	p.buttons[index].Importance = widget.HighImportance
+	p.activeIndex = index
+	p.list.Refresh()
+}
+
+func (p *picker) update(query string) {
+	p.activeIndex = 0
+	p.buttons = nil
+	p.list.Objects = nil
+	for _, e := range p.clip.history.search(query) {
+		row, button := p.row(e)
+		p.list.Objects = append(p.list.Objects, row)
+		p.buttons = append(p.buttons, button)
+	}
+
+	if len(p.buttons) == 0 {
+		empty := "Clipboard history is empty"
+		if query != "" {
+			empty = "No matching items"
+		}
+		p.list.Objects = []fyne.CanvasObject{widget.NewLabelWithStyle(empty, fyne.TextAlignCenter, fyne.TextStyle{})}
+	} else {
+		p.buttons[0].Importance = widget.HighImportance
+	}
+	p.list.Refresh()
+}
+
+func newPicker(c *clipboard) *picker {
+	var win fyne.Window
+	if d, ok := fyne.CurrentApp().Driver().(deskDriver.Driver); ok {
+		win = d.CreateSplashWindow()
+		win.SetPadded(true)
+		win.SetTitle(pickerTitle)
+	} else {
+		win = fyne.CurrentApp().NewWindow(pickerTitle)
+	}
+
+	p := &picker{clip: c, win: win, list: container.NewVBox()}
+	win.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		if ev.Name == fyne.KeyEscape {
+			p.close()
+		}
+	})
+
+	p.entry = &pickerEntry{pick: p}
+	p.entry.ExtendBaseWidget(p.entry)
+	p.entry.SetPlaceHolder("Search clipboard history")
+	p.entry.OnChanged = p.update
+	p.update("")
+
+	cancel := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), p.close)
+	win.SetContent(container.NewBorder(p.entry, cancel, nil, nil, container.NewVScroll(p.list)))
+	win.Resize(fyne.NewSize(360, 400))
+	win.CenterOnScreen()
+	win.Canvas().Focus(p.entry)
+	return p
+}
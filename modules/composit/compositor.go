@@ -0,0 +1,63 @@
+package composit
+
+import (
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+)
+
+var compositorMeta = fynedesk.ModuleMetadata{
+	Name:        "Compositor",
+	NewInstance: newCompositor,
+}
+
+// compositor is implemented by window managers that can composite windows themselves.
+type compositor interface {
+	StartCompositing() error
+	StopCompositing()
+}
+
+type comp struct {
+}
+
+func (c *comp) Destroy() {
+	c.disable()
+}
+
+func (c *comp) Metadata() fynedesk.ModuleMetadata {
+	return compositorMeta
+}
+
+func (c *comp) disable() {
+	if wm, ok := c.windowManager(); ok {
+		wm.StopCompositing()
+	}
+}
+
+func (c *comp) enable() {
+	wm, ok := c.windowManager()
+	if !ok {
+		return
+	}
+
+	if err := wm.StartCompositing(); err != nil {
+		fyne.LogError("Could not start the compositor", err)
+	}
+}
+
+func (c *comp) windowManager() (compositor, bool) {
+	desk := fynedesk.Instance()
+	if desk == nil || desk.WindowManager() == nil {
+		return nil, false
+	}
+
+	wm, ok := desk.WindowManager().(compositor)
+	return wm, ok
+}
+
+// newCompositor creates a new module that will draw windows with shadows, transparency and fades.
+func newCompositor() fynedesk.Module {
+	c := &comp{}
+	c.enable()
+	return c
+}
@@ -3,5 +3,5 @@ package composit
 import "fyshos.com/fynedesk"
 
 func init() {
-	fynedesk.RegisterModule(compizMeta)
+	fynedesk.RegisterModule(compositorMeta)
 }
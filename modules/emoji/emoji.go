@@ -0,0 +1,198 @@
+package emoji
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+	wmTheme "fyshos.com/fynedesk/theme"
+)
+
+var emojiMeta = fynedesk.ModuleMetadata{
+	Name:        "Emoji Picker",
+	NewInstance: newEmojiPicker,
+}
+
+const (
+	// launcherPrefix starts launcher input that searches for emoji, such as ":smile".
+	launcherPrefix = ":"
+
+	maxSuggestions = 8
+	maxResults     = 60
+	maxRecent      = 24
+
+	// recentPreference lists the most recently used characters, newest first, as the glyph and name separated by a tab.
+	recentPreference = "emoji.recent"
+	// tonePreference is the skin tone to use, from 0 for the default yellow to 5 for the darkest.
+	tonePreference = "emoji.skintone"
+
+	// typeDelay allows the launcher or picker to close, so that the window the user was in has focus again.
+	typeDelay = time.Second / 4
+)
+
+// textTyper is implemented by window managers that can send text to the focused window.
+type textTyper interface {
+	TypeText(text string) error
+}
+
+// recentItem is a character that was recently picked, including its skin tone.
+type recentItem struct {
+	glyph, name string
+}
+
+type emojiPicker struct {
+	list   []*emoji
+	picker *picker
+	typer  textTyper
+}
+
+func (e *emojiPicker) Destroy() {
+	if e.picker != nil {
+		e.picker.close()
+	}
+}
+
+func (e *emojiPicker) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
+	if !strings.HasPrefix(input, launcherPrefix) {
+		return nil
+	}
+	query := strings.TrimSpace(input[len(launcherPrefix):])
+
+	var list []fynedesk.LaunchSuggestion
+	if query == "" {
+		for _, item := range e.recent() {
+			list = append(list, &emojiResult{glyph: item.glyph, name: item.name, picker: e})
+			if len(list) == maxSuggestions {
+				break
+			}
+		}
+		return list
+	}
+
+	tone := e.tone()
+	for _, match := range search(e.list, query, maxSuggestions) {
+		list = append(list, &emojiResult{glyph: match.withTone(tone), name: match.name, picker: e})
+	}
+	return list
+}
+
+func (e *emojiPicker) Metadata() fynedesk.ModuleMetadata {
+	return emojiMeta
+}
+
+func (e *emojiPicker) Shortcuts() map[*fynedesk.Shortcut]func() {
+	return map[*fynedesk.Shortcut]func(){
+		fynedesk.NewShortcut("Show Emoji Picker", fyne.KeyPeriod, fynedesk.UserModifier): e.togglePicker,
+	}
+}
+
+// copy puts the glyph on the clipboard so that it can be pasted.
+func (e *emojiPicker) copy(glyph, name string) {
+	e.remember(glyph, name)
+	setClipboard(glyph)
+}
+
+// insert types the glyph into the focused window, or copies it if the window manager cannot type.
+func (e *emojiPicker) insert(glyph, name string) {
+	if e.typer == nil {
+		e.copy(glyph, name)
+		return
+	}
+
+	e.remember(glyph, name)
+	go func() {
+		time.Sleep(typeDelay)
+		if err := e.typer.TypeText(glyph); err != nil {
+			fyne.LogError("Unable to type emoji, copying instead", err)
+			setClipboard(glyph)
+		}
+	}()
+}
+
+// recent returns the characters that were picked most recently, newest first.
+func (e *emojiPicker) recent() []recentItem {
+	var list []recentItem
+	for _, line := range fyne.CurrentApp().Preferences().StringList(recentPreference) {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) == 2 {
+			list = append(list, recentItem{glyph: parts[0], name: parts[1]})
+		}
+	}
+	return list
+}
+
+// remember moves a character to the top of the recently used list.
+func (e *emojiPicker) remember(glyph, name string) {
+	lines := []string{glyph + "\t" + name}
+	for _, item := range e.recent() {
+		if item.glyph != glyph && len(lines) < maxRecent {
+			lines = append(lines, item.glyph+"\t"+item.name)
+		}
+	}
+	fyne.CurrentApp().Preferences().SetStringList(recentPreference, lines)
+}
+
+func (e *emojiPicker) setTone(tone int) {
+	fyne.CurrentApp().Preferences().SetInt(tonePreference, tone)
+}
+
+func (e *emojiPicker) tone() int {
+	return fyne.CurrentApp().Preferences().Int(tonePreference)
+}
+
+func (e *emojiPicker) togglePicker() {
+	if e.picker != nil {
+		e.picker.close()
+		return
+	}
+
+	e.picker = newPicker(e)
+	e.picker.win.SetOnClosed(func() {
+		e.picker = nil
+	})
+	e.picker.win.Show()
+}
+
+// newEmojiPicker creates a new module that finds emoji and special characters to type or copy
+func newEmojiPicker() fynedesk.Module {
+	e := &emojiPicker{list: parseTable(annotations)}
+	if fynedesk.Instance() == nil {
+		return e
+	}
+
+	if typer, ok := fynedesk.Instance().WindowManager().(textTyper); ok {
+		e.typer = typer
+	}
+	return e
+}
+
+func setClipboard(text string) {
+	windows := fyne.CurrentApp().Driver().AllWindows()
+	if len(windows) == 0 {
+		return
+	}
+	windows[0].Clipboard().SetContent(text)
+}
+
+type emojiResult struct {
+	glyph, name string
+	picker      *emojiPicker
+}
+
+func (r *emojiResult) CopyText() string {
+	return r.glyph
+}
+
+func (r *emojiResult) Icon() fyne.Resource {
+	return wmTheme.KeyboardIcon
+}
+
+func (r *emojiResult) Launch() {
+	r.picker.insert(r.glyph, r.name)
+}
+
+func (r *emojiResult) Title() string {
+	return r.glyph + "  " + r.name
+}
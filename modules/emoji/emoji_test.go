@@ -0,0 +1,50 @@
+package emoji
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTyper struct {
+	typed chan string
+}
+
+func (m *mockTyper) TypeText(text string) error {
+	m.typed <- text
+	return nil
+}
+
+func TestEmojiPicker_LaunchSuggestions(t *testing.T) {
+	test.NewApp()
+	e := &emojiPicker{list: parseTable(annotations)}
+
+	assert.Nil(t, e.LaunchSuggestions("smile"))
+	items := e.LaunchSuggestions(":smile")
+	assert.NotEmpty(t, items)
+	assert.LessOrEqual(t, len(items), maxSuggestions)
+
+	e.setTone(2)
+	items = e.LaunchSuggestions(":thumbs up")
+	assert.Equal(t, "👍🏼  thumbs up", items[0].Title())
+	assert.Equal(t, "👍🏼", items[0].(*emojiResult).CopyText())
+}
+
+func TestEmojiPicker_Recent(t *testing.T) {
+	test.NewApp()
+	typer := &mockTyper{typed: make(chan string, 1)}
+	e := &emojiPicker{list: parseTable(annotations), typer: typer}
+	assert.Empty(t, e.LaunchSuggestions(":"))
+
+	e.copy("😄", "grinning face with smiling eyes")
+	e.LaunchSuggestions(":red heart")[0].Launch()
+	assert.Equal(t, "❤️", <-typer.typed)
+
+	items := e.LaunchSuggestions(": ")
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "❤️  red heart", items[0].Title())
+
+	e.copy("😄", "grinning face with smiling eyes")
+	assert.Equal(t, []recentItem{{"😄", "grinning face with smiling eyes"}, {"❤️", "red heart"}}, e.recent())
+}
@@ -0,0 +1,356 @@
+//go:build ignore
+// +build ignore
+
+// This program builds annotations.txt, the table of emoji and special characters, from the Unicode emoji list
+// and character database with keywords from the English CLDR annotations. Run it using "go generate".
+// The source files are downloaded, unless they are found in the folder passed using the -data flag.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	emojiVersion = "15.1"
+	cldrVersion  = "44"
+
+	variationSelector = '\uFE0F'
+)
+
+// sources are the files that the table is built from, by the name they are looked for in the data folder.
+var sources = map[string]string{
+	"emoji-test.txt":  "https://unicode.org/Public/emoji/" + emojiVersion + "/emoji-test.txt",
+	"UnicodeData.txt": "https://unicode.org/Public/" + emojiVersion + ".0/ucd/UnicodeData.txt",
+	"en.xml": "https://raw.githubusercontent.com/unicode-org/cldr/release-" + cldrVersion +
+		"/common/annotations/en.xml",
+	"en-derived.xml": "https://raw.githubusercontent.com/unicode-org/cldr/release-" + cldrVersion +
+		"/common/annotationsDerived/en.xml",
+}
+
+// specials are the characters that are not emoji but are listed in the picker, with the keywords for each group.
+var specials = []struct {
+	keywords []string
+	ranges   [][2]rune
+}{
+	{[]string{"punctuation", "symbol"}, [][2]rune{{0x2010, 0x2027}, {0x2030, 0x203E}, {0xA1, 0xA1}, {0xA7, 0xA7},
+		{0xB6, 0xB6}, {0xBF, 0xBF}, {0xAB, 0xAB}, {0xBB, 0xBB}}},
+	{[]string{"currency", "symbol"}, [][2]rune{{0xA2, 0xA3}, {0xA5, 0xA5}, {0x20A0, 0x20C0}}},
+	{[]string{"maths", "symbol"}, [][2]rune{{0xB0, 0xB1}, {0xB5, 0xB5}, {0xD7, 0xD7}, {0xF7, 0xF7}, {0xAC, 0xAC},
+		{0xB2, 0xB3}, {0xB9, 0xB9}, {0xBC, 0xBE}, {0x2150, 0x215E}, {0x2070, 0x209C}, {0x2200, 0x226F}}},
+	{[]string{"arrow", "symbol"}, [][2]rune{{0x2190, 0x21FE}}},
+	{[]string{"greek", "letter", "symbol"}, [][2]rune{{0x391, 0x3A9}, {0x3B1, 0x3C9}}},
+	{[]string{"letterlike", "symbol"}, [][2]rune{{0x2103, 0x2103}, {0x2109, 0x2109}, {0x2116, 0x2117},
+		{0x2120, 0x2120}, {0x2126, 0x2126}, {0x212E, 0x212E}, {0x2113, 0x2113}, {0x2118, 0x2118},
+		{0x211D, 0x211D}, {0x2124, 0x2124}, {0x2115, 0x2115}, {0x211A, 0x211A}, {0x2102, 0x2102},
+		{0x210F, 0x210F}}},
+	{[]string{"shape", "symbol"}, [][2]rune{{0x25A0, 0x25FA}, {0x2605, 0x2606}}},
+}
+
+// annotation is a CLDR name or keyword list for a character sequence.
+type annotation struct {
+	CP   string `xml:"cp,attr"`
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// row is a line of the generated table.
+type row struct {
+	glyph, name string
+	tones       []int
+	keywords    []string
+}
+
+func main() {
+	data := flag.String("data", "", "folder containing the source files, instead of downloading them")
+	out := flag.String("o", "annotations.txt", "the table file to write")
+	flag.Parse()
+
+	names, keywords := map[string]string{}, map[string][]string{}
+	for _, file := range []string{"en.xml", "en-derived.xml"} {
+		if err := parseAnnotations(readSource(*data, file), names, keywords); err != nil {
+			log.Fatalln("Failed to parse", file, err)
+		}
+	}
+
+	rows, err := parseEmoji(readSource(*data, "emoji-test.txt"))
+	if err != nil {
+		log.Fatalln("Failed to parse emoji-test.txt", err)
+	}
+	missing := 0
+	for _, r := range rows {
+		key := annotationKey(r.glyph)
+		if name, ok := names[key]; ok {
+			r.name = name
+		}
+		r.keywords = withoutName(keywords[key], r.name)
+		if len(r.keywords) == 0 {
+			missing++
+		}
+	}
+	if missing > 0 {
+		log.Println("No CLDR keywords for", missing, "emoji")
+	}
+
+	chars, err := parseUnicodeData(readSource(*data, "UnicodeData.txt"))
+	if err != nil {
+		log.Fatalln("Failed to parse UnicodeData.txt", err)
+	}
+	rows = append(rows, specialRows(rows, chars, keywords)...)
+
+	if err = os.WriteFile(*out, formatTable(rows), 0644); err != nil {
+		log.Fatalln("Failed to write table", err)
+	}
+}
+
+// annotationKey returns the form of a glyph that CLDR uses, which leaves out emoji variation selectors.
+func annotationKey(glyph string) string {
+	return strings.ReplaceAll(glyph, string(variationSelector), "")
+}
+
+func formatTable(rows []*row) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "# Emoji and special characters for the emoji picker, one per line as:")
+	fmt.Fprintln(buf, "# glyph <tab> rune positions that take a skin tone <tab> CLDR short name <tab> keywords separated by \"|\"")
+	fmt.Fprintf(buf, "# Generated by gen.go from Unicode emoji-test.txt and UnicodeData.txt version %s, with keywords from\n",
+		emojiVersion)
+	fmt.Fprintf(buf, "# the CLDR %s English annotations. Skin tone variants are made by adding a modifier after each listed\n",
+		cldrVersion)
+	fmt.Fprintln(buf, "# rune, replacing any variation selector that follows it. Special characters use their Unicode names.")
+	fmt.Fprintln(buf, "# DO NOT EDIT.")
+
+	for _, r := range rows {
+		tones := make([]string, len(r.tones))
+		for i, pos := range r.tones {
+			tones[i] = strconv.Itoa(pos)
+		}
+		fmt.Fprintf(buf, "%s\t%s\t%s\t%s\n", r.glyph, strings.Join(tones, ","), r.name, strings.Join(r.keywords, "|"))
+	}
+	return buf.Bytes()
+}
+
+// isSkinTone returns true for the modifiers that change the skin tone of the emoji before them.
+func isSkinTone(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// parseAnnotations adds the names and keywords from a CLDR annotations file to the maps passed.
+func parseAnnotations(data []byte, names map[string]string, keywords map[string][]string) error {
+	var ldml struct {
+		Annotations []annotation `xml:"annotations>annotation"`
+	}
+	if err := xml.Unmarshal(data, &ldml); err != nil {
+		return err
+	}
+
+	for _, a := range ldml.Annotations {
+		text := strings.TrimSpace(a.Text)
+		if a.Type == "tts" {
+			names[a.CP] = text
+			continue
+		}
+
+		for _, word := range strings.Split(text, "|") {
+			if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+				keywords[a.CP] = append(keywords[a.CP], word)
+			}
+		}
+	}
+	return nil
+}
+
+// parseEmoji returns the fully qualified emoji, apart from components and skin tone variants, in CLDR order.
+// The positions that take a skin tone are found from the variants that use a single tone.
+func parseEmoji(data []byte) ([]*row, error) {
+	var rows []*row
+	byKey := make(map[string]*row)
+	group := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# group:") {
+			group = strings.TrimSpace(strings.TrimPrefix(line, "# group:"))
+			continue
+		}
+		if line == "" || line[0] == '#' || group == "Component" {
+			continue
+		}
+
+		fields := strings.SplitN(line, ";", 2)
+		if len(fields) != 2 || !strings.HasPrefix(strings.TrimSpace(fields[1]), "fully-qualified") {
+			continue
+		}
+		var runes []rune
+		for _, code := range strings.Fields(fields[0]) {
+			r, err := strconv.ParseUint(code, 16, 32)
+			if err != nil {
+				return nil, err
+			}
+			runes = append(runes, rune(r))
+		}
+
+		if base, positions, mixed := tonePositions(runes); len(positions) > 0 {
+			if r := byKey[annotationKey(string(base))]; r != nil && r.tones == nil && !mixed {
+				r.tones = baseTonePositions([]rune(r.glyph), positions)
+			}
+			continue
+		}
+		r := &row{glyph: string(runes), name: emojiName(fields[1])}
+		rows = append(rows, r)
+		byKey[annotationKey(r.glyph)] = r
+	}
+	return rows, scanner.Err()
+}
+
+// emojiName returns the name from the comment of an emoji-test.txt line, after the glyph and version.
+func emojiName(comment string) string {
+	parts := strings.SplitN(comment, "#", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	words := strings.Fields(parts[1])
+	if len(words) < 3 {
+		return ""
+	}
+	return strings.Join(words[2:], " ")
+}
+
+// tonePositions returns an emoji without its skin tones, and the positions in it that took a tone,
+// ignoring variation selectors. If the emoji uses more than one tone then mixed is true.
+func tonePositions(runes []rune) (base []rune, positions []int, mixed bool) {
+	var tone rune
+	count := 0
+	for _, r := range runes {
+		switch {
+		case isSkinTone(r):
+			mixed = mixed || (tone != 0 && r != tone)
+			tone = r
+			positions = append(positions, count-1)
+		case r == variationSelector:
+			base = append(base, r)
+		default:
+			base = append(base, r)
+			count++
+		}
+	}
+	return base, positions, mixed
+}
+
+// baseTonePositions converts positions that ignore variation selectors into rune positions of the glyph.
+func baseTonePositions(glyph []rune, positions []int) []int {
+	var ret []int
+	count := 0
+	for i, r := range glyph {
+		if r == variationSelector {
+			continue
+		}
+		for _, pos := range positions {
+			if pos == count {
+				ret = append(ret, i)
+			}
+		}
+		count++
+	}
+	return ret
+}
+
+// parseUnicodeData returns the lower case name of each assigned character.
+func parseUnicodeData(data []byte) (map[rune]string, error) {
+	chars := make(map[rune]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ";")
+		if len(fields) < 2 || strings.HasPrefix(fields[1], "<") {
+			continue // ranges and control characters have no name
+		}
+
+		r, err := strconv.ParseUint(fields[0], 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		chars[rune(r)] = strings.ToLower(fields[1])
+	}
+	return chars, scanner.Err()
+}
+
+// readSource returns the contents of a source file from the data folder, or downloads it.
+func readSource(dir, name string) []byte {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return data
+		}
+		log.Println("Downloading", name, "as it could not be read:", err)
+	}
+
+	res, err := http.Get(sources[name])
+	if err != nil {
+		log.Fatalln("Failed to download", name, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		log.Fatalln("Failed to download", name, res.Status)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		log.Fatalln("Failed to download", name, err)
+	}
+	return data
+}
+
+// specialRows lists the special characters with their Unicode names, skipping those that are emoji.
+func specialRows(emoji []*row, chars map[rune]string, keywords map[string][]string) []*row {
+	isEmoji := make(map[string]bool, len(emoji))
+	for _, r := range emoji {
+		isEmoji[annotationKey(r.glyph)] = true
+	}
+
+	var rows []*row
+	for _, special := range specials {
+		for _, span := range special.ranges {
+			for c := span[0]; c <= span[1]; c++ {
+				name, ok := chars[c]
+				if !ok || isEmoji[string(c)] {
+					continue
+				}
+
+				words := append(append([]string{}, special.keywords...), withoutName(keywords[string(c)], name)...)
+				rows = append(rows, &row{glyph: string(c), name: name, keywords: unique(words)})
+			}
+		}
+	}
+	return rows
+}
+
+func unique(list []string) []string {
+	var ret []string
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		if !seen[item] {
+			seen[item] = true
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+// withoutName returns the keywords apart from one that is the same as the name, as names are searched anyway.
+func withoutName(keywords []string, name string) []string {
+	var ret []string
+	for _, word := range keywords {
+		if word != strings.ToLower(name) {
+			ret = append(ret, word)
+		}
+	}
+	return unique(ret)
+}
@@ -0,0 +1,7 @@
+package emoji
+
+import "fyshos.com/fynedesk"
+
+func init() {
+	fynedesk.RegisterModule(emojiMeta)
+}
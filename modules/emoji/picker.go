@@ -0,0 +1,194 @@
+package emoji
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	deskDriver "fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk/internal/ui"
+)
+
+const pickerTitle = "Emoji " + ui.SkipTaskbarHint
+
+type pickerEntry struct {
+	widget.Entry
+
+	pick *picker
+}
+
+func (e *pickerEntry) TypedKey(ev *fyne.KeyEvent) {
+	switch ev.Name {
+	case fyne.KeyEscape:
+		e.pick.close()
+	case fyne.KeyReturn, fyne.KeyEnter:
+		e.pick.pickSelected()
+	case fyne.KeyUp:
+		e.pick.setActiveIndex(e.pick.activeIndex - 1)
+	case fyne.KeyDown:
+		e.pick.setActiveIndex(e.pick.activeIndex + 1)
+	default:
+		e.Entry.TypedKey(ev)
+	}
+}
+
+// TypedShortcut copies the selected character when Alt-Return is pressed, instead of typing it.
+func (e *pickerEntry) TypedShortcut(s fyne.Shortcut) {
+	if key, ok := s.(*deskDriver.CustomShortcut); ok && key.Modifier == fyne.KeyModifierAlt &&
+		(key.KeyName == fyne.KeyReturn || key.KeyName == fyne.KeyEnter) {
+		e.pick.copySelected()
+		return
+	}
+
+	e.Entry.TypedShortcut(s)
+}
+
+// picker is a popup window to search for a character, with recently used ones listed first.
+type picker struct {
+	emoji *emojiPicker
+	win   fyne.Window
+
+	entry       *pickerEntry
+	list        *fyne.Container
+	tones       []*widget.Button
+	items       []recentItem
+	buttons     []*widget.Button
+	activeIndex int
+}
+
+func (p *picker) close() {
+	p.win.Close()
+}
+
+func (p *picker) copySelected() {
+	if len(p.items) == 0 {
+		return
+	}
+
+	item := p.items[p.activeIndex]
+	p.close()
+	p.emoji.copy(item.glyph, item.name)
+}
+
+func (p *picker) pickSelected() {
+	if len(p.buttons) == 0 {
+		return
+	}
+
+	p.buttons[p.activeIndex].OnTapped()
+}
+
+func (p *picker) row(item recentItem) (fyne.CanvasObject, *widget.Button) {
+	pick := widget.NewButton(item.glyph+"  "+item.name, func() {
+		p.close()
+		p.emoji.insert(item.glyph, item.name)
+	})
+	pick.Alignment = widget.ButtonAlignLeading
+
+	cp := &widget.Button{Icon: theme.ContentCopyIcon(), Importance: widget.LowImportance, OnTapped: func() {
+		p.close()
+		p.emoji.copy(item.glyph, item.name)
+	}}
+	return container.NewBorder(nil, nil, nil, cp, pick), pick
+}
+
+func (p *picker) setActiveIndex(index int) {
+	if index < 0 || index >= len(p.buttons) {
+		return
+	}
+
+	p.buttons[p.activeIndex].Importance = widget.MediumImportance
+	p.buttons[index].Importance = widget.HighImportance
+	p.activeIndex = index
+	p.list.Refresh()
+}
+
+func (p *picker) setTone(tone int) {
+	p.emoji.setTone(tone)
+	for i, b := range p.tones {
+		b.Importance = widget.LowImportance
+		if i == tone {
+			b.Importance = widget.HighImportance
+		}
+		b.Refresh()
+	}
+	p.update(p.entry.Text)
+}
+
+func (p *picker) update(query string) {
+	p.items = nil
+	if query == "" {
+		p.items = p.emoji.recent()
+	} else {
+		tone := p.emoji.tone()
+		for _, e := range search(p.emoji.list, query, maxResults) {
+			p.items = append(p.items, recentItem{glyph: e.withTone(tone), name: e.name})
+		}
+	}
+
+	p.activeIndex = 0
+	p.buttons = nil
+	p.list.Objects = nil
+	for _, item := range p.items {
+		row, button := p.row(item)
+		p.list.Objects = append(p.list.Objects, row)
+		p.buttons = append(p.buttons, button)
+	}
+
+	if len(p.buttons) == 0 {
+		empty := "Type to search for emoji and symbols"
+		if query != "" {
+			empty = "No matching characters"
+		}
+		p.list.Objects = []fyne.CanvasObject{widget.NewLabelWithStyle(empty, fyne.TextAlignCenter, fyne.TextStyle{})}
+	} else {
+		p.buttons[0].Importance = widget.HighImportance
+	}
+	p.list.Refresh()
+}
+
+func newPicker(e *emojiPicker) *picker {
+	var win fyne.Window
+	if d, ok := fyne.CurrentApp().Driver().(deskDriver.Driver); ok {
+		win = d.CreateSplashWindow()
+		win.SetPadded(true)
+		win.SetTitle(pickerTitle)
+	} else {
+		win = fyne.CurrentApp().NewWindow(pickerTitle)
+	}
+
+	p := &picker{emoji: e, win: win, list: container.NewVBox()}
+	win.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		if ev.Name == fyne.KeyEscape {
+			p.close()
+		}
+	})
+
+	p.entry = &pickerEntry{pick: p}
+	p.entry.ExtendBaseWidget(p.entry)
+	p.entry.SetPlaceHolder("Search emoji and symbols")
+	p.entry.OnChanged = p.update
+
+	tones := container.NewHBox(widget.NewLabel("Skin tone"))
+	for i, sample := range toneSamples {
+		tone := i
+		b := &widget.Button{Text: sample, Importance: widget.LowImportance, OnTapped: func() {
+			p.setTone(tone)
+		}}
+		if tone == e.tone() {
+			b.Importance = widget.HighImportance
+		}
+		p.tones = append(p.tones, b)
+		tones.Add(b)
+	}
+	p.update("")
+
+	cancel := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), p.close)
+	win.SetContent(container.NewBorder(container.NewVBox(p.entry, tones), cancel, nil, nil,
+		container.NewVScroll(p.list)))
+	win.Resize(fyne.NewSize(400, 440))
+	win.CenterOnScreen()
+	win.Canvas().Focus(p.entry)
+	return p
+}
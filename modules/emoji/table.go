@@ -0,0 +1,170 @@
+package emoji
+
+import (
+	_ "embed" // for the annotations table
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:generate go run gen.go
+
+//go:embed annotations.txt
+var annotations string
+
+// skinTones are the modifiers that change the skin tone of people and body parts, lightest first.
+var skinTones = []rune{0x1F3FB, 0x1F3FC, 0x1F3FD, 0x1F3FE, 0x1F3FF}
+
+// toneSamples show each skin tone in the picker, starting with the default yellow.
+var toneSamples = []string{"\u270B", "\u270B\U0001F3FB", "\u270B\U0001F3FC", "\u270B\U0001F3FD",
+	"\u270B\U0001F3FE", "\u270B\U0001F3FF"}
+
+// variationSelector asks for a character to be shown as a colourful emoji.
+const variationSelector = '\uFE0F'
+
+// emoji is a character, or sequence of characters, that can be picked.
+type emoji struct {
+	glyph    string
+	name     string
+	keywords []string
+	tones    []int // the rune positions that take a skin tone modifier
+
+	nameWords []string
+}
+
+// withTone returns the glyph with a skin tone, from 1 for the lightest to 5 for the darkest.
+// Tone 0, or an emoji that has no skin, returns the plain glyph.
+func (e *emoji) withTone(tone int) string {
+	if tone <= 0 || tone > len(skinTones) || len(e.tones) == 0 {
+		return e.glyph
+	}
+
+	runes := []rune(e.glyph)
+	var toned []rune
+	for i := 0; i < len(runes); i++ {
+		toned = append(toned, runes[i])
+		if !containsInt(e.tones, i) {
+			continue
+		}
+
+		toned = append(toned, skinTones[tone-1])
+		if i+1 < len(runes) && runes[i+1] == variationSelector {
+			i++ // the modifier replaces the emoji presentation selector
+		}
+	}
+	return string(toned)
+}
+
+// matchScore returns how well a search matches, or 0 if a word of the query is not found.
+// Words that start words of the name score highest, then those that match keywords.
+func (e *emoji) matchScore(query []string) int {
+	score := 0
+	for _, word := range query {
+		switch {
+		case wordsMatch(e.nameWords, word):
+			score += 2
+		case wordsMatch(e.keywords, word):
+			score++
+		default:
+			return 0
+		}
+	}
+
+	if strings.Join(query, " ") == strings.Join(e.nameWords, " ") {
+		score += len(query) * 2 // exact name
+	}
+	return score
+}
+
+// parseTable reads the glyph, skin tone positions, name and keywords from each line of the table.
+func parseTable(data string) []*emoji {
+	var list []*emoji
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+
+		e := &emoji{glyph: fields[0], name: fields[2], nameWords: splitWords(fields[2])}
+		e.keywords = splitWords(fields[3]) // CLDR keywords may be phrases, so search each word
+		for _, pos := range strings.Split(fields[1], ",") {
+			if i, err := strconv.Atoi(pos); err == nil {
+				e.tones = append(e.tones, i)
+			}
+		}
+		list = append(list, e)
+	}
+	return list
+}
+
+// search returns up to max emoji that match every word of the query, best first then in table order.
+func search(list []*emoji, query string, max int) []*emoji {
+	words := splitWords(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	type match struct {
+		emoji *emoji
+		score int
+	}
+	var found []match
+	for _, e := range list {
+		if score := e.matchScore(words); score > 0 {
+			found = append(found, match{emoji: e, score: score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+
+	if len(found) > max {
+		found = found[:max]
+	}
+	results := make([]*emoji, len(found))
+	for i, m := range found {
+		results[i] = m.emoji
+	}
+	return results
+}
+
+func containsInt(list []int, item int) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWords returns the lower case words of a name, splitting at spaces and punctuation.
+func splitWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\''
+	})
+}
+
+// stem removes common endings so that forms of a word such as "smile" and "smiling" match.
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s", "e", "y"} {
+		if len(word)-len(suffix) >= 3 && strings.HasSuffix(word, suffix) {
+			return word[:len(word)-len(suffix)]
+		}
+	}
+	return word
+}
+
+// wordsMatch returns true if the query word starts any of the words, or a form of it does.
+func wordsMatch(words []string, query string) bool {
+	queryStem := stem(query)
+	for _, word := range words {
+		if strings.HasPrefix(word, query) || strings.HasPrefix(stem(word), queryStem) {
+			return true
+		}
+	}
+	return false
+}
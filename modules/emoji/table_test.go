@@ -0,0 +1,54 @@
+package emoji
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTable(t *testing.T) {
+	list := parseTable("# comment\n😄\t\tgrinning face with smiling eyes\tface|smile\n👍\t0\tthumbs up\thand|+1|thumbs up\nbad line\n")
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "grinning face with smiling eyes", list[0].name)
+	assert.Equal(t, []string{"face", "smile"}, list[0].keywords)
+	assert.Nil(t, list[0].tones)
+	assert.Equal(t, []int{0}, list[1].tones)
+	assert.Equal(t, []string{"hand", "1", "thumbs", "up"}, list[1].keywords)
+
+	assert.Less(t, 2000, len(parseTable(annotations)))
+}
+
+func TestSearch(t *testing.T) {
+	list := parseTable(annotations)
+
+	found := search(list, "smile", 10)
+	assert.NotEmpty(t, found)
+	assert.Contains(t, glyphs(found), "😄")
+	assert.Equal(t, "😄", glyphs(search(list, "grinning face with smiling eyes", 1))[0])
+	assert.Equal(t, "👍", glyphs(search(list, "thumbs up", 1))[0])
+	assert.Equal(t, "€", glyphs(search(list, "euro sign", 1))[0])
+	assert.Len(t, search(list, "face", 3), 3)
+	assert.Empty(t, search(list, "xyzzy", 10))
+	assert.Empty(t, search(list, " ", 10))
+}
+
+func TestEmoji_WithTone(t *testing.T) {
+	thumbs := &emoji{glyph: "👍", tones: []int{0}}
+	assert.Equal(t, "👍", thumbs.withTone(0))
+	assert.Equal(t, "👍🏽", thumbs.withTone(3))
+	assert.Equal(t, "👍", thumbs.withTone(6))
+
+	writing := &emoji{glyph: "✍️", tones: []int{0}}
+	assert.Equal(t, "✍🏿", writing.withTone(5))
+
+	smile := &emoji{glyph: "😄"}
+	assert.Equal(t, "😄", smile.withTone(2))
+}
+
+func glyphs(list []*emoji) []string {
+	var ret []string
+	for _, e := range list {
+		ret = append(ret, e.glyph)
+	}
+	return ret
+}
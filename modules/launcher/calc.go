@@ -1,8 +1,12 @@
 package launcher
 
 import (
+	"errors"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 
@@ -12,49 +16,207 @@ import (
 	"github.com/Knetic/govaluate"
 )
 
+const (
+	// historyPreference stores recent calculations, each as the sum and result separated by a tab.
+	historyPreference = "launcher.calc.history"
+	// historyPrefix is typed in the launcher to list recent calculations.
+	historyPrefix  = "="
+	maxCalcHistory = 20
+
+	// calcDigits and convertDigits are the significant digits shown for calculations and conversions.
+	calcDigits    = 12
+	convertDigits = 6
+)
+
 var (
-	exprRegex = regexp.MustCompile(`^[0-9.+\-*/()]+$`)
-	numRegex  = regexp.MustCompile(`^[0-9.]+$`)
+	exprRegex    = regexp.MustCompile(`^[0-9a-zA-Z_.+\-*/%()\s]+$`)
+	numRegex     = regexp.MustCompile(`^[0-9.]+$`)
+	wordRegex    = regexp.MustCompile(`[0-9.]*[a-zA-Z_][a-zA-Z_0-9]*`)
+	literalRegex = regexp.MustCompile(`\b0([xX][0-9a-fA-F]+|[oO][0-7]+|[bB][01]+)\b`)
+
+	percentOfRegex  = regexp.MustCompile(`([0-9.]+)%\s*of\s+`)
+	percentAddRegex = regexp.MustCompile(`^(.+?)\s*([+-])\s*([0-9.]+)%$`)
+	percentRegex    = regexp.MustCompile(`([0-9.]+)%([^0-9(]|$)`)
+
+	baseRegex    = regexp.MustCompile(`(?i)^(.+?)\s+(?:in|to|as)\s+(hex|hexadecimal|oct|octal|bin|binary|dec|decimal)$`)
+	convertRegex = regexp.MustCompile(`^(.+?)\s*([a-zA-Z°][a-zA-Z°/]*)\s+(?:in|to|as)\s+([a-zA-Z°][a-zA-Z°/]*)$`)
+
+	errNotNumber = errors.New("expression is not a number")
 )
 
+// calcConstants are the named values that can be used in a calculation.
+var calcConstants = map[string]interface{}{
+	"pi":  math.Pi,
+	"tau": 2 * math.Pi,
+	"e":   math.E,
+	"phi": math.Phi,
+}
+
+// calcFunctions are the functions of one number that can be used in a calculation.
+// Trigonometry uses radians.
+var calcFunctions = map[string]govaluate.ExpressionFunction{
+	"abs":   unaryFunction(math.Abs),
+	"acos":  unaryFunction(math.Acos),
+	"asin":  unaryFunction(math.Asin),
+	"atan":  unaryFunction(math.Atan),
+	"cbrt":  unaryFunction(math.Cbrt),
+	"ceil":  unaryFunction(math.Ceil),
+	"cos":   unaryFunction(math.Cos),
+	"exp":   unaryFunction(math.Exp),
+	"floor": unaryFunction(math.Floor),
+	"ln":    unaryFunction(math.Log),
+	"log":   unaryFunction(math.Log10),
+	"log2":  unaryFunction(math.Log2),
+	"round": unaryFunction(math.Round),
+	"sin":   unaryFunction(math.Sin),
+	"sqrt":  unaryFunction(math.Sqrt),
+	"tan":   unaryFunction(math.Tan),
+}
+
 var calcMeta = fynedesk.ModuleMetadata{
 	Name:        "Launcher: Calculate",
 	NewInstance: newCalcSuggest,
 }
 
-type calc struct{}
+type calc struct {
+	now   func() time.Time
+	rates *currencyRates
+}
 
 func (c *calc) Destroy() {
 }
 
+// LaunchSuggestions shows the result of a calculation, conversion or date sum.
+// Typing "=" lists recent calculations instead, optionally filtered by the text that follows.
 func (c *calc) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
-	if !c.isExpression(input) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, historyPrefix) {
+		return c.historyMatching(strings.TrimSpace(input[len(historyPrefix):]))
+	}
+
+	item := c.calculate(input)
+	if item == nil {
 		return nil
 	}
+	return []fynedesk.LaunchSuggestion{item}
+}
+
+// calculate tries each kind of calculation that the input may be, returning nil if it is none of them.
+func (c *calc) calculate(input string) *calcItem {
+	if parts := baseRegex.FindStringSubmatch(input); parts != nil {
+		if result, err := c.evalBase(parts[1], parts[2]); err == nil {
+			return &calcItem{calc: c, sum: input, result: result}
+		}
+	}
+	if parts := convertRegex.FindStringSubmatch(input); parts != nil {
+		if item := c.convert(input, parts[1], parts[2], parts[3]); item != nil {
+			return item
+		}
+	}
+	if result, ok := c.evalDate(input); ok {
+		return &calcItem{calc: c, sum: input, result: result}
+	}
 
+	if !c.isExpression(input) {
+		return nil
+	}
 	result, err := c.eval(input)
 	if err != nil {
 		return nil
 	}
-	return []fynedesk.LaunchSuggestion{&calcItem{sum: input, result: result}}
+	return &calcItem{calc: c, sum: input, result: result}
+}
+
+// convert changes an amount from one unit or currency to another.
+func (c *calc) convert(input, amount, from, to string) *calcItem {
+	value, err := c.evalNumber(amount)
+	if err != nil {
+		return nil
+	}
+
+	if converted, ok := convertUnit(value, from, to); ok {
+		return &calcItem{calc: c, sum: input, result: formatNumber(converted, convertDigits) + " " + to}
+	}
+	if converted, ok := c.rates.convert(value, from, to); ok {
+		return &calcItem{calc: c, sum: input, result: strconv.FormatFloat(converted, 'f', 2, 64) + " " +
+			strings.ToUpper(to), note: c.rates.describe()}
+	}
+	return nil
 }
 
 func (c *calc) eval(sum string) (string, error) {
-	expression, err := govaluate.NewEvaluableExpression(sum)
+	res, err := c.evalNumber(sum)
 	if err != nil {
 		return "", err
 	}
 
-	res, err := expression.Evaluate(nil)
+	return formatNumber(res, calcDigits), nil
+}
+
+// evalBase calculates a sum and returns the result as a hexadecimal, octal, binary or decimal number.
+func (c *calc) evalBase(sum, base string) (string, error) {
+	res, err := c.evalNumber(sum)
 	if err != nil {
 		return "", err
 	}
+	if res != math.Trunc(res) || math.Abs(res) > math.MaxInt64 {
+		return "", errNotNumber
+	}
+
+	num := int64(res)
+	sign := ""
+	if num < 0 {
+		sign = "-"
+		num = -num
+	}
+	switch strings.ToLower(base)[:3] {
+	case "hex":
+		return sign + "0x" + strconv.FormatInt(num, 16), nil
+	case "oct":
+		return sign + "0o" + strconv.FormatInt(num, 8), nil
+	case "bin":
+		return sign + "0b" + strconv.FormatInt(num, 2), nil
+	}
+	return sign + strconv.FormatInt(num, 10), nil
+}
+
+// evalNumber returns the value of a mathematical expression.
+func (c *calc) evalNumber(sum string) (float64, error) {
+	sum = normalizeExpression(sum)
+	if !validExpression(sum) {
+		return 0, errNotNumber
+	}
+
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(sum, calcFunctions)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := expression.Evaluate(calcConstants)
+	if err != nil {
+		return 0, err
+	}
 
-	if f, ok := res.(float64); ok {
-		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	f, ok := res.(float64)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errNotNumber
 	}
+	return f, nil
+}
 
-	return "", nil
+// historyMatching returns recent calculations, newest first, that contain the filter text.
+func (c *calc) historyMatching(filter string) []fynedesk.LaunchSuggestion {
+	filter = strings.ToLower(filter)
+	var items []fynedesk.LaunchSuggestion
+	for _, line := range fyne.CurrentApp().Preferences().StringList(historyPreference) {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 || !strings.Contains(strings.ToLower(parts[0]), filter) {
+			continue
+		}
+
+		items = append(items, &calcItem{calc: c, sum: parts[0], result: parts[1]})
+	}
+	return items
 }
 
 func (c *calc) Metadata() fynedesk.ModuleMetadata {
@@ -63,16 +225,104 @@ func (c *calc) Metadata() fynedesk.ModuleMetadata {
 
 // isExpression will return true if input is a mathematical expression unless it just contains a number
 func (c *calc) isExpression(input string) bool {
-	return exprRegex.MatchString(input) && !numRegex.MatchString(input)
+	if numRegex.MatchString(input) || !strings.ContainsAny(input, "0123456789") {
+		return false
+	}
+	if !strings.ContainsAny(input, "+-*/%^()") && !literalRegex.MatchString(input) {
+		return false
+	}
+
+	return validExpression(normalizeExpression(input))
+}
+
+// remember adds a calculation to the start of the history, removing an older copy of it.
+func (c *calc) remember(sum, result string) {
+	prefs := fyne.CurrentApp().Preferences()
+	history := []string{sum + "\t" + result}
+	for _, line := range prefs.StringList(historyPreference) {
+		if strings.HasPrefix(line, sum+"\t") {
+			continue
+		}
+		history = append(history, line)
+	}
+
+	if len(history) > maxCalcHistory {
+		history = history[:maxCalcHistory]
+	}
+	prefs.SetStringList(historyPreference, history)
 }
 
 // newCalcSuggest creates a new module that will show calculations in the launcher suggestions
 func newCalcSuggest() fynedesk.Module {
-	return &calc{}
+	return &calc{now: time.Now, rates: loadCurrencyRates()}
+}
+
+// formatNumber returns a number rounded to the significant digits passed, without an exponent unless
+// the number is very large or small.
+func formatNumber(f float64, digits int) string {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'g', digits, 64), 64)
+	if abs := math.Abs(rounded); abs >= 1e15 || (abs != 0 && abs < 1e-6) {
+		return strconv.FormatFloat(rounded, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// normalizeExpression turns a sum that a person may type into one that can be evaluated.
+// Percentages are expanded, number literals in other bases are made decimal and "^" means power.
+func normalizeExpression(sum string) string {
+	sum = strings.TrimSpace(sum)
+	sum = strings.NewReplacer("×", "*", "÷", "/", "^", "**").Replace(sum)
+	sum = literalRegex.ReplaceAllStringFunc(sum, func(literal string) string {
+		num, err := strconv.ParseInt(literal, 0, 64)
+		if err != nil {
+			return literal
+		}
+		return strconv.FormatInt(num, 10)
+	})
+
+	sum = percentOfRegex.ReplaceAllString(sum, "($1/100)*")
+	sum = percentAddRegex.ReplaceAllString(sum, "($1)*(1$2$3/100)")
+	return percentRegex.ReplaceAllString(sum, "($1/100)$2")
+}
+
+// unaryFunction wraps a function of one number so that it can be called from an expression.
+func unaryFunction(f func(float64) float64) govaluate.ExpressionFunction {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errors.New("function expects one number")
+		}
+		num, ok := args[0].(float64)
+		if !ok {
+			return nil, errNotNumber
+		}
+		return f(num), nil
+	}
+}
+
+// validExpression returns true if a normalized sum only contains numbers, operators and known names.
+func validExpression(sum string) bool {
+	if !exprRegex.MatchString(sum) {
+		return false
+	}
+
+	for _, word := range wordRegex.FindAllString(sum, -1) {
+		if _, ok := calcFunctions[word]; ok {
+			continue
+		}
+		if _, ok := calcConstants[word]; !ok {
+			return false // unknown name, or a number followed by letters
+		}
+	}
+	return true
 }
 
 type calcItem struct {
-	sum, result string
+	calc              *calc
+	sum, result, note string
+}
+
+func (i *calcItem) CopyText() string {
+	return i.result
 }
 
 func (i *calcItem) Icon() fyne.Resource {
@@ -80,9 +330,21 @@ func (i *calcItem) Icon() fyne.Resource {
 }
 
 func (i *calcItem) Title() string {
+	if i.note != "" {
+		return i.sum + " = " + i.result + "  (" + i.note + ")"
+	}
 	return i.sum + " = " + i.result
 }
 
+// Launch copies the result to the clipboard and adds the calculation to the history.
 func (i *calcItem) Launch() {
-	fyne.CurrentApp().Driver().AllWindows()[0].Clipboard().SetContent(i.result)
+	if i.calc != nil {
+		i.calc.remember(i.sum, i.result)
+	}
+
+	windows := fyne.CurrentApp().Driver().AllWindows()
+	if len(windows) == 0 {
+		return
+	}
+	windows[0].Clipboard().SetContent(i.result)
 }
@@ -0,0 +1,101 @@
+package launcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// currencyFile is the name of the exchange rate file in the "fynedesk" user config folder.
+// It uses the format of the European Central Bank rates published by frankfurter.app, so it can be
+// updated with a command such as "curl https://api.frankfurter.app/latest > ~/.config/fynedesk/currency.json".
+// The README explains this for users, and conversions using the built in rates show where the file goes.
+const currencyFile = "currency.json"
+
+// currencyRates is a table of exchange rates, as the amount of each currency that the base currency buys.
+type currencyRates struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+
+	builtIn bool
+}
+
+// defaultCurrencyRates are approximate reference rates, used until the user provides a rate file.
+var defaultCurrencyRates = &currencyRates{Base: "EUR", Date: "2024-06-03", builtIn: true, Rates: map[string]float64{
+	"AUD": 1.6297, "BRL": 5.6979, "CAD": 1.4835, "CHF": 0.9738, "CNY": 7.8607, "CZK": 24.730,
+	"DKK": 7.4598, "GBP": 0.8503, "HKD": 8.4857, "INR": 90.238, "JPY": 169.79, "KRW": 1491.39,
+	"MXN": 18.872, "NOK": 11.378, "NZD": 1.7660, "PLN": 4.2843, "SEK": 11.4085, "SGD": 1.4640,
+	"TRY": 34.951, "USD": 1.0854, "ZAR": 20.351,
+}}
+
+// convert changes an amount between currencies, returning false if either currency is not known.
+func (r *currencyRates) convert(amount float64, from, to string) (float64, bool) {
+	if r == nil {
+		return 0, false
+	}
+
+	src, dst := r.rate(from), r.rate(to)
+	if src <= 0 || dst <= 0 {
+		return 0, false
+	}
+	return amount / src * dst, true
+}
+
+// describe returns a note about the age of the rates, and where to save newer ones if they are built in.
+func (r *currencyRates) describe() string {
+	if !r.builtIn {
+		return "rates of " + r.Date
+	}
+
+	path := currencyPath()
+	if path == "" {
+		return "built in rates of " + r.Date
+	}
+	return "built in rates of " + r.Date + ", update in " + path
+}
+
+// rate returns how much of a currency the base currency buys, or 0 if it is not known.
+func (r *currencyRates) rate(code string) float64 {
+	code = strings.ToUpper(code)
+	if code == strings.ToUpper(r.Base) {
+		return 1
+	}
+	return r.Rates[code]
+}
+
+// loadCurrencyRates reads the user's exchange rate file, falling back to the built in rates if there is none.
+func loadCurrencyRates() *currencyRates {
+	path := currencyPath()
+	if path == "" {
+		return defaultCurrencyRates
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return defaultCurrencyRates // no rate file
+	}
+
+	rates := &currencyRates{}
+	if err = json.Unmarshal(data, rates); err != nil || rates.Base == "" {
+		fyne.LogError("Failed to read currency rates from "+currencyFile, err)
+		return defaultCurrencyRates
+	}
+	upper := make(map[string]float64, len(rates.Rates))
+	for code, rate := range rates.Rates {
+		upper[strings.ToUpper(code)] = rate
+	}
+	rates.Rates = upper
+	return rates
+}
+
+// currencyPath returns where the user's exchange rate file is saved, or "" if there is no config folder.
+func currencyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "fynedesk", currencyFile)
+}
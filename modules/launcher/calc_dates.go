@@ -0,0 +1,85 @@
+package launcher
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat   = "2006-01-02"
+	resultFormat = "Monday 2 January 2006"
+
+	// dateTerm matches the dates that can be used in a sum.
+	dateTerm = `(?:today|tomorrow|yesterday|\d{4}-\d{2}-\d{2})`
+)
+
+var (
+	dateSumRegex   = regexp.MustCompile(`(?i)^(` + dateTerm + `)((?:\s*[+-]\s*\d+\s*[a-z]+)*)$`)
+	dateDiffRegex  = regexp.MustCompile(`(?i)^(` + dateTerm + `)\s*-\s*(` + dateTerm + `)$`)
+	dateDeltaRegex = regexp.MustCompile(`(?i)([+-])\s*(\d+)\s*([a-z]+)`)
+)
+
+// evalDate works out sums such as "today + 45 days", or the number of days between two dates.
+func (c *calc) evalDate(input string) (string, bool) {
+	if parts := dateDiffRegex.FindStringSubmatch(input); parts != nil {
+		from, ok := c.parseDate(parts[2])
+		to, ok2 := c.parseDate(parts[1])
+		if !ok || !ok2 {
+			return "", false
+		}
+		days := int(math.Round(to.Sub(from).Hours() / 24))
+		if days == 1 || days == -1 {
+			return strconv.Itoa(days) + " day", true
+		}
+		return strconv.Itoa(days) + " days", true
+	}
+
+	parts := dateSumRegex.FindStringSubmatch(input)
+	if parts == nil {
+		return "", false
+	}
+	date, ok := c.parseDate(parts[1])
+	if !ok {
+		return "", false
+	}
+	for _, delta := range dateDeltaRegex.FindAllStringSubmatch(parts[2], -1) {
+		count, _ := strconv.Atoi(delta[2])
+		if delta[1] == "-" {
+			count = -count
+		}
+
+		switch strings.TrimSuffix(strings.ToLower(delta[3]), "s") {
+		case "day", "d":
+			date = date.AddDate(0, 0, count)
+		case "week", "w":
+			date = date.AddDate(0, 0, count*7)
+		case "month":
+			date = date.AddDate(0, count, 0)
+		case "year", "y":
+			date = date.AddDate(count, 0, 0)
+		default:
+			return "", false
+		}
+	}
+	return date.Format(resultFormat), true
+}
+
+// parseDate returns the date that a word such as "today" or an ISO date refers to, at midnight local time.
+func (c *calc) parseDate(word string) (time.Time, bool) {
+	now := c.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(word) {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+
+	date, err := time.ParseInLocation(dateFormat, word, now.Location())
+	return date, err == nil
+}
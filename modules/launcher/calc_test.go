@@ -1,7 +1,12 @@
 package launcher
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,9 +17,107 @@ func TestCalc_IsExpression(t *testing.T) {
 	assert.True(t, c.isExpression("5*3"))
 	assert.True(t, c.isExpression("5*(3+2)"))
 	assert.True(t, c.isExpression("5/5-1"))
+	assert.True(t, c.isExpression("sqrt(16)"))
+	assert.True(t, c.isExpression("0xff"))
+	assert.True(t, c.isExpression("20% of 150"))
 
 	assert.False(t, c.isExpression("33"))
 	assert.False(t, c.isExpression("5e"))
 	assert.False(t, c.isExpression("2,1*4"))
 	assert.False(t, c.isExpression("xterm"))
+	assert.False(t, c.isExpression("pi"))
+	assert.False(t, c.isExpression("unknown(2)"))
+}
+
+func TestCalc_Eval(t *testing.T) {
+	c := newCalcSuggest().(*calc)
+	for sum, result := range map[string]string{
+		"0.1+0.2":       "0.3",
+		"2^10":          "1024",
+		"sqrt(16)+1":    "5",
+		"round(pi*100)": "314",
+		"log(1000)":     "3",
+		"sin(0)":        "0",
+		"0xff + 0b11":   "258",
+		"0o17":          "15",
+		"20% of 150":    "30",
+		"150 + 10%":     "165",
+		"80 - 25%":      "60",
+		"50%":           "0.5",
+		"10 % 3":        "1",
+	} {
+		res, err := c.eval(sum)
+		assert.Nil(t, err, sum)
+		assert.Equal(t, result, res, sum)
+	}
+
+	_, err := c.eval("1/0")
+	assert.NotNil(t, err)
+}
+
+func TestCalc_Suggestions(t *testing.T) {
+	c := &calc{now: func() time.Time {
+		return time.Date(2024, time.March, 1, 15, 30, 0, 0, time.Local)
+	}, rates: &currencyRates{Base: "EUR", Date: "2024-03-01", Rates: map[string]float64{"USD": 2, "GBP": 0.5}}}
+
+	for input, title := range map[string]string{
+		"255 in hex":           "255 in hex = 0xff",
+		"0xff + 1 to bin":      "0xff + 1 to bin = 0b100000000",
+		"-10 as oct":           "-10 as oct = -0o12",
+		"5 km in miles":        "5 km in miles = 3.10686 miles",
+		"72F to C":             "72F to C = 22.2222 C",
+		"1.5 GiB to MB":        "1.5 GiB to MB = 1610.61 MB",
+		"10 usd in gbp":        "10 usd in gbp = 2.50 GBP  (rates of 2024-03-01)",
+		"today + 45 days":      "today + 45 days = Monday 15 April 2024",
+		"tomorrow - 1 week":    "tomorrow - 1 week = Saturday 24 February 2024",
+		"2024-01-31 + 1 month": "2024-01-31 + 1 month = Saturday 2 March 2024",
+		"2024-12-25 - today":   "2024-12-25 - today = 299 days",
+	} {
+		items := c.LaunchSuggestions(input)
+		if assert.Equal(t, 1, len(items), input) {
+			assert.Equal(t, title, items[0].Title())
+		}
+	}
+
+	assert.Nil(t, c.LaunchSuggestions("5 km in kg"))
+	assert.Nil(t, c.LaunchSuggestions("10 usd in xyz"))
+	assert.Nil(t, c.LaunchSuggestions("today + 2 fortnights"))
+	assert.Nil(t, c.LaunchSuggestions("firefox"))
+}
+
+func TestCalc_History(t *testing.T) {
+	test.NewApp()
+	c := newCalcSuggest().(*calc)
+	assert.Nil(t, c.LaunchSuggestions("="))
+
+	c.LaunchSuggestions("2*3")[0].Launch()
+	c.LaunchSuggestions("5 km in m")[0].Launch()
+	c.LaunchSuggestions("2*3")[0].Launch()
+
+	items := c.LaunchSuggestions("=")
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "2*3 = 6", items[0].Title())
+	assert.Equal(t, "5 km in m = 5000 m", items[1].Title())
+
+	items = c.LaunchSuggestions("= km")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "5000 m", items[0].(*calcItem).CopyText())
+}
+
+func TestLoadCurrencyRates(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	assert.Equal(t, defaultCurrencyRates, loadCurrencyRates())
+	assert.Equal(t, "built in rates of 2024-06-03, update in "+filepath.Join(dir, "fynedesk", currencyFile),
+		loadCurrencyRates().describe())
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "fynedesk"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "fynedesk", currencyFile),
+		[]byte(`{"amount":1.0,"base":"USD","date":"2024-05-01","rates":{"eur":0.5}}`), 0644))
+	rates := loadCurrencyRates()
+	assert.Equal(t, "2024-05-01", rates.Date)
+	assert.Equal(t, "rates of 2024-05-01", rates.describe())
+	converted, ok := rates.convert(3, "EUR", "usd")
+	assert.True(t, ok)
+	assert.Equal(t, 6.0, converted)
 }
@@ -0,0 +1,98 @@
+package launcher
+
+import "strings"
+
+// unit is a unit of measurement that can be converted to others of the same kind.
+type unit struct {
+	kind   string
+	factor float64 // the size of this unit in the base unit of its kind
+	offset float64 // added after scaling to the base unit, for temperatures
+}
+
+// unitNames lists the lower case names and abbreviations of each unit.
+// The base units are metres, grams, litres, seconds, metres per second, bytes and kelvin.
+var unitNames = []struct {
+	unit  unit
+	names []string
+}{
+	{unit{"length", 1, 0}, []string{"m", "metre", "metres", "meter", "meters"}},
+	{unit{"length", 1000, 0}, []string{"km", "kilometre", "kilometres", "kilometer", "kilometers"}},
+	{unit{"length", 0.01, 0}, []string{"cm"}},
+	{unit{"length", 0.001, 0}, []string{"mm"}},
+	{unit{"length", 1609.344, 0}, []string{"mi", "mile", "miles"}},
+	{unit{"length", 0.9144, 0}, []string{"yd", "yard", "yards"}},
+	{unit{"length", 0.3048, 0}, []string{"ft", "foot", "feet"}},
+	{unit{"length", 0.0254, 0}, []string{"in", "inch", "inches"}},
+	{unit{"length", 1852, 0}, []string{"nmi"}},
+
+	{unit{"mass", 1, 0}, []string{"g", "gram", "grams"}},
+	{unit{"mass", 0.001, 0}, []string{"mg"}},
+	{unit{"mass", 1000, 0}, []string{"kg", "kilo", "kilos", "kilogram", "kilograms"}},
+	{unit{"mass", 1e6, 0}, []string{"t", "tonne", "tonnes"}},
+	{unit{"mass", 453.59237, 0}, []string{"lb", "lbs", "pound", "pounds"}},
+	{unit{"mass", 28.349523125, 0}, []string{"oz", "ounce", "ounces"}},
+	{unit{"mass", 6350.29318, 0}, []string{"st", "stone"}},
+
+	{unit{"volume", 1, 0}, []string{"l", "litre", "litres", "liter", "liters"}},
+	{unit{"volume", 0.001, 0}, []string{"ml"}},
+	{unit{"volume", 0.01, 0}, []string{"cl"}},
+	{unit{"volume", 3.785411784, 0}, []string{"gal", "gallon", "gallons"}},
+	{unit{"volume", 0.946352946, 0}, []string{"qt", "quart", "quarts"}},
+	{unit{"volume", 0.473176473, 0}, []string{"pt", "pint", "pints"}},
+	{unit{"volume", 0.2365882365, 0}, []string{"cup", "cups"}},
+	{unit{"volume", 0.0295735295625, 0}, []string{"floz"}},
+	{unit{"volume", 0.01478676478125, 0}, []string{"tbsp"}},
+	{unit{"volume", 0.00492892159375, 0}, []string{"tsp"}},
+
+	{unit{"time", 0.001, 0}, []string{"ms"}},
+	{unit{"time", 1, 0}, []string{"s", "sec", "second", "seconds"}},
+	{unit{"time", 60, 0}, []string{"min", "minute", "minutes"}},
+	{unit{"time", 3600, 0}, []string{"h", "hr", "hour", "hours"}},
+	{unit{"time", 86400, 0}, []string{"day", "days"}},
+	{unit{"time", 604800, 0}, []string{"week", "weeks"}},
+
+	{unit{"speed", 1, 0}, []string{"m/s"}},
+	{unit{"speed", 1 / 3.6, 0}, []string{"km/h", "kph"}},
+	{unit{"speed", 0.44704, 0}, []string{"mph"}},
+	{unit{"speed", 1852.0 / 3600, 0}, []string{"kn", "knot", "knots"}},
+
+	{unit{"data", 1, 0}, []string{"b", "byte", "bytes"}},
+	{unit{"data", 1e3, 0}, []string{"kb"}},
+	{unit{"data", 1e6, 0}, []string{"mb"}},
+	{unit{"data", 1e9, 0}, []string{"gb"}},
+	{unit{"data", 1e12, 0}, []string{"tb"}},
+	{unit{"data", 1 << 10, 0}, []string{"kib"}},
+	{unit{"data", 1 << 20, 0}, []string{"mib"}},
+	{unit{"data", 1 << 30, 0}, []string{"gib"}},
+	{unit{"data", 1 << 40, 0}, []string{"tib"}},
+
+	{unit{"temperature", 1, 273.15}, []string{"c", "°c", "celsius"}},
+	{unit{"temperature", 5.0 / 9, 273.15 - 32*5.0/9}, []string{"f", "°f", "fahrenheit"}},
+	{unit{"temperature", 1, 0}, []string{"k", "kelvin"}},
+}
+
+// units maps each name in unitNames to its unit.
+var units = func() map[string]unit {
+	lookup := make(map[string]unit)
+	for _, u := range unitNames {
+		for _, name := range u.names {
+			lookup[name] = u.unit
+		}
+	}
+	return lookup
+}()
+
+// convertUnit changes a value from one unit to another, returning false if they do not measure the same thing.
+func convertUnit(value float64, from, to string) (float64, bool) {
+	src, ok := units[strings.ToLower(from)]
+	if !ok {
+		return 0, false
+	}
+	dst, ok := units[strings.ToLower(to)]
+	if !ok || src.kind != dst.kind {
+		return 0, false
+	}
+
+	base := value*src.factor + src.offset
+	return (base - dst.offset) / dst.factor, true
+}
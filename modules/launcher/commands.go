@@ -0,0 +1,127 @@
+package launcher
+
+import (
+	"os/exec"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/internal/icon"
+	"fyshos.com/fynedesk/wm"
+)
+
+const (
+	// commandPrefix starts launcher input that should be run as a shell command.
+	commandPrefix = ">"
+
+	// maxOutputLines and maxOutputLength limit the command output shown in a notification.
+	maxOutputLines  = 10
+	maxOutputLength = 500
+
+	// holdOpenScript runs the command passed as its first argument, then waits so the output can be read.
+	holdOpenScript = `sh -c "$1"; printf '\nPress Return to close'; read _`
+)
+
+var commandsMeta = fynedesk.ModuleMetadata{
+	Name:        "Launcher: Run Commands",
+	NewInstance: newCommands,
+}
+
+type commands struct{}
+
+func (c *commands) Destroy() {
+}
+
+func (c *commands) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
+	if !strings.HasPrefix(input, commandPrefix) {
+		return nil
+	}
+	command := strings.TrimSpace(input[len(commandPrefix):])
+	if command == "" {
+		return nil
+	}
+
+	return []fynedesk.LaunchSuggestion{&commandResult{command: command},
+		&commandResult{command: command, terminal: true}}
+}
+
+func (c *commands) Metadata() fynedesk.ModuleMetadata {
+	return commandsMeta
+}
+
+// newCommands creates a new module that runs shell commands typed after ">" in the launcher
+func newCommands() fynedesk.Module {
+	return &commands{}
+}
+
+// commandResult runs a shell command in the background, showing its output in a notification,
+// or in a terminal that stays open until the user closes it.
+type commandResult struct {
+	command  string
+	terminal bool
+}
+
+func (r *commandResult) CopyText() string {
+	return r.command
+}
+
+func (r *commandResult) Icon() fyne.Resource {
+	return theme.ComputerIcon()
+}
+
+func (r *commandResult) Launch() {
+	if r.terminal {
+		startCommand(icon.TerminalCommand([]string{"sh", "-c", holdOpenScript, "sh", r.command}))
+		return
+	}
+
+	go runCommand(r.command)
+}
+
+func (r *commandResult) Title() string {
+	if r.terminal {
+		return "Run in Terminal: " + r.command
+	}
+	return "Run: " + r.command
+}
+
+// commandOutput shortens the output of a command so that it fits in a notification.
+func commandOutput(out []byte) string {
+	text := strings.TrimSpace(string(out))
+	lines := strings.Split(text, "\n")
+	if len(lines) > maxOutputLines {
+		text = strings.Join(lines[:maxOutputLines], "\n") + "\n…"
+	}
+	if runes := []rune(text); len(runes) > maxOutputLength {
+		text = string(runes[:maxOutputLength]) + "…"
+	}
+	return text
+}
+
+// runCommand runs a shell command and waits for it to finish, then shows its output in a notification.
+func runCommand(command string) {
+	out, err := exec.Command("sh", "-c", command).CombinedOutput()
+	title := "Command finished"
+	if err != nil {
+		title = "Command failed: " + err.Error()
+	}
+
+	body := command
+	if output := commandOutput(out); output != "" {
+		body += "\n" + output
+	}
+	wm.SendNotification(wm.NewNotification(title, body))
+}
+
+// runInTerminal runs a shell command in the terminal configured by the user.
+func runInTerminal(command string) {
+	startCommand(icon.TerminalCommand([]string{"sh", "-c", command}))
+}
+
+func startCommand(args []string) {
+	if err := exec.Command(args[0], args[1:]...).Start(); err != nil {
+		fyne.LogError("Failed to run command "+args[0], err)
+	}
+}
@@ -0,0 +1,31 @@
+package launcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommands_LaunchSuggestions(t *testing.T) {
+	c := newCommands().(*commands)
+
+	items := c.LaunchSuggestions("> ls -l")
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "Run: ls -l", items[0].Title())
+	assert.Equal(t, "Run in Terminal: ls -l", items[1].Title())
+	assert.Equal(t, "ls -l", items[1].(*commandResult).CopyText())
+
+	assert.Nil(t, c.LaunchSuggestions(">"))
+	assert.Nil(t, c.LaunchSuggestions("ls"))
+}
+
+func TestCommandOutput(t *testing.T) {
+	assert.Equal(t, "done", commandOutput([]byte("done\n")))
+
+	lines := strings.Repeat("line\n", 20)
+	assert.Equal(t, strings.Repeat("line\n", maxOutputLines)+"…", commandOutput([]byte(lines)))
+
+	long := commandOutput([]byte(strings.Repeat("x", 1000)))
+	assert.Equal(t, maxOutputLength+1, len([]rune(long)))
+}
@@ -0,0 +1,371 @@
+package launcher
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// indexBatchSize is the number of files added to the index before it pauses, so that a cold start
+	// does not stall the desktop.
+	indexBatchSize = 500
+	indexPause     = 10 * time.Millisecond
+
+	// maxIndexedFiles stops very large home directories using too much memory.
+	maxIndexedFiles = 200000
+	// Watches are shared with every other app in the user's session, so only a quarter of the limit is used.
+	// If there are more folders than that the index is rebuilt every rescanInterval, to find changes in the others.
+	watchLimitPath    = "/proc/sys/fs/inotify/max_user_watches"
+	defaultWatchLimit = 8192
+	rescanInterval    = 15 * time.Minute
+)
+
+var errIndexClosed = errors.New("file index closed")
+
+// fileEntry is a file or folder that can be found by the launcher.
+type fileEntry struct {
+	path string
+	name string // the lower case file name, used for matching
+	dir  bool
+}
+
+// fileIndex keeps a list of the files under a root folder, updating it when files change.
+// Include globs choose the paths, relative to the root, that are indexed. Exclude globs can match the name or
+// relative path of any file or folder to leave it out.
+type fileIndex struct {
+	root             string
+	include, exclude []string
+
+	lock    sync.RWMutex
+	entries map[string]*fileEntry
+
+	watcher    *fsnotify.Watcher
+	watched    map[string]bool
+	maxWatched int
+	closed     bool
+	stop       chan bool
+}
+
+// search returns up to max entries whose names contain the query, best match first.
+func (i *fileIndex) search(query string, max int) []*fileResult {
+	query = strings.ToLower(query)
+	var found []*fileResult
+
+	i.lock.RLock()
+	for _, entry := range i.entries {
+		pos := strings.Index(entry.name, query)
+		if pos == -1 {
+			continue
+		}
+
+		found = append(found, &fileResult{entry: entry, root: i.root, score: matchScore(entry, query, pos)})
+	}
+	i.lock.RUnlock()
+
+	sort.Slice(found, func(a, b int) bool {
+		if found[a].score == found[b].score {
+			return found[a].entry.path < found[b].entry.path
+		}
+		return found[a].score > found[b].score
+	})
+	if len(found) > max {
+		found = found[:max]
+	}
+	return found
+}
+
+// matchScore ranks a file name that contains the query at pos. Files rank below apps, and deeply nested
+// files below those near the root.
+func matchScore(entry *fileEntry, query string, pos int) float64 {
+	score := 0.5
+	switch {
+	case entry.name == query:
+		score = 0.8
+	case pos == 0:
+		score = 0.7
+	default:
+		if prev, _ := utf8.DecodeLastRuneInString(entry.name[:pos]); !unicode.IsLetter(prev) {
+			score = 0.6 // the query starts a word
+		}
+	}
+
+	depth := strings.Count(entry.path, string(filepath.Separator))
+	return score - float64(depth)*0.005
+}
+
+// start builds the index in the background, then watches for changes until close is called.
+func (i *fileIndex) start() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fyne.LogError("Unable to watch for file changes", err)
+	} else {
+		i.lock.Lock()
+		if i.closed {
+			i.lock.Unlock()
+			_ = watcher.Close()
+			return
+		}
+		i.watcher = watcher
+		i.lock.Unlock()
+		i.watch(i.root)
+	}
+
+	if !i.addIncluded(nil) {
+		return
+	}
+	if watcher != nil {
+		i.watchEvents(watcher)
+	}
+}
+
+// addIncluded indexes every file matching the include globs into the entries passed, or into the index if they
+// are nil, returning false if the index was closed.
+func (i *fileIndex) addIncluded(entries map[string]*fileEntry) bool {
+	for _, pattern := range i.include {
+		roots, err := filepath.Glob(filepath.Join(i.root, pattern))
+		if err != nil {
+			fyne.LogError("Invalid file search pattern "+pattern, err)
+			continue
+		}
+		for _, root := range roots {
+			if !i.addTree(root, entries) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// addTree indexes a file or folder and everything inside it into the entries passed, or into the index if they
+// are nil, returning false if the index was closed.
+func (i *fileIndex) addTree(root string, entries map[string]*fileEntry) bool {
+	var batch []*fileEntry
+	flush := func() bool {
+		i.lock.Lock()
+		if entries == nil {
+			entries = i.entries
+		}
+		for _, entry := range batch {
+			if len(entries) >= maxIndexedFiles {
+				break
+			}
+			entries[entry.path] = entry
+		}
+		i.lock.Unlock()
+		batch = batch[:0]
+
+		select {
+		case <-i.stop:
+			return false
+		case <-time.After(indexPause):
+			return true
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip folders that cannot be read
+		}
+		if !i.included(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		batch = append(batch, &fileEntry{path: path, name: strings.ToLower(d.Name()), dir: d.IsDir()})
+		if d.IsDir() {
+			i.watch(path)
+		}
+		if len(batch) >= indexBatchSize && !flush() {
+			return errIndexClosed
+		}
+		return nil
+	})
+	if errors.Is(err, errIndexClosed) {
+		return false
+	}
+	return flush()
+}
+
+// close stops indexing and watching for changes.
+func (i *fileIndex) close() {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.closed {
+		return
+	}
+
+	i.closed = true
+	close(i.stop)
+	if i.watcher != nil {
+		_ = i.watcher.Close()
+	}
+}
+
+// included returns true if a path should be in the index, checking it against the include and exclude globs.
+func (i *fileIndex) included(path string) bool {
+	rel, err := filepath.Rel(i.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, pattern := range i.exclude {
+		for n := range parts {
+			if ok, _ := filepath.Match(pattern, parts[n]); ok {
+				return false
+			}
+			if ok, _ := filepath.Match(pattern, filepath.Join(parts[:n+1]...)); ok {
+				return false
+			}
+		}
+	}
+	for _, pattern := range i.include {
+		count := len(strings.Split(filepath.Clean(pattern), string(filepath.Separator)))
+		if count > len(parts) {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Join(parts[:count]...)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if this index was created for the root folder and globs passed.
+func (i *fileIndex) matches(root string, include, exclude []string) bool {
+	return i.root == root && equalGlobs(i.include, include) && equalGlobs(i.exclude, exclude)
+}
+
+// remove takes a file, or a folder and all of its contents, out of the index, and stops watching them.
+func (i *fileIndex) remove(path string) {
+	i.lock.Lock()
+	delete(i.entries, path)
+	prefix := path + string(filepath.Separator)
+	for key := range i.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(i.entries, key)
+		}
+	}
+
+	for dir := range i.watched {
+		if dir == path || strings.HasPrefix(dir, prefix) {
+			_ = i.watcher.Remove(dir) // fails if the folder was deleted, as that removes the watch
+			delete(i.watched, dir)
+		}
+	}
+	i.lock.Unlock()
+}
+
+// rescan indexes all files again, to find changes in folders that are not watched.
+// The new index replaces the old one once it is complete, returning false if the index was closed.
+func (i *fileIndex) rescan() bool {
+	entries := make(map[string]*fileEntry)
+	if !i.addIncluded(entries) {
+		return false
+	}
+
+	i.lock.Lock()
+	i.entries = entries
+	i.lock.Unlock()
+	return true
+}
+
+// unwatched returns true if some folders are not watched because the limit was reached.
+func (i *fileIndex) unwatched() bool {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return len(i.watched) >= i.maxWatched
+}
+
+func (i *fileIndex) watch(dir string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.watcher == nil || i.closed || i.watched[dir] || len(i.watched) >= i.maxWatched {
+		return
+	}
+
+	if err := i.watcher.Add(dir); err != nil {
+		fyne.LogError("Unable to watch folder "+dir, err)
+		return
+	}
+	i.watched[dir] = true
+}
+
+// watchEvents updates the index as files are created, removed or renamed.
+// If some folders could not be watched then everything is indexed again after each rescanInterval.
+func (i *fileIndex) watchEvents(watcher *fsnotify.Watcher) {
+	rescan := time.NewTicker(rescanInterval)
+	defer rescan.Stop()
+
+	for {
+		select {
+		case <-i.stop:
+			return
+		case <-rescan.C:
+			if i.unwatched() && !i.rescan() {
+				return
+			}
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				i.remove(event.Name)
+			}
+			if event.Has(fsnotify.Create) {
+				if _, err := os.Lstat(event.Name); err == nil && !i.addTree(event.Name, nil) {
+					return
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fyne.LogError("Error watching for file changes", err)
+		}
+	}
+}
+
+func newFileIndex(root string, include, exclude []string) *fileIndex {
+	return &fileIndex{root: root, include: include, exclude: exclude,
+		entries: make(map[string]*fileEntry), watched: make(map[string]bool), maxWatched: watchBudget(),
+		stop: make(chan bool)}
+}
+
+// watchBudget returns how many folders an index may watch, a quarter of the inotify limit for this user.
+func watchBudget() int {
+	limit := defaultWatchLimit
+	if data, err := os.ReadFile(watchLimitPath); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	return limit / 4
+}
+
+func equalGlobs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,207 @@
+package launcher
+
+import (
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"fyshos.com/fynedesk"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// IncludePreference and ExcludePreference are comma separated globs that configure which files are searched.
+	IncludePreference = "launcher.files.include"
+	ExcludePreference = "launcher.files.exclude"
+
+	// DefaultInclude and DefaultExclude search everything in the home folder apart from hidden and generated files.
+	DefaultInclude = "*"
+	DefaultExclude = ".*,node_modules,__pycache__"
+)
+
+const (
+	maxFileResults = 8
+	minFileQuery   = 2
+)
+
+var filesMeta = fynedesk.ModuleMetadata{
+	Name:        "Launcher: Files",
+	NewInstance: newFileSearch,
+}
+
+// shared is the index for this process, kept when the module reloads so the home folder is not indexed again.
+var shared struct {
+	lock  sync.Mutex
+	index *fileIndex
+}
+
+type files struct {
+	index *fileIndex
+}
+
+// Destroy keeps the index for the next instance unless this module has been turned off.
+func (f *files) Destroy() {
+	if f.index == nil || isEnabled() {
+		return
+	}
+
+	shared.lock.Lock()
+	if shared.index == f.index {
+		shared.index = nil
+	}
+	shared.lock.Unlock()
+	f.index.close()
+}
+
+func (f *files) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
+	input = strings.TrimSpace(input)
+	if f.index == nil || len([]rune(input)) < minFileQuery {
+		return nil
+	}
+
+	var list []fynedesk.LaunchSuggestion
+	for _, result := range f.index.search(input, maxFileResults) {
+		list = append(list, result)
+	}
+	return list
+}
+
+func (f *files) Metadata() fynedesk.ModuleMetadata {
+	return filesMeta
+}
+
+// newFileSearch creates a new module that will show files and folders in the launcher suggestions
+func newFileSearch() fynedesk.Module {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fyne.LogError("Unable to find home directory to search", err)
+		return &files{}
+	}
+
+	prefs := fyne.CurrentApp().Preferences()
+	include := splitGlobs(prefs.StringWithFallback(IncludePreference, DefaultInclude))
+	exclude := splitGlobs(prefs.StringWithFallback(ExcludePreference, DefaultExclude))
+
+	shared.lock.Lock()
+	defer shared.lock.Unlock()
+	if shared.index != nil {
+		if shared.index.matches(home, include, exclude) {
+			return &files{index: shared.index}
+		}
+		shared.index.close()
+	}
+
+	shared.index = newFileIndex(home, include, exclude)
+	go shared.index.start()
+	return &files{index: shared.index}
+}
+
+// isEnabled returns true if the file search module is in the current desktop settings.
+func isEnabled() bool {
+	desk := fynedesk.Instance()
+	if desk == nil {
+		return false
+	}
+
+	for _, name := range desk.Settings().ModuleNames() {
+		if name == filesMeta.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitGlobs returns the patterns in a comma separated list, ignoring empty items.
+func splitGlobs(list string) []string {
+	var globs []string
+	for _, glob := range strings.Split(list, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
+
+// fileResult is a file or folder found for the launcher input.
+// It opens with the default app for its type, can be shown in the file manager or have its path copied.
+type fileResult struct {
+	entry *fileEntry
+	root  string
+	score float64
+}
+
+func (r *fileResult) CopyText() string {
+	return r.entry.path
+}
+
+func (r *fileResult) Icon() fyne.Resource {
+	return mimeIcon(r.entry.path, r.entry.dir)
+}
+
+func (r *fileResult) Launch() {
+	uri := &url.URL{Scheme: "file", Path: r.entry.path}
+	if err := openURI(uri); err != nil {
+		fyne.LogError("Could not open file", err)
+	}
+}
+
+// Reveal asks the file manager to show the file selected in its folder, or opens the folder if that fails.
+func (r *fileResult) Reveal() {
+	uri := &url.URL{Scheme: "file", Path: r.entry.path}
+	conn, err := dbus.SessionBus()
+	if err == nil {
+		err = conn.Object("org.freedesktop.FileManager1", "/org/freedesktop/FileManager1").Call(
+			"org.freedesktop.FileManager1.ShowItems", 0, []string{uri.String()}, "").Err
+		if err == nil {
+			return
+		}
+	}
+
+	dir := &url.URL{Scheme: "file", Path: filepath.Dir(r.entry.path)}
+	if err := openURI(dir); err != nil {
+		fyne.LogError("Could not open folder", err)
+	}
+}
+
+func (r *fileResult) Score() float64 {
+	return r.score
+}
+
+// Title returns the file name with the folder that it is in, shortening the home folder to "~".
+func (r *fileResult) Title() string {
+	dir := filepath.Dir(r.entry.path)
+	if rel, err := filepath.Rel(r.root, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		dir = filepath.Join("~", rel)
+	}
+
+	return filepath.Base(r.entry.path) + "  (" + dir + ")"
+}
+
+// mimeIcon returns a theme icon that represents the type of a file.
+func mimeIcon(path string, dir bool) fyne.Resource {
+	if dir {
+		return theme.FolderIcon()
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return theme.FileImageIcon()
+	case strings.HasPrefix(mimeType, "audio/"):
+		return theme.FileAudioIcon()
+	case strings.HasPrefix(mimeType, "video/"):
+		return theme.FileVideoIcon()
+	case strings.HasPrefix(mimeType, "text/"):
+		return theme.FileTextIcon()
+	case strings.HasPrefix(mimeType, "application/"):
+		return theme.FileApplicationIcon()
+	}
+	return theme.FileIcon()
+}
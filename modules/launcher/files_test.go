@@ -0,0 +1,150 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeFiles(t *testing.T, root string, paths ...string) {
+	for _, path := range paths {
+		full := filepath.Join(root, path)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(full), 0755))
+		assert.Nil(t, os.WriteFile(full, []byte("test"), 0644))
+	}
+}
+
+func TestFileIndex_Included(t *testing.T) {
+	index := newFileIndex("/home/user", []string{"Documents", "Music/*.mp3"}, []string{".*", "node_modules"})
+
+	assert.True(t, index.included("/home/user/Documents"))
+	assert.True(t, index.included("/home/user/Documents/report.txt"))
+	assert.True(t, index.included("/home/user/Music/song.mp3"))
+	assert.False(t, index.included("/home/user/Music/song.ogg"))
+	assert.False(t, index.included("/home/user/Downloads/file.txt"))
+	assert.False(t, index.included("/home/user/Documents/.hidden"))
+	assert.False(t, index.included("/home/user/Documents/code/node_modules/lib.js"))
+	assert.False(t, index.included("/home/user"))
+	assert.False(t, index.included("/tmp/Documents"))
+}
+
+func TestFileIndex_Search(t *testing.T) {
+	root := t.TempDir()
+	makeFiles(t, root, "Documents/report.txt", "Documents/old_report.txt", "Documents/reports/q1.txt",
+		"Documents/.secret/report.txt", "Music/support.mp3")
+	index := newFileIndex(root, []string{"*"}, []string{".*"})
+	go index.start()
+	defer index.close()
+
+	assert.Eventually(t, func() bool {
+		return len(index.search("q1", 5)) == 1 && len(index.search("port.mp3", 5)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	results := index.search("REPORT", 5)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, filepath.Join(root, "Documents", "report.txt"), results[0].entry.path)
+	assert.Equal(t, filepath.Join(root, "Documents", "reports"), results[1].entry.path)
+	assert.Equal(t, filepath.Join(root, "Documents", "old_report.txt"), results[2].entry.path)
+	assert.Equal(t, 1, len(index.search("REPORT", 1)))
+}
+
+func TestFileIndex_CloseBeforeStart(t *testing.T) {
+	index := newFileIndex(t.TempDir(), []string{"*"}, nil)
+	index.close()
+	index.close()
+
+	index.start()
+	assert.Nil(t, index.watcher)
+}
+
+func TestFileIndex_Matches(t *testing.T) {
+	index := newFileIndex("/home/user", []string{"*"}, []string{".*"})
+	assert.True(t, index.matches("/home/user", []string{"*"}, []string{".*"}))
+	assert.False(t, index.matches("/home/other", []string{"*"}, []string{".*"}))
+	assert.False(t, index.matches("/home/user", []string{"*"}, nil))
+}
+
+func TestFileIndex_Watch(t *testing.T) {
+	root := t.TempDir()
+	makeFiles(t, root, "Documents/report.txt")
+	index := newFileIndex(root, []string{"*"}, nil)
+	go index.start()
+	defer index.close()
+	assert.Eventually(t, func() bool {
+		return len(index.search("report", 5)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	makeFiles(t, root, "Documents/notes.txt", "Projects/plan/notes.md")
+	assert.Eventually(t, func() bool {
+		return len(index.search("notes", 5)) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, os.RemoveAll(filepath.Join(root, "Projects")))
+	assert.Eventually(t, func() bool {
+		return len(index.search("notes", 5)) == 1 && len(index.search("plan", 5)) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, os.Rename(filepath.Join(root, "Documents", "report.txt"), filepath.Join(root, "Documents", "final.txt")))
+	assert.Eventually(t, func() bool {
+		return len(index.search("report", 5)) == 0 && len(index.search("final", 5)) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFileIndex_WatchLimit(t *testing.T) {
+	assert.Greater(t, watchBudget(), 0)
+
+	root := t.TempDir()
+	makeFiles(t, root, "Documents/report.txt", "Projects/plan.md")
+	index := newFileIndex(root, []string{"*"}, nil)
+	index.maxWatched = 2
+	go index.start()
+	defer index.close()
+	assert.Eventually(t, func() bool {
+		return len(index.search("plan", 5)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.True(t, index.unwatched())
+	index.lock.RLock()
+	assert.Equal(t, 2, len(index.watched))
+	index.lock.RUnlock()
+	assert.True(t, index.rescan())
+	assert.Equal(t, 1, len(index.search("plan", 5)))
+}
+
+func TestFiles_LaunchSuggestions(t *testing.T) {
+	test.NewApp()
+	root := t.TempDir()
+	makeFiles(t, root, "Pictures/holiday.png")
+	index := newFileIndex(root, []string{"*"}, nil)
+	go index.start()
+	f := &files{index: index}
+	defer f.Destroy()
+
+	assert.Eventually(t, func() bool {
+		return len(f.LaunchSuggestions("holi")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, f.LaunchSuggestions("h"))
+
+	result := f.LaunchSuggestions("holiday")[0].(*fileResult)
+	assert.Equal(t, "holiday.png  (~/Pictures)", result.Title())
+	assert.Equal(t, filepath.Join(root, "Pictures", "holiday.png"), result.CopyText())
+	assert.Equal(t, theme.FileImageIcon(), result.Icon())
+}
+
+func TestMimeIcon(t *testing.T) {
+	test.NewApp()
+	assert.Equal(t, theme.FolderIcon(), mimeIcon("/home/user/Music", true))
+	assert.Equal(t, theme.FileTextIcon(), mimeIcon("notes.txt", false))
+	assert.Equal(t, theme.FileIcon(), mimeIcon("unknown", false))
+}
+
+func TestSplitGlobs(t *testing.T) {
+	assert.Equal(t, []string{".*", "node_modules"}, splitGlobs(" .*, node_modules,,"))
+	assert.Nil(t, splitGlobs(""))
+}
@@ -4,5 +4,9 @@ import "fyshos.com/fynedesk"
 
 func init() {
 	fynedesk.RegisterModule(calcMeta)
+	fynedesk.RegisterModule(commandsMeta)
+	fynedesk.RegisterModule(filesMeta)
+	fynedesk.RegisterModule(keywordsMeta)
+	fynedesk.RegisterModule(sshMeta)
 	fynedesk.RegisterModule(urlMeta)
 }
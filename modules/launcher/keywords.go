@@ -0,0 +1,147 @@
+package launcher
+
+import (
+	"net/url"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"fyshos.com/fynedesk"
+	wmTheme "fyshos.com/fynedesk/theme"
+)
+
+const (
+	// KeywordsPreference lists search keywords, one per line, as the keyword then a URL or command template.
+	// The "%s" in a template is replaced by the text typed after the keyword.
+	KeywordsPreference = "launcher.keywords"
+
+	// DefaultKeywords are the search keywords used until the user sets their own.
+	DefaultKeywords = `g https://www.google.com/search?q=%s
+ddg https://duckduckgo.com/?q=%s
+gh https://github.com/search?q=%s
+wiki https://en.wikipedia.org/w/index.php?search=%s
+man man %s`
+)
+
+var keywordsMeta = fynedesk.ModuleMetadata{
+	Name:        "Launcher: Search Keywords",
+	NewInstance: newKeywords,
+}
+
+// keyword expands the text typed after it into a URL to open, or a command to run in a terminal.
+type keyword struct {
+	name, template string
+}
+
+func (k *keyword) isURL() bool {
+	return strings.Contains(k.template, "://")
+}
+
+type keywords struct {
+	list []*keyword
+}
+
+func (k *keywords) Destroy() {
+}
+
+func (k *keywords) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
+	parts := strings.SplitN(strings.TrimLeft(input, " "), " ", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+		return nil
+	}
+
+	var list []fynedesk.LaunchSuggestion
+	for _, word := range k.list {
+		if strings.EqualFold(word.name, parts[0]) {
+			list = append(list, &keywordResult{keyword: word, query: strings.TrimSpace(parts[1])})
+		}
+	}
+	return list
+}
+
+func (k *keywords) Metadata() fynedesk.ModuleMetadata {
+	return keywordsMeta
+}
+
+// newKeywords creates a new module that expands search keywords in the launcher
+func newKeywords() fynedesk.Module {
+	return &keywords{list: parseKeywords(fyne.CurrentApp().Preferences().StringWithFallback(KeywordsPreference,
+		DefaultKeywords))}
+}
+
+// parseKeywords reads keyword lines, ignoring blank lines, comments and templates with nowhere to put the query.
+func parseKeywords(text string) []*keyword {
+	var list []*keyword
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 || !strings.Contains(parts[1], "%s") {
+			fyne.LogError("Invalid launcher keyword: "+line, nil)
+			continue
+		}
+		list = append(list, &keyword{name: parts[0], template: strings.TrimSpace(parts[1])})
+	}
+	return list
+}
+
+type keywordResult struct {
+	keyword *keyword
+	query   string
+}
+
+func (r *keywordResult) CopyText() string {
+	return r.expand()
+}
+
+func (r *keywordResult) Icon() fyne.Resource {
+	if r.keyword.isURL() {
+		return wmTheme.InternetIcon
+	}
+	return theme.ComputerIcon()
+}
+
+func (r *keywordResult) Launch() {
+	if !r.keyword.isURL() {
+		runInTerminal(r.expand())
+		return
+	}
+
+	u, err := url.Parse(r.expand())
+	if err != nil {
+		fyne.LogError("Could not parse URL", err)
+		return
+	}
+	if err = openURI(u); err != nil {
+		fyne.LogError("Could not open URL", err)
+	}
+}
+
+func (r *keywordResult) Title() string {
+	if !r.keyword.isURL() {
+		return "Run " + r.expand()
+	}
+
+	site := r.keyword.template
+	if u, err := url.Parse(site); err == nil {
+		site = strings.TrimPrefix(u.Host, "www.")
+	}
+	return "Search " + site + " for " + r.query
+}
+
+// expand puts the query into the keyword template, escaped for a URL or quoted for the shell.
+func (r *keywordResult) expand() string {
+	if r.keyword.isURL() {
+		return strings.ReplaceAll(r.keyword.template, "%s", url.QueryEscape(r.query))
+	}
+	return strings.ReplaceAll(r.keyword.template, "%s", shellQuote(r.query))
+}
+
+// shellQuote returns the text as a single shell word that will not be expanded.
+func shellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
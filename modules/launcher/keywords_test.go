@@ -0,0 +1,34 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeywords_LaunchSuggestions(t *testing.T) {
+	k := &keywords{list: parseKeywords(DefaultKeywords)}
+
+	items := k.LaunchSuggestions("gh fyne desk")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "Search github.com for fyne desk", items[0].Title())
+	assert.Equal(t, "https://github.com/search?q=fyne+desk", items[0].(*keywordResult).CopyText())
+
+	items = k.LaunchSuggestions("man it's")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, `Run man 'it'\''s'`, items[0].Title())
+
+	assert.Nil(t, k.LaunchSuggestions("gh"))
+	assert.Nil(t, k.LaunchSuggestions("gh  "))
+	assert.Nil(t, k.LaunchSuggestions("ghost town"))
+}
+
+func TestParseKeywords(t *testing.T) {
+	list := parseKeywords("# comment\n\nG https://www.google.com/search?q=%s\nbad https://example.com\nonly\n")
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, "G", list[0].name)
+	assert.True(t, list[0].isURL())
+
+	k := &keywords{list: list}
+	assert.Equal(t, 1, len(k.LaunchSuggestions("g test")))
+}
@@ -0,0 +1,37 @@
+package launcher
+
+import (
+	"net/url"
+
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/internal/icon"
+)
+
+// appEnvironment is implemented by desktops that set environment variables, such as scaling, for the apps they start.
+type appEnvironment interface {
+	AppEnvironment() []string
+}
+
+// fileOpener is implemented by apps that can be started with files or URLs to open.
+type fileOpener interface {
+	RunWithFiles(env, files []string) error
+}
+
+// openURI opens a file or URL with the default app for its MIME type,
+// or asks the system to open it if no default app is known.
+func openURI(uri *url.URL) error {
+	if desk := fynedesk.Instance(); desk != nil {
+		app := desk.IconProvider().DefaultAppForMime(icon.MimeTypeForURI(uri))
+		if opener, ok := app.(fileOpener); ok {
+			var env []string
+			if vars, ok := desk.(appEnvironment); ok {
+				env = vars.AppEnvironment()
+			}
+			return opener.RunWithFiles(env, []string{uri.String()})
+		}
+	}
+
+	return fyne.CurrentApp().OpenURL(uri)
+}
@@ -0,0 +1,60 @@
+package launcher
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/test"
+)
+
+func TestOpenURI_Environment(t *testing.T) {
+	app := &mockOpener{}
+	desk := &envDesktop{testDesktop: test.NewDesktop(), app: app}
+	fynedesk.SetInstance(desk)
+	defer fynedesk.SetInstance(nil)
+
+	uri, _ := url.Parse("file:///home/user/report.txt")
+	assert.Nil(t, openURI(uri))
+	assert.Equal(t, []string{"GDK_SCALE=2"}, app.env)
+	assert.Equal(t, []string{"file:///home/user/report.txt"}, app.files)
+}
+
+// testDesktop lets the test desktop be embedded, as its Desktop method hides a field of that name.
+type testDesktop = test.Desktop
+
+// envDesktop is a test desktop that sets an app environment and opens all files with one app.
+type envDesktop struct {
+	*testDesktop
+	app fynedesk.AppData
+}
+
+func (d *envDesktop) AppEnvironment() []string {
+	return []string{"GDK_SCALE=2"}
+}
+
+func (d *envDesktop) IconProvider() fynedesk.ApplicationProvider {
+	return &defaultAppProvider{ApplicationProvider: d.testDesktop.IconProvider(), app: d.app}
+}
+
+type defaultAppProvider struct {
+	fynedesk.ApplicationProvider
+	app fynedesk.AppData
+}
+
+func (p *defaultAppProvider) DefaultAppForMime(string) fynedesk.AppData {
+	return p.app
+}
+
+// mockOpener records how it was asked to open files.
+type mockOpener struct {
+	fynedesk.AppData
+	env, files []string
+}
+
+func (m *mockOpener) RunWithFiles(env, files []string) error {
+	m.env, m.files = env, files
+	return nil
+}
@@ -0,0 +1,218 @@
+package launcher
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/internal/icon"
+	wmTheme "fyshos.com/fynedesk/theme"
+)
+
+const (
+	// sshPrefix lists the known SSH hosts that match the text after it.
+	sshPrefix = "ssh "
+
+	maxSSHResults = 8
+	minSSHQuery   = 3
+
+	// sshPrefixScore ranks hosts listed by typing "ssh" first, and sshMatchScore ranks hosts that
+	// match other input below apps.
+	sshPrefixScore = 1.0
+	sshMatchScore  = 0.5
+)
+
+var sshMeta = fynedesk.ModuleMetadata{
+	Name:        "Launcher: SSH Hosts",
+	NewInstance: newSSHHosts,
+}
+
+// sshHost is a host that can be connected to, with the port if it is not the default.
+type sshHost struct {
+	name, port string
+}
+
+// sshHosts finds the hosts in the SSH config and known hosts files, reading them again when they change.
+type sshHosts struct {
+	dir string
+
+	lock     sync.Mutex
+	hosts    []*sshHost
+	modified map[string]time.Time
+}
+
+func (s *sshHosts) Destroy() {
+}
+
+func (s *sshHosts) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
+	query := strings.ToLower(strings.TrimLeft(input, " "))
+	score := sshMatchScore
+	if strings.HasPrefix(query, sshPrefix) {
+		query = strings.TrimSpace(query[len(sshPrefix):])
+		score = sshPrefixScore
+	} else if query = strings.TrimSpace(query); len(query) < minSSHQuery {
+		return nil
+	}
+
+	var list []fynedesk.LaunchSuggestion
+	for _, host := range s.hostList() {
+		name := strings.ToLower(host.name)
+		if (score == sshPrefixScore && !strings.Contains(name, query)) ||
+			(score == sshMatchScore && !strings.HasPrefix(name, query)) {
+			continue
+		}
+
+		list = append(list, &sshResult{host: host, score: score})
+		if len(list) == maxSSHResults {
+			break
+		}
+	}
+	return list
+}
+
+func (s *sshHosts) Metadata() fynedesk.ModuleMetadata {
+	return sshMeta
+}
+
+// hostList returns the known hosts, config file hosts first, loading them again if a file has changed.
+func (s *sshHosts) hostList() []*sshHost {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	config, known := filepath.Join(s.dir, "config"), filepath.Join(s.dir, "known_hosts")
+	changed := false
+	for _, path := range []string{config, known} {
+		var mod time.Time
+		if info, err := os.Stat(path); err == nil {
+			mod = info.ModTime()
+		}
+		if !s.modified[path].Equal(mod) {
+			s.modified[path] = mod
+			changed = true
+		}
+	}
+	if !changed {
+		return s.hosts
+	}
+
+	seen := make(map[string]bool)
+	s.hosts = nil
+	for _, host := range append(parseSSHConfig(config), parseKnownHosts(known)...) {
+		if !seen[host.name] {
+			seen[host.name] = true
+			s.hosts = append(s.hosts, host)
+		}
+	}
+	return s.hosts
+}
+
+// newSSHHosts creates a new module that opens SSH sessions to known hosts from the launcher
+func newSSHHosts() fynedesk.Module {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fyne.LogError("Unable to find home directory for SSH hosts", err)
+	}
+	return &sshHosts{dir: filepath.Join(home, ".ssh"), modified: make(map[string]time.Time)}
+}
+
+// parseKnownHosts returns the first host name of each line in a known_hosts file.
+// Hashed host names cannot be read, so they are skipped.
+func parseKnownHosts(path string) []*sshHost {
+	var hosts []*sshHost
+	readLines(path, func(fields []string) {
+		if fields[0][0] == '@' || fields[0][0] == '|' {
+			return // a marker or hashed host
+		}
+
+		name := strings.Split(fields[0], ",")[0]
+		port := ""
+		if strings.HasPrefix(name, "[") {
+			if host, p, err := net.SplitHostPort(name); err == nil {
+				name, port = strings.Trim(host, "[]"), p
+			}
+		}
+		hosts = append(hosts, &sshHost{name: name, port: port})
+	})
+	return hosts
+}
+
+// parseSSHConfig returns the hosts named in an SSH config file, sorted, leaving out patterns.
+func parseSSHConfig(path string) []*sshHost {
+	var hosts []*sshHost
+	readLines(path, func(fields []string) {
+		key := strings.SplitN(fields[0], "=", 2)
+		if !strings.EqualFold(key[0], "Host") {
+			return
+		}
+		if len(key) == 2 && key[1] != "" {
+			fields = append([]string{key[1]}, fields[1:]...)
+		} else {
+			fields = fields[1:]
+		}
+
+		for _, name := range fields {
+			if !strings.ContainsAny(name, "*?!") {
+				hosts = append(hosts, &sshHost{name: name})
+			}
+		}
+	})
+
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return hosts[i].name < hosts[j].name
+	})
+	return hosts
+}
+
+// readLines calls f with the fields of each line in a file, skipping blank lines and comments.
+func readLines(path string, f func([]string)) {
+	file, err := os.Open(path)
+	if err != nil {
+		return // no file is fine
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0][0] == '#' {
+			continue
+		}
+		f(fields)
+	}
+}
+
+type sshResult struct {
+	host  *sshHost
+	score float64
+}
+
+func (r *sshResult) Icon() fyne.Resource {
+	return wmTheme.InternetIcon
+}
+
+func (r *sshResult) Launch() {
+	args := []string{"ssh"}
+	if r.host.port != "" {
+		args = append(args, "-p", r.host.port)
+	}
+	startCommand(icon.TerminalCommand(append(args, r.host.name)))
+}
+
+func (r *sshResult) Score() float64 {
+	return r.score
+}
+
+func (r *sshResult) Title() string {
+	if r.host.port != "" {
+		return "SSH to " + r.host.name + ":" + r.host.port
+	}
+	return "SSH to " + r.host.name
+}
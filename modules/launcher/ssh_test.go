@@ -0,0 +1,49 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHHosts(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "config"), []byte(`# servers
+Host web db
+  HostName db.example.com
+host=backup
+Host *.internal !secret
+`), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "known_hosts"), []byte(`web ssh-ed25519 AAAA
+gitlab.com,172.65.251.78 ecdsa-sha2-nistp256 AAAA
+[dev.example.com]:2222 ssh-ed25519 AAAA
+|1|hashed= ssh-rsa AAAA
+@cert-authority *.example.com ssh-rsa AAAA
+`), 0644))
+	s := &sshHosts{dir: dir, modified: make(map[string]time.Time)}
+
+	var titles []string
+	for _, item := range s.LaunchSuggestions("ssh ") {
+		titles = append(titles, item.Title())
+	}
+	assert.Equal(t, []string{"SSH to backup", "SSH to db", "SSH to web", "SSH to gitlab.com",
+		"SSH to dev.example.com:2222"}, titles)
+
+	items := s.LaunchSuggestions("ssh example")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, sshPrefixScore, items[0].(*sshResult).Score())
+
+	items = s.LaunchSuggestions("gitl")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, sshMatchScore, items[0].(*sshResult).Score())
+	assert.Nil(t, s.LaunchSuggestions("db"))
+	assert.Nil(t, s.LaunchSuggestions("example"))
+
+	later := time.Now().Add(time.Minute)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "config"), []byte("Host newhost\n"), 0644))
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "config"), later, later))
+	assert.Equal(t, 1, len(s.LaunchSuggestions("newh")))
+}
@@ -64,5 +64,7 @@ func (r *urlResult) Launch() {
 		return
 	}
 
-	_ = fyne.CurrentApp().OpenURL(u)
+	if err = openURI(u); err != nil {
+		fyne.LogError("Could not open URL", err)
+	}
 }
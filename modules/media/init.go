@@ -0,0 +1,7 @@
+package media
+
+import "fyshos.com/fynedesk"
+
+func init() {
+	fynedesk.RegisterModule(mediaMeta)
+}
@@ -0,0 +1,211 @@
+package media
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"fyshos.com/fynedesk/internal/dbusutil"
+)
+
+const (
+	mprisPrefix = "org.mpris.MediaPlayer2."
+	mprisPath   = "/org/mpris/MediaPlayer2"
+	mprisIface  = "org.mpris.MediaPlayer2"
+	mprisPlayer = mprisIface + ".Player"
+
+	statusPlaying = "Playing"
+	statusPaused  = "Paused"
+)
+
+// track describes what a media player is playing, and what it can do.
+type track struct {
+	player                string // the bus name of the player
+	identity, status      string
+	id                    dbus.ObjectPath
+	title, artist, artURL string
+	length, position      time.Duration
+	canNext, canPrev      bool
+	canPlay, canPause     bool
+	canSeek               bool
+}
+
+// mprisClient talks to media players using the MPRIS D-Bus interface.
+type mprisClient struct {
+	conn    *dbus.Conn
+	signals *dbusutil.SignalWatch
+}
+
+func newMPRISClient(conn *dbus.Conn) *mprisClient {
+	return &mprisClient{conn: conn}
+}
+
+func (c *mprisClient) next(player string) error {
+	return c.call(player, "Next")
+}
+
+// players returns the bus names of all media players, sorted by name.
+func (c *mprisClient) players() ([]string, error) {
+	var names []string
+	if err := c.conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names); err != nil {
+		return nil, err
+	}
+
+	var list []string
+	for _, name := range names {
+		if strings.HasPrefix(name, mprisPrefix) {
+			list = append(list, name)
+		}
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+func (c *mprisClient) playPause(player string) error {
+	return c.call(player, "PlayPause")
+}
+
+func (c *mprisClient) position(player string) (time.Duration, error) {
+	v, err := c.conn.Object(player, mprisPath).GetProperty(mprisPlayer + ".Position")
+	if err != nil {
+		return 0, err
+	}
+
+	pos, _ := v.Value().(int64)
+	return time.Duration(pos) * time.Microsecond, nil
+}
+
+func (c *mprisClient) previous(player string) error {
+	return c.call(player, "Previous")
+}
+
+// seek moves playback of the current track to the position passed.
+func (c *mprisClient) seek(t *track, pos time.Duration) error {
+	return c.conn.Object(t.player, mprisPath).Call(mprisPlayer+".SetPosition", 0, t.id, pos.Microseconds()).Err
+}
+
+func (c *mprisClient) stop(player string) error {
+	return c.call(player, "Stop")
+}
+
+// track returns the current state of a media player.
+func (c *mprisClient) track(player string) (*track, error) {
+	obj := c.conn.Object(player, mprisPath)
+	props := make(map[string]dbus.Variant)
+	if err := obj.Call("org.freedesktop.DBus.Properties.GetAll", 0, mprisPlayer).Store(&props); err != nil {
+		return nil, err
+	}
+
+	t := &track{player: player}
+	if v, err := obj.GetProperty(mprisIface + ".Identity"); err == nil {
+		t.identity, _ = v.Value().(string)
+	}
+	if t.identity == "" {
+		t.identity = strings.TrimPrefix(player, mprisPrefix)
+	}
+
+	t.status, _ = props["PlaybackStatus"].Value().(string)
+	pos, _ := props["Position"].Value().(int64)
+	t.position = time.Duration(pos) * time.Microsecond
+	t.canNext, _ = props["CanGoNext"].Value().(bool)
+	t.canPrev, _ = props["CanGoPrevious"].Value().(bool)
+	t.canPlay, _ = props["CanPlay"].Value().(bool)
+	t.canPause, _ = props["CanPause"].Value().(bool)
+	t.canSeek, _ = props["CanSeek"].Value().(bool)
+
+	meta, _ := props["Metadata"].Value().(map[string]dbus.Variant)
+	t.id, _ = meta["mpris:trackid"].Value().(dbus.ObjectPath)
+	t.title, _ = meta["xesam:title"].Value().(string)
+	t.artURL, _ = meta["mpris:artUrl"].Value().(string)
+	if artists, ok := meta["xesam:artist"].Value().([]string); ok {
+		t.artist = strings.Join(artists, ", ")
+	}
+	switch length := meta["mpris:length"].Value().(type) {
+	case int64:
+		t.length = time.Duration(length) * time.Microsecond
+	case uint64:
+		t.length = time.Duration(length) * time.Microsecond
+	}
+	return t, nil
+}
+
+// unwatch removes the player match rules so track and playback changes are no longer reported.
+func (c *mprisClient) unwatch() {
+	c.signals.Stop()
+	c.signals = nil
+}
+
+// watch calls changed when players start or exit, or when a player changes track or playback state.
+func (c *mprisClient) watch(changed func()) error {
+	signals, err := dbusutil.WatchSignals(c.conn, [][]dbus.MatchOption{
+		{dbus.WithMatchInterface("org.freedesktop.DBus"), dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchArg0Namespace("org.mpris.MediaPlayer2")},
+		{dbus.WithMatchObjectPath(mprisPath)},
+	}, func(sig *dbus.Signal) {
+		if isMediaChange(sig) {
+			changed()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.signals = signals
+	return nil
+}
+
+func (c *mprisClient) call(player, method string) error {
+	return c.conn.Object(player, mprisPath).Call(mprisPlayer+"."+method, 0).Err
+}
+
+// choosePlayer picks which player to show, preferring the current one unless another has started playing.
+func choosePlayer(current string, tracks []*track) string {
+	for _, t := range tracks {
+		if t.player == current && t.status == statusPlaying {
+			return current
+		}
+	}
+	for _, t := range tracks {
+		if t.status == statusPlaying {
+			return t.player
+		}
+	}
+	for _, t := range tracks {
+		if t.player == current {
+			return current
+		}
+	}
+	for _, t := range tracks {
+		if t.status == statusPaused {
+			return t.player
+		}
+	}
+
+	if len(tracks) == 0 {
+		return ""
+	}
+	return tracks[0].player
+}
+
+// isMediaChange returns true for signals that mean a player has changed state, started or exited.
+func isMediaChange(sig *dbus.Signal) bool {
+	switch sig.Name {
+	case "org.freedesktop.DBus.NameOwnerChanged":
+		if len(sig.Body) == 0 {
+			return false
+		}
+		name, _ := sig.Body[0].(string)
+		return strings.HasPrefix(name, mprisPrefix)
+	case "org.freedesktop.DBus.Properties.PropertiesChanged":
+		if len(sig.Body) == 0 {
+			return false
+		}
+		iface, _ := sig.Body[0].(string)
+		return iface == mprisPlayer
+	case mprisPlayer + ".Seeked":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,153 @@
+package media
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk/internal/dbusutil/dbustest"
+)
+
+type mockPlayer struct {
+	calls []string
+}
+
+func (p *mockPlayer) Next() *dbus.Error {
+	p.calls = append(p.calls, "Next")
+	return nil
+}
+
+func (p *mockPlayer) PlayPause() *dbus.Error {
+	p.calls = append(p.calls, "PlayPause")
+	return nil
+}
+
+func (p *mockPlayer) Previous() *dbus.Error {
+	p.calls = append(p.calls, "Previous")
+	return nil
+}
+
+func (p *mockPlayer) SetPosition(id dbus.ObjectPath, pos int64) *dbus.Error {
+	p.calls = append(p.calls, "SetPosition "+string(id)+" "+time.Duration(pos*1000).String())
+	return nil
+}
+
+func (p *mockPlayer) Stop() *dbus.Error {
+	p.calls = append(p.calls, "Stop")
+	return nil
+}
+
+func TestChoosePlayer(t *testing.T) {
+	a := &track{player: mprisPrefix + "a", status: statusPaused}
+	b := &track{player: mprisPrefix + "b", status: statusPlaying}
+	c := &track{player: mprisPrefix + "c", status: "Stopped"}
+
+	assert.Equal(t, "", choosePlayer("", nil))
+	assert.Equal(t, b.player, choosePlayer("", []*track{a, b, c}))
+	assert.Equal(t, b.player, choosePlayer(a.player, []*track{a, b, c}))
+	assert.Equal(t, a.player, choosePlayer("", []*track{a, c}))
+	assert.Equal(t, c.player, choosePlayer(c.player, []*track{a, c}))
+	assert.Equal(t, c.player, choosePlayer("", []*track{c}))
+}
+
+func TestFormatTime(t *testing.T) {
+	assert.Equal(t, "0:00", formatTime(0))
+	assert.Equal(t, "3:07", formatTime(187*time.Second))
+	assert.Equal(t, "1:02:03", formatTime(time.Hour+2*time.Minute+3*time.Second))
+}
+
+func TestMPRISClient(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	player := exportMockPlayer(t, server)
+	c := newMPRISClient(client)
+
+	names, err := c.players()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{mprisPrefix + "test"}, names)
+
+	song, err := c.track(names[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "Test Player", song.identity)
+	assert.Equal(t, statusPlaying, song.status)
+	assert.Equal(t, "Song", song.title)
+	assert.Equal(t, "Band, Singer", song.artist)
+	assert.Equal(t, "file:///tmp/cover.png", song.artURL)
+	assert.Equal(t, 3*time.Minute, song.length)
+	assert.Equal(t, 42*time.Second, song.position)
+	assert.True(t, song.canNext)
+	assert.False(t, song.canPrev)
+	assert.True(t, song.canSeek)
+
+	pos, err := c.position(names[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 42*time.Second, pos)
+
+	assert.Nil(t, c.playPause(names[0]))
+	assert.Nil(t, c.next(names[0]))
+	assert.Nil(t, c.previous(names[0]))
+	assert.Nil(t, c.stop(names[0]))
+	assert.Nil(t, c.seek(song, time.Minute))
+	assert.Equal(t, []string{"PlayPause", "Next", "Previous", "Stop", "SetPosition /track/1 1m0s"}, player.calls)
+}
+
+func TestMPRISClient_Watch(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	exportMockPlayer(t, server)
+	c := newMPRISClient(client)
+
+	changed := make(chan bool, 1)
+	assert.Nil(t, c.watch(func() {
+		select {
+		case changed <- true:
+		default:
+		}
+	}))
+	assert.Nil(t, server.Emit(mprisPath, mprisPlayer+".Seeked", int64(0)))
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Error("Change was not reported")
+	}
+
+	c.unwatch()
+	assert.Nil(t, server.Emit(mprisPath, mprisPlayer+".Seeked", int64(0)))
+	select {
+	case <-changed:
+		t.Error("Change was reported after unwatch")
+	case <-time.After(time.Second / 10):
+	}
+}
+
+func exportMockPlayer(t *testing.T, conn *dbus.Conn) *mockPlayer {
+	player := &mockPlayer{}
+	assert.Nil(t, conn.Export(player, mprisPath, mprisPlayer))
+	_, err := prop.Export(conn, mprisPath, prop.Map{
+		mprisIface: {"Identity": {Value: "Test Player"}},
+		mprisPlayer: {
+			"PlaybackStatus": {Value: statusPlaying},
+			"Position":       {Value: int64(42000000)},
+			"CanGoNext":      {Value: true},
+			"CanGoPrevious":  {Value: false},
+			"CanPlay":        {Value: true},
+			"CanPause":       {Value: true},
+			"CanSeek":        {Value: true},
+			"Metadata": {Value: map[string]dbus.Variant{
+				"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/track/1")),
+				"mpris:length":  dbus.MakeVariant(int64(180000000)),
+				"mpris:artUrl":  dbus.MakeVariant("file:///tmp/cover.png"),
+				"xesam:title":   dbus.MakeVariant("Song"),
+				"xesam:artist":  dbus.MakeVariant([]string{"Band", "Singer"}),
+			}},
+		},
+	})
+	assert.Nil(t, err)
+
+	reply, err := conn.RequestName(mprisPrefix+"test", dbus.NameFlagDoNotQueue)
+	assert.Nil(t, err)
+	assert.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+	return player
+}
@@ -0,0 +1,396 @@
+package media
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/godbus/dbus/v5"
+
+	"fyshos.com/fynedesk"
+)
+
+var mediaMeta = fynedesk.ModuleMetadata{
+	Name:        "Media Player",
+	NewInstance: newMedia,
+}
+
+const artSize = 48
+
+type media struct {
+	client *mprisClient
+
+	lock    sync.RWMutex
+	current *track
+	tracks  []*track
+	pinned  bool // the user chose the player, so don't switch to others as they play
+	artURL  string
+	seeking bool
+
+	content               *fyne.Container
+	art                   *canvas.Image
+	title, artist         *widget.Label
+	players               *widget.Select
+	seek                  *widget.Slider
+	prev, playPause, next *widget.Button
+	elapsed, length       *widget.Label
+	narrow                bool
+	stopTicker            chan bool
+}
+
+func (m *media) Destroy() {
+	if m.client != nil {
+		m.client.unwatch()
+	}
+	if m.stopTicker != nil {
+		close(m.stopTicker)
+	}
+}
+
+func (m *media) Metadata() fynedesk.ModuleMetadata {
+	return mediaMeta
+}
+
+func (m *media) Shortcuts() map[*fynedesk.Shortcut]func() {
+	return map[*fynedesk.Shortcut]func(){
+		fynedesk.NewShortcut("Play or Pause Media", fynedesk.KeyMediaPlay, fynedesk.AnyModifier): func() {
+			m.control("Failed to play or pause", m.client.playPause)
+		},
+		fynedesk.NewShortcut("Next Track", fynedesk.KeyMediaNext, fynedesk.AnyModifier): func() {
+			m.control("Failed to skip to next track", m.client.next)
+		},
+		fynedesk.NewShortcut("Previous Track", fynedesk.KeyMediaPrevious, fynedesk.AnyModifier): func() {
+			m.control("Failed to skip to previous track", m.client.previous)
+		},
+		fynedesk.NewShortcut("Stop Media", fynedesk.KeyMediaStop, fynedesk.AnyModifier): func() {
+			m.control("Failed to stop playback", m.client.stop)
+		},
+	}
+}
+
+func (m *media) StatusAreaWidget() fyne.CanvasObject {
+	if m.client == nil {
+		return nil
+	}
+	m.narrow = fynedesk.Instance() != nil && fynedesk.Instance().Settings().NarrowWidgetPanel()
+
+	m.art = &canvas.Image{Resource: theme.MediaMusicIcon(), FillMode: canvas.ImageFillContain}
+	m.art.SetMinSize(fyne.NewSize(artSize, artSize))
+	m.title = &widget.Label{TextStyle: fyne.TextStyle{Bold: true}, Truncation: fyne.TextTruncateEllipsis}
+	m.artist = &widget.Label{Truncation: fyne.TextTruncateEllipsis}
+	m.players = widget.NewSelect(nil, m.choosePlayer)
+	m.seek = widget.NewSlider(0, 1)
+	m.seek.Step = 1
+	m.seek.OnChanged = func(float64) {
+		m.lock.Lock()
+		m.seeking = true
+		m.lock.Unlock()
+	}
+	m.seek.OnChangeEnded = m.seekTo
+	m.elapsed = widget.NewLabel("")
+	m.length = widget.NewLabel("")
+
+	m.prev = &widget.Button{Icon: theme.MediaSkipPreviousIcon(), Importance: widget.LowImportance, OnTapped: func() {
+		m.control("Failed to skip to previous track", m.client.previous)
+	}}
+	m.playPause = &widget.Button{Icon: theme.MediaPlayIcon(), Importance: widget.LowImportance, OnTapped: func() {
+		m.control("Failed to play or pause", m.client.playPause)
+	}}
+	m.next = &widget.Button{Icon: theme.MediaSkipNextIcon(), Importance: widget.LowImportance, OnTapped: func() {
+		m.control("Failed to skip to next track", m.client.next)
+	}}
+
+	buttons := container.NewCenter(container.NewHBox(m.prev, m.playPause, m.next))
+	if m.narrow {
+		m.content = container.NewVBox(m.playPause)
+	} else {
+		info := container.NewBorder(nil, nil, m.art, nil, container.NewVBox(m.title, m.artist))
+		seek := container.NewBorder(nil, nil, m.elapsed, m.length, m.seek)
+		m.content = container.NewVBox(m.players, info, seek, buttons)
+	}
+
+	if err := m.client.watch(m.refresh); err != nil {
+		fyne.LogError("Unable to watch for media players", err)
+	}
+	m.refresh()
+
+	m.stopTicker = make(chan bool)
+	go m.tick()
+	return m.content
+}
+
+func (m *media) choosePlayer(name string) {
+	m.lock.Lock()
+	for _, t := range m.tracks {
+		if t.identity == name && (m.current == nil || t.player != m.current.player) {
+			m.current = t
+			m.pinned = true
+		}
+	}
+	m.lock.Unlock()
+
+	m.refresh()
+}
+
+// control runs an action on the current player in the background.
+func (m *media) control(failure string, action func(string) error) {
+	m.lock.RLock()
+	current := m.current
+	m.lock.RUnlock()
+	if current == nil {
+		return
+	}
+
+	go func() {
+		if err := action(current.player); err != nil {
+			fyne.LogError(failure, err)
+		}
+	}()
+}
+
+func (m *media) loadArt(artURL string) {
+	m.lock.Lock()
+	if m.artURL == artURL {
+		m.lock.Unlock()
+		return
+	}
+	m.artURL = artURL
+	m.lock.Unlock()
+
+	if artURL == "" {
+		m.art.Resource = theme.MediaMusicIcon()
+		m.art.Refresh()
+		return
+	}
+
+	go func() {
+		res, err := fetchArt(artURL)
+		if err != nil {
+			fyne.LogError("Failed to load album art", err)
+			res = theme.MediaMusicIcon()
+		}
+
+		m.lock.RLock()
+		stale := m.artURL != artURL
+		m.lock.RUnlock()
+		if stale {
+			return
+		}
+		m.art.Resource = res
+		m.art.Refresh()
+	}()
+}
+
+// refresh looks up all media players and shows the one that is playing.
+func (m *media) refresh() {
+	var tracks []*track
+	if names, err := m.client.players(); err == nil {
+		for _, name := range names {
+			if t, err := m.client.track(name); err == nil {
+				tracks = append(tracks, t)
+			}
+		}
+	}
+
+	m.lock.Lock()
+	old := ""
+	if m.current != nil {
+		old = m.current.player
+	}
+	name := ""
+	if m.pinned {
+		for _, t := range tracks {
+			if t.player == old {
+				name = old
+			}
+		}
+		m.pinned = name != ""
+	}
+	if name == "" {
+		name = choosePlayer(old, tracks)
+	}
+
+	m.current = nil
+	for _, t := range tracks {
+		if t.player == name {
+			m.current = t
+		}
+	}
+	m.tracks = tracks
+	current := m.current
+	m.lock.Unlock()
+
+	m.show(current, tracks)
+}
+
+func (m *media) seekTo(val float64) {
+	m.lock.Lock()
+	m.seeking = false
+	current := m.current
+	m.lock.Unlock()
+	if current == nil || !current.canSeek || current.id == "" {
+		return
+	}
+
+	go func() {
+		if err := m.client.seek(current, time.Duration(val)*time.Second); err != nil {
+			fyne.LogError("Failed to seek", err)
+		}
+	}()
+}
+
+func (m *media) setPosition(pos, length time.Duration) {
+	m.elapsed.SetText(formatTime(pos))
+	m.lock.RLock()
+	seeking := m.seeking
+	m.lock.RUnlock()
+	if seeking {
+		return
+	}
+
+	m.seek.Max = length.Seconds()
+	m.seek.Value = pos.Seconds()
+	m.seek.Refresh()
+}
+
+func (m *media) show(t *track, tracks []*track) {
+	if t == nil {
+		m.content.Hide()
+		return
+	}
+
+	if t.status == statusPlaying {
+		m.playPause.SetIcon(theme.MediaPauseIcon())
+	} else {
+		m.playPause.SetIcon(theme.MediaPlayIcon())
+	}
+	enable(m.playPause, t.canPlay || t.canPause)
+	if m.narrow {
+		m.content.Show()
+		return
+	}
+	enable(m.prev, t.canPrev)
+	enable(m.next, t.canNext)
+
+	var names []string
+	for _, item := range tracks {
+		names = append(names, item.identity)
+	}
+	m.players.Options = names
+	m.players.Selected = t.identity
+	m.players.Refresh()
+	if len(tracks) > 1 {
+		m.players.Show()
+	} else {
+		m.players.Hide()
+	}
+
+	title := t.title
+	if title == "" {
+		title = t.identity
+	}
+	m.title.SetText(title)
+	m.artist.SetText(t.artist)
+	m.loadArt(t.artURL)
+
+	m.length.SetText(formatTime(t.length))
+	m.setPosition(t.position, t.length)
+	if t.canSeek && t.length > 0 {
+		m.seek.Show()
+	} else {
+		m.seek.Hide()
+	}
+	m.content.Show()
+}
+
+// tick updates the position of the playing track, as players don't send a signal for this.
+func (m *media) tick() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopTicker:
+			return
+		case <-ticker.C:
+			m.lock.RLock()
+			current := m.current
+			m.lock.RUnlock()
+			if m.narrow || current == nil || current.status != statusPlaying {
+				continue
+			}
+
+			if pos, err := m.client.position(current.player); err == nil {
+				m.setPosition(pos, current.length)
+			}
+		}
+	}
+}
+
+// newMedia creates a new module that will show media controls in the status area
+func newMedia() fynedesk.Module {
+	m := &media{}
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		fyne.LogError("Unable to connect to session bus", err)
+		return m
+	}
+
+	m.client = newMPRISClient(conn)
+	return m
+}
+
+func enable(w fyne.Disableable, on bool) {
+	if on {
+		w.Enable()
+	} else {
+		w.Disable()
+	}
+}
+
+// fetchArt loads album art from a local file or web address.
+func fetchArt(artURL string) (fyne.Resource, error) {
+	u, err := url.Parse(artURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "file":
+		return fyne.LoadResourceFromPath(u.Path)
+	case "http", "https":
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Get(artURL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return fyne.NewStaticResource(u.Path, data), nil
+	}
+
+	return nil, fmt.Errorf("unsupported album art address %s", artURL)
+}
+
+// formatTime returns a track position in minutes and seconds, such as "3:07".
+func formatTime(d time.Duration) string {
+	secs := int(d.Round(time.Second).Seconds())
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, (secs/60)%60, secs%60)
+	}
+
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
@@ -0,0 +1,202 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"os"
+)
+
+// gifEncoder writes an animated GIF to a file as frames arrive, so that long recordings do not fill memory.
+// Only the part of each frame that changed is stored, and unchanged frames extend the previous one,
+// so that mostly static screen recordings stay small. As this changes the delay of the previous frame
+// it is held back until the next change, or until the encoder is closed.
+type gifEncoder struct {
+	file  *os.File
+	out   *bufio.Writer
+	delay int // hundredths of a second per frame
+
+	pending      *image.Paletted
+	pendingDelay int
+	prev         *image.Paletted
+}
+
+func newGIFEncoder(path string, size image.Point, fps int) (*gifEncoder, error) {
+	delay := 100 / fps
+	if delay < 2 { // many viewers slow down delays shorter than this
+		delay = 2
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	g := &gifEncoder{file: f, out: bufio.NewWriter(f), delay: delay}
+	if err = g.writeHeader(size); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *gifEncoder) close() error {
+	err := g.writePending()
+	if err == nil {
+		err = g.out.WriteByte(0x3b) // trailer
+	}
+	if err == nil {
+		err = g.out.Flush()
+	}
+	if closeErr := g.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (g *gifEncoder) writeFrame(img *image.NRGBA) error {
+	p := quantize(img)
+	frame := p
+	if g.prev != nil {
+		changed := changedBounds(g.prev, p)
+		if changed.Empty() {
+			g.pendingDelay += g.delay
+			return nil
+		}
+		frame = copyPaletted(p, changed)
+	}
+
+	if err := g.writePending(); err != nil {
+		return err
+	}
+	g.prev = p
+	g.pending, g.pendingDelay = frame, g.delay
+	return nil
+}
+
+// writeHeader writes the screen size, the web safe palette and the extension that makes the animation loop.
+func (g *gifEncoder) writeHeader(size image.Point) error {
+	header := []byte("GIF89a")
+	header = appendUint16(header, size.X)
+	header = appendUint16(header, size.Y)
+	header = append(header, 0xf7, 0, 0) // a global palette of 256 colours, background colour and pixel aspect
+	for i := 0; i < 256; i++ {
+		if i >= len(palette.WebSafe) {
+			header = append(header, 0, 0, 0)
+			continue
+		}
+		r, g, b, _ := palette.WebSafe[i].RGBA()
+		header = append(header, uint8(r>>8), uint8(g>>8), uint8(b>>8))
+	}
+	header = append(header, 0x21, 0xff, 0x0b)
+	header = append(header, "NETSCAPE2.0"...)
+	header = append(header, 0x03, 0x01, 0, 0, 0) // loop forever
+
+	_, err := g.out.Write(header)
+	return err
+}
+
+// writePending writes the frame that is waiting for its delay to be known.
+func (g *gifEncoder) writePending() error {
+	if g.pending == nil {
+		return nil
+	}
+	frame := g.pending
+	g.pending = nil
+
+	bounds := frame.Bounds()
+	desc := []byte{0x21, 0xf9, 0x04, gif.DisposalNone << 2}
+	desc = appendUint16(desc, g.pendingDelay)
+	desc = append(desc, 0, 0, 0x2c)
+	desc = appendUint16(desc, bounds.Min.X)
+	desc = appendUint16(desc, bounds.Min.Y)
+	desc = appendUint16(desc, bounds.Dx())
+	desc = appendUint16(desc, bounds.Dy())
+	desc = append(desc, 0, 8) // use the global palette, and the LZW code size for 256 colours
+	if _, err := g.out.Write(desc); err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+	lzwOut := lzw.NewWriter(&data, lzw.LSB, 8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		if _, err := lzwOut.Write(frame.Pix[frame.PixOffset(bounds.Min.X, y):][:bounds.Dx()]); err != nil {
+			return err
+		}
+	}
+	if err := lzwOut.Close(); err != nil {
+		return err
+	}
+
+	for data.Len() > 0 { // image data is split into blocks of up to 255 bytes
+		block := data.Next(255)
+		if err := g.out.WriteByte(byte(len(block))); err != nil {
+			return err
+		}
+		if _, err := g.out.Write(block); err != nil {
+			return err
+		}
+	}
+	return g.out.WriteByte(0)
+}
+
+func appendUint16(b []byte, v int) []byte {
+	return append(b, uint8(v), uint8(v>>8))
+}
+
+// changedBounds returns the smallest rectangle containing all pixels that differ between two frames.
+func changedBounds(a, b *image.Paletted) image.Rectangle {
+	bounds := b.Bounds()
+	changed := image.Rectangle{}
+	width := bounds.Dx()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		rowA := a.Pix[a.PixOffset(bounds.Min.X, y):][:width]
+		rowB := b.Pix[b.PixOffset(bounds.Min.X, y):][:width]
+		if bytes.Equal(rowA, rowB) {
+			continue
+		}
+
+		left, right := 0, width
+		for rowA[left] == rowB[left] {
+			left++
+		}
+		for rowA[right-1] == rowB[right-1] {
+			right--
+		}
+		changed = changed.Union(image.Rect(bounds.Min.X+left, y, bounds.Min.X+right, y+1))
+	}
+	return changed
+}
+
+// copyPaletted returns a new image containing just the area of the source passed.
+func copyPaletted(src *image.Paletted, area image.Rectangle) *image.Paletted {
+	out := image.NewPaletted(area, src.Palette)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		copy(out.Pix[out.PixOffset(area.Min.X, y):][:area.Dx()], src.Pix[src.PixOffset(area.Min.X, y):])
+	}
+	return out
+}
+
+// quantize converts an image to the web safe palette.
+// The index is calculated directly from the colour rather than searching the palette, so this is fast enough
+// to keep up with recording a full screen.
+func quantize(img *image.NRGBA) *image.Paletted {
+	bounds := img.Bounds()
+	out := image.NewPaletted(bounds, palette.WebSafe)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		in := img.Pix[img.PixOffset(bounds.Min.X, y):]
+		row := out.Pix[out.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			r, g, b := in[x*4], in[x*4+1], in[x*4+2]
+			row[x] = webSafeLevel(r)*36 + webSafeLevel(g)*6 + webSafeLevel(b)
+		}
+	}
+	return out
+}
+
+// webSafeLevel returns the nearest of the six intensities used in the web safe palette, which are 0x33 apart.
+func webSafeLevel(c uint8) uint8 {
+	return uint8((int(c) + 0x19) / 0x33)
+}
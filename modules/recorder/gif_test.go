@@ -0,0 +1,81 @@
+package recorder
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedBounds(t *testing.T) {
+	a := image.NewPaletted(image.Rect(0, 0, 10, 10), palette.WebSafe)
+	b := image.NewPaletted(image.Rect(0, 0, 10, 10), palette.WebSafe)
+	assert.True(t, changedBounds(a, b).Empty())
+
+	b.SetColorIndex(2, 3, 5)
+	b.SetColorIndex(6, 7, 5)
+	assert.Equal(t, image.Rect(2, 3, 7, 8), changedBounds(a, b))
+}
+
+func TestGIFEncoder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.gif")
+	enc, err := newGIFEncoder(path, image.Pt(8, 8), 10)
+	assert.Nil(t, err)
+
+	frame := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	assert.Nil(t, enc.writeFrame(frame))
+	assert.Nil(t, enc.writeFrame(frame))
+	changed := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	changed.Set(4, 4, color.White)
+	assert.Nil(t, enc.writeFrame(changed))
+	assert.Nil(t, enc.close())
+
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, anim.Config.Width)
+	assert.Equal(t, []int{20, 10}, anim.Delay)
+	assert.Equal(t, image.Rect(4, 4, 5, 5), anim.Image[1].Bounds())
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, anim.Image[1].At(4, 4))
+	assert.Equal(t, color.RGBA{A: 0xff}, anim.Image[0].At(1, 1))
+}
+
+func TestQuantize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{R: 0xff, G: 0x30, B: 0x01, A: 0xff})
+	img.Set(1, 0, color.NRGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff})
+
+	p := quantize(img)
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0x33, B: 0x00, A: 0xff}, p.At(0, 0))
+	assert.Equal(t, color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}, p.At(1, 0))
+}
+
+func TestGIFEncoder_LargeFrame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "large.gif")
+	enc, err := newGIFEncoder(path, image.Pt(100, 100), 30)
+	assert.Nil(t, err)
+
+	frame := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			frame.Set(x, y, color.NRGBA{R: uint8(x * 51), G: uint8(y * 51), B: uint8(x * y), A: 0xff})
+		}
+	}
+	assert.Nil(t, enc.writeFrame(frame))
+	assert.Nil(t, enc.close())
+
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3}, anim.Delay)
+	assert.Equal(t, quantize(frame).Pix, anim.Image[0].Pix)
+}
@@ -0,0 +1,7 @@
+package recorder
+
+import "fyshos.com/fynedesk"
+
+func init() {
+	fynedesk.RegisterModule(recorderMeta)
+}
@@ -0,0 +1,384 @@
+package recorder
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/internal/ui"
+	"fyshos.com/fynedesk/wm"
+)
+
+var recorderMeta = fynedesk.ModuleMetadata{
+	Name:        "Screen Recorder",
+	NewInstance: newRecorder,
+}
+
+const (
+	defaultFPS = 10
+
+	formatGIF  = "gif"
+	formatWebM = "webm"
+	formatMP4  = "mp4"
+)
+
+var frameRates = []int{5, 10, 15, 30}
+
+type recordMode int
+
+const (
+	recordScreen recordMode = iota
+	recordWindow
+	recordRegion
+)
+
+// active is the recording in progress. It is kept outside of the module so that recording continues
+// when the module is reloaded after a settings change.
+var active struct {
+	lock sync.Mutex
+	rec  *recording
+	path string
+}
+
+type recorder struct {
+	lock sync.Mutex
+
+	content        *fyne.Container
+	record, stop   *widget.Button
+	active         *fyne.Container
+	elapsed        *widget.Label
+	narrow         bool
+	stopTicker     chan bool
+	checkEncoder   sync.Once
+	canEncodeVideo bool
+}
+
+// Destroy leaves a recording running if the module is being reloaded, so that it can be stopped from the new one.
+// If the module has been turned off the recording is stopped and saved, and the user is told where it is.
+func (r *recorder) Destroy() {
+	r.lock.Lock()
+	if r.stopTicker != nil {
+		close(r.stopTicker)
+		r.stopTicker = nil
+	}
+	r.lock.Unlock()
+
+	if !isEnabled() {
+		r.finish()
+	}
+}
+
+func (r *recorder) Metadata() fynedesk.ModuleMetadata {
+	return recorderMeta
+}
+
+func (r *recorder) Shortcuts() map[*fynedesk.Shortcut]func() {
+	return map[*fynedesk.Shortcut]func(){
+		fynedesk.NewShortcut("Start or Stop Screen Recording", fyne.KeyR, fynedesk.UserModifier|fyne.KeyModifierShift): func() {
+			if r.isRecording() {
+				r.finish()
+				return
+			}
+			r.start(recordScreen)
+		},
+	}
+}
+
+func (r *recorder) StatusAreaWidget() fyne.CanvasObject {
+	r.narrow = fynedesk.Instance() != nil && fynedesk.Instance().Settings().NarrowWidgetPanel()
+
+	r.record = &widget.Button{Icon: theme.MediaRecordIcon(), Importance: widget.LowImportance}
+	r.record.OnTapped = r.showMenu
+	r.stop = &widget.Button{Icon: theme.MediaStopIcon(), Importance: widget.DangerImportance, OnTapped: r.finish}
+	r.elapsed = widget.NewLabel("")
+	if r.narrow {
+		r.active = container.NewVBox(r.stop)
+	} else {
+		r.record.SetText("Record")
+		r.stop.SetText("Stop")
+		dot := canvas.NewCircle(theme.ErrorColor())
+		indicator := container.NewCenter(container.NewGridWrap(fyne.NewSquareSize(theme.IconInlineSize()/2), dot))
+		r.active = container.NewBorder(nil, nil, container.NewHBox(indicator, r.elapsed), r.stop)
+	}
+
+	r.content = container.NewVBox(r.record, r.active)
+	active.lock.Lock()
+	rec := active.rec
+	active.lock.Unlock()
+	r.showRecording(rec != nil)
+	if rec != nil { // continue showing a recording that started before the module was reloaded
+		r.startTicker(rec)
+	}
+	return r.content
+}
+
+// begin starts recording the area of the images returned by capture, or the whole image if the area is empty.
+func (r *recorder) begin(capture func() image.Image, area image.Rectangle) {
+	format := r.format()
+	path := filepath.Join(videosDir(), recordingFileName(format))
+	rec, err := startRecording(capture, area, r.frameRate(), func(size image.Point, fps int) (frameEncoder, error) {
+		if format == formatGIF {
+			return newGIFEncoder(path, size, fps)
+		}
+		return newVideoEncoder(path, format, size, fps)
+	})
+	if err != nil {
+		fyne.LogError("Unable to start recording", err)
+		wm.SendNotification(wm.NewNotification("Recording failed", err.Error()))
+		return
+	}
+
+	active.lock.Lock()
+	active.rec, active.path = rec, path
+	active.lock.Unlock()
+
+	r.showRecording(true)
+	r.startTicker(rec)
+}
+
+// finish stops the current recording and notifies the user once the file is saved.
+func (r *recorder) finish() {
+	active.lock.Lock()
+	rec, path := active.rec, active.path
+	active.rec = nil
+	active.lock.Unlock()
+
+	r.lock.Lock()
+	if r.stopTicker != nil {
+		close(r.stopTicker)
+		r.stopTicker = nil
+	}
+	r.lock.Unlock()
+	if rec == nil {
+		return
+	}
+
+	r.showRecording(false)
+	go func() {
+		if err := rec.finish(); err != nil {
+			fyne.LogError("Failed to save recording", err)
+			wm.SendNotification(wm.NewNotification("Recording failed", err.Error()))
+			return
+		}
+
+		wm.SendNotification(wm.NewNotification("Recording saved", path))
+	}()
+}
+
+// format returns the file format chosen by the user, falling back to GIF if no video encoder is installed.
+func (r *recorder) format() string {
+	format := fyne.CurrentApp().Preferences().StringWithFallback("recorder.format", formatGIF)
+	if format != formatGIF && !r.hasVideoEncoder() {
+		fyne.LogError("Unable to find "+encoderCommand+", recording as GIF", nil)
+		return formatGIF
+	}
+	return format
+}
+
+func (r *recorder) frameRate() int {
+	return fyne.CurrentApp().Preferences().IntWithFallback("recorder.fps", defaultFPS)
+}
+
+func (r *recorder) hasVideoEncoder() bool {
+	r.checkEncoder.Do(func() {
+		r.canEncodeVideo = hasVideoEncoder()
+	})
+	return r.canEncodeVideo
+}
+
+func (r *recorder) isRecording() bool {
+	active.lock.Lock()
+	defer active.lock.Unlock()
+	return active.rec != nil
+}
+
+func (r *recorder) menu() *fyne.Menu {
+	prefs := fyne.CurrentApp().Preferences()
+	formats := []string{formatGIF}
+	if r.hasVideoEncoder() {
+		formats = append(formats, formatWebM, formatMP4)
+	}
+	current := prefs.StringWithFallback("recorder.format", formatGIF)
+	var formatItems []*fyne.MenuItem
+	for _, f := range formats {
+		format := f
+		item := fyne.NewMenuItem(strings.ToUpper(format), func() {
+			prefs.SetString("recorder.format", format)
+		})
+		item.Checked = format == current || (format == formatGIF && !r.hasVideoEncoder())
+		formatItems = append(formatItems, item)
+	}
+	formatMenu := fyne.NewMenuItem("Format", nil)
+	formatMenu.ChildMenu = fyne.NewMenu("", formatItems...)
+
+	rate := r.frameRate()
+	var rateItems []*fyne.MenuItem
+	for _, f := range frameRates {
+		fps := f
+		item := fyne.NewMenuItem(strconv.Itoa(fps)+" fps", func() {
+			prefs.SetInt("recorder.fps", fps)
+		})
+		item.Checked = fps == rate
+		rateItems = append(rateItems, item)
+	}
+	rateMenu := fyne.NewMenuItem("Frame Rate", nil)
+	rateMenu.ChildMenu = fyne.NewMenu("", rateItems...)
+
+	return fyne.NewMenu("",
+		fyne.NewMenuItem("Record Screen", func() { r.start(recordScreen) }),
+		fyne.NewMenuItem("Record Window", func() { r.start(recordWindow) }),
+		fyne.NewMenuItem("Record Region", func() { r.start(recordRegion) }),
+		fyne.NewMenuItemSeparator(),
+		formatMenu,
+		rateMenu,
+	)
+}
+
+func (r *recorder) showMenu() {
+	c := fyne.CurrentApp().Driver().CanvasForObject(r.record)
+	if c == nil {
+		return
+	}
+
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(r.record)
+	widget.ShowPopUpMenuAtPosition(r.menu(), c, pos.Add(fyne.NewPos(0, r.record.Size().Height)))
+}
+
+func (r *recorder) showRecording(on bool) {
+	if r.content == nil {
+		return
+	}
+
+	if on {
+		r.elapsed.SetText(formatElapsed(0))
+		r.record.Hide()
+		r.active.Show()
+	} else {
+		r.active.Hide()
+		r.record.Show()
+	}
+}
+
+// start records the screen, the top window or a region chosen by the user.
+func (r *recorder) start(mode recordMode) {
+	if r.isRecording() {
+		return
+	}
+
+	manager := fynedesk.Instance().WindowManager()
+	switch mode {
+	case recordWindow:
+		win := manager.TopWindow()
+		if win == nil {
+			fyne.LogError("Unable to record window with no window visible", nil)
+			return
+		}
+		go r.begin(win.Capture, image.Rectangle{})
+	case recordRegion:
+		ui.SelectScreenRegion(func(area image.Rectangle) {
+			if area.Empty() {
+				return
+			}
+			r.begin(manager.Capture, area)
+		})
+	default:
+		go r.begin(manager.Capture, image.Rectangle{})
+	}
+}
+
+// startTicker shows the recording time in the status area until the recording finishes.
+func (r *recorder) startTicker(rec *recording) {
+	r.lock.Lock()
+	r.stopTicker = make(chan bool)
+	stopTicker := r.stopTicker
+	r.lock.Unlock()
+
+	go r.tick(rec, stopTicker)
+}
+
+// tick updates the recording time shown in the status area.
+func (r *recorder) tick(rec *recording, stop chan bool) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if r.elapsed != nil {
+				r.elapsed.SetText(formatElapsed(rec.elapsed()))
+			}
+		}
+	}
+}
+
+// newRecorder creates a new module that records the screen, with controls in the status area.
+func newRecorder() fynedesk.Module {
+	return &recorder{}
+}
+
+// isEnabled returns true if the user has this module turned on.
+func isEnabled() bool {
+	desk := fynedesk.Instance()
+	if desk == nil {
+		return false
+	}
+
+	for _, name := range desk.Settings().ModuleNames() {
+		if name == recorderMeta.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatElapsed returns a recording length in minutes and seconds, such as "1:05".
+func formatElapsed(d time.Duration) string {
+	secs := int(d.Truncate(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+func recordingFileName(format string) string {
+	now := time.Now().Format("20060102T150405") // YYYYMMDD"T"HHMMSS
+	return "recording-" + now + "." + format
+}
+
+// videosDir returns the user's videos folder, falling back to their home directory.
+func videosDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return os.TempDir()
+	}
+
+	const xdg = "xdg-user-dir"
+	if out, err := exec.Command(xdg, "VIDEOS").Output(); err == nil {
+		location := strings.TrimSpace(string(out))
+		if location != "" && location != home && isDir(location) {
+			return location
+		}
+	}
+
+	if dir := filepath.Join(home, "Videos"); isDir(dir) {
+		return dir
+	}
+	return home
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,129 @@
+package recorder
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"time"
+)
+
+// frameEncoder writes the frames of a recording to a file.
+// Frames are passed at the frame rate of the recording and are all the same size.
+type frameEncoder interface {
+	close() error
+	writeFrame(*image.NRGBA) error
+}
+
+// recording captures frames at a regular interval and passes them to an encoder until it is stopped.
+type recording struct {
+	capture func() image.Image
+	area    image.Rectangle // the part of each capture to keep
+	size    image.Point
+	fps     int
+	enc     frameEncoder
+	started time.Time
+
+	last    *image.NRGBA
+	written int
+	stop    chan bool
+	done    chan error
+}
+
+// startRecording begins capturing frames from the function passed.
+// The area describes the part of each captured image to record; if it is empty the whole image is used.
+// The encoder is created once the size of the frames is known.
+func startRecording(capture func() image.Image, area image.Rectangle, fps int,
+	newEncoder func(size image.Point, fps int) (frameEncoder, error)) (*recording, error) {
+	first := capture()
+	if first == nil {
+		return nil, errors.New("unable to capture the screen")
+	}
+	if area.Empty() {
+		area = first.Bounds()
+	}
+	area = area.Canon().Intersect(first.Bounds())
+	size := evenSize(area.Size())
+	if size.X == 0 || size.Y == 0 {
+		return nil, errors.New("the area to record is too small")
+	}
+	if fps < 1 {
+		fps = defaultFPS
+	}
+
+	enc, err := newEncoder(size, fps)
+	if err != nil {
+		return nil, err
+	}
+	r := &recording{capture: capture, area: area, size: size, fps: fps, enc: enc, started: time.Now(),
+		stop: make(chan bool), done: make(chan error, 1)}
+	go r.run(first)
+	return r, nil
+}
+
+// elapsed returns the length of the recording so far.
+func (r *recording) elapsed() time.Duration {
+	return time.Since(r.started)
+}
+
+// finish stops capturing and waits for the file to be written.
+func (r *recording) finish() error {
+	close(r.stop)
+	return <-r.done
+}
+
+// frame crops an image to the recorded area, keeping the same size if a window has been resized.
+func (r *recording) frame(img image.Image) *image.NRGBA {
+	out := image.NewNRGBA(image.Rect(0, 0, r.size.X, r.size.Y))
+	draw.Draw(out, out.Bounds(), img, r.area.Min, draw.Src)
+	return out
+}
+
+func (r *recording) run(first image.Image) {
+	ticker := time.NewTicker(time.Second / time.Duration(r.fps))
+	defer ticker.Stop()
+
+	err := r.write(r.frame(first))
+	for err == nil {
+		select {
+		case <-r.stop:
+			r.done <- r.enc.close()
+			return
+		case <-ticker.C:
+			if img := r.capture(); img != nil {
+				err = r.write(r.frame(img))
+			} else {
+				err = r.write(nil)
+			}
+		}
+	}
+
+	// an encoding error ends the recording, but we still wait to be stopped
+	_ = r.enc.close()
+	<-r.stop
+	r.done <- err
+}
+
+// write passes a frame to the encoder, repeating it if capturing fell behind so that the recording keeps time.
+// If the frame is nil then the previous frame is repeated.
+func (r *recording) write(img *image.NRGBA) error {
+	if img == nil {
+		img = r.last
+	}
+	r.last = img
+
+	due := int(r.elapsed()*time.Duration(r.fps)/time.Second) + 1
+	if due <= r.written {
+		due = r.written + 1
+	}
+	for ; r.written < due; r.written++ {
+		if err := r.enc.writeFrame(img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evenSize rounds a size down to even numbers, as many video formats require.
+func evenSize(size image.Point) image.Point {
+	return image.Pt(size.X&^1, size.Y&^1)
+}
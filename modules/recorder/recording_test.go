@@ -0,0 +1,130 @@
+package recorder
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/test"
+)
+
+type mockEncoder struct {
+	frames []*image.NRGBA
+	closed bool
+	err    error
+
+	lock sync.Mutex
+}
+
+func (m *mockEncoder) close() error {
+	m.lock.Lock()
+	m.closed = true
+	m.lock.Unlock()
+	return nil
+}
+
+func (m *mockEncoder) isClosed() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.closed
+}
+
+func (m *mockEncoder) writeFrame(img *image.NRGBA) error {
+	m.frames = append(m.frames, img)
+	return m.err
+}
+
+func TestEvenSize(t *testing.T) {
+	assert.Equal(t, image.Pt(10, 6), evenSize(image.Pt(11, 6)))
+	assert.Equal(t, image.Pt(0, 0), evenSize(image.Pt(1, 1)))
+}
+
+func TestFormatElapsed(t *testing.T) {
+	assert.Equal(t, "0:00", formatElapsed(0))
+	assert.Equal(t, "1:05", formatElapsed(65*time.Second+500*time.Millisecond))
+}
+
+func TestRecording(t *testing.T) {
+	screen := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	screen.Set(5, 5, color.White)
+	enc := &mockEncoder{}
+	var size image.Point
+	rec, err := startRecording(func() image.Image {
+		return screen
+	}, image.Rect(5, 5, 12, 9), 50, func(s image.Point, fps int) (frameEncoder, error) {
+		size = s
+		assert.Equal(t, 50, fps)
+		return enc, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, image.Pt(6, 4), size)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(t, rec.finish())
+	assert.True(t, enc.closed)
+	assert.GreaterOrEqual(t, len(enc.frames), 3)
+	for _, f := range enc.frames {
+		assert.Equal(t, image.Rect(0, 0, 6, 4), f.Bounds())
+		assert.Equal(t, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, f.At(0, 0))
+	}
+}
+
+func TestRecording_Errors(t *testing.T) {
+	_, err := startRecording(func() image.Image {
+		return nil
+	}, image.Rectangle{}, 10, func(image.Point, int) (frameEncoder, error) {
+		return &mockEncoder{}, nil
+	})
+	assert.NotNil(t, err)
+
+	screen := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	_, err = startRecording(func() image.Image {
+		return screen
+	}, image.Rect(30, 30, 40, 40), 10, func(image.Point, int) (frameEncoder, error) {
+		return &mockEncoder{}, nil
+	})
+	assert.NotNil(t, err)
+
+	enc := &mockEncoder{err: errors.New("disk full")}
+	rec, err := startRecording(func() image.Image {
+		return screen
+	}, image.Rectangle{}, 10, func(image.Point, int) (frameEncoder, error) {
+		return enc, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, enc.err, rec.finish())
+	assert.True(t, enc.closed)
+}
+
+func TestRecorder_Destroy(t *testing.T) {
+	desk := test.NewDesktopWithWM(nil)
+	settings := desk.Settings().(*test.Settings)
+	settings.SetModuleNames([]string{recorderMeta.Name})
+	defer fynedesk.SetInstance(nil)
+
+	enc := &mockEncoder{}
+	rec, err := startRecording(func() image.Image {
+		return image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	}, image.Rectangle{}, 50, func(image.Point, int) (frameEncoder, error) {
+		return enc, nil
+	})
+	assert.Nil(t, err)
+	active.rec, active.path = rec, "test.gif"
+
+	r := &recorder{}
+	r.Destroy() // reloaded, so the recording continues
+	reloaded := &recorder{}
+	assert.True(t, reloaded.isRecording())
+	assert.False(t, enc.isClosed())
+
+	settings.SetModuleNames(nil)
+	reloaded.Destroy() // turned off, so the recording is saved
+	assert.False(t, reloaded.isRecording())
+	assert.Eventually(t, enc.isClosed, time.Second, time.Millisecond*10)
+}
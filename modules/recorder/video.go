@@ -0,0 +1,74 @@
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+const encoderCommand = "ffmpeg"
+
+// videoEncoder streams raw frames to a local ffmpeg process that compresses them to a video file.
+type videoEncoder struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+}
+
+func newVideoEncoder(path, format string, size image.Point, fps int) (*videoEncoder, error) {
+	args, err := encoderArgs(path, format, size, fps)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(encoderCommand, args...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &videoEncoder{cmd: cmd, in: in}, nil
+}
+
+func (v *videoEncoder) close() error {
+	err := v.in.Close()
+	if waitErr := v.cmd.Wait(); waitErr != nil {
+		return fmt.Errorf("video encoder failed: %w", waitErr)
+	}
+	return err
+}
+
+func (v *videoEncoder) writeFrame(img *image.NRGBA) error {
+	_, err := v.in.Write(img.Pix)
+	return err
+}
+
+// encoderArgs returns the ffmpeg arguments to read raw RGBA frames from standard input and
+// encode them in the format requested.
+func encoderArgs(path, format string, size image.Point, fps int) ([]string, error) {
+	var codec []string
+	switch format {
+	case formatWebM:
+		codec = []string{"-c:v", "libvpx-vp9", "-deadline", "realtime", "-b:v", "0", "-crf", "32"}
+	case formatMP4:
+		codec = []string{"-c:v", "libx264", "-preset", "veryfast", "-crf", "23", "-movflags", "+faststart"}
+	default:
+		return nil, errors.New("unsupported video format " + format)
+	}
+
+	args := []string{"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "rgba", "-s", fmt.Sprintf("%dx%d", size.X, size.Y),
+		"-r", strconv.Itoa(fps), "-i", "-"}
+	args = append(args, codec...)
+	return append(args, "-pix_fmt", "yuv420p", path), nil
+}
+
+// hasVideoEncoder returns true if ffmpeg is installed so that recordings can be saved as video.
+func hasVideoEncoder() bool {
+	_, err := exec.LookPath(encoderCommand)
+	return err == nil
+}
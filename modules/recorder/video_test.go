@@ -0,0 +1,23 @@
+package recorder
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderArgs(t *testing.T) {
+	args, err := encoderArgs("/tmp/out.webm", formatWebM, image.Pt(640, 480), 15)
+	assert.Nil(t, err)
+	assert.Contains(t, args, "640x480")
+	assert.Contains(t, args, "libvpx-vp9")
+	assert.Equal(t, "/tmp/out.webm", args[len(args)-1])
+
+	args, err = encoderArgs("/tmp/out.mp4", formatMP4, image.Pt(640, 480), 15)
+	assert.Nil(t, err)
+	assert.Contains(t, args, "libx264")
+
+	_, err = encoderArgs("/tmp/out.avi", "avi", image.Pt(640, 480), 15)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,118 @@
+package status
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var (
+	backlightRoot = "/sys/class/backlight"
+
+	// backlightPreference lists backlight types, best first, as recommended by the kernel documentation
+	backlightPreference = []string{"firmware", "platform", "raw"}
+	drmCardPrefix       = regexp.MustCompile(`^card[0-9]+-`)
+)
+
+// backlight is a panel backlight found in sysfs.
+type backlight struct {
+	name, kind, output string
+	max                int
+
+	set func(name string, value int) error
+}
+
+// findBacklights returns the backlights on this computer, with preferred types first.
+func findBacklights() []*backlight {
+	dirs, err := os.ReadDir(backlightRoot)
+	if err != nil {
+		return nil
+	}
+
+	var list []*backlight
+	for _, dir := range dirs {
+		path := filepath.Join(backlightRoot, dir.Name())
+		max, err := readSysInt(filepath.Join(path, "max_brightness"))
+		if err != nil || max <= 0 {
+			continue
+		}
+
+		kind, _ := os.ReadFile(filepath.Join(path, "type"))
+		light := &backlight{name: dir.Name(), kind: strings.TrimSpace(string(kind)), max: max, set: setBacklight}
+		if dev, err := filepath.EvalSymlinks(filepath.Join(path, "device")); err == nil {
+			if base := filepath.Base(dev); drmCardPrefix.MatchString(base) {
+				light.output = drmCardPrefix.ReplaceAllString(base, "")
+			}
+		}
+		list = append(list, light)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return backlightRank(list[i].kind) < backlightRank(list[j].kind)
+	})
+	return list
+}
+
+func (l *backlight) brightness() (float64, error) {
+	path := filepath.Join(backlightRoot, l.name)
+	val, err := readSysInt(filepath.Join(path, "actual_brightness"))
+	if err != nil {
+		val, err = readSysInt(filepath.Join(path, "brightness"))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return float64(val) / float64(l.max), nil
+}
+
+func (l *backlight) connector() string {
+	return l.output
+}
+
+func (l *backlight) setBrightness(val float64) error {
+	return l.set(l.name, int(math.Round(val*float64(l.max))))
+}
+
+func backlightRank(kind string) int {
+	for i, k := range backlightPreference {
+		if k == kind {
+			return i
+		}
+	}
+	return len(backlightPreference)
+}
+
+func readSysInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// setBacklight asks logind to change the brightness, so that root access is not required.
+// If logind is not available then we try to write the value directly.
+func setBacklight(name string, value int) error {
+	conn, err := dbus.SystemBus()
+	if err == nil {
+		err = conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto").
+			Call("org.freedesktop.login1.Session.SetBrightness", 0, "backlight", name, uint32(value)).Err
+		if err == nil {
+			return nil
+		}
+	}
+
+	path := filepath.Join(backlightRoot, name, "brightness")
+	if os.WriteFile(path, []byte(strconv.Itoa(value)), 0644) != nil {
+		return err // report the logind failure, writing directly is only a fallback
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package status
 
 import (
+	"fmt"
 	"image/color"
-	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -11,8 +13,11 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/godbus/dbus/v5"
+
 	"fyshos.com/fynedesk"
 	wmtheme "fyshos.com/fynedesk/theme"
+	"fyshos.com/fynedesk/wm"
 )
 
 var batteryMeta = fynedesk.ModuleMetadata{
@@ -20,34 +25,51 @@ var batteryMeta = fynedesk.ModuleMetadata{
 	NewInstance: newBattery,
 }
 
+const (
+	// batteryPollInterval is how often the battery is read when UPower is not available to report changes.
+	batteryPollInterval = time.Second * 10
+
+	batteryLow      = 10 // percentage at which the user is warned
+	batteryCritical = 5  // percentage at which the critical action is taken
+
+	criticalNothing   = "Nothing"
+	criticalSuspend   = "Suspend"
+	criticalHibernate = "Hibernate"
+	criticalPowerOff  = "Power Off"
+)
+
+const (
+	warnedNone = iota
+	warnedLow
+	warnedCritical
+)
+
 type battery struct {
 	bar  *widget.ProgressBar
-	done bool
 	icon *widget.Icon
 	fill *canvas.Rectangle
-}
 
-func pickChargeOrEnergy() (string, string) {
-	_, err := os.Stat("/sys/class/power_supply/BAT0/charge_now")
-	if err != nil {
-		return "/sys/class/power_supply/BAT0/energy_now", "/sys/class/power_supply/BAT0/energy_full"
-	}
-	return "/sys/class/power_supply/BAT0/charge_now", "/sys/class/power_supply/BAT0/charge_full"
-}
-
-func (b *battery) batteryTick() {
-	tick := time.NewTicker(time.Second * 10)
-	go func() {
-		for !b.done {
-			<-tick.C
-			val, _ := b.value()
-			b.setValue(val)
-		}
-	}()
+	client   *upowerClient // nil if UPower is not running, so the battery is read directly
+	stop     chan struct{}
+	state    uint32
+	remain   time.Duration
+	warned   int
+	details  fyne.Window
+	list     *fyne.Container
+	detailed bool
 }
 
 func (b *battery) Destroy() {
-	b.done = true
+	if b.client != nil {
+		b.client.unwatch()
+	}
+	if b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+	if b.details != nil {
+		b.details.Close()
+	}
 }
 
 func (b *battery) Metadata() fynedesk.ModuleMetadata {
@@ -55,24 +77,87 @@ func (b *battery) Metadata() fynedesk.ModuleMetadata {
 }
 
 func (b *battery) StatusAreaWidget() fyne.CanvasObject {
-	if _, err := b.value(); err != nil {
-		return nil
+	if conn, err := dbus.SystemBus(); err == nil {
+		b.client = newUPowerClient(conn)
+		if list, err := b.client.batteries(); err != nil {
+			b.client = nil // UPower is not running
+		} else if len(list) == 0 {
+			return nil
+		}
+	}
+	if b.client == nil {
+		if _, err := b.value(); err != nil {
+			return nil
+		}
 	}
 
 	b.bar = widget.NewProgressBar()
+	b.bar.TextFormatter = b.barText
 	b.icon = widget.NewIcon(wmtheme.BatteryIcon)
 	b.fill = canvas.NewRectangle(theme.ForegroundColor())
 	prop := canvas.NewRectangle(color.Transparent)
 	prop.SetMinSize(b.icon.MinSize().Add(fyne.NewSize(theme.Padding()*4, 0)))
-	icon := container.NewStack(container.NewCenter(prop, b.icon), container.NewWithoutLayout(b.fill))
+	tap := &widget.Button{Importance: widget.LowImportance, OnTapped: b.showDetails}
+	icon := container.NewStack(tap, container.NewCenter(prop, b.icon), container.NewWithoutLayout(b.fill))
 
-	// Set first value then tick
-	val, _ := b.value()
-	b.setValue(val)
-	go b.batteryTick()
+	if b.client == nil {
+		b.stop = make(chan struct{})
+		go b.poll(b.stop)
+	} else if err := b.client.watch(b.refresh); err != nil {
+		fyne.LogError("Unable to watch for battery changes", err)
+	}
+	b.refresh()
 	return container.New(&handleNarrow{}, icon, b.bar)
 }
 
+// batteries returns all batteries from UPower, or the system battery if it is not running.
+func (b *battery) batteries() ([]*powerDevice, error) {
+	if b.client != nil {
+		return b.client.batteries()
+	}
+
+	dev, err := b.systemBattery()
+	if err != nil {
+		return nil, err
+	}
+	return []*powerDevice{dev}, nil
+}
+
+func (b *battery) barText() string {
+	text := strconv.Itoa(int(b.bar.Value*100)) + "%"
+	if b.remain > 0 {
+		text += " (" + formatDuration(b.remain) + ")"
+	}
+	return text
+}
+
+// checkLevel warns the user when the battery is low, and takes the configured action when it is critical.
+func (b *battery) checkLevel(dev *powerDevice) {
+	if dev.state != upowerStateDischarging {
+		b.warned = warnedNone
+		return
+	}
+
+	if dev.percent <= batteryCritical && b.warned < warnedCritical {
+		b.warned = warnedCritical
+		action := b.criticalAction()
+		body := "Battery is almost empty, connect to power now."
+		if action != criticalNothing {
+			body = fmt.Sprintf("Battery is almost empty, the computer will %s now.", strings.ToLower(action))
+		}
+		wm.SendNotification(wm.NewNotification("Battery Critical", body))
+		go b.runCriticalAction(action)
+	} else if dev.percent <= batteryLow && b.warned < warnedLow {
+		b.warned = warnedLow
+		wm.SendNotification(wm.NewNotification("Battery Low",
+			fmt.Sprintf("%d%% remaining, about %s.", int(dev.percent), formatDuration(dev.toEmpty))))
+	}
+}
+
+func (b *battery) criticalAction() string {
+	return fyne.CurrentApp().Preferences().StringWithFallback("battery.criticalaction", criticalSuspend)
+}
+
 func (b *battery) positionFill(val float64) {
 	max := float32(12)
 	down := max - max*float32(val)
@@ -80,18 +165,116 @@ func (b *battery) positionFill(val float64) {
 	b.fill.Resize(fyne.NewSize(8, 13-down))
 }
 
+// poll reads the battery regularly when UPower is not running, until stop is closed.
+func (b *battery) poll(stop chan struct{}) {
+	tick := time.NewTicker(batteryPollInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			b.refresh()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// refresh updates the status area, and the battery details if they are showing, to match the current battery state.
+func (b *battery) refresh() {
+	var dev *powerDevice
+	var err error
+	if b.client != nil {
+		dev, err = b.client.displayDevice()
+	} else {
+		dev, err = b.systemBattery()
+	}
+	system := err == nil && dev.kind == upowerTypeBattery
+	if !system {
+		// no system battery, show the first peripheral instead
+		list, err := b.batteries()
+		if err != nil || len(list) == 0 {
+			return
+		}
+		dev = list[0]
+	}
+
+	b.state = dev.state
+	b.remain = dev.toEmpty
+	if dev.charging() {
+		b.remain = dev.toFull
+	}
+	b.setValue(dev.percent / 100)
+	if system {
+		b.checkLevel(dev)
+	}
+
+	if b.detailed {
+		b.refreshDetails()
+	}
+}
+
+func (b *battery) refreshDetails() {
+	list, err := b.batteries()
+	if err != nil {
+		fyne.LogError("Failed to list batteries", err)
+		return
+	}
+
+	var rows []fyne.CanvasObject
+	for _, dev := range list {
+		name := dev.model
+		if name == "" {
+			name = "Battery"
+		}
+		bar := widget.NewProgressBar()
+		bar.SetValue(dev.percent / 100)
+		rows = append(rows, widget.NewCard(name, batteryStatus(dev), bar))
+	}
+	b.list.Objects = rows
+	b.list.Refresh()
+}
+
+func (b *battery) runCriticalAction(action string) {
+	method := ""
+	switch action {
+	case criticalSuspend:
+		method = "Suspend"
+	case criticalHibernate:
+		method = "Hibernate"
+	case criticalPowerOff:
+		method = "PowerOff"
+	default:
+		return
+	}
+
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		fyne.LogError("Unable to connect to the system bus", err)
+		return
+	}
+	call := conn.Object("org.freedesktop.login1", "/org/freedesktop/login1").
+		Call("org.freedesktop.login1.Manager."+method, 0, false)
+	if call.Err != nil {
+		fyne.LogError("Failed to "+action+" on critical battery", call.Err)
+	}
+}
+
 func (b *battery) setValue(val float64) {
 	b.bar.SetValue(val)
 	b.positionFill(val)
-	if on, err := b.powered(); on || err != nil {
+	switch {
+	case b.state == upowerStateCharging:
+		b.icon.SetResource(wmtheme.BatteryChargingIcon)
+		b.fill.Hide()
+	case b.state == upowerStateFullyCharged:
 		b.icon.SetResource(wmtheme.PowerIcon)
 		b.fill.Hide()
-	} else if val < 0.1 {
+	case val < 0.1:
 		b.icon.SetResource(theme.NewErrorThemedResource(wmtheme.BatteryIcon))
 		b.fill.FillColor = theme.ErrorColor()
 		b.fill.Refresh()
 		b.fill.Show()
-	} else {
+	default:
 		b.icon.SetResource(wmtheme.BatteryIcon)
 		b.fill.FillColor = theme.ForegroundColor()
 		b.fill.Refresh()
@@ -99,11 +282,87 @@ func (b *battery) setValue(val float64) {
 	}
 }
 
+// systemBattery reads the state of the computer's battery without UPower, so there are no time estimates.
+func (b *battery) systemBattery() (*powerDevice, error) {
+	val, err := b.value()
+	if err != nil {
+		return nil, err
+	}
+
+	dev := &powerDevice{kind: upowerTypeBattery, state: upowerStateDischarging, percent: val * 100, powerSupply: true}
+	if on, err := b.powered(); on || err != nil {
+		dev.state = upowerStateCharging
+		if val >= 1 {
+			dev.state = upowerStateFullyCharged
+		}
+	}
+	return dev, nil
+}
+
+func (b *battery) showDetails() {
+	b.detailed = true
+	if b.details != nil {
+		b.refreshDetails()
+		b.details.Show()
+		b.details.RequestFocus()
+		return
+	}
+
+	b.list = container.NewVBox()
+	action := widget.NewSelect([]string{criticalNothing, criticalSuspend, criticalHibernate, criticalPowerOff},
+		func(action string) {
+			fyne.CurrentApp().Preferences().SetString("battery.criticalaction", action)
+		})
+	action.SetSelected(b.criticalAction())
+
+	w := fyne.CurrentApp().NewWindow("Battery")
+	w.SetContent(container.NewBorder(nil, widget.NewForm(widget.NewFormItem("When Critical", action)), nil, nil,
+		container.NewVScroll(b.list)))
+	w.SetCloseIntercept(func() {
+		b.detailed = false
+		w.Hide()
+	})
+	w.Resize(fyne.NewSize(320, 360))
+	b.details = w
+	b.refreshDetails()
+	w.Show()
+}
+
 // newBattery creates a new module that will show battery level in the status area
 func newBattery() fynedesk.Module {
 	return &battery{}
 }
 
+// batteryStatus describes whether a battery is charging and how long until it is full or empty.
+func batteryStatus(dev *powerDevice) string {
+	switch dev.state {
+	case upowerStateCharging:
+		if dev.toFull > 0 {
+			return "Charging, " + formatDuration(dev.toFull) + " until full"
+		}
+		return "Charging"
+	case upowerStateDischarging:
+		if dev.toEmpty > 0 {
+			return formatDuration(dev.toEmpty) + " remaining"
+		}
+		return "Discharging"
+	case upowerStateFullyCharged:
+		return "Fully charged"
+	}
+
+	return strconv.Itoa(int(dev.percent)) + "%"
+}
+
+// formatDuration returns a short description of a time estimate, such as "2h 05m".
+func formatDuration(d time.Duration) string {
+	mins := int(d.Round(time.Minute).Minutes())
+	if mins < 60 {
+		return strconv.Itoa(mins) + "m"
+	}
+
+	return fmt.Sprintf("%dh %02dm", mins/60, mins%60)
+}
+
 type handleNarrow struct{}
 
 func (h *handleNarrow) Layout(objects []fyne.CanvasObject, size fyne.Size) {
@@ -11,6 +11,14 @@ import (
 	"fyne.io/fyne/v2"
 )
 
+func pickChargeOrEnergy() (string, string) {
+	_, err := os.Stat("/sys/class/power_supply/BAT0/charge_now")
+	if err != nil {
+		return "/sys/class/power_supply/BAT0/energy_now", "/sys/class/power_supply/BAT0/energy_full"
+	}
+	return "/sys/class/power_supply/BAT0/charge_now", "/sys/class/power_supply/BAT0/charge_full"
+}
+
 func (b *battery) powered() (bool, error) {
 	status, err := os.ReadFile("/sys/class/power_supply/BAT0/status")
 	if err != nil {
@@ -2,9 +2,16 @@ package status
 
 import (
 	"testing"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/test"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk/internal/dbusutil/dbustest"
 )
 
 func TestBattery_Render(t *testing.T) {
@@ -38,3 +45,53 @@ func TestBattery_Render_LowWarning(t *testing.T) {
 	b.setValue(0.09)
 	test.AssertImageMatches(t, "battery_low.png", w.Canvas().Capture())
 }
+
+type mockUPower struct{}
+
+func (m *mockUPower) EnumerateDevices() ([]dbus.ObjectPath, *dbus.Error) {
+	return []dbus.ObjectPath{upowerPath + "/devices/line_power_AC", upowerPath + "/devices/mouse_0",
+		upowerPath + "/devices/battery_BAT0"}, nil
+}
+
+func (m *mockUPower) GetDisplayDevice() (dbus.ObjectPath, *dbus.Error) {
+	return upowerPath + "/devices/DisplayDevice", nil
+}
+
+func TestUPowerClient(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	assert.Nil(t, server.Export(&mockUPower{}, upowerPath, upowerIface))
+	devices := map[dbus.ObjectPath]map[string]*prop.Prop{
+		"line_power_AC": {"Type": {Value: uint32(upowerTypeLinePower)}},
+		"mouse_0": {"Type": {Value: uint32(5)}, "Model": {Value: "Mouse"}, "Percentage": {Value: 60.0},
+			"IsPresent": {Value: true}, "PowerSupply": {Value: false}},
+		"battery_BAT0": {"Type": {Value: uint32(upowerTypeBattery)}, "Percentage": {Value: 42.0},
+			"State": {Value: uint32(upowerStateDischarging)}, "TimeToEmpty": {Value: int64(5400)},
+			"IsPresent": {Value: true}, "PowerSupply": {Value: true}},
+		"DisplayDevice": {"Type": {Value: uint32(upowerTypeBattery)}, "Percentage": {Value: 42.0},
+			"State": {Value: uint32(upowerStateDischarging)}, "TimeToEmpty": {Value: int64(5400)}},
+	}
+	for name, props := range devices {
+		_, err := prop.Export(server, upowerPath+"/devices/"+name, prop.Map{upowerDeviceIface: props})
+		assert.Nil(t, err)
+	}
+	_, err := server.RequestName(upowerService, dbus.NameFlagDoNotQueue)
+	assert.Nil(t, err)
+
+	c := newUPowerClient(client)
+	list, err := c.batteries()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	assert.True(t, list[0].powerSupply)
+	assert.Equal(t, 42.0, list[0].percent)
+	assert.Equal(t, "Mouse", list[1].model)
+
+	dev, err := c.displayDevice()
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Minute, dev.toEmpty)
+	assert.Equal(t, "1h 30m remaining", batteryStatus(dev))
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "45m", formatDuration(45*time.Minute))
+	assert.Equal(t, "2h 05m", formatDuration(125*time.Minute))
+}
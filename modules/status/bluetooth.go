@@ -0,0 +1,239 @@
+package status
+
+import (
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/godbus/dbus/v5"
+
+	"fyshos.com/fynedesk"
+	wmtheme "fyshos.com/fynedesk/theme"
+)
+
+var bluetoothMeta = fynedesk.ModuleMetadata{
+	Name:        "Bluetooth",
+	NewInstance: newBluetooth,
+}
+
+type bluetooth struct {
+	name *widget.Label
+	icon *widget.Button
+
+	client *bluezClient
+	agent  *bluetoothAgent
+
+	panel      fyne.Window
+	panelShown bool
+	power      *widget.Check
+	scan       *widget.Button
+	devices    *fyne.Container
+}
+
+func (b *bluetooth) Destroy() {
+	if b.agent != nil {
+		b.agent.unregister()
+	}
+	if b.client == nil {
+		return
+	}
+
+	b.client.unwatch()
+	if on, _ := b.client.discovering(); on {
+		_ = b.client.scan(false)
+	}
+	if b.panel != nil {
+		b.panel.Close()
+	}
+}
+
+func (b *bluetooth) Metadata() fynedesk.ModuleMetadata {
+	return bluetoothMeta
+}
+
+func (b *bluetooth) StatusAreaWidget() fyne.CanvasObject {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil
+	}
+	b.client = newBluezClient(conn)
+	if _, err = b.client.adapter(); err != nil {
+		return nil // no Bluetooth hardware, or BlueZ is not running
+	}
+
+	b.agent = newBluetoothAgent(b.client)
+	if err = b.agent.register(conn); err != nil {
+		fyne.LogError("Could not register Bluetooth pairing agent", err)
+	}
+
+	b.name = widget.NewLabel("")
+	b.icon = &widget.Button{Icon: wmtheme.BluetoothOffIcon, Importance: widget.LowImportance, OnTapped: b.showPanel}
+	if err = b.client.watch(b.refresh); err != nil {
+		fyne.LogError("Unable to watch for Bluetooth changes", err)
+	}
+	b.refresh()
+
+	return container.New(&handleNarrow{}, b.icon, b.name)
+}
+
+func (b *bluetooth) deviceRow(dev *bluetoothDevice) fyne.CanvasObject {
+	status := container.NewHBox()
+	if dev.battery >= 0 {
+		status.Add(widget.NewLabel(strconv.Itoa(dev.battery) + "%"))
+	}
+
+	if !dev.paired {
+		status.Add(&widget.Button{Text: "Pair", Importance: widget.LowImportance, OnTapped: func() {
+			b.run("Failed to pair with "+dev.name, func() error {
+				if err := b.client.pair(dev); err != nil {
+					return err
+				}
+				if err := b.client.setTrusted(dev, true); err != nil {
+					return err
+				}
+				return b.client.connect(dev)
+			})
+		}})
+	} else {
+		trust := widget.NewCheck("Trust", func(on bool) {
+			b.run("Failed to change trust of "+dev.name, func() error {
+				return b.client.setTrusted(dev, on)
+			})
+		})
+		trust.Checked = dev.trusted
+		status.Add(trust)
+		status.Add(&widget.Button{Icon: theme.DeleteIcon(), Importance: widget.LowImportance, OnTapped: func() {
+			b.run("Failed to forget "+dev.name, func() error {
+				return b.client.remove(dev)
+			})
+		}})
+	}
+
+	var icon fyne.Resource = wmtheme.BluetoothIcon
+	if dev.connected {
+		icon = theme.ConfirmIcon()
+	}
+	connect := &widget.Button{Text: dev.name, Icon: icon, Alignment: widget.ButtonAlignLeading,
+		Importance: widget.LowImportance, OnTapped: func() {
+			if dev.connected {
+				b.run("Failed to disconnect "+dev.name, func() error { return b.client.disconnect(dev) })
+			} else if dev.paired {
+				b.run("Failed to connect to "+dev.name, func() error { return b.client.connect(dev) })
+			}
+		}}
+	return container.NewBorder(nil, nil, nil, status, connect)
+}
+
+// refresh updates the status area, and the Bluetooth panel if it is showing, to match the current state.
+func (b *bluetooth) refresh() {
+	on, err := b.client.powered()
+	if err != nil || !on {
+		b.icon.SetIcon(wmtheme.BluetoothOffIcon)
+		b.name.SetText("")
+	} else {
+		b.icon.SetIcon(wmtheme.BluetoothIcon)
+		name := ""
+		if list, err := b.client.devices(); err == nil && len(list) > 0 && list[0].connected {
+			name = list[0].name
+		}
+		if name != b.name.Text {
+			b.name.SetText(name)
+		}
+	}
+
+	if b.panelShown {
+		b.refreshPanel()
+	}
+}
+
+func (b *bluetooth) refreshPanel() {
+	on, _ := b.client.powered()
+	b.power.OnChanged = nil
+	b.power.SetChecked(on)
+	b.power.OnChanged = b.setPowered
+
+	if scanning, _ := b.client.discovering(); scanning {
+		b.scan.SetText("Stop Scanning")
+	} else {
+		b.scan.SetText("Scan")
+	}
+	if on {
+		b.scan.Enable()
+	} else {
+		b.scan.Disable()
+	}
+
+	var items []fyne.CanvasObject
+	if on {
+		if list, err := b.client.devices(); err == nil {
+			for _, dev := range list {
+				items = append(items, b.deviceRow(dev))
+			}
+		}
+	}
+	if len(items) == 0 {
+		items = append(items, widget.NewLabel("No devices"))
+	}
+	b.devices.Objects = items
+	b.devices.Refresh()
+}
+
+func (b *bluetooth) run(failure string, action func() error) {
+	go func() {
+		if err := action(); err != nil {
+			fyne.LogError(failure, err)
+		}
+	}()
+}
+
+func (b *bluetooth) setPowered(on bool) {
+	b.run("Failed to turn Bluetooth on or off", func() error {
+		return b.client.setPowered(on)
+	})
+}
+
+func (b *bluetooth) showPanel() {
+	b.panelShown = true
+	if b.panel != nil {
+		b.refreshPanel()
+		b.panel.Show()
+		b.panel.RequestFocus()
+		return
+	}
+
+	b.power = widget.NewCheck("Bluetooth", b.setPowered)
+	b.devices = container.NewVBox()
+	b.scan = &widget.Button{Text: "Scan", Icon: theme.SearchIcon(), OnTapped: func() {
+		b.run("Failed to scan for Bluetooth devices", func() error {
+			scanning, err := b.client.discovering()
+			if err != nil {
+				return err
+			}
+			return b.client.scan(!scanning)
+		})
+	}}
+
+	devices := widget.NewCard("Devices", "", container.NewBorder(nil, b.scan, nil, nil,
+		container.NewVScroll(b.devices)))
+	w := fyne.CurrentApp().NewWindow("Bluetooth")
+	w.SetContent(container.NewBorder(b.power, nil, nil, nil, devices))
+	w.SetCloseIntercept(func() {
+		b.panelShown = false
+		if scanning, _ := b.client.discovering(); scanning {
+			b.run("Failed to stop scanning", func() error { return b.client.scan(false) })
+		}
+		w.Hide()
+	})
+	w.Resize(fyne.NewSize(360, 420))
+	b.panel = w
+	b.refreshPanel()
+	w.Show()
+}
+
+// newBluetooth creates a new module that will show Bluetooth devices in the status area
+func newBluetooth() fynedesk.Module {
+	return &bluetooth{}
+}
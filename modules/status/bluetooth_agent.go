@@ -0,0 +1,172 @@
+package status
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	bluezAgentIface = "org.bluez.Agent1"
+	bluezAgentPath  = "/io/fyshos/fynedesk/BluetoothAgent"
+)
+
+// bluetoothAgent is a BlueZ pairing agent, it asks the user to confirm pairing and enter or check codes.
+type bluetoothAgent struct {
+	name    func(dbus.ObjectPath) string
+	trusted func(dbus.ObjectPath) bool
+	conn    *dbus.Conn
+	prompts pendingPrompts
+
+	// ask requests a code from the user, returning false if they cancelled.
+	ask func(title, message string, cancel <-chan struct{}) (string, bool)
+	// confirm asks the user to accept a request, returning true if they did.
+	confirm func(title, message string, cancel <-chan struct{}) bool
+	// show tells the user a code to enter on the other device.
+	show func(title, message string, cancel <-chan struct{})
+}
+
+func newBluetoothAgent(client *bluezClient) *bluetoothAgent {
+	return &bluetoothAgent{name: client.deviceName, trusted: client.deviceTrusted, ask: askBluetoothCode, confirm: confirmBluetooth,
+		show: showBluetoothCode}
+}
+
+// register exports the agent and asks BlueZ to use it for pairing requests.
+func (a *bluetoothAgent) register(conn *dbus.Conn) error {
+	if err := conn.Export(a, bluezAgentPath, bluezAgentIface); err != nil {
+		return err
+	}
+
+	manager := conn.Object(bluezService, bluezPath)
+	err := manager.Call(bluezAgentManager+".RegisterAgent", 0, dbus.ObjectPath(bluezAgentPath), "KeyboardDisplay").Err
+	if err != nil {
+		return err
+	}
+
+	a.conn = conn
+	return manager.Call(bluezAgentManager+".RequestDefaultAgent", 0, dbus.ObjectPath(bluezAgentPath)).Err
+}
+
+// unregister tells BlueZ to stop sending pairing requests to the agent, and stops exporting it.
+func (a *bluetoothAgent) unregister() {
+	a.prompts.cancelAll()
+	if a.conn == nil {
+		return
+	}
+
+	err := a.conn.Object(bluezService, bluezPath).Call(bluezAgentManager+".UnregisterAgent", 0,
+		dbus.ObjectPath(bluezAgentPath)).Err
+	if err != nil {
+		fyne.LogError("Could not unregister Bluetooth pairing agent", err)
+	}
+	_ = a.conn.Export(nil, bluezAgentPath, bluezAgentIface)
+	a.conn = nil
+}
+
+// AuthorizeService is called when a device wants to use a service, such as audio.
+// Paired devices that the user trusts are allowed, otherwise the user is asked.
+func (a *bluetoothAgent) AuthorizeService(device dbus.ObjectPath, _ string) *dbus.Error {
+	if a.trusted(device) {
+		return nil
+	}
+
+	if !a.confirm("Bluetooth", "Allow \""+a.name(device)+"\" to connect?", a.prompts.cancelled()) {
+		return bluezRejected()
+	}
+	return nil
+}
+
+// Cancel is called by BlueZ if a request is no longer needed, it closes any pairing messages that are open.
+func (a *bluetoothAgent) Cancel() *dbus.Error {
+	a.prompts.cancelAll()
+	return nil
+}
+
+// DisplayPasskey shows the passkey that the user should type on the device.
+func (a *bluetoothAgent) DisplayPasskey(device dbus.ObjectPath, passkey uint32, _ uint16) *dbus.Error {
+	message := fmt.Sprintf("Type %06d on \"%s\" and press Enter", passkey, a.name(device))
+	a.show("Bluetooth Pairing", message, a.prompts.cancelled())
+	return nil
+}
+
+// DisplayPinCode shows the PIN code that the user should type on the device.
+func (a *bluetoothAgent) DisplayPinCode(device dbus.ObjectPath, pin string) *dbus.Error {
+	a.show("Bluetooth Pairing", "Type "+pin+" on \""+a.name(device)+"\"", a.prompts.cancelled())
+	return nil
+}
+
+// Release is called when BlueZ stops using this agent.
+func (a *bluetoothAgent) Release() *dbus.Error {
+	return nil
+}
+
+// RequestAuthorization asks if a device that is not using a code may pair.
+func (a *bluetoothAgent) RequestAuthorization(device dbus.ObjectPath) *dbus.Error {
+	if !a.confirm("Bluetooth Pairing", "Allow \""+a.name(device)+"\" to pair?", a.prompts.cancelled()) {
+		return bluezRejected()
+	}
+	return nil
+}
+
+// RequestConfirmation asks the user to check that a passkey matches the one shown on the device.
+func (a *bluetoothAgent) RequestConfirmation(device dbus.ObjectPath, passkey uint32) *dbus.Error {
+	message := fmt.Sprintf("Does \"%s\" show the code %06d?", a.name(device), passkey)
+	if !a.confirm("Bluetooth Pairing", message, a.prompts.cancelled()) {
+		return bluezRejected()
+	}
+	return nil
+}
+
+// RequestPasskey asks the user for the numeric passkey of a device.
+func (a *bluetoothAgent) RequestPasskey(device dbus.ObjectPath) (uint32, *dbus.Error) {
+	code, ok := a.ask("Bluetooth Pairing", "Enter the passkey for \""+a.name(device)+"\"", a.prompts.cancelled())
+	if !ok {
+		return 0, bluezCanceled()
+	}
+
+	key, err := strconv.ParseUint(code, 10, 32)
+	if err != nil || key > 999999 {
+		return 0, bluezRejected()
+	}
+	return uint32(key), nil
+}
+
+// RequestPinCode asks the user for the PIN code of a device.
+func (a *bluetoothAgent) RequestPinCode(device dbus.ObjectPath) (string, *dbus.Error) {
+	code, ok := a.ask("Bluetooth Pairing", "Enter the PIN code for \""+a.name(device)+"\"", a.prompts.cancelled())
+	if !ok {
+		return "", bluezCanceled()
+	}
+	if code == "" || len(code) > 16 {
+		return "", bluezRejected()
+	}
+	return code, nil
+}
+
+func bluezCanceled() *dbus.Error {
+	return dbus.NewError("org.bluez.Error.Canceled", nil)
+}
+
+func bluezRejected() *dbus.Error {
+	return dbus.NewError("org.bluez.Error.Rejected", nil)
+}
+
+// askBluetoothCode shows a modal window asking for a pairing code, and waits for the user to respond.
+func askBluetoothCode(title, message string, cancel <-chan struct{}) (string, bool) {
+	return showPrompt(title, message, widget.NewEntry(), "Pair", cancel)
+}
+
+// confirmBluetooth shows a modal window asking the user to accept a pairing, and waits for them to respond.
+func confirmBluetooth(title, message string, cancel <-chan struct{}) bool {
+	_, ok := showPrompt(title, message, nil, "Pair", cancel)
+	return ok
+}
+
+// showBluetoothCode shows a modal window with a code to type on the device being paired.
+func showBluetoothCode(title, message string, cancel <-chan struct{}) {
+	go showPrompt(title, message, nil, "OK", cancel)
+}
@@ -0,0 +1,277 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk/internal/dbusutil/dbustest"
+)
+
+const (
+	mockAdapterPath = "/org/bluez/hci0"
+	mockHeadsetPath = "/org/bluez/hci0/dev_00_11_22_33_44_55"
+	mockMousePath   = "/org/bluez/hci0/dev_66_77_88_99_AA_BB"
+)
+
+type mockBluez struct {
+	objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	calls   []string
+	agent   dbus.ObjectPath
+}
+
+func (m *mockBluez) GetManagedObjects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, *dbus.Error) {
+	return m.objects, nil
+}
+
+type mockBluezAgentManager struct {
+	m *mockBluez
+}
+
+func (a *mockBluezAgentManager) RegisterAgent(path dbus.ObjectPath, capability string) *dbus.Error {
+	a.m.agent = path
+	a.m.calls = append(a.m.calls, "RegisterAgent "+capability)
+	return nil
+}
+
+func (a *mockBluezAgentManager) UnregisterAgent(path dbus.ObjectPath) *dbus.Error {
+	a.m.agent = ""
+	a.m.calls = append(a.m.calls, "UnregisterAgent "+string(path))
+	return nil
+}
+
+func (a *mockBluezAgentManager) RequestDefaultAgent(path dbus.ObjectPath) *dbus.Error {
+	a.m.calls = append(a.m.calls, "RequestDefaultAgent "+string(path))
+	return nil
+}
+
+type mockBluezAdapter struct {
+	m *mockBluez
+}
+
+func (a *mockBluezAdapter) RemoveDevice(dev dbus.ObjectPath) *dbus.Error {
+	a.m.calls = append(a.m.calls, "RemoveDevice "+string(dev))
+	return nil
+}
+
+func (a *mockBluezAdapter) StartDiscovery() *dbus.Error {
+	a.m.calls = append(a.m.calls, "StartDiscovery")
+	return nil
+}
+
+func (a *mockBluezAdapter) StopDiscovery() *dbus.Error {
+	a.m.calls = append(a.m.calls, "StopDiscovery")
+	return nil
+}
+
+type mockBluezDevice struct {
+	m    *mockBluez
+	path dbus.ObjectPath
+}
+
+func (d *mockBluezDevice) Connect() *dbus.Error {
+	d.m.calls = append(d.m.calls, "Connect "+string(d.path))
+	return nil
+}
+
+func (d *mockBluezDevice) Disconnect() *dbus.Error {
+	d.m.calls = append(d.m.calls, "Disconnect "+string(d.path))
+	return nil
+}
+
+func (d *mockBluezDevice) Pair() *dbus.Error {
+	d.m.calls = append(d.m.calls, "Pair "+string(d.path))
+	return nil
+}
+
+func TestBluezClient(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	m := exportMockBluez(t, server)
+	c := newBluezClient(client)
+
+	adapter, err := c.adapter()
+	assert.Nil(t, err)
+	assert.Equal(t, dbus.ObjectPath(mockAdapterPath), adapter)
+
+	on, err := c.powered()
+	assert.Nil(t, err)
+	assert.True(t, on)
+	assert.Nil(t, c.setPowered(false))
+	on, _ = c.powered()
+	assert.False(t, on)
+
+	list, err := c.devices()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "Headphones", list[0].name)
+	assert.True(t, list[0].connected)
+	assert.Equal(t, 72, list[0].battery)
+	assert.Equal(t, "66:77:88:99:AA:BB", list[1].name)
+	assert.False(t, list[1].paired)
+	assert.Equal(t, -1, list[1].battery)
+	assert.Equal(t, "Headphones", c.deviceName(mockHeadsetPath))
+
+	assert.Nil(t, c.scan(true))
+	assert.Nil(t, c.pair(list[1]))
+	assert.Nil(t, c.connect(list[1]))
+	assert.Nil(t, c.disconnect(list[0]))
+	assert.Nil(t, c.remove(list[0]))
+	assert.Equal(t, []string{"StartDiscovery", "Pair " + mockMousePath, "Connect " + mockMousePath,
+		"Disconnect " + mockHeadsetPath, "RemoveDevice " + mockHeadsetPath}, m.calls)
+
+	assert.Nil(t, c.setTrusted(list[1], true))
+	v, err := client.Object(bluezService, mockMousePath).GetProperty(bluezDevice + ".Trusted")
+	assert.Nil(t, err)
+	assert.Equal(t, true, v.Value())
+}
+
+func TestBluezClient_Watch(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	exportMockBluez(t, server)
+	c := newBluezClient(client)
+
+	changed := make(chan bool, 1)
+	assert.Nil(t, c.watch(func() {
+		select {
+		case changed <- true:
+		default:
+		}
+	}))
+	assert.Nil(t, server.Emit("/", "org.freedesktop.DBus.ObjectManager.InterfacesAdded",
+		dbus.ObjectPath(mockAdapterPath+"/dev_CC"), map[string]map[string]dbus.Variant{}))
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Error("Change was not reported")
+	}
+
+	c.unwatch()
+	assert.Nil(t, server.Emit("/", "org.freedesktop.DBus.ObjectManager.InterfacesAdded",
+		dbus.ObjectPath(mockAdapterPath+"/dev_DD"), map[string]map[string]dbus.Variant{}))
+	select {
+	case <-changed:
+		t.Error("Change was reported after unwatch")
+	case <-time.After(time.Second / 10):
+	}
+}
+
+func TestBluetoothAgent(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	m := exportMockBluez(t, server)
+
+	asked, shown := "", ""
+	bluez := newBluezClient(server)
+	a := &bluetoothAgent{name: bluez.deviceName, trusted: bluez.deviceTrusted,
+		ask: func(_, message string, _ <-chan struct{}) (string, bool) {
+			asked = message
+			return "123456", true
+		},
+		confirm: func(_, message string, _ <-chan struct{}) bool {
+			asked = message
+			return false
+		},
+		show: func(_, message string, _ <-chan struct{}) {
+			shown = message
+		}}
+	assert.Nil(t, a.register(client))
+	assert.Equal(t, dbus.ObjectPath(bluezAgentPath), m.agent)
+	assert.Equal(t, []string{"RegisterAgent KeyboardDisplay", "RequestDefaultAgent " + bluezAgentPath}, m.calls)
+
+	agent := server.Object(client.Names()[0], bluezAgentPath)
+	var key uint32
+	assert.Nil(t, agent.Call(bluezAgentIface+".RequestPasskey", 0, dbus.ObjectPath(mockHeadsetPath)).Store(&key))
+	assert.Equal(t, uint32(123456), key)
+	assert.Equal(t, "Enter the passkey for \"Headphones\"", asked)
+
+	var pin string
+	assert.Nil(t, agent.Call(bluezAgentIface+".RequestPinCode", 0, dbus.ObjectPath(mockHeadsetPath)).Store(&pin))
+	assert.Equal(t, "123456", pin)
+
+	err := agent.Call(bluezAgentIface+".RequestConfirmation", 0, dbus.ObjectPath(mockHeadsetPath), uint32(42)).Err
+	assert.NotNil(t, err)
+	assert.Equal(t, "Does \"Headphones\" show the code 000042?", asked)
+
+	asked = ""
+	assert.Nil(t, agent.Call(bluezAgentIface+".AuthorizeService", 0, dbus.ObjectPath(mockHeadsetPath), "0000110b").Err)
+	assert.Equal(t, "", asked) // paired and trusted, so the user is not asked
+	err = agent.Call(bluezAgentIface+".AuthorizeService", 0, dbus.ObjectPath(mockMousePath), "00001124").Err
+	assert.NotNil(t, err)
+	assert.Equal(t, "org.bluez.Error.Rejected", err.(dbus.Error).Name)
+	assert.Contains(t, asked, "to connect?")
+
+	assert.Nil(t, agent.Call(bluezAgentIface+".DisplayPasskey", 0, dbus.ObjectPath(mockHeadsetPath), uint32(7),
+		uint16(0)).Err)
+	assert.Equal(t, "Type 000007 on \"Headphones\" and press Enter", shown)
+
+	a.unregister()
+	assert.Equal(t, dbus.ObjectPath(""), m.agent)
+	assert.Equal(t, "UnregisterAgent "+bluezAgentPath, m.calls[len(m.calls)-1])
+	assert.NotNil(t, agent.Call(bluezAgentIface+".Cancel", 0).Err) // no longer exported
+}
+
+func TestBluetoothAgent_Cancel(t *testing.T) {
+	asked := make(chan bool)
+	a := &bluetoothAgent{name: func(dbus.ObjectPath) string { return "Headphones" },
+		confirm: func(_, _ string, cancel <-chan struct{}) bool {
+			asked <- true
+			<-cancel
+			return false
+		}}
+
+	result := make(chan *dbus.Error)
+	go func() {
+		result <- a.RequestAuthorization(mockHeadsetPath)
+	}()
+	<-asked
+	assert.Nil(t, a.Cancel())
+
+	select {
+	case err := <-result:
+		assert.NotNil(t, err)
+	case <-time.After(time.Second):
+		t.Error("Request was not cancelled")
+	}
+}
+
+func exportMockBluez(t *testing.T, conn *dbus.Conn) *mockBluez {
+	m := &mockBluez{objects: map[dbus.ObjectPath]map[string]map[string]dbus.Variant{
+		mockAdapterPath: {bluezAdapter: {"Powered": dbus.MakeVariant(true)}},
+		mockHeadsetPath: {
+			bluezDevice: {"Address": dbus.MakeVariant("00:11:22:33:44:55"), "Alias": dbus.MakeVariant("Headphones"),
+				"Paired": dbus.MakeVariant(true), "Trusted": dbus.MakeVariant(true), "Connected": dbus.MakeVariant(true)},
+			bluezBattery: {"Percentage": dbus.MakeVariant(byte(72))},
+		},
+		mockMousePath: {bluezDevice: {"Address": dbus.MakeVariant("66:77:88:99:AA:BB"),
+			"Paired": dbus.MakeVariant(false)}},
+	}}
+
+	assert.Nil(t, conn.Export(m, "/", "org.freedesktop.DBus.ObjectManager"))
+	assert.Nil(t, conn.Export(&mockBluezAgentManager{m}, bluezPath, bluezAgentManager))
+	assert.Nil(t, conn.Export(&mockBluezAdapter{m}, mockAdapterPath, bluezAdapter))
+	_, err := prop.Export(conn, mockAdapterPath, prop.Map{bluezAdapter: {
+		"Powered":     {Value: true, Writable: true},
+		"Discovering": {Value: false},
+	}})
+	assert.Nil(t, err)
+	for _, path := range []dbus.ObjectPath{mockHeadsetPath, mockMousePath} {
+		assert.Nil(t, conn.Export(&mockBluezDevice{m, path}, path, bluezDevice))
+		alias, _ := m.objects[path][bluezDevice]["Alias"].Value().(string)
+		paired, _ := m.objects[path][bluezDevice]["Paired"].Value().(bool)
+		trusted, _ := m.objects[path][bluezDevice]["Trusted"].Value().(bool)
+		_, err = prop.Export(conn, path, prop.Map{bluezDevice: {
+			"Alias":   {Value: alias},
+			"Paired":  {Value: paired},
+			"Trusted": {Value: trusted, Writable: true},
+		}})
+		assert.Nil(t, err)
+	}
+
+	reply, err := conn.RequestName(bluezService, dbus.NameFlagDoNotQueue)
+	assert.Nil(t, err)
+	assert.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+	return m
+}
@@ -0,0 +1,255 @@
+package status
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+
+	"fyshos.com/fynedesk/internal/dbusutil"
+)
+
+const (
+	bluezService      = "org.bluez"
+	bluezPath         = "/org/bluez"
+	bluezAdapter      = "org.bluez.Adapter1"
+	bluezAgentManager = "org.bluez.AgentManager1"
+	bluezBattery      = "org.bluez.Battery1"
+	bluezDevice       = "org.bluez.Device1"
+)
+
+// bluetoothDevice describes a device that BlueZ has found or remembered.
+type bluetoothDevice struct {
+	path                       dbus.ObjectPath
+	name, address, icon        string
+	paired, trusted, connected bool
+	battery                    int // percentage charged, or -1 if not known
+}
+
+// bluezClient talks to BlueZ over D-Bus.
+type bluezClient struct {
+	conn    *dbus.Conn
+	signals *dbusutil.SignalWatch
+}
+
+func newBluezClient(conn *dbus.Conn) *bluezClient {
+	return &bluezClient{conn: conn}
+}
+
+// adapter returns the path of the first Bluetooth adapter.
+func (c *bluezClient) adapter() (dbus.ObjectPath, error) {
+	objects, err := c.managedObjects()
+	if err != nil {
+		return "", err
+	}
+
+	var paths []string
+	for path, ifaces := range objects {
+		if _, ok := ifaces[bluezAdapter]; ok {
+			paths = append(paths, string(path))
+		}
+	}
+	if len(paths) == 0 {
+		return "", errors.New("no Bluetooth adapter found")
+	}
+
+	sort.Strings(paths)
+	return dbus.ObjectPath(paths[0]), nil
+}
+
+func (c *bluezClient) connect(dev *bluetoothDevice) error {
+	return c.object(dev.path).Call(bluezDevice+".Connect", 0).Err
+}
+
+// devices returns the devices that are paired or have been found by scanning.
+// Connected devices are listed first, then paired devices, and then by name.
+func (c *bluezClient) devices() ([]*bluetoothDevice, error) {
+	objects, err := c.managedObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*bluetoothDevice
+	for path, ifaces := range objects {
+		props, ok := ifaces[bluezDevice]
+		if !ok {
+			continue
+		}
+
+		dev := &bluetoothDevice{path: path, battery: -1}
+		dev.address, _ = props["Address"].Value().(string)
+		dev.name, _ = props["Alias"].Value().(string)
+		if dev.name == "" {
+			dev.name = dev.address
+		}
+		dev.icon, _ = props["Icon"].Value().(string)
+		dev.paired, _ = props["Paired"].Value().(bool)
+		dev.trusted, _ = props["Trusted"].Value().(bool)
+		dev.connected, _ = props["Connected"].Value().(bool)
+		if battery, ok := ifaces[bluezBattery]; ok {
+			if percent, ok := battery["Percentage"].Value().(byte); ok {
+				dev.battery = int(percent)
+			}
+		}
+		list = append(list, dev)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].connected != list[j].connected {
+			return list[i].connected
+		}
+		if list[i].paired != list[j].paired {
+			return list[i].paired
+		}
+		return strings.ToLower(list[i].name) < strings.ToLower(list[j].name)
+	})
+	return list, nil
+}
+
+func (c *bluezClient) deviceName(path dbus.ObjectPath) string {
+	v, err := c.object(path).GetProperty(bluezDevice + ".Alias")
+	if err != nil {
+		return string(path)
+	}
+
+	name, _ := v.Value().(string)
+	return name
+}
+
+// deviceTrusted returns true if the device at path is both paired and trusted.
+func (c *bluezClient) deviceTrusted(path dbus.ObjectPath) bool {
+	for _, name := range []string{"Paired", "Trusted"} {
+		v, err := c.object(path).GetProperty(bluezDevice + "." + name)
+		if err != nil {
+			return false
+		}
+		if on, _ := v.Value().(bool); !on {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *bluezClient) disconnect(dev *bluetoothDevice) error {
+	return c.object(dev.path).Call(bluezDevice+".Disconnect", 0).Err
+}
+
+func (c *bluezClient) discovering() (bool, error) {
+	return c.adapterBool("Discovering")
+}
+
+func (c *bluezClient) pair(dev *bluetoothDevice) error {
+	return c.object(dev.path).Call(bluezDevice+".Pair", 0).Err
+}
+
+func (c *bluezClient) powered() (bool, error) {
+	return c.adapterBool("Powered")
+}
+
+// remove tells the adapter to forget a device, removing the pairing.
+func (c *bluezClient) remove(dev *bluetoothDevice) error {
+	adapter, err := c.adapter()
+	if err != nil {
+		return err
+	}
+
+	return c.object(adapter).Call(bluezAdapter+".RemoveDevice", 0, dev.path).Err
+}
+
+// scan starts or stops looking for new devices.
+func (c *bluezClient) scan(on bool) error {
+	adapter, err := c.adapter()
+	if err != nil {
+		return err
+	}
+
+	method := ".StopDiscovery"
+	if on {
+		method = ".StartDiscovery"
+	}
+	return c.object(adapter).Call(bluezAdapter+method, 0).Err
+}
+
+func (c *bluezClient) setPowered(on bool) error {
+	adapter, err := c.adapter()
+	if err != nil {
+		return err
+	}
+
+	return c.object(adapter).SetProperty(bluezAdapter+".Powered", dbus.MakeVariant(on))
+}
+
+func (c *bluezClient) setTrusted(dev *bluetoothDevice, trust bool) error {
+	return c.object(dev.path).SetProperty(bluezDevice+".Trusted", dbus.MakeVariant(trust))
+}
+
+// unwatch stops listening for adapter and device signals from BlueZ.
+func (c *bluezClient) unwatch() {
+	c.signals.Stop()
+	c.signals = nil
+}
+
+// watch calls changed whenever an adapter or device changes, or devices are found or removed.
+func (c *bluezClient) watch(changed func()) error {
+	signals, err := dbusutil.WatchSignals(c.conn, [][]dbus.MatchOption{
+		{dbus.WithMatchObjectPath("/"), dbus.WithMatchInterface("org.freedesktop.DBus.ObjectManager")},
+		{dbus.WithMatchPathNamespace(bluezPath), dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged")},
+	}, func(sig *dbus.Signal) {
+		if isBluetoothChange(sig) {
+			changed()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.signals = signals
+	return nil
+}
+
+func (c *bluezClient) adapterBool(name string) (bool, error) {
+	adapter, err := c.adapter()
+	if err != nil {
+		return false, err
+	}
+
+	v, err := c.object(adapter).GetProperty(bluezAdapter + "." + name)
+	if err != nil {
+		return false, err
+	}
+	on, _ := v.Value().(bool)
+	return on, nil
+}
+
+func (c *bluezClient) managedObjects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
+	objects := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant)
+	err := c.object("/").Call("org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0).Store(&objects)
+	return objects, err
+}
+
+func (c *bluezClient) object(path dbus.ObjectPath) dbus.BusObject {
+	return c.conn.Object(bluezService, path)
+}
+
+// isBluetoothChange returns true for signals that mean an adapter or device has changed.
+func isBluetoothChange(sig *dbus.Signal) bool {
+	switch sig.Name {
+	case "org.freedesktop.DBus.ObjectManager.InterfacesAdded",
+		"org.freedesktop.DBus.ObjectManager.InterfacesRemoved":
+		return len(sig.Body) > 0 && strings.HasPrefix(objectPathArg(sig.Body[0]), bluezPath)
+	case "org.freedesktop.DBus.Properties.PropertiesChanged":
+		if len(sig.Body) == 0 {
+			return false
+		}
+		iface, _ := sig.Body[0].(string)
+		return iface == bluezAdapter || iface == bluezDevice || iface == bluezBattery
+	}
+
+	return false
+}
+
+func objectPathArg(arg interface{}) string {
+	path, _ := arg.(dbus.ObjectPath)
+	return string(path)
+}
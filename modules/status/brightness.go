@@ -1,11 +1,10 @@
 package status
 
 import (
-	"errors"
 	"image/color"
-	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -15,6 +14,7 @@ import (
 
 	"fyshos.com/fynedesk"
 	wmtheme "fyshos.com/fynedesk/theme"
+	"fyshos.com/fynedesk/wm"
 )
 
 var brightnessMeta = fynedesk.ModuleMetadata{
@@ -22,97 +22,53 @@ var brightnessMeta = fynedesk.ModuleMetadata{
 	NewInstance: newBrightness,
 }
 
-type brightType int
-
 const (
-	noBacklight brightType = iota
-	xbacklight
-	brightnessctl
+	brightnessMenuWidth = 280
+	minBrightness       = 0.01 // don't turn the screen off completely
 )
 
-// Brightness is a progress bar module to modify screen brightness
-type brightness struct {
-	bar *widget.ProgressBar
-
-	mode brightType
+// brightnessDevice is something that can change the brightness of a screen, such as a backlight or a DDC monitor.
+type brightnessDevice interface {
+	brightness() (float64, error)
+	connector() string // the output that this device controls, or "" if unknown
+	setBrightness(float64) error
 }
 
-func (b *brightness) Destroy() {
+// screenBrightness links a screen to the device that controls its brightness.
+type screenBrightness struct {
+	screen *fynedesk.Screen
+	device brightnessDevice
 }
 
-func (b *brightness) value() (float64, error) {
-	switch b.mode {
-	case brightnessctl:
-		out, err := exec.Command("brightnessctl", "get").Output()
-		if err != nil {
-			fyne.LogError("Error running brightnessctl", err)
-			return 0, err
-		}
-		maxOut, _ := exec.Command("brightnessctl", "max").Output()
-		val, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
-		if err != nil {
-			fyne.LogError("Error parsing brightnessctl info", err)
-			return 0, err
-		}
-		max, _ := strconv.ParseFloat(strings.TrimSpace(string(maxOut)), 64)
-		return val / max, nil
-	default:
-		out, err := exec.Command("xbacklight").Output()
-		if err != nil {
-			fyne.LogError("Error running xbacklight", err)
-			return 0, err
-		}
-
-		if strings.TrimSpace(string(out)) == "" {
-			return 0, errors.New("no back-lit screens found")
-		}
-		ret, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
-		if err != nil {
-			fyne.LogError("Error parsing xbacklight info", err)
-			return 0, err
-		}
-		return ret / 100, nil
-	}
+// screenListener passes screen changes to the brightness module that is loaded.
+// Screen listeners cannot be removed, so only one is added however many times the module is reloaded.
+var screenListener struct {
+	once   sync.Once
+	lock   sync.Mutex
+	module *brightness
 }
 
-func (b *brightness) offsetValue(diff int) {
-	floatVal, _ := b.value()
-	if floatVal <= 0.01 { // don't start doing 6, 11 etc just because we were on 1 (min)
-		floatVal = 0
-	}
-	value := int(floatVal*100) + diff
+// Brightness is a progress bar module to modify screen brightness
+type brightness struct {
+	bar    *widget.ProgressBar
+	icon   *widget.Button
+	status fyne.CanvasObject
 
-	b.setValue(value)
+	lock     sync.RWMutex
+	devices  []brightnessDevice
+	displays []*screenBrightness
 }
 
-func (b *brightness) setValue(value int) {
-	if value < 1 {
-		value = 1
-	} else if value > 100 {
-		value = 100
-	}
-
-	switch b.mode {
-	case brightnessctl:
-		err := exec.Command("brightnessctl", "set", strconv.Itoa(value)+"%").Run()
-		if err != nil {
-			fyne.LogError("Error running brightnessctl", err)
-			return
-		}
-	default:
-		err := exec.Command("xbacklight", "-set", strconv.Itoa(value)).Run()
-		if err != nil {
-			fyne.LogError("Error running xbacklight", err)
-			return
-		}
+func (b *brightness) Destroy() {
+	screenListener.lock.Lock()
+	if screenListener.module == b {
+		screenListener.module = nil
 	}
-
-	newVal, _ := b.value()
-	b.bar.SetValue(newVal)
+	screenListener.lock.Unlock()
 }
 
 func (b *brightness) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
-	if b.mode == noBacklight {
+	if b.current() == nil {
 		return nil // don't load if not present
 	}
 
@@ -157,23 +113,28 @@ func (b *brightness) Shortcuts() map[*fynedesk.Shortcut]func() {
 	return map[*fynedesk.Shortcut]func(){
 		fynedesk.NewShortcut("Increase Screen Brightness", fynedesk.KeyBrightnessDown, fynedesk.AnyModifier): func() {
 			b.offsetValue(-5)
+			b.showLevel()
 		},
 		fynedesk.NewShortcut("Reduce Screen Brightness", fynedesk.KeyBrightnessUp, fynedesk.AnyModifier): func() {
 			b.offsetValue(5)
+			b.showLevel()
 		},
 	}
 }
 
+// StatusAreaWidget shows the controls straight away for backlights, DDC monitors are probed in the background
+// as this can take a few seconds, the controls are shown when they are found.
 func (b *brightness) StatusAreaWidget() fyne.CanvasObject {
-	if b.mode == noBacklight {
+	b.devices = findBacklightDevices()
+	if len(b.devices) == 0 && !hasDDCSupport {
 		return nil
 	}
 
 	b.bar = widget.NewProgressBar()
-	brightnessIcon := widget.NewIcon(wmtheme.BrightnessIcon)
+	b.icon = &widget.Button{Icon: wmtheme.BrightnessIcon, Importance: widget.LowImportance, OnTapped: b.showScreens}
 	prop := canvas.NewRectangle(color.Transparent)
-	prop.SetMinSize(brightnessIcon.MinSize().Add(fyne.NewSize(theme.Padding()*4, 0)))
-	icon := container.NewCenter(prop, brightnessIcon)
+	prop.SetMinSize(b.icon.MinSize().Add(fyne.NewSize(theme.Padding()*2, 0)))
+	icon := container.NewStack(prop, b.icon)
 
 	less := &widget.Button{Icon: theme.ContentRemoveIcon(), Importance: widget.LowImportance, OnTapped: func() {
 		b.offsetValue(-5)
@@ -185,25 +146,182 @@ func (b *brightness) StatusAreaWidget() fyne.CanvasObject {
 
 	bright := container.NewBorder(nil, nil, less, more, b.bar)
 
-	go b.offsetValue(0)
-	return container.New(&handleNarrow{}, icon, bright)
+	b.status = container.New(&handleNarrow{}, icon, bright)
+	if len(b.devices) == 0 {
+		b.status.Hide()
+	}
+
+	b.matchScreens()
+	b.listenForScreens()
+	go b.refresh()
+	go b.probeDDC()
+	return b.status
 }
 
-// newBrightness creates a new module that will show screen brightness in the status area
-func newBrightness() fynedesk.Module {
-	mode := xbacklight
-	cmd := exec.Command("xbacklight")
-	err := cmd.Run()
-	if err != nil || cmd.ProcessState.ExitCode() != 0 {
-		err = exec.Command("brightnessctl").Run()
-		if err != nil {
-			fyne.LogError("Could not launch xbacklight or brightnessctl", err)
-			mode = noBacklight
-		} else {
-			mode = brightnessctl
+// current returns the brightness control for the active screen, or the first one if it has none.
+func (b *brightness) current() *screenBrightness {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if len(b.displays) == 0 {
+		return nil
+	}
+	if desk := fynedesk.Instance(); desk != nil {
+		active := desk.Screens().Active()
+		for _, d := range b.displays {
+			if active != nil && d.screen.Name == active.Name {
+				return d
+			}
 		}
 	}
-	return &brightness{mode: mode}
+
+	return b.displays[0]
+}
+
+// matchScreens links the brightness devices to the screens that they control.
+func (b *brightness) matchScreens() {
+	desk := fynedesk.Instance()
+	if desk == nil {
+		return
+	}
+
+	b.lock.RLock()
+	devices := b.devices
+	b.lock.RUnlock()
+
+	displays := matchBrightnessDevices(desk.Screens().Screens(), desk.Screens().Primary(), devices)
+	b.lock.Lock()
+	b.displays = displays
+	b.lock.Unlock()
+}
+
+func (b *brightness) offsetValue(diff int) {
+	d := b.current()
+	if d == nil {
+		return
+	}
+
+	floatVal, err := d.device.brightness()
+	if err != nil {
+		fyne.LogError("Failed to read brightness", err)
+		return
+	}
+	if floatVal <= minBrightness { // don't start doing 6, 11 etc just because we were on 1 (min)
+		floatVal = 0
+	}
+
+	b.setValue(d, int(floatVal*100)+diff)
+}
+
+// listenForScreens makes this module the one that is told when screens change.
+func (b *brightness) listenForScreens() {
+	screenListener.lock.Lock()
+	screenListener.module = b
+	screenListener.lock.Unlock()
+
+	desk := fynedesk.Instance()
+	if desk == nil {
+		return
+	}
+	screenListener.once.Do(func() {
+		desk.Screens().AddChangeListener(func() {
+			screenListener.lock.Lock()
+			module := screenListener.module
+			screenListener.lock.Unlock()
+
+			if module != nil {
+				module.matchScreens()
+			}
+		})
+	})
+}
+
+// probeDDC looks for monitors that can be controlled over DDC/CI and shows the controls if any are found.
+func (b *brightness) probeDDC() {
+	displays := findDDCDisplays()
+	if len(displays) == 0 {
+		return
+	}
+
+	b.lock.Lock()
+	for _, ddc := range displays {
+		b.devices = append(b.devices, ddc)
+	}
+	b.lock.Unlock()
+
+	b.matchScreens()
+	b.status.Show()
+	b.refresh()
+}
+
+// refresh updates the status area to show the brightness of the active screen.
+func (b *brightness) refresh() {
+	d := b.current()
+	if d == nil {
+		return
+	}
+
+	if val, err := d.device.brightness(); err == nil {
+		b.bar.SetValue(val)
+	}
+}
+
+func (b *brightness) setValue(d *screenBrightness, value int) {
+	val := float64(value) / 100
+	if val < minBrightness {
+		val = minBrightness
+	} else if val > 1 {
+		val = 1
+	}
+
+	if err := d.device.setBrightness(val); err != nil {
+		fyne.LogError("Failed to set brightness of "+d.screen.Name, err)
+		return
+	}
+
+	if d == b.current() {
+		b.bar.SetValue(val)
+	}
+}
+
+// showLevel shows the brightness of the active screen, used after changing it with keyboard shortcuts.
+func (b *brightness) showLevel() {
+	d := b.current()
+	if d == nil {
+		return
+	}
+
+	if val, err := d.device.brightness(); err == nil {
+		wm.ShowOnScreenDisplay(wmtheme.BrightnessIcon, val)
+	}
+}
+
+// showScreens opens a menu with a brightness slider for each screen that can be controlled.
+func (b *brightness) showScreens() {
+	b.lock.RLock()
+	displays := b.displays
+	b.lock.RUnlock()
+
+	var items []fyne.CanvasObject
+	for _, item := range displays {
+		d := item
+		slider := widget.NewSlider(minBrightness*100, 100)
+		if val, err := d.device.brightness(); err == nil {
+			slider.Value = val * 100
+		}
+		slider.OnChangeEnded = func(val float64) {
+			go b.setValue(d, int(val))
+		}
+
+		items = append(items, widget.NewLabel(d.screen.Name), slider)
+	}
+
+	showStatusPopUp(b.icon, container.NewVBox(items...), brightnessMenuWidth)
+}
+
+// newBrightness creates a new module that will show screen brightness in the status area
+func newBrightness() fynedesk.Module {
+	return &brightness{}
 }
 
 type brightItem struct {
@@ -229,8 +347,64 @@ func (i *brightItem) Launch() {
 	if startsWith(i.input, "down") {
 		i.b.offsetValue(-5)
 	} else if val, err := strconv.Atoi(i.input); err == nil {
-		i.b.setValue(val)
+		if d := i.b.current(); d != nil {
+			i.b.setValue(d, val)
+		}
+	} else {
+		i.b.offsetValue(5)
+	}
+}
+
+// findBacklightDevices returns the backlights that can be controlled.
+func findBacklightDevices() []brightnessDevice {
+	var list []brightnessDevice
+	for _, light := range findBacklights() {
+		list = append(list, light)
 	}
 
-	i.b.offsetValue(5)
+	return list
+}
+
+// isInternalOutput returns true if the output name is a built in panel, such as on a laptop.
+func isInternalOutput(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasPrefix(lower, "edp") || strings.HasPrefix(lower, "lvds") || strings.HasPrefix(lower, "dsi")
+}
+
+// matchBrightnessDevices pairs screens with devices that control them.
+// Devices with no known connector, such as ACPI backlights, control an internal panel or the primary screen.
+// Where more than one device matches a screen the first is used.
+func matchBrightnessDevices(screens []*fynedesk.Screen, primary *fynedesk.Screen,
+	devices []brightnessDevice) []*screenBrightness {
+	fallback := primary
+	for _, s := range screens {
+		if isInternalOutput(s.Name) {
+			fallback = s
+			break
+		}
+	}
+
+	var list []*screenBrightness
+	for _, s := range screens {
+		for _, dev := range devices {
+			conn := dev.connector()
+			if (conn == "" && s == fallback) || (conn != "" && outputMatches(conn, s.Name)) {
+				list = append(list, &screenBrightness{screen: s, device: dev})
+				break
+			}
+		}
+	}
+	return list
+}
+
+// outputMatches returns true if a kernel connector name, such as "HDMI-A-1", is the same as a RandR output name.
+// Drivers name outputs differently, so "HDMI-1", "HDMI1" and "HDMI-A-1" all match.
+func outputMatches(connector, output string) bool {
+	return normalizeOutput(connector) == normalizeOutput(output)
+}
+
+func normalizeOutput(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Replace(name, "-a-", "-", 1)
+	return strings.ReplaceAll(name, "-", "")
 }
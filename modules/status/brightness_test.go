@@ -0,0 +1,141 @@
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk"
+)
+
+func TestBacklight(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := backlightRoot
+	backlightRoot = root
+	defer func() { backlightRoot = oldRoot }()
+
+	writeBacklight(t, root, "intel_backlight", "raw", 1200, 600)
+	writeBacklight(t, root, "acpi_video0", "firmware", 15, 3)
+	assert.Nil(t, os.Mkdir(filepath.Join(root, "card0-eDP-1"), 0755))
+	assert.Nil(t, os.Symlink(filepath.Join(root, "card0-eDP-1"), filepath.Join(root, "intel_backlight", "device")))
+
+	lights := findBacklights()
+	assert.Equal(t, 2, len(lights))
+	assert.Equal(t, "acpi_video0", lights[0].name)
+	assert.Equal(t, "", lights[0].connector())
+	assert.Equal(t, "intel_backlight", lights[1].name)
+	assert.Equal(t, "eDP-1", lights[1].connector())
+
+	val, err := lights[1].brightness()
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, val)
+
+	var setName string
+	var setValue int
+	lights[1].set = func(name string, value int) error {
+		setName, setValue = name, value
+		return nil
+	}
+	assert.Nil(t, lights[1].setBrightness(0.25))
+	assert.Equal(t, "intel_backlight", setName)
+	assert.Equal(t, 300, setValue)
+}
+
+func TestDDCDisplay(t *testing.T) {
+	var sent [][]byte
+	d := &ddcDisplay{bus: "i2c-4", transfer: func(bus string, req []byte, _ time.Duration, replyLen int) ([]byte, error) {
+		assert.Equal(t, "i2c-4", bus)
+		sent = append(sent, req)
+		if replyLen == 0 {
+			return nil, nil
+		}
+		return ddcReply(vcpBrightness, 100, 80), nil
+	}}
+
+	val, err := d.brightness()
+	assert.Nil(t, err)
+	assert.Equal(t, 0.8, val)
+	assert.Equal(t, []byte{0x51, 0x82, 0x01, 0x10, 0xac}, sent[0])
+
+	assert.Nil(t, d.setBrightness(0.5))
+	assert.Equal(t, []byte{0x51, 0x84, 0x03, 0x10, 0x00, 0x32, 0x9a}, sent[1])
+}
+
+func TestParseDDCReply(t *testing.T) {
+	reply := ddcReply(vcpBrightness, 255, 128)
+	cur, max, err := parseDDCReply(reply, vcpBrightness)
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(128), cur)
+	assert.Equal(t, uint16(255), max)
+
+	_, _, err = parseDDCReply(reply, 0x12)
+	assert.NotNil(t, err)
+	reply[9]++
+	_, _, err = parseDDCReply(reply, vcpBrightness)
+	assert.NotNil(t, err)
+	_, _, err = parseDDCReply(reply[:5], vcpBrightness)
+	assert.NotNil(t, err)
+}
+
+func TestMatchBrightnessDevices(t *testing.T) {
+	laptop := &fynedesk.Screen{Name: "eDP1"}
+	hdmi := &fynedesk.Screen{Name: "HDMI-1"}
+	dp := &fynedesk.Screen{Name: "DP-2"}
+	acpi := &backlight{name: "acpi_video0"}
+	monitor := &ddcDisplay{output: "HDMI-A-1"}
+
+	list := matchBrightnessDevices([]*fynedesk.Screen{hdmi, laptop, dp}, hdmi, []brightnessDevice{acpi, monitor})
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, hdmi, list[0].screen)
+	assert.Equal(t, monitor, list[0].device)
+	assert.Equal(t, laptop, list[1].screen)
+	assert.Equal(t, acpi, list[1].device)
+
+	list = matchBrightnessDevices([]*fynedesk.Screen{dp}, dp, []brightnessDevice{acpi})
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, dp, list[0].screen)
+}
+
+func TestOutputMatches(t *testing.T) {
+	assert.True(t, outputMatches("HDMI-A-1", "HDMI-1"))
+	assert.True(t, outputMatches("HDMI-A-1", "HDMI1"))
+	assert.True(t, outputMatches("eDP-1", "eDP1"))
+	assert.True(t, outputMatches("DP-3", "DP-3"))
+	assert.False(t, outputMatches("DP-3", "DP-1"))
+	assert.False(t, outputMatches("HDMI-A-1", "DP-1"))
+}
+
+func ddcReply(code byte, max, cur uint16) []byte {
+	reply := []byte{0x6e, 0x88, ddcGetVCPReply, 0, code, 0, byte(max >> 8), byte(max), byte(cur >> 8), byte(cur)}
+	sum := byte(ddcReadChecksum)
+	for _, b := range reply {
+		sum ^= b
+	}
+	return append(reply, sum)
+}
+
+func writeBacklight(t *testing.T, root, name, kind string, max, val int) {
+	dir := filepath.Join(root, name)
+	assert.Nil(t, os.Mkdir(dir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "type"), []byte(kind+"\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "max_brightness"), []byte(strconv.Itoa(max)+"\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "actual_brightness"), []byte(strconv.Itoa(val)+"\n"), 0644))
+}
+
+func TestBrightness_Destroy(t *testing.T) {
+	b := &brightness{}
+	b.listenForScreens()
+	assert.Equal(t, b, screenListener.module)
+
+	reloaded := &brightness{}
+	reloaded.listenForScreens()
+	b.Destroy()
+	assert.Equal(t, reloaded, screenListener.module)
+
+	reloaded.Destroy()
+	assert.Nil(t, screenListener.module)
+}
@@ -0,0 +1,104 @@
+package status
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	ddcAddress       = 0x37 // the I2C address of DDC/CI on a monitor
+	ddcHostAddress   = 0x51
+	ddcWriteChecksum = 0x6e // the monitor address, as written on the bus
+	ddcReadChecksum  = 0x50 // the virtual host address used to check replies
+
+	ddcGetVCP      = 0x01
+	ddcGetVCPReply = 0x02
+	ddcSetVCP      = 0x03
+
+	vcpBrightness = 0x10
+
+	ddcGetDelay = 40 * time.Millisecond // monitors need time to prepare a reply
+	ddcSetDelay = 50 * time.Millisecond
+)
+
+// ddcDisplay is an external monitor that can have its brightness set using DDC/CI.
+type ddcDisplay struct {
+	bus, output string
+	max         uint16
+
+	transfer func(bus string, req []byte, delay time.Duration, replyLen int) ([]byte, error)
+}
+
+func newDDCDisplay(bus, output string) *ddcDisplay {
+	return &ddcDisplay{bus: bus, output: output, transfer: i2cTransfer}
+}
+
+func (d *ddcDisplay) brightness() (float64, error) {
+	reply, err := d.transfer(d.bus, ddcRequest(ddcGetVCP, vcpBrightness), ddcGetDelay, 11)
+	if err != nil {
+		return 0, err
+	}
+
+	cur, max, err := parseDDCReply(reply, vcpBrightness)
+	if err != nil {
+		return 0, err
+	}
+	d.max = max
+	return float64(cur) / float64(max), nil
+}
+
+func (d *ddcDisplay) connector() string {
+	return d.output
+}
+
+func (d *ddcDisplay) setBrightness(val float64) error {
+	if d.max == 0 {
+		if _, err := d.brightness(); err != nil {
+			return err
+		}
+	}
+
+	level := uint16(math.Round(val * float64(d.max)))
+	_, err := d.transfer(d.bus, ddcRequest(ddcSetVCP, vcpBrightness, byte(level>>8), byte(level)), ddcSetDelay, 0)
+	return err
+}
+
+// ddcRequest encodes a DDC/CI message with the length header and checksum that monitors expect.
+func ddcRequest(op byte, args ...byte) []byte {
+	msg := append([]byte{ddcHostAddress, 0x80 | byte(len(args)+1), op}, args...)
+	sum := byte(ddcWriteChecksum)
+	for _, b := range msg {
+		sum ^= b
+	}
+
+	return append(msg, sum)
+}
+
+// parseDDCReply returns the current and maximum value from a reply to a get VCP feature request.
+func parseDDCReply(reply []byte, code byte) (uint16, uint16, error) {
+	if len(reply) < 11 {
+		return 0, 0, errors.New("DDC reply too short")
+	}
+
+	sum := byte(ddcReadChecksum)
+	for _, b := range reply[:10] {
+		sum ^= b
+	}
+	if sum != reply[10] {
+		return 0, 0, errors.New("DDC reply checksum incorrect")
+	}
+	if reply[2] != ddcGetVCPReply || reply[4] != code {
+		return 0, 0, errors.New("unexpected DDC reply")
+	}
+	if reply[3] != 0 {
+		return 0, 0, errors.New("monitor does not support this DDC feature")
+	}
+
+	max := uint16(reply[6])<<8 | uint16(reply[7])
+	cur := uint16(reply[8])<<8 | uint16(reply[9])
+	if max == 0 {
+		return 0, 0, errors.New("DDC feature has no range")
+	}
+	return cur, max, nil
+}
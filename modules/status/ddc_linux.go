@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const i2cSlave = 0x0703 // ioctl to set the address of the I2C device we talk to
+
+// hasDDCSupport is true as external monitors may be controlled over DDC/CI on Linux.
+const hasDDCSupport = true
+
+var drmRoot = "/sys/class/drm"
+
+// findDDCDisplays returns external monitors that respond to DDC/CI brightness requests.
+// The i2c-dev kernel module must be loaded, and the user needs permission to use /dev/i2c-*.
+func findDDCDisplays() []*ddcDisplay {
+	connectors, _ := filepath.Glob(filepath.Join(drmRoot, "card*-*"))
+
+	var list []*ddcDisplay
+	for _, path := range connectors {
+		output := drmCardPrefix.ReplaceAllString(filepath.Base(path), "")
+		if isInternalOutput(output) {
+			continue // panels use a backlight instead
+		}
+		status, err := os.ReadFile(filepath.Join(path, "status"))
+		if err != nil || strings.TrimSpace(string(status)) != "connected" {
+			continue
+		}
+
+		bus := connectorI2CBus(path)
+		if bus == "" {
+			continue
+		}
+		d := newDDCDisplay(bus, output)
+		if _, err = d.brightness(); err != nil {
+			continue
+		}
+		list = append(list, d)
+	}
+
+	return list
+}
+
+// connectorI2CBus returns the I2C device used for DDC on a connector.
+// DisplayPort uses an AUX channel bus inside the connector, other types link to a ddc bus.
+func connectorI2CBus(path string) string {
+	if ddc, err := filepath.EvalSymlinks(filepath.Join(path, "ddc")); err == nil {
+		return filepath.Base(ddc)
+	}
+
+	if aux, _ := filepath.Glob(filepath.Join(path, "i2c-*")); len(aux) > 0 {
+		return filepath.Base(aux[0])
+	}
+	return ""
+}
+
+func i2cTransfer(bus string, req []byte, delay time.Duration, replyLen int) ([]byte, error) {
+	f, err := os.OpenFile(filepath.Join("/dev", bus), os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), i2cSlave, ddcAddress)
+	if errno != 0 {
+		return nil, errno
+	}
+	if _, err = f.Write(req); err != nil {
+		return nil, err
+	}
+
+	time.Sleep(delay)
+	if replyLen == 0 {
+		return nil, nil
+	}
+	reply := make([]byte, replyLen)
+	_, err = f.Read(reply)
+	return reply, err
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package status
+
+import (
+	"errors"
+	"time"
+)
+
+// hasDDCSupport is false as DDC/CI monitors cannot be found on this platform.
+const hasDDCSupport = false
+
+// findDDCDisplays returns no monitors, DDC/CI requires the Linux i2c-dev interface.
+func findDDCDisplays() []*ddcDisplay {
+	return nil
+}
+
+func i2cTransfer(string, []byte, time.Duration, int) ([]byte, error) {
+	return nil, errors.New("DDC/CI is not supported on this platform")
+}
@@ -0,0 +1,153 @@
+package status
+
+import (
+	"sort"
+	"strconv"
+
+	"fyshos.com/fynedesk/internal/pulseaudio"
+)
+
+// mixerClient is the part of the PulseAudio client that the mixer uses, this works with PulseAudio and PipeWire.
+type mixerClient interface {
+	Cards() ([]pulseaudio.Card, error)
+	ServerInfo() (*pulseaudio.Server, error)
+	SinkInputs() ([]pulseaudio.Stream, error)
+	Sinks() ([]pulseaudio.Device, error)
+	Sources() ([]pulseaudio.Device, error)
+
+	SetCardProfile(cardIndex uint32, profileName string) error
+	SetDefaultSink(sinkName string) error
+	SetDefaultSource(sourceName string) error
+	SetSinkInputMute(index uint32, mute bool) error
+	SetSinkInputVolume(index uint32, volume float32) error
+	SetSinkPort(sinkName, portName string) error
+	SetSourceMute(sourceName string, mute bool) error
+	SetSourceVolume(sourceName string, volume float32) error
+}
+
+// mixer controls sound devices, application streams and card profiles.
+type mixer struct {
+	client mixerClient
+}
+
+func newMixer(client mixerClient) *mixer {
+	return &mixer{client: client}
+}
+
+func (m *mixer) cards() ([]pulseaudio.Card, error) {
+	return m.client.Cards()
+}
+
+func (m *mixer) defaultSink() string {
+	info, err := m.client.ServerInfo()
+	if err != nil {
+		return ""
+	}
+	return info.DefaultSink
+}
+
+func (m *mixer) defaultSource() string {
+	info, err := m.client.ServerInfo()
+	if err != nil {
+		return ""
+	}
+	return info.DefaultSource
+}
+
+func (m *mixer) setCardProfile(card pulseaudio.Card, profile string) error {
+	return m.client.SetCardProfile(card.Index, profile)
+}
+
+func (m *mixer) setDefaultSink(name string) error {
+	return m.client.SetDefaultSink(name)
+}
+
+func (m *mixer) setDefaultSource(name string) error {
+	return m.client.SetDefaultSource(name)
+}
+
+func (m *mixer) setSinkPort(sink, port string) error {
+	return m.client.SetSinkPort(sink, port)
+}
+
+func (m *mixer) setSourceMute(name string, mute bool) error {
+	return m.client.SetSourceMute(name, mute)
+}
+
+func (m *mixer) setSourceVolume(name string, percent int) error {
+	return m.client.SetSourceVolume(name, float32(percent)/100)
+}
+
+func (m *mixer) setStreamMute(index uint32, mute bool) error {
+	return m.client.SetSinkInputMute(index, mute)
+}
+
+func (m *mixer) setStreamVolume(index uint32, percent int) error {
+	return m.client.SetSinkInputVolume(index, float32(percent)/100)
+}
+
+func (m *mixer) sinks() ([]pulseaudio.Device, error) {
+	return m.client.Sinks()
+}
+
+// sources returns the input devices, leaving out monitors of output devices.
+func (m *mixer) sources() ([]pulseaudio.Device, error) {
+	all, err := m.client.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []pulseaudio.Device
+	for _, dev := range all {
+		if dev.MonitorOf == "" {
+			list = append(list, dev)
+		}
+	}
+	return list, nil
+}
+
+func (m *mixer) streams() ([]pulseaudio.Stream, error) {
+	return m.client.SinkInputs()
+}
+
+func cardDescription(c pulseaudio.Card) string {
+	if desc := c.PropList["device.description"]; desc != "" {
+		return desc
+	}
+
+	return c.Name
+}
+
+// cardProfiles returns the available profiles of a card, most preferred first.
+func cardProfiles(c pulseaudio.Card) []string {
+	var names []string
+	for name, p := range c.Profiles {
+		if p.Available != 0 {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return c.Profiles[names[i]].Priority > c.Profiles[names[j]].Priority
+	})
+	return names
+}
+
+func streamName(s pulseaudio.Stream) string {
+	if name := s.PropList["application.name"]; name != "" {
+		return name
+	}
+	if name := s.PropList["media.name"]; name != "" {
+		return name
+	}
+	if s.Name != "" {
+		return s.Name
+	}
+
+	return "Stream " + strconv.Itoa(int(s.Index))
+}
+
+// volumePercent returns a volume as a whole percentage.
+func volumePercent(vol float32) int {
+	return int(vol*100 + 0.5)
+}
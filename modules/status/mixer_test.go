@@ -0,0 +1,155 @@
+package status
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk/internal/pulseaudio"
+)
+
+func TestMixer_Devices(t *testing.T) {
+	m := newMixer(&mockMixerClient{})
+
+	sinks, err := m.sinks()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sinks))
+	assert.Equal(t, "Built-in Audio", sinks[0].Description)
+	assert.Equal(t, 67, volumePercent(sinks[0].Volume))
+	assert.Equal(t, 2, len(sinks[0].Ports))
+	assert.Equal(t, "analog-output-speaker", sinks[0].ActivePort)
+	assert.Equal(t, "alsa_output.analog", m.defaultSink())
+	assert.Equal(t, "alsa_input.analog", m.defaultSource())
+
+	sources, err := m.sources()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sources))
+	assert.Equal(t, "Built-in Microphone", sources[0].Description)
+	assert.True(t, sources[0].Muted)
+	assert.Equal(t, 40, volumePercent(sources[0].Volume))
+
+	streams, err := m.streams()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(streams))
+	assert.Equal(t, "Music Player", streamName(streams[0]))
+	assert.Equal(t, 100, volumePercent(streams[0].Volume))
+	assert.Equal(t, "Playback", streamName(streams[1]))
+	assert.Equal(t, 0, volumePercent(streams[1].Volume))
+	assert.Equal(t, "Stream 9", streamName(streams[2]))
+
+	cards, err := m.cards()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cards))
+	assert.Equal(t, "Built-in Audio", cardDescription(cards[0]))
+	assert.Equal(t, []string{"output:analog-stereo", "off"}, cardProfiles(cards[0]))
+}
+
+func TestMixer_Set(t *testing.T) {
+	client := &mockMixerClient{}
+	m := newMixer(client)
+
+	cards, _ := m.cards()
+	assert.Nil(t, m.setDefaultSink("alsa_output.hdmi"))
+	assert.Nil(t, m.setDefaultSource("alsa_input.usb"))
+	assert.Nil(t, m.setSinkPort("alsa_output.analog", "analog-output-headphones"))
+	assert.Nil(t, m.setSourceVolume("alsa_input.analog", 55))
+	assert.Nil(t, m.setSourceMute("alsa_input.analog", false))
+	assert.Nil(t, m.setStreamVolume(7, 30))
+	assert.Nil(t, m.setStreamMute(7, true))
+	assert.Nil(t, m.setCardProfile(cards[0], "off"))
+
+	assert.Equal(t, []string{
+		"default sink alsa_output.hdmi",
+		"default source alsa_input.usb",
+		"sink port alsa_output.analog analog-output-headphones",
+		"source volume alsa_input.analog 0.55",
+		"source mute alsa_input.analog false",
+		"stream volume 7 0.30",
+		"stream mute 7 true",
+		"card profile 0 off",
+	}, client.calls)
+}
+
+// mockMixerClient returns a fixed set of devices and records the changes that are asked for.
+type mockMixerClient struct {
+	calls []string
+}
+
+func (c *mockMixerClient) Cards() ([]pulseaudio.Card, error) {
+	return []pulseaudio.Card{{Index: 0, Name: "alsa_card.pci",
+		PropList: map[string]string{"device.description": "Built-in Audio"},
+		Profiles: map[string]*pulseaudio.Profile{
+			"off":                  {Name: "off", Description: "Off", Priority: 0, Available: 1},
+			"output:analog-stereo": {Name: "output:analog-stereo", Priority: 6500, Available: 1},
+			"output:hdmi-stereo":   {Name: "output:hdmi-stereo", Priority: 5900, Available: 0},
+		}}}, nil
+}
+
+func (c *mockMixerClient) ServerInfo() (*pulseaudio.Server, error) {
+	return &pulseaudio.Server{DefaultSink: "alsa_output.analog", DefaultSource: "alsa_input.analog"}, nil
+}
+
+func (c *mockMixerClient) SinkInputs() ([]pulseaudio.Stream, error) {
+	return []pulseaudio.Stream{
+		{Index: 7, Volume: 1, PropList: map[string]string{"application.name": "Music Player", "media.name": "Song"}},
+		{Index: 8, Muted: true, PropList: map[string]string{"media.name": "Playback"}},
+		{Index: 9},
+	}, nil
+}
+
+func (c *mockMixerClient) Sinks() ([]pulseaudio.Device, error) {
+	return []pulseaudio.Device{{Index: 1, Name: "alsa_output.analog", Description: "Built-in Audio", Volume: 0.67,
+		Ports: []pulseaudio.DevicePort{
+			{Name: "analog-output-speaker", Description: "Speakers", Available: true},
+			{Name: "analog-output-headphones", Description: "Headphones", Available: true},
+		}, ActivePort: "analog-output-speaker"}}, nil
+}
+
+func (c *mockMixerClient) Sources() ([]pulseaudio.Device, error) {
+	return []pulseaudio.Device{
+		{Index: 1, Name: "alsa_output.analog.monitor", Description: "Monitor of Built-in Audio",
+			MonitorOf: "alsa_output.analog"},
+		{Index: 2, Name: "alsa_input.analog", Description: "Built-in Microphone", Volume: 0.4, Muted: true},
+	}, nil
+}
+
+func (c *mockMixerClient) SetCardProfile(cardIndex uint32, profileName string) error {
+	c.calls = append(c.calls, fmt.Sprintf("card profile %d %s", cardIndex, profileName))
+	return nil
+}
+
+func (c *mockMixerClient) SetDefaultSink(sinkName string) error {
+	c.calls = append(c.calls, "default sink "+sinkName)
+	return nil
+}
+
+func (c *mockMixerClient) SetDefaultSource(sourceName string) error {
+	c.calls = append(c.calls, "default source "+sourceName)
+	return nil
+}
+
+func (c *mockMixerClient) SetSinkInputMute(index uint32, mute bool) error {
+	c.calls = append(c.calls, fmt.Sprintf("stream mute %d %t", index, mute))
+	return nil
+}
+
+func (c *mockMixerClient) SetSinkInputVolume(index uint32, volume float32) error {
+	c.calls = append(c.calls, fmt.Sprintf("stream volume %d %.2f", index, volume))
+	return nil
+}
+
+func (c *mockMixerClient) SetSinkPort(sinkName, portName string) error {
+	c.calls = append(c.calls, "sink port "+sinkName+" "+portName)
+	return nil
+}
+
+func (c *mockMixerClient) SetSourceMute(sourceName string, mute bool) error {
+	c.calls = append(c.calls, fmt.Sprintf("source mute %s %t", sourceName, mute))
+	return nil
+}
+
+func (c *mockMixerClient) SetSourceVolume(sourceName string, volume float32) error {
+	c.calls = append(c.calls, fmt.Sprintf("source volume %s %.2f", sourceName, volume))
+	return nil
+}
@@ -1,16 +1,15 @@
 package status
 
 import (
-	"log"
-	"os"
-	"os/exec"
-	"strings"
-	"time"
+	"strconv"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/godbus/dbus/v5"
+
 	"fyshos.com/fynedesk"
 	wmtheme "fyshos.com/fynedesk/theme"
 )
@@ -20,173 +19,218 @@ var networkMeta = fynedesk.ModuleMetadata{
 	NewInstance: NewNetwork,
 }
 
-const networkNameEthernet = "Ethernet"
-
 type network struct {
 	name *widget.Label
 	icon *widget.Button
+
+	client *nmClient
+	agent  *networkAgent
+
+	panel                   fyne.Window
+	panelShown              bool
+	airplane                *widget.Check
+	connections, wifi, vpns *fyne.Container
 }
 
 func (n *network) Destroy() {
+	if n.client != nil {
+		n.client.unwatch()
+	}
+	if n.agent != nil {
+		n.agent.unregister()
+	}
+	if n.panel != nil {
+		n.panel.Close()
+	}
 }
 
-func (n *network) wirelessName() (string, error) {
-	net := ""
-	iw, _ := exec.LookPath("iw")
-	if iw == "" {
-		iw, _ = exec.LookPath("/usr/sbin/iw")
-	}
-	if iw != "" {
-		out, err := exec.Command("bash", []string{"-c", iw + " dev | grep Interface | cut -d \" \" -f2"}...).Output()
-		if err != nil {
-			log.Println("Error running iw", err)
-			return "", err
-		}
-		dev := strings.TrimSpace(string(out))
-		if dev == "" {
-			return "", nil
-		}
-
-		out, err = exec.Command("bash", []string{"-c", iw + " dev " + dev + " info | grep ssid | sed 's\\ssid\\\\g'"}...).Output()
-		if err != nil {
-			log.Println("Error running iw", err)
-			return "", err
-		}
-		net = string(out)
-	} else {
-		out, err := exec.Command("bash", []string{"-c", "/System/Library/PrivateFrameworks/Apple80211.framework/Resources/airport -I  | awk -F' SSID: '  '/ SSID: / {print $2}'"}...).Output()
-		if err != nil {
-			log.Println("Error getting network info from airport utility", err)
-			return "", err
-		}
+func (n *network) StatusAreaWidget() fyne.CanvasObject {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		fyne.LogError("Unable to connect to the system bus", err)
+		return nil
+	}
+	n.client = newNMClient(conn)
+	if _, err = n.client.primaryConnection(); err != nil {
+		fyne.LogError("NetworkManager is not available", err)
+		return nil
+	}
 
-		net = string(out)
+	n.agent = newNetworkAgent()
+	if err = n.agent.register(conn); err != nil {
+		fyne.LogError("Could not register network password agent", err)
 	}
-	return strings.TrimSpace(net), nil
-}
 
-func (n *network) isEthernetConnected() (bool, error) {
-	if ip, _ := exec.LookPath("ip"); ip != "" {
-		out, err := exec.Command("bash", []string{"-c", "ip link | grep \",UP,\" | grep -v LOOPBACK | grep -v \": wl\" | wc -l"}...).Output()
-		if err != nil {
-			log.Println("Error running ip tool", err)
-			return false, err
-		}
-		if strings.TrimSpace(string(out)) == "0" {
-			return false, nil
-		}
-	} else if scutil, _ := exec.LookPath("scutil"); scutil != "" {
-		out, err := exec.Command("bash", []string{"-c", "scutil --nwi | grep address | wc -l"}...).Output()
-		if err != nil {
-			log.Println("Error running scutil tool", err)
-			return false, err
-		}
-		if strings.TrimSpace(string(out)) == "0" {
-			return false, nil
-		}
-	} else {
-		out, err := exec.Command("bash", []string{"-c", "ifconfig | pcregrep -M -o '^[^\\t:]+:([^\\n]|\\n\\t)*status: active'"}...).Output()
-		if err != nil {
-			log.Println("Error running ifconfig tool", err)
-			return false, err
-		}
-		if !strings.Contains(string(out), "broadcast") {
-			return false, nil
-		}
+	n.name = widget.NewLabel("")
+	n.icon = &widget.Button{Icon: wmtheme.WifiOffIcon, Importance: widget.LowImportance, OnTapped: n.showPanel}
+	if err = n.client.watch(n.refresh); err != nil {
+		fyne.LogError("Unable to watch for network changes", err)
 	}
-	return true, nil
+	n.refresh()
+
+	return container.New(&handleNarrow{}, n.icon, n.name)
 }
 
-func (n *network) networkName() string {
-	name, _ := n.wirelessName()
-	if name != "" {
-		return name
-	}
+func (n *network) Metadata() fynedesk.ModuleMetadata {
+	return networkMeta
+}
 
-	ether, _ := n.isEthernetConnected()
-	if ether {
-		return networkNameEthernet
+func (n *network) connectionIcon(kind string) fyne.Resource {
+	switch kind {
+	case nmConnectionWifi:
+		return wmtheme.WifiIcon
+	case nmConnectionEther:
+		return wmtheme.EthernetIcon
+	case "":
+		return wmtheme.WifiOffIcon
 	}
-	return ""
-}
 
-func (n *network) tick() {
-	tick := time.NewTicker(time.Second * 10)
-	go func() {
-		for {
-			val := n.networkName()
-			if val != n.name.Text {
-				n.name.SetText(val)
-
-				if val == "" {
-					n.icon.SetIcon(wmtheme.WifiOffIcon)
-				} else if val == networkNameEthernet {
-					n.icon.SetIcon(wmtheme.EthernetIcon)
-				} else {
-					n.icon.SetIcon(wmtheme.WifiIcon)
-				}
-			}
-			<-tick.C
-		}
-	}()
+	return wmtheme.InternetIcon
 }
 
-func (n *network) StatusAreaWidget() fyne.CanvasObject {
-	if _, err := n.wirelessName(); err != nil {
-		if _, err = n.isEthernetConnected(); err != nil {
-			return nil
-		}
+// refresh updates the status area, and the network panel if it is showing, to match the current network state.
+func (n *network) refresh() {
+	conn, err := n.client.primaryConnection()
+	if err != nil {
+		fyne.LogError("Failed to look up network connection", err)
 	}
 
-	n.name = widget.NewLabel("")
-	n.icon = &widget.Button{Icon: wmtheme.WifiOffIcon, Importance: widget.LowImportance, OnTapped: n.showSettings}
-	n.tick()
+	name, kind := "", ""
+	if conn != nil {
+		name, kind = conn.id, conn.kind
+	}
+	if name != n.name.Text {
+		n.name.SetText(name)
+	}
+	n.icon.SetIcon(n.connectionIcon(kind))
 
-	return container.New(&handleNarrow{}, n.icon, n.name)
+	if n.panelShown {
+		n.refreshPanel()
+	}
 }
 
-func (n *network) Metadata() fynedesk.ModuleMetadata {
-	return networkMeta
-}
+func (n *network) refreshPanel() {
+	if on, err := n.client.airplaneMode(); err == nil {
+		n.airplane.OnChanged = nil
+		n.airplane.SetChecked(on)
+		n.airplane.OnChanged = n.setAirplaneMode
+	}
 
-func (n *network) showSettings() {
-	gui := &networkApp{}
+	var items []fyne.CanvasObject
+	if active, err := n.client.activeConnections(); err == nil {
+		for _, conn := range active {
+			items = append(items, container.NewBorder(nil, nil, widget.NewIcon(n.connectionIcon(conn.kind)), nil,
+				widget.NewLabel(conn.id)))
+		}
+	}
+	if len(items) == 0 {
+		items = append(items, widget.NewLabel("Not connected"))
+	}
+	n.connections.Objects = items
+	n.connections.Refresh()
 
-	if err := fynedesk.Instance().RunApp(gui); err != nil {
-		fyne.LogError("Failed to find WiFi settings tool connman-gtk", err)
-		return
+	items = nil
+	if aps, err := n.client.accessPoints(); err == nil {
+		for _, ap := range aps {
+			items = append(items, n.accessPointRow(ap))
+		}
 	}
+	n.wifi.Objects = items
+	n.wifi.Refresh()
+
+	items = nil
+	if vpns, err := n.client.vpnConnections(); err == nil {
+		for _, vpn := range vpns {
+			v := vpn
+			check := widget.NewCheck(v.id, nil)
+			check.SetChecked(v.active != "")
+			check.OnChanged = func(on bool) {
+				go n.setVPN(v, on)
+			}
+			items = append(items, check)
+		}
+	}
+	if len(items) == 0 {
+		items = append(items, widget.NewLabel("No VPN connections"))
+	}
+	n.vpns.Objects = items
+	n.vpns.Refresh()
 }
 
-// NewNetwork creates a new module that will show network information in the status area
-func NewNetwork() fynedesk.Module {
-	return &network{}
+func (n *network) accessPointRow(ap *accessPoint) fyne.CanvasObject {
+	status := container.NewHBox()
+	if ap.secure {
+		status.Add(widget.NewIcon(wmtheme.LockIcon))
+	}
+	status.Add(widget.NewLabel(strconv.Itoa(int(ap.strength)) + "%"))
+
+	var icon fyne.Resource = wmtheme.WifiIcon
+	if ap.active {
+		icon = theme.ConfirmIcon()
+	}
+	join := &widget.Button{Text: ap.ssid, Icon: icon, Alignment: widget.ButtonAlignLeading,
+		Importance: widget.LowImportance, OnTapped: func() {
+			go func() {
+				if err := n.client.connectWifi(ap); err != nil {
+					fyne.LogError("Failed to connect to "+ap.ssid, err)
+				}
+			}()
+		}}
+	return container.NewBorder(nil, nil, nil, status, join)
 }
 
-type networkApp struct {
+func (n *network) setAirplaneMode(on bool) {
+	go func() {
+		if err := n.client.setAirplaneMode(on); err != nil {
+			fyne.LogError("Failed to set airplane mode", err)
+		}
+	}()
 }
 
-func (n *networkApp) Name() string {
-	return "Network Settings"
+func (n *network) setVPN(vpn *nmConnection, on bool) {
+	if err := n.client.setVPN(vpn, on); err != nil {
+		fyne.LogError("Failed to change VPN "+vpn.id, err)
+	}
 }
 
-func (n *networkApp) Run(env []string) error {
-	vars := os.Environ()
-	vars = append(vars, env...)
+func (n *network) showPanel() {
+	n.panelShown = true
+	if n.panel != nil {
+		n.refreshPanel()
+		n.panel.Show()
+		n.panel.RequestFocus()
+		return
+	}
 
-	cmd := exec.Command("connman-gtk")
-	cmd.Env = vars
-	return cmd.Start()
-}
+	n.airplane = widget.NewCheck("Airplane Mode", n.setAirplaneMode)
+	n.connections = container.NewVBox()
+	n.wifi = container.NewVBox()
+	n.vpns = container.NewVBox()
+	scan := &widget.Button{Text: "Scan", Icon: theme.ViewRefreshIcon(), OnTapped: func() {
+		go func() {
+			if err := n.client.scan(); err != nil {
+				fyne.LogError("Failed to scan for Wi-Fi networks", err)
+			}
+		}()
+	}}
 
-func (n *networkApp) Categories() []string {
-	return []string{"Settings"}
-}
+	top := container.NewVBox(n.airplane, widget.NewCard("Connections", "", n.connections))
+	bottom := widget.NewCard("VPN", "", n.vpns)
+	wifi := widget.NewCard("Wi-Fi", "", container.NewBorder(nil, scan, nil, nil, container.NewVScroll(n.wifi)))
 
-func (n *networkApp) Hidden() bool {
-	return true
+	w := fyne.CurrentApp().NewWindow("Network")
+	w.SetContent(container.NewBorder(top, bottom, nil, nil, wifi))
+	w.SetCloseIntercept(func() {
+		n.panelShown = false
+		w.Hide()
+	})
+	w.Resize(fyne.NewSize(320, 480))
+	n.panel = w
+	n.refreshPanel()
+	w.Show()
 }
 
-func (n *networkApp) Icon(theme string, size int) fyne.Resource {
-	return wmtheme.WifiIcon
+// NewNetwork creates a new module that will show network information in the status area
+func NewNetwork() fynedesk.Module {
+	return &network{}
 }
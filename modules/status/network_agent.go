@@ -0,0 +1,101 @@
+package status
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	nmAgentIface        = "org.freedesktop.NetworkManager.SecretAgent"
+	nmAgentPath         = "/org/freedesktop/NetworkManager/SecretAgent"
+	nmAgentManagerPath  = "/org/freedesktop/NetworkManager/AgentManager"
+	nmAgentManagerIface = "org.freedesktop.NetworkManager.AgentManager"
+
+	nmSecretsAllowInteraction = 0x1
+)
+
+// networkAgent is a NetworkManager secret agent, it asks the user for Wi-Fi passwords when they are needed.
+type networkAgent struct {
+	conn    *dbus.Conn
+	prompts pendingPrompts
+
+	// prompt asks for the password of the named network, returning false if the user cancelled.
+	prompt func(network string, cancel <-chan struct{}) (string, bool)
+}
+
+func newNetworkAgent() *networkAgent {
+	return &networkAgent{prompt: promptPassword}
+}
+
+// register exports the agent and tells NetworkManager to send secret requests to it.
+func (a *networkAgent) register(conn *dbus.Conn) error {
+	if err := conn.Export(a, nmAgentPath, nmAgentIface); err != nil {
+		return err
+	}
+
+	a.conn = conn
+	return conn.Object(nmService, nmAgentManagerPath).Call(nmAgentManagerIface+".Register", 0, "io.fyshos.fynedesk").Err
+}
+
+// unregister tells NetworkManager to stop sending secret requests to the agent, and stops exporting it.
+func (a *networkAgent) unregister() {
+	a.prompts.cancelAll()
+	if a.conn == nil {
+		return
+	}
+
+	err := a.conn.Object(nmService, nmAgentManagerPath).Call(nmAgentManagerIface+".Unregister", 0).Err
+	if err != nil {
+		fyne.LogError("Could not unregister network password agent", err)
+	}
+	_ = a.conn.Export(nil, nmAgentPath, nmAgentIface)
+	a.conn = nil
+}
+
+// CancelGetSecrets is called by NetworkManager if a request for secrets is no longer needed,
+// it closes the password prompt if one is open.
+func (a *networkAgent) CancelGetSecrets(_ dbus.ObjectPath, _ string) *dbus.Error {
+	a.prompts.cancelAll()
+	return nil
+}
+
+// DeleteSecrets is called when a connection is removed, we do not store secrets so there is nothing to do.
+func (a *networkAgent) DeleteSecrets(_ map[string]map[string]dbus.Variant, _ dbus.ObjectPath) *dbus.Error {
+	return nil
+}
+
+// GetSecrets returns the password for a Wi-Fi connection, asking the user for it.
+func (a *networkAgent) GetSecrets(conn map[string]map[string]dbus.Variant, _ dbus.ObjectPath, setting string,
+	_ []string, flags uint32) (map[string]map[string]dbus.Variant, *dbus.Error) {
+	if setting != nmConnectionWifi+"-security" || flags&nmSecretsAllowInteraction == 0 {
+		return nil, dbus.NewError(nmAgentIface+".Error.NoSecrets", nil)
+	}
+
+	name, _ := conn["connection"]["id"].Value().(string)
+	if ssid := wifiSSID(conn); ssid != "" {
+		name = ssid
+	}
+	pass, ok := a.prompt(name, a.prompts.cancelled())
+	if !ok {
+		return nil, dbus.NewError(nmAgentIface+".Error.UserCanceled", nil)
+	}
+
+	key := "psk"
+	if mgmt, _ := conn[setting]["key-mgmt"].Value().(string); mgmt == "none" {
+		key = "wep-key0"
+	}
+	return map[string]map[string]dbus.Variant{setting: {key: dbus.MakeVariant(pass)}}, nil
+}
+
+// SaveSecrets is called when NetworkManager would like an agent to store secrets, which we leave to it.
+func (a *networkAgent) SaveSecrets(_ map[string]map[string]dbus.Variant, _ dbus.ObjectPath) *dbus.Error {
+	return nil
+}
+
+// promptPassword shows a modal window asking for a network password, and waits for the user to respond.
+func promptPassword(network string, cancel <-chan struct{}) (string, bool) {
+	return showPrompt("Network Password", "Enter the password for \""+network+"\"", widget.NewPasswordEntry(),
+		"Connect", cancel)
+}
@@ -0,0 +1,250 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/stretchr/testify/assert"
+
+	"fyshos.com/fynedesk/internal/dbusutil/dbustest"
+)
+
+type mockNM struct {
+	activated  []dbus.ObjectPath
+	added      []string
+	agentCalls []string
+}
+
+func (m *mockNM) ActivateConnection(conn, _, _ dbus.ObjectPath) (dbus.ObjectPath, *dbus.Error) {
+	m.activated = append(m.activated, conn)
+	return "/org/freedesktop/NetworkManager/ActiveConnection/9", nil
+}
+
+func (m *mockNM) AddAndActivateConnection(settings map[string]map[string]dbus.Variant, _, _ dbus.ObjectPath) (
+	dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	m.added = append(m.added, wifiSSID(settings))
+	return "/org/freedesktop/NetworkManager/Settings/9", "/org/freedesktop/NetworkManager/ActiveConnection/9", nil
+}
+
+func (m *mockNM) DeactivateConnection(_ dbus.ObjectPath) *dbus.Error {
+	return nil
+}
+
+func (m *mockNM) GetDevices() ([]dbus.ObjectPath, *dbus.Error) {
+	return []dbus.ObjectPath{"/org/freedesktop/NetworkManager/Devices/1"}, nil
+}
+
+type mockNMAgentManager struct {
+	m *mockNM
+}
+
+func (a *mockNMAgentManager) Register(id string) *dbus.Error {
+	a.m.agentCalls = append(a.m.agentCalls, "Register "+id)
+	return nil
+}
+
+func (a *mockNMAgentManager) Unregister() *dbus.Error {
+	a.m.agentCalls = append(a.m.agentCalls, "Unregister")
+	return nil
+}
+
+type mockNMWireless struct{}
+
+func (m *mockNMWireless) GetAllAccessPoints() ([]dbus.ObjectPath, *dbus.Error) {
+	return []dbus.ObjectPath{"/org/freedesktop/NetworkManager/AccessPoint/1",
+		"/org/freedesktop/NetworkManager/AccessPoint/2", "/org/freedesktop/NetworkManager/AccessPoint/3"}, nil
+}
+
+func (m *mockNMWireless) RequestScan(map[string]dbus.Variant) *dbus.Error {
+	return nil
+}
+
+type mockNMSettings struct{}
+
+func (m *mockNMSettings) ListConnections() ([]dbus.ObjectPath, *dbus.Error) {
+	return []dbus.ObjectPath{"/org/freedesktop/NetworkManager/Settings/1",
+		"/org/freedesktop/NetworkManager/Settings/2"}, nil
+}
+
+type mockNMConnection struct {
+	settings map[string]map[string]dbus.Variant
+}
+
+func (m *mockNMConnection) GetSettings() (map[string]map[string]dbus.Variant, *dbus.Error) {
+	return m.settings, nil
+}
+
+func TestNMClient(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	nm := exportMockNM(t, server)
+	c := newNMClient(client)
+
+	primary, err := c.primaryConnection()
+	assert.Nil(t, err)
+	assert.Equal(t, "Home", primary.id)
+	assert.Equal(t, nmConnectionWifi, primary.kind)
+
+	aps, err := c.accessPoints()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(aps))
+	assert.Equal(t, "Cafe", aps[0].ssid)
+	assert.Equal(t, uint8(80), aps[0].strength)
+	assert.False(t, aps[0].secure)
+	assert.Equal(t, "Home", aps[1].ssid)
+	assert.True(t, aps[1].secure)
+	assert.True(t, aps[1].active)
+
+	assert.Nil(t, c.connectWifi(aps[1]))
+	assert.Equal(t, []dbus.ObjectPath{"/org/freedesktop/NetworkManager/Settings/1"}, nm.activated)
+	assert.Nil(t, c.connectWifi(aps[0]))
+	assert.Equal(t, []string{"Cafe"}, nm.added)
+
+	vpns, err := c.vpnConnections()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(vpns))
+	assert.Equal(t, "Work", vpns[0].id)
+	assert.Equal(t, dbus.ObjectPath(""), vpns[0].active)
+	assert.Nil(t, c.setVPN(vpns[0], true))
+	assert.Equal(t, dbus.ObjectPath("/org/freedesktop/NetworkManager/Settings/2"), nm.activated[1])
+
+	on, err := c.airplaneMode()
+	assert.Nil(t, err)
+	assert.False(t, on)
+	assert.Nil(t, c.setAirplaneMode(true))
+	on, _ = c.airplaneMode()
+	assert.True(t, on)
+}
+
+func TestNMClient_Watch(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	exportMockNM(t, server)
+	c := newNMClient(client)
+
+	changed := make(chan bool, 1)
+	assert.Nil(t, c.watch(func() {
+		select {
+		case changed <- true:
+		default:
+		}
+	}))
+	assert.Nil(t, server.Emit(nmPath, nmIface+".StateChanged", uint32(70)))
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Error("Change was not reported")
+	}
+
+	c.unwatch()
+	assert.Nil(t, server.Emit(nmPath, nmIface+".StateChanged", uint32(70)))
+	select {
+	case <-changed:
+		t.Error("Change was reported after unwatch")
+	case <-time.After(time.Second / 10):
+	}
+}
+
+func TestNetworkAgent_Register(t *testing.T) {
+	server, client := dbustest.StartPrivateBus(t)
+	nm := exportMockNM(t, server)
+	a := newNetworkAgent()
+
+	assert.Nil(t, a.register(client))
+	assert.Equal(t, []string{"Register io.fyshos.fynedesk"}, nm.agentCalls)
+	a.unregister()
+	assert.Equal(t, []string{"Register io.fyshos.fynedesk", "Unregister"}, nm.agentCalls)
+
+	err := server.Object(client.Names()[0], nmAgentPath).Call(nmAgentIface+".CancelGetSecrets", 0,
+		dbus.ObjectPath("/"), nmConnectionWifi+"-security").Err
+	assert.NotNil(t, err) // no longer exported
+}
+
+func TestNetworkAgent_GetSecrets(t *testing.T) {
+	asked := ""
+	a := &networkAgent{prompt: func(network string, _ <-chan struct{}) (string, bool) {
+		asked = network
+		return "secret", true
+	}}
+	conn := map[string]map[string]dbus.Variant{
+		"connection":     {"id": dbus.MakeVariant("Home connection")},
+		nmConnectionWifi: {"ssid": dbus.MakeVariant([]byte("Home"))},
+	}
+
+	secrets, err := a.GetSecrets(conn, "/", nmConnectionWifi+"-security", nil, nmSecretsAllowInteraction)
+	assert.Nil(t, err)
+	assert.Equal(t, "Home", asked)
+	assert.Equal(t, "secret", secrets[nmConnectionWifi+"-security"]["psk"].Value())
+
+	_, err = a.GetSecrets(conn, "/", nmConnectionWifi+"-security", nil, 0)
+	assert.NotNil(t, err)
+
+	a.prompt = func(string, <-chan struct{}) (string, bool) {
+		return "", false
+	}
+	_, err = a.GetSecrets(conn, "/", nmConnectionWifi+"-security", nil, nmSecretsAllowInteraction)
+	assert.NotNil(t, err)
+}
+
+func exportMockNM(t *testing.T, conn *dbus.Conn) *mockNM {
+	nm := &mockNM{}
+	exports := map[dbus.ObjectPath]struct {
+		obj   interface{}
+		iface string
+		props prop.Map
+	}{
+		nmPath: {nm, nmIface, prop.Map{nmIface: {
+			"PrimaryConnection": {Value: dbus.ObjectPath("/org/freedesktop/NetworkManager/ActiveConnection/1")},
+			"ActiveConnections": {Value: []dbus.ObjectPath{"/org/freedesktop/NetworkManager/ActiveConnection/1"}},
+			"WirelessEnabled":   {Value: true, Writable: true},
+			"WwanEnabled":       {Value: true, Writable: true},
+		}}},
+		"/org/freedesktop/NetworkManager/Devices/1": {&mockNMWireless{}, nmWireless, prop.Map{
+			nmDevice:   {"DeviceType": {Value: uint32(nmDeviceTypeWifi)}},
+			nmWireless: {"ActiveAccessPoint": {Value: dbus.ObjectPath("/org/freedesktop/NetworkManager/AccessPoint/1")}},
+		}},
+		"/org/freedesktop/NetworkManager/AccessPoint/1": {nil, "", mockAccessPoint("Home", 40, 0x188)},
+		"/org/freedesktop/NetworkManager/AccessPoint/2": {nil, "", mockAccessPoint("Cafe", 80, 0)},
+		"/org/freedesktop/NetworkManager/AccessPoint/3": {nil, "", mockAccessPoint("Cafe", 30, 0)},
+		"/org/freedesktop/NetworkManager/ActiveConnection/1": {nil, "", prop.Map{nmActive: {
+			"Id":         {Value: "Home"},
+			"Type":       {Value: nmConnectionWifi},
+			"Connection": {Value: dbus.ObjectPath("/org/freedesktop/NetworkManager/Settings/1")},
+		}}},
+		nmAgentManagerPath: {&mockNMAgentManager{nm}, nmAgentManagerIface, nil},
+		nmSettingsPath:     {&mockNMSettings{}, nmSettings, nil},
+		"/org/freedesktop/NetworkManager/Settings/1": {&mockNMConnection{map[string]map[string]dbus.Variant{
+			"connection":     {"id": dbus.MakeVariant("Home"), "type": dbus.MakeVariant(nmConnectionWifi)},
+			nmConnectionWifi: {"ssid": dbus.MakeVariant([]byte("Home"))},
+		}}, nmSettingsConn, nil},
+		"/org/freedesktop/NetworkManager/Settings/2": {&mockNMConnection{map[string]map[string]dbus.Variant{
+			"connection": {"id": dbus.MakeVariant("Work"), "type": dbus.MakeVariant(nmConnectionVPN)},
+		}}, nmSettingsConn, nil},
+	}
+
+	for path, export := range exports {
+		if export.obj != nil {
+			assert.Nil(t, conn.Export(export.obj, path, export.iface))
+		}
+		if export.props != nil {
+			_, err := prop.Export(conn, path, export.props)
+			assert.Nil(t, err)
+		}
+	}
+
+	reply, err := conn.RequestName(nmService, dbus.NameFlagDoNotQueue)
+	assert.Nil(t, err)
+	assert.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+	return nm
+}
+
+func mockAccessPoint(ssid string, strength uint8, rsn uint32) prop.Map {
+	return prop.Map{nmAccessPoint: {
+		"Ssid":     {Value: []byte(ssid)},
+		"Strength": {Value: strength},
+		"Flags":    {Value: uint32(0)},
+		"WpaFlags": {Value: uint32(0)},
+		"RsnFlags": {Value: rsn},
+	}}
+}
@@ -0,0 +1,363 @@
+package status
+
+import (
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+
+	"fyshos.com/fynedesk/internal/dbusutil"
+)
+
+const (
+	nmService         = "org.freedesktop.NetworkManager"
+	nmPath            = "/org/freedesktop/NetworkManager"
+	nmSettingsPath    = "/org/freedesktop/NetworkManager/Settings"
+	nmIface           = "org.freedesktop.NetworkManager"
+	nmAccessPoint     = nmIface + ".AccessPoint"
+	nmActive          = nmIface + ".Connection.Active"
+	nmDevice          = nmIface + ".Device"
+	nmSettings        = nmIface + ".Settings"
+	nmSettingsConn    = nmIface + ".Settings.Connection"
+	nmWireless        = nmIface + ".Device.Wireless"
+	nmDeviceTypeWifi  = 2
+	nmConnectionWifi  = "802-11-wireless"
+	nmConnectionVPN   = "vpn"
+	nmConnectionWG    = "wireguard"
+	nmConnectionEther = "802-3-ethernet"
+)
+
+// accessPoint describes a Wi-Fi network that is in range.
+type accessPoint struct {
+	path, device dbus.ObjectPath
+	ssid         string
+	strength     uint8
+	secure       bool
+	active       bool
+}
+
+// nmConnection describes a network connection that NetworkManager knows about.
+// If the connection is in use then active will be the path of the active connection.
+type nmConnection struct {
+	path, active dbus.ObjectPath
+	id, kind     string
+}
+
+// nmClient talks to NetworkManager over D-Bus.
+type nmClient struct {
+	conn    *dbus.Conn
+	signals *dbusutil.SignalWatch
+}
+
+func newNMClient(conn *dbus.Conn) *nmClient {
+	return &nmClient{conn: conn}
+}
+
+// accessPoints returns the Wi-Fi networks in range of any wireless device, strongest first.
+// Each network is only listed once, using the strongest signal if it is seen more than once.
+func (c *nmClient) accessPoints() ([]*accessPoint, error) {
+	devices, err := c.wirelessDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*accessPoint)
+	for _, dev := range devices {
+		var active dbus.ObjectPath
+		_ = c.property(dev, nmWireless, "ActiveAccessPoint", &active)
+
+		var paths []dbus.ObjectPath
+		if err := c.object(dev).Call(nmWireless+".GetAllAccessPoints", 0).Store(&paths); err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			ap, err := c.accessPoint(path)
+			if err != nil || ap.ssid == "" {
+				continue // access points can disappear while we look them up, and hidden ones have no name
+			}
+			ap.device = dev
+			ap.active = path == active
+
+			if old, ok := found[ap.ssid]; ok && !ap.active && (old.active || old.strength >= ap.strength) {
+				continue
+			}
+			found[ap.ssid] = ap
+		}
+	}
+
+	list := make([]*accessPoint, 0, len(found))
+	for _, ap := range found {
+		list = append(list, ap)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].strength == list[j].strength {
+			return list[i].ssid < list[j].ssid
+		}
+		return list[i].strength > list[j].strength
+	})
+	return list, nil
+}
+
+func (c *nmClient) accessPoint(path dbus.ObjectPath) (*accessPoint, error) {
+	ap := &accessPoint{path: path}
+	var ssid []byte
+	if err := c.property(path, nmAccessPoint, "Ssid", &ssid); err != nil {
+		return nil, err
+	}
+	ap.ssid = string(ssid)
+	if err := c.property(path, nmAccessPoint, "Strength", &ap.strength); err != nil {
+		return nil, err
+	}
+
+	var flags, wpa, rsn uint32
+	_ = c.property(path, nmAccessPoint, "Flags", &flags)
+	_ = c.property(path, nmAccessPoint, "WpaFlags", &wpa)
+	_ = c.property(path, nmAccessPoint, "RsnFlags", &rsn)
+	ap.secure = flags&0x1 != 0 || wpa != 0 || rsn != 0 // 0x1 is the privacy flag
+	return ap, nil
+}
+
+// activeConnections returns the connections that are currently in use.
+func (c *nmClient) activeConnections() ([]*nmConnection, error) {
+	var paths []dbus.ObjectPath
+	if err := c.property(nmPath, nmIface, "ActiveConnections", &paths); err != nil {
+		return nil, err
+	}
+
+	var list []*nmConnection
+	for _, path := range paths {
+		if conn, err := c.activeConnection(path); err == nil {
+			list = append(list, conn)
+		}
+	}
+	return list, nil
+}
+
+func (c *nmClient) activeConnection(path dbus.ObjectPath) (*nmConnection, error) {
+	conn := &nmConnection{active: path}
+	if err := c.property(path, nmActive, "Id", &conn.id); err != nil {
+		return nil, err
+	}
+	if err := c.property(path, nmActive, "Type", &conn.kind); err != nil {
+		return nil, err
+	}
+
+	_ = c.property(path, nmActive, "Connection", &conn.path)
+	return conn, nil
+}
+
+// airplaneMode returns true if all wireless radios have been turned off.
+func (c *nmClient) airplaneMode() (bool, error) {
+	var wireless bool
+	if err := c.property(nmPath, nmIface, "WirelessEnabled", &wireless); err != nil {
+		return false, err
+	}
+
+	var wwan bool
+	_ = c.property(nmPath, nmIface, "WwanEnabled", &wwan)
+	return !wireless && !wwan, nil
+}
+
+// connectWifi joins a Wi-Fi network, using a saved connection if there is one.
+// If the network needs a password then NetworkManager will ask the secret agent for it.
+func (c *nmClient) connectWifi(ap *accessPoint) error {
+	saved, err := c.savedConnections()
+	if err != nil {
+		return err
+	}
+
+	for path, settings := range saved {
+		if connectionType(settings) != nmConnectionWifi || wifiSSID(settings) != ap.ssid {
+			continue
+		}
+		return c.object(nmPath).Call(nmIface+".ActivateConnection", 0, path, ap.device, ap.path).Err
+	}
+
+	settings := map[string]map[string]dbus.Variant{
+		"connection":     {"type": dbus.MakeVariant(nmConnectionWifi), "id": dbus.MakeVariant(ap.ssid)},
+		nmConnectionWifi: {"ssid": dbus.MakeVariant([]byte(ap.ssid))},
+	}
+	if ap.secure {
+		settings[nmConnectionWifi+"-security"] = map[string]dbus.Variant{"key-mgmt": dbus.MakeVariant("wpa-psk")}
+	}
+	return c.object(nmPath).Call(nmIface+".AddAndActivateConnection", 0, settings, ap.device, ap.path).Err
+}
+
+// primaryConnection returns the connection used for the default route, or nil if there is none.
+func (c *nmClient) primaryConnection() (*nmConnection, error) {
+	var path dbus.ObjectPath
+	if err := c.property(nmPath, nmIface, "PrimaryConnection", &path); err != nil {
+		return nil, err
+	}
+	if path == "" || path == "/" {
+		return nil, nil
+	}
+
+	return c.activeConnection(path)
+}
+
+func (c *nmClient) object(path dbus.ObjectPath) dbus.BusObject {
+	return c.conn.Object(nmService, path)
+}
+
+func (c *nmClient) property(path dbus.ObjectPath, iface, name string, value interface{}) error {
+	v, err := c.object(path).GetProperty(iface + "." + name)
+	if err != nil {
+		return err
+	}
+
+	return v.Store(value)
+}
+
+func (c *nmClient) savedConnections() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
+	var paths []dbus.ObjectPath
+	if err := c.object(nmSettingsPath).Call(nmSettings+".ListConnections", 0).Store(&paths); err != nil {
+		return nil, err
+	}
+
+	saved := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant, len(paths))
+	for _, path := range paths {
+		var settings map[string]map[string]dbus.Variant
+		if err := c.object(path).Call(nmSettingsConn+".GetSettings", 0).Store(&settings); err != nil {
+			continue
+		}
+		saved[path] = settings
+	}
+	return saved, nil
+}
+
+// scan asks all wireless devices to look for networks, results arrive as access point signals.
+func (c *nmClient) scan() error {
+	devices, err := c.wirelessDevices()
+	if err != nil {
+		return err
+	}
+
+	for _, dev := range devices {
+		err = c.object(dev).Call(nmWireless+".RequestScan", 0, map[string]dbus.Variant{}).Err
+	}
+	return err
+}
+
+func (c *nmClient) setAirplaneMode(on bool) error {
+	if err := c.object(nmPath).SetProperty(nmIface+".WirelessEnabled", dbus.MakeVariant(!on)); err != nil {
+		return err
+	}
+
+	return c.object(nmPath).SetProperty(nmIface+".WwanEnabled", dbus.MakeVariant(!on))
+}
+
+// setVPN connects or disconnects a VPN connection.
+func (c *nmClient) setVPN(vpn *nmConnection, on bool) error {
+	if !on {
+		if vpn.active == "" {
+			return nil
+		}
+		return c.object(nmPath).Call(nmIface+".DeactivateConnection", 0, vpn.active).Err
+	}
+
+	return c.object(nmPath).Call(nmIface+".ActivateConnection", 0, vpn.path, dbus.ObjectPath("/"),
+		dbus.ObjectPath("/")).Err
+}
+
+// vpnConnections returns all saved VPN connections, with the active path set for any that are in use.
+func (c *nmClient) vpnConnections() ([]*nmConnection, error) {
+	saved, err := c.savedConnections()
+	if err != nil {
+		return nil, err
+	}
+	active, err := c.activeConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*nmConnection
+	for path, settings := range saved {
+		kind := connectionType(settings)
+		if kind != nmConnectionVPN && kind != nmConnectionWG {
+			continue
+		}
+
+		id, _ := settings["connection"]["id"].Value().(string)
+		vpn := &nmConnection{path: path, id: id, kind: kind}
+		for _, a := range active {
+			if a.path == path {
+				vpn.active = a.active
+			}
+		}
+		list = append(list, vpn)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].id < list[j].id
+	})
+	return list, nil
+}
+
+// unwatch stops listening for NetworkManager connection and access point signals.
+func (c *nmClient) unwatch() {
+	c.signals.Stop()
+	c.signals = nil
+}
+
+// watch calls changed whenever NetworkManager reports a change to connections or access points.
+func (c *nmClient) watch(changed func()) error {
+	var matches [][]dbus.MatchOption
+	for _, iface := range []string{nmIface, nmDevice, nmWireless} {
+		matches = append(matches, []dbus.MatchOption{dbus.WithMatchPathNamespace(nmPath), dbus.WithMatchInterface(iface)})
+	}
+	matches = append(matches, []dbus.MatchOption{dbus.WithMatchPathNamespace(nmPath),
+		dbus.WithMatchInterface("org.freedesktop.DBus.Properties"), dbus.WithMatchMember("PropertiesChanged")})
+
+	signals, err := dbusutil.WatchSignals(c.conn, matches, func(sig *dbus.Signal) {
+		if isNetworkChange(sig) {
+			changed()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.signals = signals
+	return nil
+}
+
+func (c *nmClient) wirelessDevices() ([]dbus.ObjectPath, error) {
+	var devices []dbus.ObjectPath
+	if err := c.object(nmPath).Call(nmIface+".GetDevices", 0).Store(&devices); err != nil {
+		return nil, err
+	}
+
+	var wireless []dbus.ObjectPath
+	for _, dev := range devices {
+		var kind uint32
+		if err := c.property(dev, nmDevice, "DeviceType", &kind); err == nil && kind == nmDeviceTypeWifi {
+			wireless = append(wireless, dev)
+		}
+	}
+	return wireless, nil
+}
+
+func connectionType(settings map[string]map[string]dbus.Variant) string {
+	kind, _ := settings["connection"]["type"].Value().(string)
+	return kind
+}
+
+// isNetworkChange returns true for signals that mean the connection list or access points have changed.
+func isNetworkChange(sig *dbus.Signal) bool {
+	switch sig.Name {
+	case nmIface + ".StateChanged", nmDevice + ".StateChanged",
+		nmWireless + ".AccessPointAdded", nmWireless + ".AccessPointRemoved":
+		return true
+	case "org.freedesktop.DBus.Properties.PropertiesChanged":
+		if len(sig.Body) == 0 {
+			return false
+		}
+		iface, _ := sig.Body[0].(string)
+		return iface == nmIface || iface == nmAccessPoint || iface == nmActive || iface == nmWireless
+	}
+
+	return false
+}
+
+func wifiSSID(settings map[string]map[string]dbus.Variant) string {
+	ssid, _ := settings[nmConnectionWifi]["ssid"].Value().([]byte)
+	return string(ssid)
+}
@@ -0,0 +1,455 @@
+package status
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk"
+	wmtheme "fyshos.com/fynedesk/theme"
+)
+
+var nightLightMeta = fynedesk.ModuleMetadata{
+	Name:        "Night Light",
+	NewInstance: newNightLight,
+}
+
+const (
+	nightLightManual   = "Manual"
+	nightLightSchedule = "Schedule"
+	nightLightSun      = "Sunset to Sunrise"
+
+	neutralTemperature = 6500 // the colour temperature of normal screen output, in Kelvin
+	warmestTemperature = 2500 // the colour temperature used at full strength, in Kelvin
+
+	nightLightFadeSteps = 20
+	nightLightFadeTime  = time.Second
+	nightLightInterval  = time.Minute
+	nightLightSunFade   = 30 // the minutes taken to fade in after the night starts, and out before it ends
+)
+
+// colorBalancer is implemented by window managers that can tint the output of all screens.
+type colorBalancer interface {
+	SetColorBalance(red, green, blue float64)
+}
+
+// nightLight is a module that warms the colour of screens in the evening
+type nightLight struct {
+	bar      *widget.ProgressBar
+	toggle   *widget.Button
+	settings fyne.Window
+
+	current float64 // the temperature currently applied
+	update  chan struct{}
+	stop    chan struct{}
+}
+
+func (n *nightLight) Destroy() {
+	if n.stop == nil {
+		return
+	}
+
+	close(n.stop)
+	n.stop = nil
+}
+
+func (n *nightLight) LaunchSuggestions(input string) []fynedesk.LaunchSuggestion {
+	if n.balancer() == nil {
+		return nil
+	}
+
+	lower := strings.ToLower(input)
+	for _, prefix := range []string{"night light", "nightlight", "night"} {
+		if lower != prefix && !startsWith(lower, prefix+" ") {
+			continue
+		}
+
+		val := strings.TrimSpace(lower[len(prefix):])
+		if strength, ok := parseStrength(val); ok || val == "" || val == "on" || val == "off" {
+			return []fynedesk.LaunchSuggestion{&nightLightItem{input: val, strength: strength, n: n}}
+		}
+		return nil
+	}
+
+	return nil
+}
+
+func (n *nightLight) Metadata() fynedesk.ModuleMetadata {
+	return nightLightMeta
+}
+
+func (n *nightLight) StatusAreaWidget() fyne.CanvasObject {
+	if n.balancer() == nil {
+		return nil
+	}
+
+	n.bar = &widget.ProgressBar{Max: 100, Value: float64(n.strength())}
+	n.toggle = &widget.Button{Icon: wmtheme.NightLightIcon, OnTapped: func() {
+		n.setEnabled(!n.enabled())
+	}}
+	n.refreshToggle()
+
+	less := &widget.Button{Icon: theme.ContentRemoveIcon(), Importance: widget.LowImportance, OnTapped: func() {
+		n.setStrength(n.strength() - 10)
+	}}
+	more := &widget.Button{Icon: theme.ContentAddIcon(), Importance: widget.LowImportance, OnTapped: func() {
+		n.setStrength(n.strength() + 10)
+	}}
+	settings := &widget.Button{Icon: theme.SettingsIcon(), Importance: widget.LowImportance, OnTapped: n.showSettings}
+
+	night := container.NewBorder(nil, nil, less, container.NewHBox(more, settings), n.bar)
+	return container.New(&handleNarrow{}, n.toggle, night)
+}
+
+func (n *nightLight) applyTemperature(kelvin float64) {
+	b := n.balancer()
+	if b == nil {
+		return
+	}
+
+	n.current = kelvin
+	b.SetColorBalance(colorBalance(kelvin))
+}
+
+func (n *nightLight) balancer() colorBalancer {
+	desk := fynedesk.Instance()
+	if desk == nil {
+		return nil
+	}
+
+	b, _ := desk.WindowManager().(colorBalancer)
+	return b
+}
+
+func (n *nightLight) enabled() bool {
+	return fyne.CurrentApp().Preferences().Bool("nightlight.enabled")
+}
+
+// fadeTo changes the screen temperature gradually so that the change is not distracting.
+func (n *nightLight) fadeTo(kelvin float64) {
+	start := n.current
+	if math.Abs(kelvin-start) < 1 {
+		return
+	}
+
+	for i := 1; i <= nightLightFadeSteps; i++ {
+		n.applyTemperature(start + (kelvin-start)*float64(i)/nightLightFadeSteps)
+		if i < nightLightFadeSteps {
+			time.Sleep(nightLightFadeTime / nightLightFadeSteps)
+		}
+	}
+}
+
+// level returns how far into the night it is, from 0 during the day to 1 when fully night.
+func (n *nightLight) level(now time.Time) float64 {
+	prefs := fyne.CurrentApp().Preferences()
+	switch prefs.StringWithFallback("nightlight.mode", nightLightManual) {
+	case nightLightSchedule:
+		from, err := parseClock(prefs.StringWithFallback("nightlight.from", "21:00"))
+		if err != nil {
+			return 0
+		}
+		to, err := parseClock(prefs.StringWithFallback("nightlight.to", "07:00"))
+		if err != nil {
+			return 0
+		}
+		return nightLevelAt(minuteOfDay(now), from, to, nightLightSunFade)
+	case nightLightSun:
+		lat := prefs.Float("nightlight.latitude")
+		lon := prefs.Float("nightlight.longitude")
+		rise, set := sunTimes(now, lat, lon)
+		if set.Sub(rise) <= 0 {
+			return 1 // polar night
+		} else if set.Sub(rise) >= 24*time.Hour {
+			return 0 // midnight sun
+		}
+		return nightLevelAt(minuteOfDay(now), minuteOfDay(set.In(now.Location())),
+			minuteOfDay(rise.In(now.Location())), nightLightSunFade)
+	default:
+		return 1
+	}
+}
+
+func (n *nightLight) refresh() {
+	select {
+	case n.update <- struct{}{}:
+	default: // an update is already waiting
+	}
+}
+
+func (n *nightLight) refreshToggle() {
+	if n.toggle == nil {
+		return
+	}
+
+	if n.enabled() {
+		n.toggle.Importance = widget.HighImportance
+	} else {
+		n.toggle.Importance = widget.LowImportance
+	}
+	n.toggle.Refresh()
+}
+
+func (n *nightLight) run(stop chan struct{}) {
+	ticker := time.NewTicker(nightLightInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			n.fadeTo(neutralTemperature)
+			return
+		case <-n.update:
+		case <-ticker.C:
+		}
+
+		if n.balancer() == nil {
+			continue // not running in a desktop that supports colour balance
+		}
+		n.fadeTo(n.targetTemperature(time.Now()))
+	}
+}
+
+func (n *nightLight) setEnabled(on bool) {
+	fyne.CurrentApp().Preferences().SetBool("nightlight.enabled", on)
+	n.refreshToggle()
+	n.refresh()
+}
+
+func (n *nightLight) setStrength(value int) {
+	if value < 0 {
+		value = 0
+	} else if value > 100 {
+		value = 100
+	}
+
+	fyne.CurrentApp().Preferences().SetInt("nightlight.strength", value)
+	if n.bar != nil {
+		n.bar.SetValue(float64(value))
+	}
+	n.refresh()
+}
+
+func (n *nightLight) showSettings() {
+	if n.settings != nil {
+		n.settings.Show()
+		n.settings.RequestFocus()
+		return
+	}
+
+	prefs := fyne.CurrentApp().Preferences()
+	mode := widget.NewSelect([]string{nightLightManual, nightLightSchedule, nightLightSun}, nil)
+	mode.SetSelected(prefs.StringWithFallback("nightlight.mode", nightLightManual))
+	from := widget.NewEntry()
+	from.SetText(prefs.StringWithFallback("nightlight.from", "21:00"))
+	from.Validator = validateClock
+	to := widget.NewEntry()
+	to.SetText(prefs.StringWithFallback("nightlight.to", "07:00"))
+	to.Validator = validateClock
+	lat := widget.NewEntry()
+	lat.SetText(strconv.FormatFloat(prefs.Float("nightlight.latitude"), 'f', -1, 64))
+	lat.Validator = validateDegrees(90)
+	lon := widget.NewEntry()
+	lon.SetText(strconv.FormatFloat(prefs.Float("nightlight.longitude"), 'f', -1, 64))
+	lon.Validator = validateDegrees(180)
+
+	win := fyne.CurrentApp().NewWindow("Night Light Settings")
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Mode", Widget: mode},
+			{Text: "Night From", Widget: from, HintText: "Time as HH:MM, for schedule mode"},
+			{Text: "Night To", Widget: to},
+			{Text: "Latitude", Widget: lat, HintText: "Degrees north, for sunset to sunrise mode"},
+			{Text: "Longitude", Widget: lon, HintText: "Degrees east"},
+		},
+		SubmitText: "Apply",
+		OnSubmit: func() {
+			prefs.SetString("nightlight.mode", mode.Selected)
+			prefs.SetString("nightlight.from", from.Text)
+			prefs.SetString("nightlight.to", to.Text)
+			latVal, _ := strconv.ParseFloat(lat.Text, 64)
+			prefs.SetFloat("nightlight.latitude", latVal)
+			lonVal, _ := strconv.ParseFloat(lon.Text, 64)
+			prefs.SetFloat("nightlight.longitude", lonVal)
+
+			n.refresh()
+			win.Hide()
+		},
+	}
+	win.SetContent(form)
+	win.SetCloseIntercept(win.Hide)
+	n.settings = win
+	win.Show()
+}
+
+func (n *nightLight) strength() int {
+	return fyne.CurrentApp().Preferences().IntWithFallback("nightlight.strength", 50)
+}
+
+// targetTemperature returns the screen temperature that should be used at the given time.
+func (n *nightLight) targetTemperature(now time.Time) float64 {
+	if !n.enabled() {
+		return neutralTemperature
+	}
+
+	amount := n.level(now) * float64(n.strength()) / 100
+	return neutralTemperature - amount*(neutralTemperature-warmestTemperature)
+}
+
+// newNightLight creates a new module that will warm screen colours at night
+func newNightLight() fynedesk.Module {
+	n := &nightLight{current: neutralTemperature, update: make(chan struct{}, 1), stop: make(chan struct{})}
+	go n.run(n.stop)
+	n.refresh()
+	return n
+}
+
+type nightLightItem struct {
+	input    string
+	strength int
+	n        *nightLight
+}
+
+func (i *nightLightItem) Icon() fyne.Resource {
+	return wmtheme.NightLightIcon
+}
+
+func (i *nightLightItem) Title() string {
+	switch {
+	case i.input == "on":
+		return "Night light on"
+	case i.input == "off":
+		return "Night light off"
+	case i.input != "":
+		return "Night light " + strconv.Itoa(i.strength) + "%"
+	case i.n.enabled():
+		return "Night light off"
+	}
+
+	return "Night light on"
+}
+
+func (i *nightLightItem) Launch() {
+	switch {
+	case i.input == "on":
+		i.n.setEnabled(true)
+	case i.input == "off":
+		i.n.setEnabled(false)
+	case i.input != "":
+		i.n.setStrength(i.strength)
+		i.n.setEnabled(true)
+	default:
+		i.n.setEnabled(!i.n.enabled())
+	}
+}
+
+// colorBalance returns the red, green and blue scale that makes a screen appear to have the given temperature.
+// The values are relative to the neutral temperature so that it produces no change.
+func colorBalance(kelvin float64) (red, green, blue float64) {
+	if kelvin >= neutralTemperature {
+		return 1, 1, 1
+	}
+
+	r, g, b := blackBody(kelvin)
+	nr, ng, nb := blackBody(neutralTemperature)
+	return math.Min(r/nr, 1), math.Min(g/ng, 1), math.Min(b/nb, 1)
+}
+
+// blackBody approximates the colour of light at a temperature, using the curve fit by Tanner Helland.
+func blackBody(kelvin float64) (red, green, blue float64) {
+	t := kelvin / 100
+	if t <= 66 {
+		red = 255
+		green = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		red = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		green = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+
+	switch {
+	case t >= 66:
+		blue = 255
+	case t <= 19:
+		blue = 0
+	default:
+		blue = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+
+	clamp := func(v float64) float64 {
+		return math.Max(0, math.Min(255, v)) / 255
+	}
+	return clamp(red), clamp(green), clamp(blue)
+}
+
+func minuteOfDay(t time.Time) float64 {
+	return float64(t.Hour()*60+t.Minute()) + float64(t.Second())/60
+}
+
+// nightLevelAt returns how far into the night a minute of the day is, between 0 and 1.
+// The night runs from the start minute to the end minute, possibly wrapping over midnight,
+// and fades in after it starts and out before it ends over the given number of minutes.
+func nightLevelAt(now, start, end, fade float64) float64 {
+	length := math.Mod(end-start+24*60, 24*60)
+	since := math.Mod(now-start+24*60, 24*60)
+	if since >= length {
+		return 0
+	}
+
+	level := 1.0
+	if fade > 0 {
+		level = math.Min(level, since/fade)
+		level = math.Min(level, (length-since)/fade)
+	}
+	return level
+}
+
+func parseClock(s string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return 0, errors.New("time should be written as HH:MM")
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, errors.New("hour should be between 0 and 23")
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, errors.New("minute should be between 0 and 59")
+	}
+	return float64(hour*60 + minute), nil
+}
+
+func parseStrength(s string) (int, bool) {
+	val, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if err != nil || val < 0 || val > 100 {
+		return 0, false
+	}
+
+	return val, true
+}
+
+func validateClock(s string) error {
+	_, err := parseClock(s)
+	return err
+}
+
+func validateDegrees(max float64) func(string) error {
+	return func(s string) error {
+		val, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		if val < -max || val > max {
+			return errors.New("out of range")
+		}
+		return nil
+	}
+}
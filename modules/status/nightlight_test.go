@@ -0,0 +1,68 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorBalance(t *testing.T) {
+	r, g, b := colorBalance(neutralTemperature)
+	assert.Equal(t, 1.0, r)
+	assert.Equal(t, 1.0, g)
+	assert.Equal(t, 1.0, b)
+
+	r, g, b = colorBalance(3000)
+	assert.Equal(t, 1.0, r)
+	assert.Less(t, g, 1.0)
+	assert.Less(t, b, g)
+}
+
+func TestNightLevelAt(t *testing.T) {
+	from, _ := parseClock("21:00")
+	to, _ := parseClock("07:00")
+
+	assert.Equal(t, 0.0, nightLevelAt(12*60, from, to, 30))
+	assert.Equal(t, 0.5, nightLevelAt(21*60+15, from, to, 30))
+	assert.Equal(t, 1.0, nightLevelAt(2*60, from, to, 30))
+	assert.Equal(t, 0.5, nightLevelAt(6*60+45, from, to, 30))
+	assert.Equal(t, 0.0, nightLevelAt(7*60, from, to, 30))
+
+	assert.Equal(t, 1.0, nightLevelAt(14*60, 13*60, 15*60, 0))
+}
+
+func TestParseClock(t *testing.T) {
+	val, err := parseClock("07:30")
+	assert.Nil(t, err)
+	assert.Equal(t, 450.0, val)
+
+	_, err = parseClock("24:00")
+	assert.NotNil(t, err)
+	_, err = parseClock("7")
+	assert.NotNil(t, err)
+}
+
+func TestParseStrength(t *testing.T) {
+	val, ok := parseStrength("60%")
+	assert.True(t, ok)
+	assert.Equal(t, 60, val)
+
+	_, ok = parseStrength("160")
+	assert.False(t, ok)
+	_, ok = parseStrength("on")
+	assert.False(t, ok)
+}
+
+func TestSunTimes(t *testing.T) {
+	day := time.Date(2024, time.June, 21, 12, 0, 0, 0, time.UTC)
+	rise, set := sunTimes(day, 51.5, -0.13) // London
+
+	assert.WithinDuration(t, time.Date(2024, time.June, 21, 3, 43, 0, 0, time.UTC), rise, 5*time.Minute)
+	assert.WithinDuration(t, time.Date(2024, time.June, 21, 20, 21, 0, 0, time.UTC), set, 5*time.Minute)
+
+	rise, set = sunTimes(day, 80, 0) // midnight sun
+	assert.Equal(t, 24*time.Hour, set.Sub(rise))
+	rise, set = sunTimes(day, -80, 0) // polar night
+	assert.Equal(t, time.Duration(0), set.Sub(rise))
+}
@@ -0,0 +1,31 @@
+package status
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showStatusPopUp opens a menu next to a status area item, keeping it inside the canvas.
+func showStatusPopUp(from, content fyne.CanvasObject, width float32) {
+	c := fyne.CurrentApp().Driver().CanvasForObject(from)
+	if c == nil {
+		return
+	}
+
+	scroll := container.NewVScroll(container.NewPadded(content))
+	min := scroll.Content.MinSize()
+	size := fyne.NewSize(width, fyne.Min(min.Height, c.Size().Height-theme.Padding()*4))
+	scroll.SetMinSize(size)
+
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(from)
+	pos.X = fyne.Max(theme.Padding(), fyne.Min(pos.X, c.Size().Width-size.Width-theme.Padding()*3))
+	if pos.Y+size.Height > c.Size().Height {
+		pos.Y = c.Size().Height - size.Height - theme.Padding()*3
+	} else {
+		pos.Y += from.Size().Height
+	}
+
+	widget.NewPopUp(scroll, c).ShowAtPosition(pos)
+}
@@ -0,0 +1,94 @@
+package status
+
+import (
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"fyshos.com/fynedesk"
+)
+
+// pendingPrompts lets a system service cancel the prompts that it opened, such as when pairing times out.
+type pendingPrompts struct {
+	lock   sync.Mutex
+	cancel chan struct{}
+}
+
+// cancelAll closes any prompts that are open, as if the user cancelled them.
+func (p *pendingPrompts) cancelAll() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cancel != nil {
+		close(p.cancel)
+		p.cancel = nil
+	}
+}
+
+// cancelled returns a channel that is closed when the open prompts are cancelled.
+func (p *pendingPrompts) cancelled() <-chan struct{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cancel == nil {
+		p.cancel = make(chan struct{})
+	}
+	return p.cancel
+}
+
+// showPrompt shows a modal window for a request from a system service, with an entry if one is passed,
+// and waits for the user to respond. If the action button is tapped then the text entered is returned with true.
+// An "OK" action has no cancel button. The window is closed if the cancel channel is closed first.
+func showPrompt(title, message string, entry *widget.Entry, action string, cancel <-chan struct{}) (string, bool) {
+	result := make(chan string, 1)
+	var once sync.Once
+	finish := func(text string, ok bool) {
+		once.Do(func() { // the entry may be submitted more than once before the window closes
+			if ok {
+				result <- text
+			}
+			close(result)
+		})
+	}
+	w := fyne.CurrentApp().NewWindow(title)
+
+	ok := &widget.Button{Text: action, Importance: widget.HighImportance, OnTapped: func() {
+		text := ""
+		if entry != nil {
+			text = entry.Text
+		}
+		finish(text, true)
+		w.Close()
+	}}
+	buttons := container.NewHBox(layout.NewSpacer(), ok)
+	if action != "OK" {
+		buttons.Objects = []fyne.CanvasObject{layout.NewSpacer(), widget.NewButton("Cancel", w.Close), ok}
+	}
+	w.SetOnClosed(func() {
+		finish("", false)
+	})
+
+	var content fyne.CanvasObject = layout.NewSpacer()
+	if entry != nil {
+		entry.OnSubmitted = func(string) {
+			ok.OnTapped()
+		}
+		content = entry
+	}
+	w.SetContent(container.NewBorder(widget.NewLabel(message), buttons, nil, nil, content))
+	fynedesk.Instance().WindowManager().ShowModal(w, fyne.NewSize(320, 120))
+	if entry != nil {
+		w.Canvas().Focus(entry)
+	}
+
+	select {
+	case text, done := <-result:
+		return text, done
+	case <-cancel:
+		w.Close()
+		return "", false
+	}
+}
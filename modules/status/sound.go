@@ -9,10 +9,10 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
-	"github.com/mafik/pulseaudio"
-
 	"fyshos.com/fynedesk"
+	"fyshos.com/fynedesk/internal/pulseaudio"
 	wmtheme "fyshos.com/fynedesk/theme"
+	"fyshos.com/fynedesk/wm"
 )
 
 var soundMeta = fynedesk.ModuleMetadata{
@@ -21,9 +21,11 @@ var soundMeta = fynedesk.ModuleMetadata{
 }
 
 type sound struct {
-	bar    *widget.ProgressBar
-	client *pulseaudio.Client
-	mute   *widget.Button
+	bar       *widget.ProgressBar
+	client    *pulseaudio.Client
+	mixer     *mixer
+	mute      *widget.Button
+	stopWatch chan struct{}
 }
 
 func newSound() fynedesk.Module {
@@ -67,12 +69,15 @@ func (b *sound) Shortcuts() map[*fynedesk.Shortcut]func() {
 	return map[*fynedesk.Shortcut]func(){
 		fynedesk.NewShortcut("Mute Sound", fynedesk.KeyVolumeMute, fynedesk.AnyModifier): func() {
 			b.toggleMute()
+			b.showLevel()
 		},
 		fynedesk.NewShortcut("Increase Sound Volume", fynedesk.KeyVolumeDown, fynedesk.AnyModifier): func() {
 			b.offsetValue(-5)
+			b.showLevel()
 		},
 		fynedesk.NewShortcut("Reduce Sound Volume", fynedesk.KeyVolumeUp, fynedesk.AnyModifier): func() {
 			b.offsetValue(5)
+			b.showLevel()
 		},
 	}
 }
@@ -98,9 +103,15 @@ func (b *sound) StatusAreaWidget() fyne.CanvasObject {
 		b.offsetValue(5)
 	}}
 
-	sound := container.NewBorder(nil, nil, less, more, b.bar)
+	var controls fyne.CanvasObject = more
+	if b.mixer != nil {
+		menu := &widget.Button{Icon: theme.MenuDropDownIcon(), Importance: widget.LowImportance, OnTapped: b.showMixer}
+		controls = container.NewHBox(more, menu)
+	}
+	sound := container.NewBorder(nil, nil, less, controls, b.bar)
 
 	go b.offsetValue(0)
+	b.watch()
 	return container.New(&handleNarrow{}, b.mute, sound)
 }
 
@@ -126,6 +137,36 @@ func (b *sound) offsetValue(diff int) {
 	b.setValue(value)
 }
 
+// refresh updates the volume and mute state shown, used when they are changed outside of this module.
+func (b *sound) refresh() {
+	val, err := b.value()
+	if err != nil {
+		return
+	}
+
+	b.bar.SetValue(float64(val))
+	if b.muted() {
+		b.mute.SetIcon(wmtheme.MuteIcon)
+	} else {
+		b.mute.SetIcon(wmtheme.SoundIcon)
+	}
+}
+
+// showLevel shows the current volume on screen, used after changing it with keyboard shortcuts.
+func (b *sound) showLevel() {
+	val, err := b.value()
+	if err != nil {
+		return
+	}
+
+	icon := wmtheme.SoundIcon
+	if b.muted() {
+		icon = wmtheme.MuteIcon
+		val = 0
+	}
+	wm.ShowOnScreenDisplay(icon, float64(val)/100)
+}
+
 type volItem struct {
 	input string
 	s     *sound
@@ -52,6 +52,10 @@ func (b *sound) setup() error {
 	return err
 }
 
+func (b *sound) watch() {
+	// the mixer command cannot report changes
+}
+
 func (b *sound) setValue(vol int) {
 	volStr := strconv.Itoa(vol)
 	level := volStr + ":" + volStr
@@ -0,0 +1,249 @@
+package status
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	wmtheme "fyshos.com/fynedesk/theme"
+)
+
+const mixerWidth = 320
+
+// mixerContent builds the controls for choosing devices and setting microphone and application volumes.
+func (b *sound) mixerContent() fyne.CanvasObject {
+	var items []fyne.CanvasObject
+	items = append(items, b.outputControls()...)
+	items = append(items, widget.NewSeparator())
+	items = append(items, b.inputControls()...)
+
+	if streams := b.streamControls(); len(streams) > 0 {
+		items = append(items, widget.NewSeparator(), widget.NewLabelWithStyle("Applications", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}))
+		items = append(items, streams...)
+	}
+	if profiles := b.profileControls(); len(profiles) > 0 {
+		items = append(items, widget.NewSeparator(), widget.NewLabelWithStyle("Profiles", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}))
+		items = append(items, profiles...)
+	}
+
+	return container.NewVBox(items...)
+}
+
+func (b *sound) inputControls() []fyne.CanvasObject {
+	sources, err := b.mixer.sources()
+	if err != nil {
+		fyne.LogError("Failed to list sound inputs", err)
+	}
+	if len(sources) == 0 {
+		return []fyne.CanvasObject{widget.NewLabel("No inputs")}
+	}
+
+	current := sources[0]
+	name := b.mixer.defaultSource()
+	names := make([]string, len(sources))
+	for i, src := range sources {
+		names[i] = src.Description
+		if src.Name == name {
+			current = src
+		}
+	}
+
+	input := widget.NewSelect(names, nil)
+	input.SetSelected(current.Description)
+	input.OnChanged = func(desc string) {
+		for _, src := range sources {
+			if src.Description == desc {
+				b.runMixer("Failed to set sound input", func() error { return b.mixer.setDefaultSource(src.Name) })
+				return
+			}
+		}
+	}
+
+	level := widget.NewSlider(0, 100)
+	level.Value = float64(volumePercent(current.Volume))
+	level.OnChangeEnded = func(val float64) {
+		b.runMixer("Failed to set microphone volume", func() error {
+			return b.mixer.setSourceVolume(current.Name, int(val))
+		})
+	}
+
+	mute := b.muteButton(current.Muted, func(mute bool) error {
+		return b.mixer.setSourceMute(current.Name, mute)
+	})
+	return []fyne.CanvasObject{widget.NewLabelWithStyle("Input", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		input, container.NewBorder(nil, nil, mute, nil, level)}
+}
+
+func (b *sound) muteButton(muted bool, set func(bool) error) *widget.Button {
+	button := &widget.Button{Icon: wmtheme.SoundIcon, Importance: widget.LowImportance}
+	if muted {
+		button.Icon = wmtheme.MuteIcon
+	}
+	button.OnTapped = func() {
+		muted = !muted
+		if muted {
+			button.SetIcon(wmtheme.MuteIcon)
+		} else {
+			button.SetIcon(wmtheme.SoundIcon)
+		}
+		b.runMixer("Failed to change mute", func() error { return set(muted) })
+	}
+	return button
+}
+
+func (b *sound) outputControls() []fyne.CanvasObject {
+	sinks, err := b.mixer.sinks()
+	if err != nil {
+		fyne.LogError("Failed to list sound outputs", err)
+	}
+	if len(sinks) == 0 {
+		return []fyne.CanvasObject{widget.NewLabel("No outputs")}
+	}
+
+	current := sinks[0]
+	name := b.mixer.defaultSink()
+	names := make([]string, len(sinks))
+	for i, sink := range sinks {
+		names[i] = sink.Description
+		if sink.Name == name {
+			current = sink
+		}
+	}
+
+	output := widget.NewSelect(names, nil)
+	output.SetSelected(current.Description)
+	output.OnChanged = func(desc string) {
+		for _, sink := range sinks {
+			if sink.Description == desc {
+				b.runMixer("Failed to set sound output", func() error { return b.mixer.setDefaultSink(sink.Name) })
+				return
+			}
+		}
+	}
+
+	items := []fyne.CanvasObject{widget.NewLabelWithStyle("Output", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		output}
+	if len(current.Ports) > 1 {
+		ports := make([]string, len(current.Ports))
+		for i, p := range current.Ports {
+			ports[i] = p.Description
+		}
+		port := widget.NewSelect(ports, nil)
+		for _, p := range current.Ports {
+			if p.Name == current.ActivePort {
+				port.SetSelected(p.Description)
+			}
+		}
+		port.OnChanged = func(desc string) {
+			for _, p := range current.Ports {
+				if p.Description == desc {
+					b.runMixer("Failed to set output port", func() error { return b.mixer.setSinkPort(current.Name, p.Name) })
+					return
+				}
+			}
+		}
+		items = append(items, port)
+	}
+
+	return items
+}
+
+func (b *sound) profileControls() []fyne.CanvasObject {
+	cards, err := b.mixer.cards()
+	if err != nil {
+		fyne.LogError("Failed to list sound cards", err)
+		return nil
+	}
+
+	var items []fyne.CanvasObject
+	for _, c := range cards {
+		card := c
+		names := cardProfiles(card)
+		if len(names) < 2 {
+			continue
+		}
+
+		descs := make([]string, len(names))
+		for i, name := range names {
+			descs[i] = card.Profiles[name].Description
+		}
+		profile := widget.NewSelect(descs, nil)
+		if card.ActiveProfile != nil {
+			profile.SetSelected(card.ActiveProfile.Description)
+		}
+		profile.OnChanged = func(desc string) {
+			for _, name := range names {
+				if card.Profiles[name].Description == desc {
+					b.runMixer("Failed to set card profile", func() error {
+						return b.mixer.setCardProfile(card, name)
+					})
+					return
+				}
+			}
+		}
+		items = append(items, widget.NewLabel(cardDescription(card)), profile)
+	}
+	return items
+}
+
+// runMixer applies a change in the background so that the menu stays responsive.
+func (b *sound) runMixer(failure string, change func() error) {
+	go func() {
+		if err := change(); err != nil {
+			fyne.LogError(failure, err)
+		}
+	}()
+}
+
+// showMixer opens the audio menu above or below the sound status widget.
+func (b *sound) showMixer() {
+	c := fyne.CurrentApp().Driver().CanvasForObject(b.mute)
+	if c == nil {
+		return
+	}
+
+	content := container.NewVScroll(container.NewPadded(b.mixerContent()))
+	min := content.Content.MinSize()
+	size := fyne.NewSize(mixerWidth, fyne.Min(min.Height, c.Size().Height-theme.Padding()*4))
+	content.SetMinSize(size)
+
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(b.mute)
+	pos.X = fyne.Max(theme.Padding(), fyne.Min(pos.X, c.Size().Width-size.Width-theme.Padding()*3))
+	if pos.Y+size.Height > c.Size().Height {
+		pos.Y = c.Size().Height - size.Height - theme.Padding()*3
+	} else {
+		pos.Y += b.mute.Size().Height
+	}
+
+	pop := widget.NewPopUp(content, c)
+	pop.ShowAtPosition(pos)
+}
+
+func (b *sound) streamControls() []fyne.CanvasObject {
+	streams, err := b.mixer.streams()
+	if err != nil {
+		fyne.LogError("Failed to list playing applications", err)
+		return nil
+	}
+
+	var items []fyne.CanvasObject
+	for _, s := range streams {
+		stream := s
+		level := widget.NewSlider(0, 100)
+		level.Value = float64(volumePercent(stream.Volume))
+		level.OnChangeEnded = func(val float64) {
+			b.runMixer("Failed to set application volume", func() error {
+				return b.mixer.setStreamVolume(stream.Index, int(val))
+			})
+		}
+
+		mute := b.muteButton(stream.Muted, func(mute bool) error {
+			return b.mixer.setStreamMute(stream.Index, mute)
+		})
+		items = append(items, widget.NewLabel(streamName(stream)), container.NewBorder(nil, nil, mute, nil, level))
+	}
+	return items
+}
@@ -6,13 +6,16 @@ package status
 import (
 	"fyne.io/fyne/v2"
 
-	"github.com/mafik/pulseaudio"
-
+	"fyshos.com/fynedesk/internal/pulseaudio"
 	wmtheme "fyshos.com/fynedesk/theme"
 )
 
 // Destroy tidies up resources
 func (b *sound) Destroy() {
+	if b.stopWatch != nil {
+		close(b.stopWatch)
+		b.stopWatch = nil
+	}
 	if b.client == nil {
 		return
 	}
@@ -39,6 +42,7 @@ func (b *sound) setup() error {
 		return err
 	}
 	b.client = client
+	b.mixer = newMixer(client)
 	return nil
 }
 
@@ -63,5 +67,27 @@ func (b *sound) toggleMute() {
 	} else {
 		b.mute.SetIcon(wmtheme.SoundIcon)
 	}
+}
+
+// watch listens for changes on the sound server, so the status area follows volume set by other apps.
+// The updates stop when the module is destroyed.
+func (b *sound) watch() {
+	updates, err := b.client.Updates()
+	if err != nil {
+		fyne.LogError("Unable to watch for sound changes", err)
+		return
+	}
 
+	stop := make(chan struct{})
+	b.stopWatch = stop
+	go func() {
+		for {
+			select {
+			case <-updates:
+				b.refresh()
+			case <-stop:
+				return
+			}
+		}
+	}()
 }
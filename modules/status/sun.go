@@ -0,0 +1,48 @@
+package status
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	julianEpoch = 2440587.5 // the Julian day of the Unix epoch
+	julian2000  = 2451545.0 // the Julian day of noon on 1 January 2000
+)
+
+// sunTimes calculates when the sun rises and sets on a day at the given latitude and longitude.
+// It uses the sunrise equation so that no network lookup is required, results are within a few minutes.
+// In polar day the sun sets 24 hours after it rises and in polar night it sets at the same time that it rises.
+func sunTimes(day time.Time, lat, lon float64) (rise, set time.Time) {
+	y, m, d := day.Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	n := math.Round(toJulian(noon) - julian2000 + 0.0008)
+
+	meanNoon := n - lon/360
+	anomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	centre := 1.9148*sin(anomaly) + 0.02*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	longitude := math.Mod(anomaly+centre+180+102.9372, 360)
+	transit := julian2000 + meanNoon + 0.0053*sin(anomaly) - 0.0069*sin(2*longitude)
+
+	declination := math.Asin(sin(longitude) * sin(23.4397))
+	hourAngle := (sin(-0.833) - sin(lat)*math.Sin(declination)) / (cos(lat) * math.Cos(declination))
+	hourAngle = math.Acos(math.Max(-1, math.Min(1, hourAngle))) * 180 / math.Pi
+
+	return fromJulian(transit - hourAngle/360), fromJulian(transit + hourAngle/360)
+}
+
+func cos(degrees float64) float64 {
+	return math.Cos(degrees * math.Pi / 180)
+}
+
+func fromJulian(day float64) time.Time {
+	return time.Unix(int64(math.Round((day-julianEpoch)*86400)), 0)
+}
+
+func sin(degrees float64) float64 {
+	return math.Sin(degrees * math.Pi / 180)
+}
+
+func toJulian(t time.Time) float64 {
+	return float64(t.Unix())/86400 + julianEpoch
+}
@@ -0,0 +1,159 @@
+package status
+
+import (
+	"sort"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"fyshos.com/fynedesk/internal/dbusutil"
+)
+
+const (
+	upowerService     = "org.freedesktop.UPower"
+	upowerPath        = "/org/freedesktop/UPower"
+	upowerIface       = "org.freedesktop.UPower"
+	upowerDeviceIface = upowerIface + ".Device"
+
+	upowerTypeLinePower = 1
+	upowerTypeBattery   = 2
+
+	upowerStateCharging     = 1
+	upowerStateDischarging  = 2
+	upowerStateFullyCharged = 4
+)
+
+// powerDevice describes a battery reported by UPower, this may be a system battery or one in a peripheral.
+type powerDevice struct {
+	path        dbus.ObjectPath
+	kind, state uint32
+	model       string
+	percent     float64
+	powerSupply bool // true for batteries that power the computer
+	toEmpty     time.Duration
+	toFull      time.Duration
+}
+
+func (d *powerDevice) charging() bool {
+	return d.state == upowerStateCharging || d.state == upowerStateFullyCharged
+}
+
+// upowerClient talks to UPower over D-Bus.
+type upowerClient struct {
+	conn    *dbus.Conn
+	signals *dbusutil.SignalWatch
+}
+
+func newUPowerClient(conn *dbus.Conn) *upowerClient {
+	return &upowerClient{conn: conn}
+}
+
+// batteries returns all batteries that are present, system batteries first and then peripherals by name.
+func (c *upowerClient) batteries() ([]*powerDevice, error) {
+	var paths []dbus.ObjectPath
+	if err := c.conn.Object(upowerService, upowerPath).Call(upowerIface+".EnumerateDevices", 0).Store(&paths); err != nil {
+		return nil, err
+	}
+
+	var list []*powerDevice
+	for _, path := range paths {
+		dev, err := c.device(path)
+		if err != nil || dev.kind == upowerTypeLinePower {
+			continue
+		}
+
+		var present bool
+		if err = c.property(path, "IsPresent", &present); err == nil && !present {
+			continue
+		}
+		list = append(list, dev)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].powerSupply != list[j].powerSupply {
+			return list[i].powerSupply
+		}
+		return list[i].model < list[j].model
+	})
+	return list, nil
+}
+
+func (c *upowerClient) device(path dbus.ObjectPath) (*powerDevice, error) {
+	props := make(map[string]dbus.Variant)
+	err := c.conn.Object(upowerService, path).Call("org.freedesktop.DBus.Properties.GetAll", 0, upowerDeviceIface).
+		Store(&props)
+	if err != nil {
+		return nil, err
+	}
+
+	dev := &powerDevice{path: path}
+	dev.kind, _ = props["Type"].Value().(uint32)
+	dev.state, _ = props["State"].Value().(uint32)
+	dev.model, _ = props["Model"].Value().(string)
+	dev.percent, _ = props["Percentage"].Value().(float64)
+	dev.powerSupply, _ = props["PowerSupply"].Value().(bool)
+	toEmpty, _ := props["TimeToEmpty"].Value().(int64)
+	dev.toEmpty = time.Duration(toEmpty) * time.Second
+	toFull, _ := props["TimeToFull"].Value().(int64)
+	dev.toFull = time.Duration(toFull) * time.Second
+	return dev, nil
+}
+
+// displayDevice returns the combined state of all system batteries.
+func (c *upowerClient) displayDevice() (*powerDevice, error) {
+	var path dbus.ObjectPath
+	if err := c.conn.Object(upowerService, upowerPath).Call(upowerIface+".GetDisplayDevice", 0).Store(&path); err != nil {
+		return nil, err
+	}
+
+	return c.device(path)
+}
+
+func (c *upowerClient) property(path dbus.ObjectPath, name string, value interface{}) error {
+	v, err := c.conn.Object(upowerService, path).GetProperty(upowerDeviceIface + "." + name)
+	if err != nil {
+		return err
+	}
+
+	return v.Store(value)
+}
+
+// unwatch stops listening for battery and power device signals.
+func (c *upowerClient) unwatch() {
+	c.signals.Stop()
+	c.signals = nil
+}
+
+// watch calls changed whenever a battery changes or a device is added or removed.
+func (c *upowerClient) watch(changed func()) error {
+	signals, err := dbusutil.WatchSignals(c.conn, [][]dbus.MatchOption{
+		{dbus.WithMatchPathNamespace(upowerPath), dbus.WithMatchInterface(upowerIface)},
+		{dbus.WithMatchPathNamespace(upowerPath), dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged")},
+	}, func(sig *dbus.Signal) {
+		if isPowerChange(sig) {
+			changed()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.signals = signals
+	return nil
+}
+
+// isPowerChange returns true for signals that mean a battery or the list of devices has changed.
+func isPowerChange(sig *dbus.Signal) bool {
+	switch sig.Name {
+	case upowerIface + ".DeviceAdded", upowerIface + ".DeviceRemoved":
+		return true
+	case "org.freedesktop.DBus.Properties.PropertiesChanged":
+		if len(sig.Body) == 0 {
+			return false
+		}
+		iface, _ := sig.Body[0].(string)
+		return iface == upowerIface || iface == upowerDeviceIface
+	}
+
+	return false
+}
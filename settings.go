@@ -1,21 +1,35 @@
 package fynedesk
 
-import "fyne.io/fyne/v2"
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+)
 
 // DeskSettings describes the configuration options available for Fyne desktop
 type DeskSettings interface {
 	Background() string
+	BackgroundFor(screen string, desk int) string
+	BackgroundMode() string
+	BackgroundSlideshowInterval() time.Duration
 	IconTheme() string
 	BorderButtonPosition() string
 	ClockFormatting() string
 	NarrowWidgetPanel() bool
 	NarrowLeftLauncher() bool
+	WidgetPanelPosition() string
 
 	LauncherIcons() []string
 	LauncherIconSize() float32
 	LauncherDisableTaskbar() bool
 	LauncherDisableZoom() bool
 	LauncherZoomScale() float32
+	LauncherPosition() string
+	LauncherAutoHide() bool
+	LauncherWindowList() bool
+	LauncherAllScreens() bool
+	LauncherScreens() []string
+	LauncherTaskbarScreenOnly() bool
 
 	KeyboardModifier() fyne.KeyModifier
 	ModuleNames() []string
@@ -27,6 +27,10 @@ func (tad *testAppData) Run([]string) error {
 	return nil
 }
 
+func (tad *testAppData) Actions() []fynedesk.AppAction {
+	return nil
+}
+
 func (tad *testAppData) Categories() []string {
 	return tad.categories
 }
@@ -59,6 +63,10 @@ func NewAppProvider(appNames ...string) fynedesk.ApplicationProvider {
 	return provider
 }
 
+func (tap *testAppProvider) AppsForMime(string) []fynedesk.AppData {
+	return nil
+}
+
 func (tap *testAppProvider) AvailableApps() []fynedesk.AppData {
 	return tap.apps
 }
@@ -88,6 +96,10 @@ func (tap *testAppProvider) FindAppsMatching(pattern string) []fynedesk.AppData
 	return ret
 }
 
+func (tap *testAppProvider) DefaultAppForMime(string) fynedesk.AppData {
+	return nil
+}
+
 func (tap *testAppProvider) DefaultApps() []fynedesk.AppData {
 	return nil
 }
@@ -1,6 +1,9 @@
 package test
 
 import (
+	"strconv"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyshos.com/fynedesk"
 )
@@ -8,18 +11,28 @@ import (
 // Settings is a simple struct for managing settings within our tests
 type Settings struct {
 	background             string
+	backgrounds            map[string]string
+	backgroundMode         string
+	backgroundInterval     time.Duration
 	iconTheme              string
 	launcherIcons          []string
 	launcherIconSize       float32
 	launcherZoomScale      float32
 	launcherDisableZoom    bool
 	launcherDisableTaskbar bool
+	launcherPosition       string
+	launcherAutoHide       bool
+	launcherWindowList     bool
+	launcherAllScreens     bool
+	launcherScreens        []string
+	launcherScreenTaskbar  bool
 	borderButtonPosition   string
 	clockFormatting        string
 
 	moduleNames []string
 
 	narrowPanel, narrowLeftLauncher bool
+	widgetPanelPosition             string
 }
 
 // NewSettings returns an in-memory settings instance
@@ -41,6 +54,52 @@ func (s *Settings) SetBackground(bg string) {
 	s.background = bg
 }
 
+// BackgroundFor returns the background configured for a screen and desktop, falling back to the default background
+func (s *Settings) BackgroundFor(screen string, desk int) string {
+	if bg, ok := s.backgrounds[screen+"/"+strconv.Itoa(desk)]; ok {
+		return bg
+	}
+	if bg, ok := s.backgrounds[screen+"/"]; ok {
+		return bg
+	}
+	if bg, ok := s.backgrounds["/"+strconv.Itoa(desk)]; ok {
+		return bg
+	}
+	return s.background
+}
+
+// SetBackgroundFor configures a background for a screen and desktop, an empty screen name or negative desktop matches all
+func (s *Settings) SetBackgroundFor(screen string, desk int, bg string) {
+	if s.backgrounds == nil {
+		s.backgrounds = make(map[string]string)
+	}
+	key := screen + "/"
+	if desk >= 0 {
+		key += strconv.Itoa(desk)
+	}
+	s.backgrounds[key] = bg
+}
+
+// BackgroundMode returns the way that background images are scaled to fit the screen
+func (s *Settings) BackgroundMode() string {
+	return s.backgroundMode
+}
+
+// SetBackgroundMode configures the way that background images are scaled
+func (s *Settings) SetBackgroundMode(mode string) {
+	s.backgroundMode = mode
+}
+
+// BackgroundSlideshowInterval returns how long each image is shown when the background is a directory
+func (s *Settings) BackgroundSlideshowInterval() time.Duration {
+	return s.backgroundInterval
+}
+
+// SetBackgroundSlideshowInterval configures how long each image is shown when the background is a directory
+func (s *Settings) SetBackgroundSlideshowInterval(interval time.Duration) {
+	s.backgroundInterval = interval
+}
+
 // IconTheme returns the configured icon theme
 func (s *Settings) IconTheme() string {
 	return s.iconTheme
@@ -107,6 +166,73 @@ func (s *Settings) SetLauncherZoomScale(scale float32) {
 	s.launcherZoomScale = scale
 }
 
+// LauncherPosition returns the screen edge that the app bar is shown on.
+// If not set this is "Left" for a narrow launcher and "Bottom" otherwise.
+func (s *Settings) LauncherPosition() string {
+	if s.launcherPosition != "" {
+		return s.launcherPosition
+	}
+	if s.narrowLeftLauncher {
+		return "Left"
+	}
+	return "Bottom"
+}
+
+// SetLauncherPosition allows tests to specify the screen edge that the app bar is shown on
+func (s *Settings) SetLauncherPosition(pos string) {
+	s.launcherPosition = pos
+}
+
+// LauncherAutoHide returns true if the app bar should hide when windows cover it
+func (s *Settings) LauncherAutoHide() bool {
+	return s.launcherAutoHide
+}
+
+// SetLauncherAutoHide allows configuring whether the app bar hides when windows cover it
+func (s *Settings) SetLauncherAutoHide(hide bool) {
+	s.launcherAutoHide = hide
+}
+
+// LauncherWindowList returns true if the taskbar should list window titles instead of icons
+func (s *Settings) LauncherWindowList() bool {
+	return s.launcherWindowList
+}
+
+// SetLauncherWindowList allows configuring whether the taskbar lists window titles
+func (s *Settings) SetLauncherWindowList(list bool) {
+	s.launcherWindowList = list
+}
+
+// LauncherAllScreens returns true if an app bar should be shown on every screen
+func (s *Settings) LauncherAllScreens() bool {
+	return s.launcherAllScreens
+}
+
+// SetLauncherAllScreens allows configuring whether an app bar is shown on every screen
+func (s *Settings) SetLauncherAllScreens(all bool) {
+	s.launcherAllScreens = all
+}
+
+// LauncherScreens returns the names of additional screens that should show an app bar
+func (s *Settings) LauncherScreens() []string {
+	return s.launcherScreens
+}
+
+// SetLauncherScreens configures the additional screens that should show an app bar
+func (s *Settings) SetLauncherScreens(names []string) {
+	s.launcherScreens = names
+}
+
+// LauncherTaskbarScreenOnly returns true if each taskbar should only list windows on its own screen
+func (s *Settings) LauncherTaskbarScreenOnly() bool {
+	return s.launcherScreenTaskbar
+}
+
+// SetLauncherTaskbarScreenOnly allows configuring whether each taskbar lists only windows on its screen
+func (s *Settings) SetLauncherTaskbarScreenOnly(only bool) {
+	s.launcherScreenTaskbar = only
+}
+
 // KeyboardModifier returns the preferred keyboard modifier for shortcuts.
 func (s *Settings) KeyboardModifier() fyne.KeyModifier {
 	return fyne.KeyModifierSuper
@@ -142,6 +268,19 @@ func (s *Settings) SetNarrowWidgetPanel(narrow bool) {
 	s.narrowPanel = narrow
 }
 
+// WidgetPanelPosition returns the side of the screen that the widget panel is shown on, "Left" or "Right".
+func (s *Settings) WidgetPanelPosition() string {
+	if s.widgetPanelPosition == "" {
+		return "Right"
+	}
+	return s.widgetPanelPosition
+}
+
+// SetWidgetPanelPosition allows tests to specify which side the widget panel is shown on.
+func (s *Settings) SetWidgetPanelPosition(pos string) {
+	s.widgetPanelPosition = pos
+}
+
 // BorderButtonPosition returns the position of the toolbar buttons.
 func (s *Settings) BorderButtonPosition() string {
 	return s.borderButtonPosition
@@ -14,6 +14,30 @@ var resourceBatterySvg = &fyne.StaticResource{
 	StaticContent: batterySvg,
 }
 
+//go:embed assets/battery_charging.svg
+var batteryChargingSvg []byte
+
+var resourceBatteryChargingSvg = &fyne.StaticResource{
+	StaticName:    "battery_charging.svg",
+	StaticContent: batteryChargingSvg,
+}
+
+//go:embed assets/bluetooth.svg
+var bluetoothSvg []byte
+
+var resourceBluetoothSvg = &fyne.StaticResource{
+	StaticName:    "bluetooth.svg",
+	StaticContent: bluetoothSvg,
+}
+
+//go:embed assets/bluetooth_off.svg
+var bluetoothOffSvg []byte
+
+var resourceBluetoothOffSvg = &fyne.StaticResource{
+	StaticName:    "bluetooth_off.svg",
+	StaticContent: bluetoothOffSvg,
+}
+
 //go:embed assets/brightness.svg
 var brightnessSvg []byte
 
@@ -126,6 +150,14 @@ var resourceMuteSvg = &fyne.StaticResource{
 	StaticContent: muteSvg,
 }
 
+//go:embed assets/nightlight.svg
+var nightlightSvg []byte
+
+var resourceNightlightSvg = &fyne.StaticResource{
+	StaticName:    "nightlight.svg",
+	StaticContent: nightlightSvg,
+}
+
 //go:embed assets/person.svg
 var personSvg []byte
 
@@ -134,6 +166,14 @@ var resourcePersonSvg = &fyne.StaticResource{
 	StaticContent: personSvg,
 }
 
+//go:embed assets/pin.svg
+var pinSvg []byte
+
+var resourcePinSvg = &fyne.StaticResource{
+	StaticName:    "pin.svg",
+	StaticContent: pinSvg,
+}
+
 //go:embed assets/pointer.png
 var pointerPng []byte
 
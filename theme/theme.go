@@ -23,6 +23,12 @@ var (
 
 	// BatteryIcon is the material design icon for battery in light and dark theme
 	BatteryIcon = theme.NewThemedResource(resourceBatterySvg)
+	// BatteryChargingIcon is the material design icon for a charging battery in light and dark theme
+	BatteryChargingIcon = theme.NewThemedResource(resourceBatteryChargingSvg)
+	// BluetoothIcon is the material design icon for bluetooth in light and dark theme
+	BluetoothIcon = theme.NewThemedResource(resourceBluetoothSvg)
+	// BluetoothOffIcon is the material design icon for bluetooth turned off in light and dark theme
+	BluetoothOffIcon = theme.NewThemedResource(resourceBluetoothOffSvg)
 	// BrightnessIcon is the material design icon for brightness in light and dark theme
 	BrightnessIcon = theme.NewThemedResource(resourceBrightnessSvg)
 	// CalculateIcon is the material design icon for a calculator in light and dark theme
@@ -37,6 +43,10 @@ var (
 	WifiIcon = theme.NewThemedResource(resourceWifiSvg)
 	// WifiOffIcon is the material design icon for a wireless device without a connection
 	WifiOffIcon = theme.NewThemedResource(resourceWifioffSvg)
+	// NightLightIcon is the material design icon for night light in light and dark theme
+	NightLightIcon = theme.NewThemedResource(resourceNightlightSvg)
+	// PinIcon is the material design icon for pinning an item in light and dark theme
+	PinIcon = theme.NewThemedResource(resourcePinSvg)
 	// PowerIcon is the material design icon for a power connection in light and dark theme
 	PowerIcon = theme.NewThemedResource(resourcePowerSvg)
 	// UserIcon is the material design icon for a user in light and dark theme
@@ -40,6 +40,13 @@ func CallMethod(in []interface{}, path, iface, meth string) ([]interface{}, erro
 // return types, in which case a non-nil error will send an error message
 // instead of the object response.
 func RegisterService(obj interface{}, path, iface string) error {
+	return RegisterNamedService(obj, path, iface, iface)
+}
+
+// RegisterNamedService exports an object in the same way as RegisterService but
+// requests a bus name that is different to the interface name. This allows a
+// single service name to offer many interfaces, as portal backends do.
+func RegisterNamedService(obj interface{}, path, iface, name string) error {
 	conn, err := dbus.SessionBus()
 	if err != nil {
 		fyne.LogError("Error accessing to shared DBus connection", err)
@@ -51,11 +58,11 @@ func RegisterService(obj interface{}, path, iface string) error {
 		return err
 	}
 
-	reply, err := conn.RequestName(iface, dbus.NameFlagDoNotQueue)
+	reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
 	if err != nil {
 		return err
 	}
-	if reply != dbus.RequestNameReplyPrimaryOwner {
+	if reply != dbus.RequestNameReplyPrimaryOwner && reply != dbus.RequestNameReplyAlreadyOwner {
 		return errors.New("name already taken")
 	}
 
@@ -0,0 +1,27 @@
+package wm
+
+import "fyne.io/fyne/v2"
+
+var osdListener func(*OnScreenDisplay)
+
+// OnScreenDisplay is a brief indicator of a level, such as volume or brightness, shown after it changes
+type OnScreenDisplay struct {
+	Icon  fyne.Resource
+	Value float64 // Value is the level to show, between 0 and 1
+}
+
+// SetOnScreenDisplayListener connects the user interface to display on screen indicators.
+// Other developers should not use this call.
+func SetOnScreenDisplayListener(listen func(*OnScreenDisplay)) {
+	osdListener = listen
+}
+
+// ShowOnScreenDisplay briefly shows the level passed with an icon to describe it.
+// This is used to confirm changes made with keyboard shortcuts, where no other interface is visible.
+func ShowOnScreenDisplay(icon fyne.Resource, value float64) {
+	if osdListener == nil {
+		return
+	}
+
+	osdListener(&OnScreenDisplay{Icon: icon, Value: value})
+}